@@ -2,52 +2,106 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"bbapp/internal/api"
+	"bbapp/internal/auth"
 	"bbapp/internal/browser"
+	"bbapp/internal/config"
+	"bbapp/internal/dance"
+	"bbapp/internal/environment"
+	"bbapp/internal/filter"
 	"bbapp/internal/fingerprint"
+	"bbapp/internal/goals"
+	"bbapp/internal/leaderboard"
 	"bbapp/internal/listener"
 	"bbapp/internal/logger"
+	"bbapp/internal/message"
 	"bbapp/internal/overlayserver"
 	"bbapp/internal/profile"
+	"bbapp/internal/report"
+	"bbapp/internal/rules"
 	"bbapp/internal/session"
+	"bbapp/internal/sinks"
 	"bbapp/internal/stomp"
 
 	"github.com/joho/godotenv"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// reportsDir is where end-of-session reports are written (<room>/<session>.{json,csv,html})
+const reportsDir = "./reports"
+
+const (
+	stopTimeout     = 5 * time.Second  // Bounds BB-Core calls when the user stops a room
+	commandTimeout  = 5 * time.Second  // Bounds BB-Core calls of control channel commands
+	shutdownTimeout = 10 * time.Second // Shared by all rooms stopped on app shutdown
+	loginTimeout    = 10 * time.Second // Bounds the token refresh of the startup auto-login
+)
+
+// credentialsDir holds one encrypted login per account (<accountId>/credentials.enc, see auth.Manager)
+const credentialsDir = "./data/auth"
+
+// fingerprintPath keeps the device fingerprint stable across small hardware changes (see fingerprint.Generate)
+const fingerprintPath = "./data/fingerprint.json"
+
+// environmentsDir holds the BB-Core environments and accounts (see environment.Store)
+const environmentsDir = "./data"
+
+// defaultOfflineGrace is how long after the last trial validation streams may start while BB-Core
+// is unreachable. BB_OFFLINE_GRACE overrides it (e.g. "6h", "0" disables offline starts).
+const defaultOfflineGrace = 24 * time.Hour
+
+// defaultTokenTTL is assumed when BB-Core does not say when an access token expires
+const defaultTokenTTL = time.Hour
+
 // App struct
 type App struct {
 	ctx            context.Context
 	browserMgr     *browser.Manager
 	stompClient    *stomp.Client
 	logger         *logger.Logger
-	listeners      map[string]*listener.BigoListener
-	cancels        map[string]context.CancelFunc
-	session        *session.Manager
+	listeners      map[string]map[string]*listener.BigoListener // roomId -> bigoRoomId -> listener
+	cancels        map[string]map[string]context.CancelFunc     // roomId -> bigoRoomId -> cancel
+	sessions       *session.Registry                            // One session manager per BB-Core room
 	heartbeat      *session.Heartbeat
 	deviceHash     string
+	device         api.DeviceIdentity // Fingerprint reported to BB-Core, including the previous hash while migrating
 	mutex          sync.RWMutex
 	overlayServer  *overlayserver.Server
+	controlToken   string // Grants operator commands on the overlay control channel
 	bbCoreURL      string
+	stompURL       string             // STOMP endpoint of the active environment
+	envStore       *environment.Store // BB-Core environments and the accounts stored for them
 	apiClient      *api.Client
+	authManager    *auth.Manager          // Persists the login across launches
+	authRefresher  *auth.Refresher        // Refreshes the access token before it expires
+	entitlements   *auth.EntitlementCache // Last trial validation of the account, for offline starts
 	profileManager *profile.Manager
 	giftLibrary    []api.GiftDefinition
-	currentConfig  *api.Config // Cache for internal use
+	configs        map[string]*api.Config // roomId -> config cache for internal use
 }
 
 // NewApp creates new App
 func NewApp() *App {
 	return &App{
-		listeners: make(map[string]*listener.BigoListener),
-		cancels:   make(map[string]context.CancelFunc),
+		listeners: make(map[string]map[string]*listener.BigoListener),
+		cancels:   make(map[string]map[string]context.CancelFunc),
+		sessions:  session.NewRegistry(0), // Quota is applied from Agency.MaxRooms on login
+		configs:   make(map[string]*api.Config),
 	}
 }
 
@@ -75,6 +129,15 @@ func (a *App) startup(ctx context.Context) {
 		log.Fatal("Failed to start overlay server:", err)
 	}
 	a.overlayServer = server
+	server.SetLeaderboardProvider(a.overlayLeaderboard)
+
+	// Connections without the token may only subscribe and ack alerts
+	a.controlToken, err = newControlToken()
+	if err != nil {
+		log.Fatal("Failed to create control token:", err)
+	}
+	server.SetControlToken(a.controlToken, overlayserver.OperatorPermissions)
+	server.SetCommandHandler(a.handleControlCommand)
 
 	// Start server in background
 	go func() {
@@ -103,6 +166,13 @@ func (a *App) startup(ctx context.Context) {
 	a.profileManager = profile.NewManager(profileDir)
 	fmt.Println("[App] Profile manager initialized (data stored in ./data/profiles)")
 
+	// Select the active environment and restore its login so the user does not have to log in on every launch
+	if err := a.initAccounts(bbCoreURL); err != nil {
+		fmt.Printf("[App] WARNING: Login persistence unavailable: %v\n", err)
+	} else if err := a.restoreLogin(); err != nil {
+		fmt.Printf("[App] WARNING: Stored login not restored: %v\n", err)
+	}
+
 	// Create debug frames directory
 	if err := os.MkdirAll("./debug_frames", 0755); err != nil {
 		fmt.Printf("[App] WARNING: Could not create debug_frames directory: %v\n", err)
@@ -125,6 +195,10 @@ func (a *App) startup(ctx context.Context) {
 func (a *App) shutdown(ctx context.Context) {
 	fmt.Println("[App] Shutting down BBapp...")
 
+	if a.authRefresher != nil {
+		a.authRefresher.Stop()
+	}
+
 	if a.stompClient != nil {
 		fmt.Println("[App] Disconnecting from BB-Core STOMP...")
 		a.stompClient.Disconnect()
@@ -132,9 +206,7 @@ func (a *App) shutdown(ctx context.Context) {
 
 	// Ensure all browser instances are closed
 	fmt.Println("[App] Stopping all sessions and browsers...")
-	if err := a.StopPKSession("App shutdown"); err != nil {
-		fmt.Printf("[App] WARNING: Error stopping sessions during shutdown: %v\n", err)
-	}
+	a.stopAllRooms("App shutdown")
 
 	if a.logger != nil {
 		fmt.Println("[App] Closing activity logger...")
@@ -144,6 +216,25 @@ func (a *App) shutdown(ctx context.Context) {
 	fmt.Println("[App] Shutdown complete")
 }
 
+// stopAllRooms stops every room; all rooms share one deadline so this stays fast during a BB-Core outage
+func (a *App) stopAllRooms(reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Rooms stop in parallel so one slow room doesn't use up the deadline of the others
+	var wg sync.WaitGroup
+	for _, roomId := range a.sessions.RoomIds() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := a.stopPKSession(ctx, roomId, reason); err != nil {
+				fmt.Printf("[App] WARNING: Error stopping room %s (%s): %v\n", roomId, reason, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 // ConnectToCore connects to BB-Core STOMP
 func (a *App) ConnectToCore(url, username, password string) error {
 	fmt.Printf("[App] Connecting to BB-Core STOMP at: %s\n", url)
@@ -152,7 +243,13 @@ func (a *App) ConnectToCore(url, username, password string) error {
 		fmt.Println("[App] Using authentication token")
 	}
 
-	client, err := stomp.NewClient(url, username, password)
+	// When connecting with the logged-in token, follow its refreshes on reconnect
+	var opts []stomp.Option
+	if a.apiClient != nil && username != "" && username == a.apiClient.GetAccessToken() {
+		opts = append(opts, stomp.WithTokenProvider(a.apiClient))
+	}
+
+	client, err := stomp.NewClient(url, username, password, opts...)
 	if err != nil {
 		fmt.Printf("[App] ERROR: STOMP connection failed: %v\n", err)
 		return fmt.Errorf("connection failed: %w", err)
@@ -167,11 +264,17 @@ func (a *App) ConnectToCore(url, username, password string) error {
 func (a *App) AddStreamer(bigoRoomId, teamId, roomId string) error {
 	fmt.Printf("[App] AddStreamer called: bigoRoom=%s, team=%s, room=%s\n", bigoRoomId, teamId, roomId)
 
+	// Events go through the room's session so filter rules apply and BB-Core gets them on the room's connection
+	sess, err := a.ensureSessionManager(roomId)
+	if err != nil {
+		return err
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
 	// Check if already exists
-	if _, exists := a.listeners[bigoRoomId]; exists {
+	if _, exists := a.listeners[roomId][bigoRoomId]; exists {
 		fmt.Printf("[App] ERROR: Already monitoring room %s\n", bigoRoomId)
 		return fmt.Errorf("already monitoring room %s", bigoRoomId)
 	}
@@ -186,7 +289,7 @@ func (a *App) AddStreamer(bigoRoomId, teamId, roomId string) error {
 	fmt.Printf("[App] ✓ Browser created successfully\n")
 
 	// Store cancel function for cleanup
-	a.cancels[bigoRoomId] = cancel
+	a.roomCancels(roomId)[bigoRoomId] = cancel
 
 	// Create listener
 	bigoListener := listener.NewBigoListener(bigoRoomId, ctx)
@@ -227,37 +330,8 @@ func (a *App) AddStreamer(bigoRoomId, teamId, roomId string) error {
 			fmt.Printf("[App] ✓ Gift logged to file\n")
 		}
 
-		// Send to BB-Core with COMPLETE payload
-		if a.stompClient != nil {
-			payload := map[string]interface{}{
-				"type":           "GIFT",
-				"roomId":         roomId,
-				"bigoRoomId":     gift.BigoRoomId,
-				"senderId":       gift.SenderId,
-				"senderName":     gift.SenderName,
-				"senderAvatar":   gift.SenderAvatar,
-				"senderLevel":    gift.SenderLevel,
-				"streamerId":     gift.StreamerId,
-				"streamerName":   gift.StreamerName,
-				"streamerAvatar": gift.StreamerAvatar,
-				"giftId":         gift.GiftId,
-				"giftName":       gift.GiftName,
-				"giftCount":      gift.GiftCount,
-				"diamonds":       gift.Diamonds,
-				"giftImageUrl":   gift.GiftImageUrl,
-				"timestamp":      gift.Timestamp,
-				"deviceHash":     a.deviceHash,
-			}
-
-			destination := "/app/room/" + roomId + "/bigo"
-			if err := a.stompClient.Publish(destination, payload); err != nil {
-				fmt.Printf("[App] ERROR: Failed to forward to BB-Core: %v\n", err)
-			} else {
-				fmt.Printf("[App] ✓ Gift forwarded to BB-Core: %s\n", destination)
-			}
-		} else {
-			fmt.Println("[App] WARNING: STOMP client not connected, gift not forwarded to BB-Core")
-		}
+		// Filtered for BB-Core (when the room's stream is running) and the overlay by the session
+		sess.DispatchEvent(gift)
 	})
 
 	// Setup chat handler
@@ -265,29 +339,7 @@ func (a *App) AddStreamer(bigoRoomId, teamId, roomId string) error {
 		fmt.Printf("[App] 💬 CHAT RECEIVED: %s said \"%s\" in room %s\n",
 			chat.SenderName, chat.Message, bigoRoomId)
 
-		if a.stompClient != nil {
-			payload := map[string]interface{}{
-				"type":         "CHAT",
-				"roomId":       roomId,
-				"bigoRoomId":   chat.BigoRoomId,
-				"senderId":     chat.SenderId,
-				"senderName":   chat.SenderName,
-				"senderAvatar": chat.SenderAvatar,
-				"senderLevel":  chat.SenderLevel,
-				"message":      chat.Message,
-				"timestamp":    chat.Timestamp,
-				"deviceHash":   a.deviceHash,
-			}
-
-			destination := "/app/room/" + roomId + "/bigo"
-			if err := a.stompClient.Publish(destination, payload); err != nil {
-				fmt.Printf("[App] ERROR: Failed to publish chat: %v\n", err)
-			} else {
-				fmt.Printf("[App] ✓ Chat forwarded to BB-Core: %s\n", destination)
-			}
-		} else {
-			fmt.Println("[App] WARNING: STOMP client not connected, chat not forwarded to BB-Core")
-		}
+		sess.DispatchEvent(chat)
 	})
 
 	// Start listening
@@ -295,53 +347,92 @@ func (a *App) AddStreamer(bigoRoomId, teamId, roomId string) error {
 	if _, err := bigoListener.Start(); err != nil {
 		fmt.Printf("[App] ERROR: Failed to start listener: %v\n", err)
 		cancel()
+		delete(a.cancels[roomId], bigoRoomId)
 		return err
 	}
 
-	a.listeners[bigoRoomId] = bigoListener
+	a.roomListeners(roomId)[bigoRoomId] = bigoListener
 	fmt.Printf("[App] ✓ Successfully added streamer: %s (monitoring active)\n", bigoRoomId)
 	return nil
 }
 
-// RemoveStreamer stops monitoring a streamer
-func (a *App) RemoveStreamer(bigoRoomId string) error {
-	fmt.Printf("[App] RemoveStreamer called for room: %s\n", bigoRoomId)
+// RemoveStreamer stops monitoring a streamer in a BB-Core room
+func (a *App) RemoveStreamer(bigoRoomId, roomId string) error {
+	fmt.Printf("[App] RemoveStreamer called for room: %s (BB-Core room: %s)\n", bigoRoomId, roomId)
 
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if _, exists := a.listeners[bigoRoomId]; !exists {
+	if _, exists := a.listeners[roomId][bigoRoomId]; !exists {
 		fmt.Printf("[App] ERROR: Not monitoring room %s\n", bigoRoomId)
 		return fmt.Errorf("not monitoring room %s", bigoRoomId)
 	}
 
 	// Cancel browser context to clean up
-	if cancel, exists := a.cancels[bigoRoomId]; exists && cancel != nil {
+	if cancel, exists := a.cancels[roomId][bigoRoomId]; exists && cancel != nil {
 		cancel()
-		delete(a.cancels, bigoRoomId)
+		delete(a.cancels[roomId], bigoRoomId)
 	}
 
-	delete(a.listeners, bigoRoomId)
+	delete(a.listeners[roomId], bigoRoomId)
 
 	fmt.Printf("[App] ✓ Stopped monitoring room: %s\n", bigoRoomId)
 	return nil
 }
 
-// GetConnections returns active connections
+// GetConnections returns active connections across all BB-Core rooms
 func (a *App) GetConnections() []map[string]string {
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
 
 	var connections []map[string]string
-	for roomId := range a.listeners {
-		connections = append(connections, map[string]string{
-			"bigoRoomId": roomId,
-			"status":     "connected",
-		})
+	for roomId, listeners := range a.listeners {
+		for bigoRoomId := range listeners {
+			connections = append(connections, map[string]string{
+				"roomId":     roomId,
+				"bigoRoomId": bigoRoomId,
+				"status":     "connected",
+			})
+		}
 	}
 	return connections
 }
 
+// roomListeners returns the listener map of a BB-Core room, creating it if needed (caller holds a.mutex)
+func (a *App) roomListeners(roomId string) map[string]*listener.BigoListener {
+	if a.listeners[roomId] == nil {
+		a.listeners[roomId] = make(map[string]*listener.BigoListener)
+	}
+	return a.listeners[roomId]
+}
+
+// roomCancels returns the browser cancel map of a BB-Core room, creating it if needed (caller holds a.mutex)
+func (a *App) roomCancels(roomId string) map[string]context.CancelFunc {
+	if a.cancels[roomId] == nil {
+		a.cancels[roomId] = make(map[string]context.CancelFunc)
+	}
+	return a.cancels[roomId]
+}
+
+// stopRoomListeners cancels all browser listeners of a BB-Core room and releases their
+// connections from the room's session (nil when it has none)
+func (a *App) stopRoomListeners(roomId string, sess *session.Manager, reason string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for bigoRoomId, cancel := range a.cancels[roomId] {
+		fmt.Printf("[App] Stopping browser for room: %s\n", bigoRoomId)
+		if sess != nil {
+			sess.ReleaseConnection(bigoRoomId, reason)
+		}
+		if cancel != nil {
+			cancel()
+		}
+	}
+	delete(a.listeners, roomId)
+	delete(a.cancels, roomId)
+}
+
 // StartPKSession starts a complete PK session with BB-Core integration
 // Takes roomId and config from the wizard (config already fetched and validated)
 func (a *App) StartPKSession(bbCoreUrl, authToken, roomId string, cfg api.Config, durationMinutes int) error {
@@ -359,36 +450,38 @@ func (a *App) StartPKSession(bbCoreUrl, authToken, roomId string, cfg api.Config
 
 	// Initialize API client if not already set
 	if a.apiClient == nil {
-		a.apiClient = api.NewClient(bbCoreUrl, authToken)
+		a.setAPIClient(a.newAPIClient(bbCoreUrl, authToken))
 		a.bbCoreURL = bbCoreUrl
 		fmt.Printf("[App] API client initialized\n")
 	}
 
-	// Initialize session manager
-	a.session = session.NewManager()
-	a.session.Initialize(a.apiClient, a.deviceHash)
-
 	// Force reload Gift Library to ensure freshness
 	fmt.Println("[App] Reloading Gift Library from file before session start...")
 	a.giftLibrary = a.loadGiftLibraryFromFile()
-
-	// Inject Gift Library
-	fmt.Printf("[App] Injecting Gift Library to session (Size: %d)\n", len(a.giftLibrary))
 	if len(a.giftLibrary) == 0 {
 		fmt.Println("[App] WARNING: Injecting EMPTY gift library! Overrides will not work.")
 	}
-	a.session.SetGiftLibrary(a.giftLibrary)
 
-	fmt.Printf("[App] Session manager initialized\n")
+	// Get (or create) the isolated session manager for this room
+	_, existed := a.sessions.Get(roomId)
+	sess, err := a.startableSession(roomId)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("[App] Session manager initialized for room %s\n", roomId)
 
 	// Start session (validates trial, connects STOMP, starts heartbeat)
 	// Enhanced session manager now handles everything
-	if err := a.session.Start(roomId, &cfg, bbCoreUrl, authToken, durationMinutes); err != nil {
+	if err := sess.Start(roomId, &cfg, a.stompEndpoint(bbCoreUrl), authToken, durationMinutes); err != nil {
+		if !existed {
+			a.sessions.Remove(roomId) // Created for this start only
+		}
 		return fmt.Errorf("session start failed: %w", err)
 	}
 
 	// Get all Bigo rooms from config
-	cfgMgr := a.session.GetConfig()
+	cfgMgr := sess.GetConfig()
 	bigoRooms := cfgMgr.GetAllBigoRoomIds()
 	fmt.Printf("[App] Starting %d Bigo listeners from config\n", len(bigoRooms))
 
@@ -401,74 +494,110 @@ func (a *App) StartPKSession(bbCoreUrl, authToken, roomId string, cfg api.Config
 		}
 	}
 
-	fmt.Printf("[App] ✓✓✓ PK session started successfully with %d active listeners\n", len(a.listeners))
+	a.mutex.RLock()
+	listenerCount := len(a.listeners[roomId])
+	a.mutex.RUnlock()
+
+	fmt.Printf("[App] ✓✓✓ PK session started successfully with %d active listeners\n", listenerCount)
 	return nil
 }
 
-// StopPKSession stops the current PK session
-func (a *App) StopPKSession(reason string) error {
-	fmt.Printf("[App] Stopping PK session: %s\n", reason)
+// StopPKSession stops the PK session of a BB-Core room and releases its slot
+func (a *App) StopPKSession(roomId, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	return a.stopPKSession(ctx, roomId, reason)
+}
 
-	// Stop all browser listeners
-	a.mutex.Lock()
-	for bigoRoomId, cancel := range a.cancels {
-		fmt.Printf("[App] Stopping browser for room: %s\n", bigoRoomId)
-		if cancel != nil {
-			cancel()
+// stopPKSession stops a room, bounding the BB-Core stop call by ctx
+func (a *App) stopPKSession(ctx context.Context, roomId, reason string) error {
+	fmt.Printf("[App] Stopping PK session for room %s: %s\n", roomId, reason)
+
+	// Stop session (handles heartbeat, STOMP, BB-Core notification). It goes first so the
+	// final STATUS reports the streamer rooms as DISCONNECTED before their browsers close.
+	sess := a.sessions.Remove(roomId)
+	if sess != nil {
+		if err := sess.StopContext(ctx, reason); err != nil {
+			fmt.Printf("[App] WARNING: Session stop failed: %v\n", err)
+			// Continue cleanup even if session stop fails
 		}
 	}
-	a.listeners = make(map[string]*listener.BigoListener)
-	a.cancels = make(map[string]context.CancelFunc)
-	a.mutex.Unlock()
+
+	// Stop all browser listeners of this room
+	a.stopRoomListeners(roomId, sess, reason)
 	fmt.Printf("[App] ✓ All browsers stopped\n")
 
-	// Stop session (handles heartbeat, STOMP, BB-Core notification)
-	if a.session != nil {
-		if err := a.session.Stop(reason); err != nil {
-			fmt.Printf("[App] WARNING: Session stop failed: %v\n", err)
-			// Continue cleanup even if session stop fails
+	if sess != nil {
+		// Write the end-of-session report (after Stop so BB-Core final data is included)
+		summary, err := report.Write(filepath.Join(reportsDir, roomId), sess.BuildReport(roomId, reason))
+		if err != nil {
+			fmt.Printf("[App] WARNING: Failed to write session report: %v\n", err)
+		} else {
+			fmt.Printf("[App] ✓ Session report written to %s\n", summary.HTMLPath)
 		}
-		a.session = nil
+	}
+
+	a.mutex.Lock()
+	delete(a.configs, roomId)
+	a.mutex.Unlock()
+
+	if a.overlayServer != nil {
+		a.overlayServer.ClearRoomConfig(roomId)
 	}
 
 	fmt.Printf("[App] ✓✓✓ PK session stopped successfully\n")
 	return nil
 }
 
-// ResetSession completely wipes the current session manager and creates a new one
-func (a *App) ResetSession() error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-
-	fmt.Println("[App] Force resetting session manager...")
-	if a.session != nil {
-		a.session.Stop("Force reset")
+// ResetSession completely wipes the session manager of a room and creates a new one
+func (a *App) ResetSession(roomId string) error {
+	fmt.Printf("[App] Force resetting session manager for room %s...\n", roomId)
+	if sess := a.sessions.Remove(roomId); sess != nil {
+		sess.Stop("Force reset")
 	}
 
-	a.session = session.NewManager()
-	if a.apiClient != nil {
-		a.session.Initialize(a.apiClient, a.deviceHash)
+	if _, err := a.ensureSessionManager(roomId); err != nil {
+		return err
 	}
 	fmt.Println("[App] ✓ Session manager reset")
 	return nil
 }
 
-// ensureSessionManager is a safety check to ensure session manager is initialized
-func (a *App) ensureSessionManager() error {
+// ListSessionRooms returns the BB-Core room IDs that currently have a session manager
+func (a *App) ListSessionRooms() []string {
+	return a.sessions.RoomIds()
+}
+
+// startableSession returns the session manager of a room about to start, failing when other
+// running rooms already use the agency's MaxRooms quota
+func (a *App) startableSession(roomId string) (*session.Manager, error) {
+	if err := a.sessions.CheckQuota(roomId); err != nil {
+		return nil, err
+	}
+	return a.ensureSessionManager(roomId)
+}
+
+// ensureSessionManager returns the session manager of a room, creating and wiring it if needed.
+// Idle rooms don't count against the MaxRooms quota; starts check it through startableSession.
+func (a *App) ensureSessionManager(roomId string) (*session.Manager, error) {
 	// Always inject the latest library to be safe, even if session exists
-	if a.session != nil {
+	if sess, ok := a.sessions.Get(roomId); ok {
 		if len(a.giftLibrary) > 0 {
-			a.session.SetGiftLibrary(a.giftLibrary)
+			sess.SetGiftLibrary(a.giftLibrary)
 		}
-		return nil
+		// Attach the API client if the session was created before login
+		if a.apiClient != nil {
+			sess.AttachAPIClient(a.apiClient, a.deviceHash)
+		}
+		return sess, nil
 	}
 
-	// Try to initialize using existing client
+	// Listener-only modes (e.g. Sticker Dance) work without login; BB-Core streaming needs the API client
 	if a.apiClient == nil {
-		return fmt.Errorf("session manager not initialized and API client missing - please login again")
+		fmt.Printf("[App] WARNING: No API client for room %s - BB-Core streaming unavailable until login\n", roomId)
 	}
 
-	fmt.Printf("[App] Safety initializing session manager...\n")
+	fmt.Printf("[App] Safety initializing session manager for room %s...\n", roomId)
 
 	// Generate device hash if not set
 	if a.deviceHash == "" {
@@ -480,304 +609,705 @@ func (a *App) ensureSessionManager() error {
 		}
 	}
 
-	a.session = session.NewManager()
-	a.session.Initialize(a.apiClient, a.deviceHash)
-	// Inject Gift Library
-	fmt.Printf("[App] Injecting Gift Library to safety session (Size: %d)\n", len(a.giftLibrary))
-	a.session.SetGiftLibrary(a.giftLibrary)
+	sess, err := a.sessions.GetOrCreate(roomId, func(m *session.Manager) {
+		m.Initialize(a.apiClient, a.deviceHash)
+		m.SetTrialValidator(a.validateTrial, func(reason string) {
+			a.onTrialRevoked(roomId, reason)
+		})
+		// Inject Gift Library
+		fmt.Printf("[App] Injecting Gift Library to safety session (Size: %d)\n", len(a.giftLibrary))
+		m.SetGiftLibrary(a.giftLibrary)
 
-	// Subscribe to internal listeners for SSE broadcasting
-	a.session.SubscribeOnGift(func(event interface{}) {
-		gift, ok := event.(listener.BigoGift)
-		if !ok {
-			return
-		}
+		// Subscribe to internal listeners for SSE broadcasting (after overlay filter rules)
+		m.SubscribeSink(filter.SinkOverlay, func(event interface{}) {
+			a.broadcastGiftToOverlay(roomId, event)
+		})
 
-		// Log minimal
-		fmt.Printf("[App] Internal Gift Event: %s x%d (Room: %s)\n", gift.GiftName, gift.GiftCount, gift.BigoRoomId)
-
-		// Resolve TeamId using a.currentConfig (This ensures consistency with Overlay)
-		var teamId string
-		a.mutex.RLock()
-		if a.currentConfig != nil {
-			// 1. Check Binding Gifts first (Priority)
-			if gift.GiftName != "" {
-				for _, team := range a.currentConfig.Teams {
-					if strings.EqualFold(team.BindingGift, gift.GiftName) {
-						teamId = team.TeamId
-						// fmt.Printf("[App] Resolved Team via Binding Gift: %s -> %s\n", gift.GiftName, team.TeamId)
-						break
-					}
-				}
+		// Sticker Dance performances are broadcast as start/end events
+		m.SubscribeDance(func(event dance.Event) {
+			if a.overlayServer != nil {
+				a.overlayServer.BroadcastRoomEvent(roomId, map[string]interface{}{
+					"type":   event.Type,
+					"roomId": roomId,
+					"data":   event,
+				})
 			}
+		})
 
-			// 2. Check Streamers if not found
-			if teamId == "" {
-				for _, team := range a.currentConfig.Teams {
-					for _, s := range team.Streamers {
-						// Check BigoRoomId (numeric) or BigoId (username/id)
-						if (s.BigoRoomId != "" && s.BigoRoomId == gift.BigoRoomId) ||
-							(s.BigoId != "" && strings.EqualFold(s.BigoId, gift.BigoRoomId)) {
-							teamId = team.TeamId
-							break
-						}
-					}
-					if teamId != "" {
-						break
-					}
-				}
+		// Free Mode goal progress goes to the overlay; milestones are also emitted to the UI
+		m.SubscribeGoals(func(event goals.Event) {
+			if a.overlayServer != nil {
+				a.overlayServer.BroadcastRoomEvent(roomId, map[string]interface{}{
+					"type":   event.Type,
+					"roomId": roomId,
+					"data":   event,
+				})
 			}
-		}
-		a.mutex.RUnlock()
+			if event.Type == goals.EventMilestone && a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "goal:milestone", roomId, event)
+			}
+		})
 
-		if teamId != "" {
-			fmt.Printf("[App] SSE Broadcast: Resolved %s -> %s\n", gift.BigoRoomId, teamId)
-		} else {
-			// Debug: why missing?
-			fmt.Printf("[App] SSE Broadcast WARNING: No TeamID for %s\n", gift.BigoRoomId)
-		}
+		// Trigger/action rules fire against the same events BB-Core scores
+		m.SubscribeRules(func(firing rules.Firing) {
+			a.executeRuleAction(roomId, firing)
+		})
 
-		// Construct payload for Overlay
-		// Using map to be flexible and match JS expectations
-		payload := map[string]interface{}{
-			"type":           "GIFT",
-			"roomId":         "INTERNAL", // Or get from session status if needed
-			"teamId":         teamId,     // CRITICAL: Inject Resolved ID
-			"bigoRoomId":     gift.BigoRoomId,
-			"senderId":       gift.SenderId,
-			"senderName":     gift.SenderName,
-			"senderAvatar":   gift.SenderAvatar,
-			"senderLevel":    gift.SenderLevel,
-			"streamerId":     gift.StreamerId,
-			"streamerName":   gift.StreamerName,
-			"streamerAvatar": gift.StreamerAvatar,
-			"giftId":         gift.GiftId,
-			"giftName":       gift.GiftName,
-			"giftCount":      gift.GiftCount,
-			"diamonds":       gift.Diamonds,
-			"giftImageUrl":   gift.GiftImageUrl,
-			"timestamp":      gift.Timestamp,
-		}
+		// BB-Core connection health is shown in the overlay and the UI
+		m.SubscribeStompState(func(event stomp.StateEvent) {
+			if a.overlayServer != nil {
+				a.overlayServer.BroadcastRoomEvent(roomId, map[string]interface{}{
+					"type":   "STOMP_STATE",
+					"roomId": roomId,
+					"data":   event,
+				})
+			}
+			if a.ctx != nil {
+				runtime.EventsEmit(a.ctx, "stomp:state", roomId, event)
+			}
+		})
 
-		if a.overlayServer != nil {
-			a.overlayServer.BroadcastEvent(payload)
-		}
+		// Leaderboards are pushed to overlays as deltas and written to disk when the session stops
+		m.SetLeaderboardDir(filepath.Join("./data/leaderboards", roomId))
+		m.SubscribeLeaderboard(func(delta leaderboard.Delta) {
+			if a.overlayServer != nil {
+				a.overlayServer.BroadcastRoomEvent(roomId, map[string]interface{}{
+					"type":   "LEADERBOARD_UPDATE",
+					"roomId": roomId,
+					"data":   delta,
+				})
+			}
+		})
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Printf("[App] Session manager safety initialized\n")
-	return nil
+	fmt.Printf("[App] Session manager safety initialized for room %s\n", roomId)
+	return sess, nil
 }
 
-// GetSessionStatus returns current session status
-func (a *App) GetSessionStatus() session.Status {
-	if a.session == nil {
-		return session.Status{IsActive: false}
+// broadcastGiftToOverlay forwards a gift event of a room to its overlay clients over SSE
+func (a *App) broadcastGiftToOverlay(roomId string, event interface{}) {
+	gift, ok := event.(listener.BigoGift)
+	if !ok {
+		return
+	}
+
+	// Log minimal
+	fmt.Printf("[App] Internal Gift Event: %s x%d (Room: %s)\n", gift.GiftName, gift.GiftCount, gift.BigoRoomId)
+
+	// Resolve TeamId with the session's config index, the same attribution BB-Core and leaderboards use
+	var teamId string
+	if sess, ok := a.sessions.Get(roomId); ok {
+		teamId, _ = sess.AttributeGift(gift)
+	}
+
+	if teamId != "" {
+		fmt.Printf("[App] SSE Broadcast: Resolved %s -> %s\n", gift.BigoRoomId, teamId)
+	} else {
+		// Debug: why missing?
+		fmt.Printf("[App] SSE Broadcast WARNING: No TeamID for %s\n", gift.BigoRoomId)
+	}
+
+	// Construct payload for Overlay
+	// Using map to be flexible and match JS expectations
+	payload := map[string]interface{}{
+		"type":           "GIFT",
+		"roomId":         roomId,
+		"teamId":         teamId, // CRITICAL: Inject Resolved ID
+		"bigoRoomId":     gift.BigoRoomId,
+		"senderId":       gift.SenderId,
+		"senderName":     gift.SenderName,
+		"senderAvatar":   gift.SenderAvatar,
+		"senderLevel":    gift.SenderLevel,
+		"streamerId":     gift.StreamerId,
+		"streamerName":   gift.StreamerName,
+		"streamerAvatar": gift.StreamerAvatar,
+		"giftId":         gift.GiftId,
+		"giftName":       gift.GiftName,
+		"giftCount":      gift.GiftCount,
+		"diamonds":       gift.Diamonds,
+		"giftImageUrl":   gift.GiftImageUrl,
+		"timestamp":      gift.Timestamp,
+	}
+
+	if a.overlayServer != nil {
+		a.overlayServer.BroadcastRoomEvent(roomId, payload)
 	}
-	return a.session.GetStatus()
 }
 
-// StartBigoListener starts only the Bigo listener session
-func (a *App) StartBigoListener(cfg api.Config) error {
-	fmt.Printf("[App] StartBigoListener called. Session address: %p\n", a.session)
-	if err := a.ensureSessionManager(); err != nil {
-		return err
+// GetLeaderboard returns the top gifters of a room overall, per team and per streamer (limit <= 0 means all)
+func (a *App) GetLeaderboard(roomId string, limit int) leaderboard.Snapshot {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return leaderboard.NewBoard().Snapshot(limit)
 	}
+	return sess.GetLeaderboard(limit)
+}
 
-	// Ensure library is set before starting
-	if len(a.giftLibrary) > 0 && a.session != nil {
-		a.session.SetGiftLibrary(a.giftLibrary)
+// overlayLeaderboard serves the /leaderboard endpoint; an empty roomId selects the first running room
+func (a *App) overlayLeaderboard(roomId string, limit int) (interface{}, bool) {
+	if roomId == "" {
+		rooms := a.sessions.RoomIds()
+		if len(rooms) == 0 {
+			return nil, false
+		}
+		roomId = rooms[0]
 	}
 
-	fmt.Printf("[App] Using session manager at %p\n", a.session)
-	return a.session.StartBigoListener(&cfg)
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return nil, false
+	}
+	return sess.GetLeaderboard(limit), true
 }
 
-// StopBigoListener stops only the Bigo listener session
-func (a *App) StopBigoListener() error {
-	if err := a.ensureSessionManager(); err != nil {
-		return err
+// handleControlCommand runs an operator command received on the overlay control channel
+func (a *App) handleControlCommand(cmd overlayserver.Command) (interface{}, error) {
+	sess, ok := a.sessions.Get(cmd.RoomId)
+	if !ok {
+		return nil, fmt.Errorf("room %s is not running", cmd.RoomId)
+	}
+	status := sess.GetBBCoreStreamStatus()
+	if !status.IsActive {
+		return nil, fmt.Errorf("room %s has no active BB-Core session", cmd.RoomId)
+	}
+
+	switch cmd.Name {
+	case overlayserver.CommandAddTime:
+		// BB-Core owns no timer extension, so the overlay timer applies the adjustment to the current round
+		if cmd.Seconds <= 0 {
+			return nil, fmt.Errorf("seconds must be positive, got %d", cmd.Seconds)
+		}
+		a.overlayServer.BroadcastRoomEvent(cmd.RoomId, map[string]interface{}{
+			"type":    "TIMER_ADJUST",
+			"roomId":  cmd.RoomId,
+			"seconds": cmd.Seconds,
+		})
+		return map[string]int{"seconds": cmd.Seconds}, nil
+	}
+
+	client := a.apiClient
+	if client == nil {
+		return nil, fmt.Errorf("not connected to BB-Core")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	switch cmd.Name {
+	case overlayserver.CommandPause:
+		return client.PauseSessionContext(ctx, status.SessionId)
+	case overlayserver.CommandResume:
+		return client.ResumeSessionContext(ctx, status.SessionId)
+	case overlayserver.CommandNextRound:
+		return client.NextRoundContext(ctx, status.SessionId)
+	default:
+		return nil, fmt.Errorf("unknown command %q", cmd.Name)
 	}
-	return a.session.StopBigoListener()
 }
 
-// StartBBCoreStream starts only the BB-Core streaming session
-func (a *App) StartBBCoreStream(roomId string, cfg api.Config, durationMinutes int) error {
-	if err := a.ensureSessionManager(); err != nil {
+// newControlToken returns a random operator token for the overlay control channel
+func newControlToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StartStickerDance starts Sticker Dance mode for a room using only the Bigo listener
+func (a *App) StartStickerDance(roomId string, cfg api.Config, danceCfg dance.Config) error {
+	sess, err := a.startableSession(roomId)
+	if err != nil {
 		return err
 	}
 
-	// Ensure config has correct room ID
 	cfg.RoomId = roomId
+	return sess.StartStickerDance(&cfg, danceCfg)
+}
 
-	// Save configuration to ensure backend has the latest teams data
-	// This fixes the "Room has 0 teams" error
-	if err := a.SaveBBAppConfig(roomId, cfg); err != nil {
-		fmt.Printf("[App] WARNING: Failed to save config before stream start: %v\n", err)
-		// We could return error here, but we'll try to proceed in case it was a network glitch
-		// and the config was already saved previously.
-		// return fmt.Errorf("failed to save config: %w", err)
-	} else {
-		fmt.Printf("[App] ✓ Config saved to BB-Core before stream start\n")
+// StopStickerDance stops Sticker Dance mode for a room
+func (a *App) StopStickerDance(roomId string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
 	}
+	return sess.StopStickerDance()
+}
 
-	// Get BB-Core URL and access token
-	bbCoreURL := a.bbCoreURL
-	accessToken := ""
-	if a.apiClient != nil {
-		accessToken = a.apiClient.GetAccessToken()
+// SkipStickerDance ends the current performance so the next one starts
+func (a *App) SkipStickerDance(roomId string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
 	}
+	return sess.SkipDance()
+}
 
-	return a.session.StartBBCoreStream(roomId, &cfg, bbCoreURL, accessToken, durationMinutes)
+// GetStickerDanceState returns the current and upcoming performances of a room
+func (a *App) GetStickerDanceState(roomId string) dance.State {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return dance.State{Upcoming: []dance.Performance{}}
+	}
+	return sess.GetStickerDanceState()
 }
 
-// StopBBCoreStream stops only the BB-Core streaming session
-func (a *App) StopBBCoreStream(reason string) error {
-	if err := a.ensureSessionManager(); err != nil {
+// StartFreeMode starts Free Mode (no teams, gift goals) for a room using only the Bigo listener
+func (a *App) StartFreeMode(roomId string, goalsCfg goals.Config) error {
+	sess, err := a.startableSession(roomId)
+	if err != nil {
 		return err
 	}
-	return a.session.StopBBCoreStream(reason)
+	return sess.StartFreeMode(&api.Config{RoomId: roomId}, goalsCfg)
 }
 
-// GetBigoListenerStatus returns the status of the Bigo listener session
-func (a *App) GetBigoListenerStatus() session.BigoListenerStatus {
-	if a.session == nil {
-		return session.BigoListenerStatus{}
+// StopFreeMode stops Free Mode for a room
+func (a *App) StopFreeMode(roomId string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
 	}
-	status := a.session.GetBigoListenerStatus()
-	// fmt.Printf("[App] GetBigoListenerStatus from %p: active=%v, idols=%d\n", a.session, status.IsActive, status.TotalIdols)
-	return status
+	return sess.StopFreeMode()
 }
 
-// GetBBCoreStreamStatus returns the status of the BB-Core stream session
-func (a *App) GetBBCoreStreamStatus() session.BBCoreStreamStatus {
-	if a.session == nil {
-		return session.BBCoreStreamStatus{}
+// GetGoalProgress returns the Free Mode goal progress of a room
+func (a *App) GetGoalProgress(roomId string) []goals.Progress {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return []goals.Progress{}
 	}
-	return a.session.GetBBCoreStreamStatus()
+	return sess.GetGoalProgress()
 }
 
-// addBigoListenerForSession adds a Bigo listener for session-based workflow
-func (a *App) addBigoListenerForSession(bigoRoomId, roomId string) error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
+// ResetGoal sets a Free Mode goal's progress back to zero
+func (a *App) ResetGoal(roomId, goalId string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
+	}
+	return sess.ResetGoal(goalId)
+}
 
-	// Create browser
-	ctx, cancel, err := a.browserMgr.CreateBrowser(bigoRoomId)
+// ListReports returns the end-of-session reports of a room, newest first (empty roomId lists all rooms)
+func (a *App) ListReports(roomId string) ([]report.Summary, error) {
+	return report.List(reportsDir, roomId)
+}
+
+// GetReport loads a session report for display in the UI
+func (a *App) GetReport(roomId, sessionId string) (*report.Report, error) {
+	path, err := reportPath(roomId, sessionId, "json")
+	if err != nil {
+		return nil, err
+	}
+	return report.Load(path)
+}
+
+// OpenReport opens a session report with the system's default application (format: json, csv or html)
+func (a *App) OpenReport(roomId, sessionId, format string) error {
+	path, err := reportPath(roomId, sessionId, format)
 	if err != nil {
 		return err
 	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("report not found: %w", err)
+	}
 
-	// Store cancel function for cleanup
-	a.cancels[bigoRoomId] = cancel
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve report path: %w", err)
+	}
+	runtime.BrowserOpenURL(a.ctx, (&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String())
+	return nil
+}
 
-	// Create listener
-	bigoListener := listener.NewBigoListener(bigoRoomId, ctx)
+// reportPath builds the path of a report file, rejecting IDs that would escape the reports directory
+func reportPath(roomId, sessionId, format string) (string, error) {
+	switch format {
+	case "json", "csv", "html":
+	default:
+		return "", fmt.Errorf("unsupported report format: %s", format)
+	}
 
-	// Setup gift handler with enhanced payload
-	bigoListener.OnGift(func(gift listener.Gift) {
-		// Log activity
-		a.logger.LogGift(gift.BigoRoomId, gift.SenderName, gift.GiftName, gift.Diamonds)
+	name := report.FileName(report.Report{SessionId: sessionId})
+	if !report.ValidRoomId(roomId) || sessionId == "" {
+		return "", fmt.Errorf("invalid report reference %s/%s", roomId, sessionId)
+	}
+	return filepath.Join(reportsDir, roomId, name+"."+format), nil
+}
 
-		// Send to BB-Core with COMPLETE payload
-		if a.stompClient != nil && a.session != nil {
-			payload := map[string]interface{}{
-				"type":           "GIFT",
-				"roomId":         a.session.GetStatus().RoomId,
-				"bigoRoomId":     gift.BigoRoomId,
-				"senderId":       gift.SenderId,
-				"senderName":     gift.SenderName,
-				"senderAvatar":   gift.SenderAvatar,
-				"senderLevel":    gift.SenderLevel,
-				"streamerId":     gift.StreamerId,
-				"streamerName":   gift.StreamerName,
-				"streamerAvatar": gift.StreamerAvatar,
-				"giftId":         gift.GiftId,
-				"giftName":       gift.GiftName,
-				"giftCount":      gift.GiftCount,
-				"diamonds":       gift.Diamonds,
-				"giftImageUrl":   gift.GiftImageUrl,
-				"timestamp":      gift.Timestamp,
-				"deviceHash":     a.deviceHash,
-			}
+// executeRuleAction performs an action fired by a trigger/action rule
+func (a *App) executeRuleAction(roomId string, firing rules.Firing) {
+	action := firing.Action
+	fmt.Printf("[App] Rule '%s' fired: %s\n", firing.RuleId, action.Type)
 
-			destination := "/app/room/" + a.session.GetStatus().RoomId + "/bigo"
-			a.stompClient.Publish(destination, payload)
+	switch action.Type {
+	case rules.ActionOverlay:
+		a.broadcastRuleEvent(roomId, action.Event, firing, nil)
 
-			// Broadcast directly to overlay via SSE (Local, Robust)
-			// This bypasses STOMP broker issues completely
-			if a.overlayServer != nil {
-				fmt.Printf("[App] Broadcasting GIFT event via SSE. Payload type: %v\n", payload["type"])
-				// We attach the teamId if possible, but the overlay can resolve it.
-				// For consistency with STOMP, we send the same payload.
-				a.overlayServer.BroadcastEvent(payload)
-			} else {
-				fmt.Printf("[App] WARNING: OverlayServer is nil, cannot broadcast SSE event\n")
-			}
+	case rules.ActionSound:
+		a.broadcastRuleEvent(roomId, "SOUND_CUE", firing, map[string]interface{}{"sound": action.Sound})
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "rule:sound", roomId, action.Sound)
 		}
 
-		// Update session connection status
-		if a.session != nil {
-			a.session.UpdateConnectionStatus(gift.BigoRoomId, "CONNECTED", "", bigoListener.GetStats()["frameCount"].(int64))
+	case rules.ActionStomp:
+		payload := map[string]interface{}{"roomId": roomId, "ruleId": firing.RuleId, "event": firing.Event}
+		for k, v := range action.Payload {
+			payload[k] = v
 		}
-	})
+		a.publishRuleMessage(roomId, strings.ReplaceAll(action.Destination, "{roomId}", roomId), payload)
 
-	// Setup chat handler
-	bigoListener.OnChat(func(chat listener.BigoChat) {
-		if a.stompClient != nil && a.session != nil {
-			payload := map[string]interface{}{
-				"type":         "CHAT",
-				"roomId":       a.session.GetStatus().RoomId,
-				"bigoRoomId":   chat.BigoRoomId,
-				"senderId":     chat.SenderId,
-				"senderName":   chat.SenderName,
-				"senderAvatar": chat.SenderAvatar,
-				"senderLevel":  chat.SenderLevel,
-				"message":      chat.Message,
-				"timestamp":    chat.Timestamp,
-				"deviceHash":   a.deviceHash,
+	case rules.ActionWebhook:
+		go func() {
+			if err := rules.PostWebhook(roomId, firing); err != nil {
+				fmt.Printf("[App] WARNING: Rule '%s' webhook failed: %v\n", firing.RuleId, err)
 			}
+		}()
 
-			destination := "/app/room/" + a.session.GetStatus().RoomId + "/bigo"
-			a.stompClient.Publish(destination, payload)
+	case rules.ActionScoreBonus:
+		teamId := action.TeamId
+		if teamId == "" {
+			teamId = firing.Event.TeamId
+		}
+		if teamId == "" {
+			fmt.Printf("[App] WARNING: Rule '%s' score bonus has no team\n", firing.RuleId)
+			return
 		}
-	})
 
-	// Start listening
-	if _, err := bigoListener.Start(); err != nil {
-		return err
+		// Bonuses are scored by BB-Core as a regular GIFT message credited to the team
+		bonus := &message.GiftMessage{
+			SchemaVersion: message.SchemaVersion,
+			Type:          message.TypeGift,
+			RoomID:        roomId,
+			SenderID:      "bbapp-rule:" + firing.RuleId,
+			SenderName:    "BBapp",
+			TeamID:        teamId,
+			GiftID:        "rule:" + firing.RuleId,
+			GiftName:      "Rule bonus: " + firing.RuleName,
+			GiftCount:     1,
+			Diamonds:      action.Bonus,
+			Timestamp:     time.Now().UnixMilli(),
+			DeviceHash:    a.deviceHash,
+		}
+		if err := bonus.Validate(); err != nil {
+			fmt.Printf("[App] WARNING: Rule '%s' score bonus dropped: %v\n", firing.RuleId, err)
+			return
+		}
+		a.publishRuleMessage(roomId, fmt.Sprintf("/app/room/%s/bigo", roomId), bonus)
+		a.broadcastRuleEvent(roomId, "SCORE_BONUS", firing, map[string]interface{}{"teamId": teamId, "bonus": action.Bonus})
 	}
-
-	a.listeners[bigoRoomId] = bigoListener
-	return nil
 }
 
-// GetOverlayURL generates the overlay URL for OBS Browser Source.
-// Returns the complete URL with scene, roomId, bbCoreUrl, and token as query parameters.
-// Returns empty string if inputs are invalid or overlay server is unavailable.
-func (a *App) GetOverlayURL(scene, roomId, token string) string {
-	// Validate inputs
-	if strings.TrimSpace(scene) == "" || strings.TrimSpace(roomId) == "" || strings.TrimSpace(token) == "" {
-		return ""
-	}
-
-	// Check if overlay server is available
+// broadcastRuleEvent sends a rule-triggered event to the room's overlays
+func (a *App) broadcastRuleEvent(roomId, eventType string, firing rules.Firing, extra map[string]interface{}) {
 	if a.overlayServer == nil {
-		return ""
+		return
 	}
 
-	baseURL := a.overlayServer.GetURL()
-
-	// Use url.Values for safe encoding
-	params := url.Values{}
-	params.Add("scene", scene)
-	params.Add("roomId", roomId)
-	params.Add("bbCoreUrl", a.bbCoreURL)
-	params.Add("token", token)
-
-	return fmt.Sprintf("%s/overlay?%s", baseURL, params.Encode())
+	payload := map[string]interface{}{
+		"type":    eventType,
+		"roomId":  roomId,
+		"ruleId":  firing.RuleId,
+		"data":    firing.Event,
+		"payload": firing.Action.Payload,
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	a.overlayServer.BroadcastRoomEvent(roomId, payload)
 }
 
-// GetBBCoreURL returns the configured BB-Core base URL from environment.
+// publishRuleMessage publishes a rule-triggered message on the room's BB-Core STOMP connection
+func (a *App) publishRuleMessage(roomId, destination string, payload interface{}) {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return
+	}
+	client, ok := sess.GetStompClient().(*stomp.Client)
+	if !ok || client == nil {
+		fmt.Printf("[App] WARNING: Rule message to %s dropped - BB-Core stream not running\n", destination)
+		return
+	}
+	if err := client.Publish(destination, payload); err != nil {
+		fmt.Printf("[App] WARNING: Rule message to %s failed: %v\n", destination, err)
+	}
+}
+
+// SetRules validates and applies trigger/action rules to a running room
+func (a *App) SetRules(roomId string, set rules.RuleSet) error {
+	sess, err := a.ensureSessionManager(roomId)
+	if err != nil {
+		return err
+	}
+	return sess.SetRules(set)
+}
+
+// SetEventSinks validates and opens a room's webhook/file/broker event sinks, replacing the previous ones
+func (a *App) SetEventSinks(roomId string, cfg sinks.Config) error {
+	sess, err := a.ensureSessionManager(roomId)
+	if err != nil {
+		return err
+	}
+	return sess.SetSinks(roomId, cfg)
+}
+
+// GetEventSinkHealth returns the delivery status of each event sink of a room
+func (a *App) GetEventSinkHealth(roomId string) []sinks.Health {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return []sinks.Health{}
+	}
+	return sess.GetSinkHealth()
+}
+
+// DryRunRules evaluates rules against the events recorded in a session report, without executing actions
+func (a *App) DryRunRules(set rules.RuleSet, roomId, sessionId string) ([]rules.Firing, error) {
+	rep, err := a.GetReport(roomId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return rules.DryRun(set, recordedRuleEvents(rep))
+}
+
+// recordedRuleEvents converts a report's gifts and chats into rule events in time order
+func recordedRuleEvents(rep *report.Report) []rules.Event {
+	events := make([]rules.Event, 0, len(rep.Gifts)+len(rep.Chats))
+	for _, g := range rep.Gifts {
+		events = append(events, rules.Event{
+			Type:       rules.EventGift,
+			BigoRoomId: g.BigoRoomId,
+			SenderId:   g.SenderId,
+			SenderName: g.SenderName,
+			TeamId:     g.TeamId,
+			StreamerId: g.StreamerId,
+			GiftName:   g.GiftName,
+			GiftCount:  g.GiftCount,
+			Diamonds:   g.Diamonds,
+			At:         g.At,
+		})
+	}
+	for _, c := range rep.Chats {
+		events = append(events, rules.Event{
+			Type:       rules.EventChat,
+			BigoRoomId: c.BigoRoomId,
+			SenderId:   c.SenderId,
+			SenderName: c.SenderName,
+			Message:    c.Message,
+			At:         c.At,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+	return events
+}
+
+// GetSessionStatus returns the session status of a room
+func (a *App) GetSessionStatus(roomId string) session.Status {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return session.Status{RoomId: roomId, IsActive: false}
+	}
+	return sess.GetStatus()
+}
+
+// StartBigoListener starts only the Bigo listener session of a room
+func (a *App) StartBigoListener(roomId string, cfg api.Config) error {
+	sess, err := a.startableSession(roomId)
+	if err != nil {
+		return err
+	}
+
+	// Ensure library is set before starting
+	if len(a.giftLibrary) > 0 {
+		sess.SetGiftLibrary(a.giftLibrary)
+	}
+
+	cfg.RoomId = roomId
+	fmt.Printf("[App] Using session manager at %p for room %s\n", sess, roomId)
+	return sess.StartBigoListener(&cfg)
+}
+
+// StopBigoListener stops only the Bigo listener session of a room
+func (a *App) StopBigoListener(roomId string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
+	}
+	return sess.StopBigoListener()
+}
+
+// StartBBCoreStream starts only the BB-Core streaming session of a room
+func (a *App) StartBBCoreStream(roomId string, cfg api.Config, durationMinutes int) error {
+	sess, err := a.startableSession(roomId)
+	if err != nil {
+		return err
+	}
+
+	// Ensure config has correct room ID
+	cfg.RoomId = roomId
+
+	// Save configuration to ensure backend has the latest teams data
+	// This fixes the "Room has 0 teams" error
+	if err := a.SaveBBAppConfig(roomId, cfg); err != nil {
+		fmt.Printf("[App] WARNING: Failed to save config before stream start: %v\n", err)
+		// We could return error here, but we'll try to proceed in case it was a network glitch
+		// and the config was already saved previously.
+		// return fmt.Errorf("failed to save config: %w", err)
+	} else {
+		fmt.Printf("[App] ✓ Config saved to BB-Core before stream start\n")
+	}
+
+	// Get BB-Core URL and access token
+	bbCoreURL := a.bbCoreURL
+	accessToken := ""
+	if a.apiClient != nil {
+		accessToken = a.apiClient.GetAccessToken()
+	}
+
+	return sess.StartBBCoreStream(roomId, &cfg, a.stompEndpoint(bbCoreURL), accessToken, durationMinutes)
+}
+
+// StopBBCoreStream stops only the BB-Core streaming session of a room
+func (a *App) StopBBCoreStream(roomId, reason string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
+	}
+	return sess.StopBBCoreStream(reason)
+}
+
+// GetBigoListenerStatus returns the status of the Bigo listener session of a room
+func (a *App) GetBigoListenerStatus(roomId string) session.BigoListenerStatus {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return session.BigoListenerStatus{}
+	}
+	return sess.GetBigoListenerStatus()
+}
+
+// GetBBCoreStreamStatus returns the status of the BB-Core stream session of a room
+func (a *App) GetBBCoreStreamStatus(roomId string) session.BBCoreStreamStatus {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return session.BBCoreStreamStatus{RoomId: roomId}
+	}
+	return sess.GetBBCoreStreamStatus()
+}
+
+// addBigoListenerForSession adds a Bigo listener for session-based workflow
+func (a *App) addBigoListenerForSession(bigoRoomId, roomId string) error {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return fmt.Errorf("no session for room %s", roomId)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	// The room's session reports this connection as STATUS to BB-Core, like its own
+	sess.TrackConnection(bigoRoomId)
+
+	// Create browser
+	ctx, cancel, err := a.browserMgr.CreateBrowser(bigoRoomId)
+	if err != nil {
+		sess.UpdateConnectionStatus(bigoRoomId, "ERROR", err.Error(), 0)
+		return err
+	}
+
+	// Store cancel function for cleanup; stopping first tells the watcher below it wasn't a failure
+	stopped, stop := context.WithCancel(context.Background())
+	a.roomCancels(roomId)[bigoRoomId] = func() {
+		stop()
+		cancel()
+	}
+
+	// Create listener
+	bigoListener := listener.NewBigoListener(bigoRoomId, ctx)
+
+	// Setup gift handler with enhanced payload
+	bigoListener.OnGift(func(gift listener.Gift) {
+		// Log activity
+		a.logger.LogGift(gift.BigoRoomId, gift.SenderName, gift.GiftName, gift.Diamonds)
+
+		// BB-Core and the overlay get the gift through the session's filtered sinks
+		sess.DispatchEvent(gift)
+
+		// Update session connection status
+		sess.UpdateConnectionStatus(bigoRoomId, "CONNECTED", "", bigoListener.GetStats()["frameCount"].(int64))
+	})
+
+	// Setup chat handler
+	bigoListener.OnChat(func(chat listener.BigoChat) {
+		sess.DispatchEvent(chat)
+	})
+
+	// Start listening
+	if _, err := bigoListener.Start(); err != nil {
+		stop()
+		cancel()
+		delete(a.cancels[roomId], bigoRoomId)
+		sess.UpdateConnectionStatus(bigoRoomId, "ERROR", err.Error(), 0)
+		return err
+	}
+	sess.UpdateConnectionStatus(bigoRoomId, "CONNECTED", "", 0)
+
+	// A browser that goes away without being stopped is a failed connection
+	go func() {
+		<-ctx.Done()
+		if stopped.Err() == nil {
+			sess.UpdateConnectionStatus(bigoRoomId, "ERROR", fmt.Sprintf("browser closed: %v", ctx.Err()), 0)
+		}
+	}()
+
+	a.roomListeners(roomId)[bigoRoomId] = bigoListener
+	return nil
+}
+
+// GetOverlayURL generates the overlay URL for OBS Browser Source.
+// Returns the complete URL with scene, roomId, bbCoreUrl, and token as query parameters.
+// Returns empty string if inputs are invalid or overlay server is unavailable.
+func (a *App) GetOverlayURL(scene, roomId, token string) string {
+	// Validate inputs
+	if strings.TrimSpace(scene) == "" || strings.TrimSpace(roomId) == "" || strings.TrimSpace(token) == "" {
+		return ""
+	}
+
+	// Check if overlay server is available
+	if a.overlayServer == nil {
+		return ""
+	}
+
+	baseURL := a.overlayServer.GetURL()
+
+	// Use url.Values for safe encoding
+	params := url.Values{}
+	params.Add("scene", scene)
+	params.Add("roomId", roomId)
+	params.Add("bbCoreUrl", a.bbCoreURL)
+	params.Add("token", token)
+
+	return fmt.Sprintf("%s/overlay?%s", baseURL, params.Encode())
+}
+
+// GetControlURL returns the WebSocket URL of the overlay control channel for a room,
+// carrying the operator token that allows pause, resume, add_time and next_round
+func (a *App) GetControlURL(roomId string) string {
+	if strings.TrimSpace(roomId) == "" || a.overlayServer == nil {
+		return ""
+	}
+
+	params := url.Values{}
+	params.Add("roomId", roomId)
+	params.Add("token", a.controlToken)
+
+	baseURL := strings.Replace(a.overlayServer.GetURL(), "http://", "ws://", 1)
+	return fmt.Sprintf("%s/ws?%s", baseURL, params.Encode())
+}
+
+// GetBBCoreURL returns the configured BB-Core base URL from environment.
 // Defaults to "http://localhost:8080" if not set in .env file.
 func (a *App) GetBBCoreURL() string {
 	return a.bbCoreURL
@@ -786,18 +1316,27 @@ func (a *App) GetBBCoreURL() string {
 // InitializeBBCoreClient initializes the API client for BB-Core communication.
 // This must be called before GetBBAppConfig.
 func (a *App) InitializeBBCoreClient(bbCoreUrl, authToken string) error {
-	a.apiClient = api.NewClient(bbCoreUrl, authToken)
-	a.bbCoreURL = bbCoreUrl
-	fmt.Printf("[App] BB-Core API client initialized: %s\n", bbCoreUrl)
+	// Keep the refresh token of the current login, so an expired access token can still be refreshed
+	refreshToken := ""
+	if a.authManager != nil {
+		if creds := a.authManager.GetCredentials(); creds != nil && creds.AccessToken == authToken {
+			refreshToken = creds.RefreshToken
+		}
+	}
 
-	// Initialize session manager if not already set by calling ensureSessionManager
-	// This ensures the global subscription to events is properly registered
-	if err := a.ensureSessionManager(); err != nil {
-		fmt.Printf("[App] ERROR: Failed to ensure session manager in InitializeBBCoreClient: %v\n", err)
+	if a.apiClient != nil && a.bbCoreURL == bbCoreUrl {
+		// Same server: update in place so sessions and STOMP clients holding the client stay in sync
+		a.apiClient.SetTokens(authToken, refreshToken)
 	} else {
-		fmt.Printf("[App] Session manager initialized via ensureSessionManager\n")
+		client := a.newAPIClient(bbCoreUrl, authToken)
+		client.SetTokens(authToken, refreshToken)
+		a.setAPIClient(client)
 	}
+	a.bbCoreURL = bbCoreUrl
+	fmt.Printf("[App] BB-Core API client initialized: %s\n", bbCoreUrl)
 
+	// Session managers are created per room on first use (see ensureSessionManager),
+	// which also registers the SSE subscription for that room.
 	return nil
 }
 
@@ -817,12 +1356,12 @@ func (a *App) GetBBAppConfig(roomId string) (*api.Config, error) {
 
 	// Update local cache and overlay server with the authoritative config
 	a.mutex.Lock()
-	a.currentConfig = config
+	a.configs[roomId] = config
 	a.mutex.Unlock()
 
 	if a.overlayServer != nil {
 		fmt.Printf("[App] Syncing local overlay server with authoritative config\n")
-		a.overlayServer.SetConfig(config)
+		a.overlayServer.SetRoomConfig(roomId, config)
 
 		// Optional: Broadcast generic update if needed, but Wizard serves as main driver here
 		// a.overlayServer.BroadcastEvent(...)
@@ -833,21 +1372,26 @@ func (a *App) GetBBAppConfig(roomId string) (*api.Config, error) {
 }
 
 // SaveBBAppConfig saves configuration to BB-Core
-func (a *App) SaveBBAppConfig(roomId string, config api.Config) error {
+func (a *App) SaveBBAppConfig(roomId string, cfg api.Config) error {
 	if a.apiClient == nil {
 		return fmt.Errorf("not connected to BB-Core")
 	}
 
-	// Enrich config with Binding Gift Images from Library
+	// Compared with the saved cfg to broadcast only real changes
+	a.mutex.RLock()
+	previous := a.configs[roomId]
+	a.mutex.RUnlock()
+
+	// Enrich cfg with Binding Gift Images from Library
 	if len(a.giftLibrary) > 0 {
-		for i, team := range config.Teams {
+		for i, team := range cfg.Teams {
 			// Only populate if not already set or if we want to ensure it's correct
 			// Let's look up by name
 			if team.BindingGift != "" {
 				for _, gift := range a.giftLibrary {
 					if strings.EqualFold(gift.Name, team.BindingGift) {
 						if gift.Image != "" {
-							config.Teams[i].BindingGiftImage = gift.Image
+							cfg.Teams[i].BindingGiftImage = gift.Image
 							// fmt.Printf("[App] Populated BindingGiftImage for %s: %s\n", team.Name, gift.Image)
 						}
 						break
@@ -857,24 +1401,103 @@ func (a *App) SaveBBAppConfig(roomId string, config api.Config) error {
 		}
 	}
 
-	if err := a.apiClient.SaveConfig(roomId, &config); err != nil {
+	if err := config.Validate(&cfg, a.giftLibrary); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if err := a.apiClient.SaveConfig(roomId, &cfg); err != nil {
 		return err
 	}
 
-	// CRITICAL: Refetch config from Core immediately to ensure we have the Authoritative IDs.
+	// CRITICAL: Refetch cfg from Core immediately to ensure we have the Authoritative IDs.
 	// The Core might have generated different IDs or modified the data.
 	// We must align with Core to avoid ID mismatches.
 	authoritativeConfig, err := a.GetBBAppConfig(roomId)
 	if err != nil {
-		fmt.Printf("[App] WARNING: Failed to refetch config after save: %v. Using local version (risk of desync).\n", err)
+		fmt.Printf("[App] WARNING: Failed to refetch cfg after save: %v. Using local version (risk of desync).\n", err)
 	} else {
-		// Use the authoritative config
-		config = *authoritativeConfig
-		fmt.Printf("[App] ✓ Refetched authoritative config from Core. Teams: %d\n", len(config.Teams))
+		// Use the authoritative cfg
+		cfg = *authoritativeConfig
+		fmt.Printf("[App] ✓ Refetched authoritative cfg from Core. Teams: %d\n", len(cfg.Teams))
 	}
 
-	// Broadcast config update to overlay
-	if a.stompClient != nil {
+	a.mutex.Lock()
+	a.configs[roomId] = &cfg
+	a.mutex.Unlock()
+
+	// Push the cfg into the running session and its listeners
+	a.applyConfig(roomId, &cfg)
+
+	// Update local overlay server cfg (for visual settings persistence)
+	if a.overlayServer != nil {
+		a.overlayServer.SetRoomConfig(roomId, &cfg)
+	}
+
+	if previous != nil && reflect.DeepEqual(*previous, cfg) {
+		fmt.Printf("[App] Config of room %s unchanged, no update broadcast\n", roomId)
+		return nil
+	}
+	a.broadcastConfig(roomId, &cfg)
+	return nil
+}
+
+// applyConfig hot-reloads a saved config into a room's session: attribution indexes are swapped
+// and listeners of added or removed Bigo rooms are started or stopped
+func (a *App) applyConfig(roomId string, cfg *api.Config) {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok || sess.GetConfig() == nil {
+		return // Nothing running yet; the config is used at the next start
+	}
+
+	diff := sess.UpdateConfig(cfg)
+	if diff.Empty() {
+		return
+	}
+	fmt.Printf("[App] Config of room %s applied live: %s\n", roomId, diff)
+
+	a.mutex.RLock()
+	pkRunning := len(a.cancels[roomId]) > 0
+	a.mutex.RUnlock()
+	if !pkRunning {
+		return // Listener-only modes connect to the main room, which does not change
+	}
+
+	for _, bigoRoomId := range diff.RemovedBigoRooms {
+		a.stopBigoListener(roomId, bigoRoomId)
+	}
+	for _, bigoRoomId := range diff.AddedBigoRooms {
+		if err := a.addBigoListenerForSession(bigoRoomId, roomId); err != nil {
+			fmt.Printf("[App] ERROR: Failed to start listener for %s: %v\n", bigoRoomId, err)
+		}
+	}
+}
+
+// stopBigoListener stops the browser listener of one Bigo room of a BB-Core room
+func (a *App) stopBigoListener(roomId, bigoRoomId string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if sess, ok := a.sessions.Get(roomId); ok {
+		sess.ReleaseConnection(bigoRoomId, "removed from config")
+	}
+	if cancel := a.cancels[roomId][bigoRoomId]; cancel != nil {
+		fmt.Printf("[App] Stopping browser for room: %s\n", bigoRoomId)
+		cancel()
+	}
+	delete(a.cancels[roomId], bigoRoomId)
+	delete(a.listeners[roomId], bigoRoomId)
+}
+
+// broadcastConfig sends one CONFIG_UPDATE to the room's overlays, over STOMP and over SSE
+func (a *App) broadcastConfig(roomId string, config *api.Config) {
+	// Broadcast config update to overlay, preferring the room's own STOMP connection
+	stompClient := a.stompClient
+	if sess, ok := a.sessions.Get(roomId); ok {
+		if roomClient, ok := sess.GetStompClient().(*stomp.Client); ok && roomClient != nil {
+			stompClient = roomClient
+		}
+	}
+	if stompClient != nil {
 		fmt.Printf("[App] Broadcasting config update for room: %s\n", roomId)
 		fmt.Printf("[App] Overlay Settings: %+v\n", config.OverlaySettings)
 		// We send the full config object
@@ -920,32 +1543,46 @@ func (a *App) SaveBBAppConfig(roomId string, config api.Config) error {
 		// I will try publishing to `/app/room/{roomId}/broadcast` if it exists, or just try `/topic/room/{roomId}/config` directly.
 		// Many configs allow client publish to /topic. Let's try `/topic/room/{roomId}/config`.
 		destination := "/topic/room/" + roomId + "/config"
-		if err := a.stompClient.Publish(destination, config); err != nil {
+		if err := stompClient.Publish(destination, config); err != nil {
 			fmt.Printf("[App] WARNING: Failed to broadcast config: %v\n", err)
 		}
 	}
 
-	// Update local cache
-	a.mutex.Lock()
-	a.currentConfig = &config
-	a.mutex.Unlock()
-
-	// Update local overlay server config (for visual settings persistence)
 	if a.overlayServer != nil {
-		fmt.Printf("[App] Updating local overlay server config\n")
-		a.overlayServer.SetConfig(&config)
-
 		// Broadcast update via SSE to ensure Overlay stays in sync (bypassing flaky STOMP)
 		fmt.Printf("[App] Broadcasting CONFIG_UPDATE via SSE\n")
-		a.overlayServer.BroadcastEvent(map[string]interface{}{
-			"type": "CONFIG_UPDATE",
-			"data": config,
+		a.overlayServer.BroadcastRoomEvent(roomId, map[string]interface{}{
+			"type":   "CONFIG_UPDATE",
+			"roomId": roomId,
+			"data":   config,
 		})
 	}
+}
+
+// Event Filter Wails Bindings
 
+// SetFilterRules validates and applies sender/anti-spam filter rules to a room's event pipeline
+func (a *App) SetFilterRules(roomId string, rules filter.Rules) error {
+	sess, err := a.ensureSessionManager(roomId)
+	if err != nil {
+		return err
+	}
+	if err := sess.SetFilterRules(rules); err != nil {
+		return fmt.Errorf("invalid filter rules: %w", err)
+	}
+	fmt.Printf("[App] ✓ Filter rules applied to room %s\n", roomId)
 	return nil
 }
 
+// GetFilterStats returns filter hit counters of a room for the dashboard
+func (a *App) GetFilterStats(roomId string) filter.Stats {
+	sess, ok := a.sessions.Get(roomId)
+	if !ok {
+		return filter.Stats{Hits: map[string]int64{}}
+	}
+	return sess.GetFilterStats()
+}
+
 // Profile Management Wails Bindings
 
 // CreateProfile creates a new profile with the given name, room ID, and config
@@ -953,7 +1590,14 @@ func (a *App) CreateProfile(name, roomID string, config api.Config) (*profile.Pr
 	if a.profileManager == nil {
 		return nil, fmt.Errorf("profile manager not initialized")
 	}
-	return a.profileManager.CreateProfile(name, roomID, config)
+	p, err := a.profileManager.CreateProfile(name, roomID, config)
+	if err != nil || a.envStore == nil {
+		return p, err
+	}
+
+	// Tag the profile with the environment its room lives in
+	env, _ := a.envStore.Active()
+	return a.profileManager.UpdateProfileEnvironment(p.ID, env.Id)
 }
 
 // LoadProfile loads a profile by ID and updates its lastUsedAt timestamp
@@ -980,6 +1624,49 @@ func (a *App) UpdateProfileBigoInfo(id, avatar, nickname string) (*profile.Profi
 	return a.profileManager.UpdateProfileBigoInfo(id, avatar, nickname)
 }
 
+// UpdateProfileFilters validates and stores event filter rules in a profile
+func (a *App) UpdateProfileFilters(id string, rules filter.Rules) (*profile.Profile, error) {
+	if a.profileManager == nil {
+		return nil, fmt.Errorf("profile manager not initialized")
+	}
+	if _, err := filter.NewFilter(rules); err != nil {
+		return nil, fmt.Errorf("invalid filter rules: %w", err)
+	}
+	return a.profileManager.UpdateProfileFilters(id, rules)
+}
+
+// UpdateProfileStickerDance saves a profile's Sticker Dance gift/action setup
+func (a *App) UpdateProfileStickerDance(id string, cfg dance.Config) (*profile.Profile, error) {
+	if a.profileManager == nil {
+		return nil, fmt.Errorf("profile manager not initialized")
+	}
+	return a.profileManager.UpdateProfileStickerDance(id, cfg)
+}
+
+// UpdateProfileGoals saves a profile's Free Mode goals
+func (a *App) UpdateProfileGoals(id string, cfg goals.Config) (*profile.Profile, error) {
+	if a.profileManager == nil {
+		return nil, fmt.Errorf("profile manager not initialized")
+	}
+	return a.profileManager.UpdateProfileGoals(id, cfg)
+}
+
+// UpdateProfileRules validates and saves a profile's trigger/action rules
+func (a *App) UpdateProfileRules(id string, set rules.RuleSet) (*profile.Profile, error) {
+	if a.profileManager == nil {
+		return nil, fmt.Errorf("profile manager not initialized")
+	}
+	return a.profileManager.UpdateProfileRules(id, set)
+}
+
+// UpdateProfileSinks validates and saves a profile's event sinks
+func (a *App) UpdateProfileSinks(id string, cfg sinks.Config) (*profile.Profile, error) {
+	if a.profileManager == nil {
+		return nil, fmt.Errorf("profile manager not initialized")
+	}
+	return a.profileManager.UpdateProfileSinks(id, cfg)
+}
+
 // DeleteProfile deletes a profile by ID
 func (a *App) DeleteProfile(id string) error {
 	if a.profileManager == nil {
@@ -988,12 +1675,24 @@ func (a *App) DeleteProfile(id string) error {
 	return a.profileManager.DeleteProfile(id)
 }
 
-// ListProfiles returns all profiles sorted by lastUsedAt desc
+// ListProfiles returns the profiles of the active environment sorted by lastUsedAt desc
 func (a *App) ListProfiles() []*profile.Profile {
 	if a.profileManager == nil {
 		return []*profile.Profile{}
 	}
-	return a.profileManager.ListProfiles()
+	if a.envStore == nil {
+		return a.profileManager.ListProfiles()
+	}
+
+	// Profiles from before environments existed belong to the default one
+	env, _ := a.envStore.Active()
+	profiles := []*profile.Profile{}
+	for _, p := range a.profileManager.ListProfiles() {
+		if p.EnvironmentID == env.Id || (p.EnvironmentID == "" && env.Id == environment.DefaultId) {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
 }
 
 // Authentication Wails Bindings
@@ -1003,7 +1702,7 @@ func (a *App) Login(username, password string) (*api.AuthResponse, error) {
 	// Create temporary client if not connected
 	client := a.apiClient
 	if client == nil {
-		client = api.NewClient(a.bbCoreURL, "")
+		client = a.newAPIClient(a.bbCoreURL, "")
 	}
 
 	authResp, err := client.Login(username, password)
@@ -1011,11 +1710,7 @@ func (a *App) Login(username, password string) (*api.AuthResponse, error) {
 		return nil, err
 	}
 
-	// Update API client with new tokens
-	if a.apiClient != nil {
-		a.apiClient.SetTokens(authResp.AccessToken, authResp.RefreshToken)
-	}
-
+	a.applyLogin(authResp)
 	return authResp, nil
 }
 
@@ -1024,7 +1719,7 @@ func (a *App) Register(username, email, password, agencyName, firstName, lastNam
 	// Create temporary client if not connected
 	client := a.apiClient
 	if client == nil {
-		client = api.NewClient(a.bbCoreURL, "")
+		client = a.newAPIClient(a.bbCoreURL, "")
 	}
 
 	authResp, err := client.Register(username, email, password, agencyName, firstName, lastName)
@@ -1032,11 +1727,7 @@ func (a *App) Register(username, email, password, agencyName, firstName, lastNam
 		return nil, err
 	}
 
-	// Update API client with new tokens
-	if a.apiClient != nil {
-		a.apiClient.SetTokens(authResp.AccessToken, authResp.RefreshToken)
-	}
-
+	a.applyLogin(authResp)
 	return authResp, nil
 }
 
@@ -1044,7 +1735,7 @@ func (a *App) Register(username, email, password, agencyName, firstName, lastNam
 func (a *App) RefreshAuthToken(refreshToken string) (*api.AuthResponse, error) {
 	client := a.apiClient
 	if client == nil {
-		client = api.NewClient(a.bbCoreURL, "")
+		client = a.newAPIClient(a.bbCoreURL, "")
 	}
 
 	authResp, err := client.RefreshToken(refreshToken)
@@ -1052,12 +1743,501 @@ func (a *App) RefreshAuthToken(refreshToken string) (*api.AuthResponse, error) {
 		return nil, err
 	}
 
-	// Update API client with new tokens
+	a.applyLogin(authResp)
+	return authResp, nil
+}
+
+// GetAuthSession returns the login restored at startup (or made since), or nil when the user has to log in
+func (a *App) GetAuthSession() *auth.Credentials {
+	if a.authManager == nil {
+		return nil
+	}
+	return a.authManager.GetCredentials()
+}
+
+// Logout stops refreshing tokens and clears the stored login of the active account; the account
+// itself stays listed for switching back (see RemoveAccount)
+func (a *App) Logout() error {
+	if a.authRefresher != nil {
+		a.authRefresher.Stop()
+	}
 	if a.apiClient != nil {
-		a.apiClient.SetTokens(authResp.AccessToken, authResp.RefreshToken)
+		a.apiClient.SetTokens("", "")
 	}
+	if err := a.forgetLogin(); err != nil {
+		return err
+	}
+	fmt.Println("[App] ✓ Logged out")
+	return nil
+}
 
-	return authResp, nil
+// Environment and Account Wails Bindings
+
+// GetEnvironments returns the BB-Core environments, the accounts stored for them and the current selection
+func (a *App) GetEnvironments() environment.State {
+	if a.envStore == nil {
+		return environment.State{}
+	}
+	return a.envStore.State()
+}
+
+// SaveEnvironment adds or updates a BB-Core environment. Changing the active one rebuilds its clients.
+func (a *App) SaveEnvironment(env environment.Environment) error {
+	if a.envStore == nil {
+		return fmt.Errorf("environments unavailable")
+	}
+	if err := a.envStore.SaveEnvironment(env); err != nil {
+		return err
+	}
+
+	active, account := a.envStore.Active()
+	if active.Id != strings.TrimSpace(env.Id) || (active.BaseURL == a.bbCoreURL && active.STOMPURL() == a.stompURL) {
+		return nil
+	}
+	accountId := ""
+	if account != nil {
+		accountId = account.Id
+	}
+	_, err := a.switchTo(active.Id, accountId)
+	return err
+}
+
+// DeleteEnvironment removes an environment without accounts
+func (a *App) DeleteEnvironment(id string) error {
+	if a.envStore == nil {
+		return fmt.Errorf("environments unavailable")
+	}
+	return a.envStore.DeleteEnvironment(id)
+}
+
+// SwitchEnvironment makes another environment active with its most recently used account.
+// Running rooms are stopped first. Returns nil credentials when the user has to log in.
+func (a *App) SwitchEnvironment(id string) (*auth.Credentials, error) {
+	if a.envStore == nil {
+		return nil, fmt.Errorf("environments unavailable")
+	}
+
+	var recent *environment.Account
+	for _, acc := range a.envStore.State().Accounts {
+		if acc.EnvironmentId == id && (recent == nil || (acc.LastUsedAt != nil && (recent.LastUsedAt == nil || acc.LastUsedAt.After(*recent.LastUsedAt)))) {
+			recent = &acc
+		}
+	}
+
+	accountId := ""
+	if recent != nil {
+		accountId = recent.Id
+	}
+	return a.switchTo(id, accountId)
+}
+
+// SwitchAccount logs in with another stored account. Running rooms are stopped first.
+// "" shows the login page to add an account, keeping the stored ones.
+func (a *App) SwitchAccount(accountId string) (*auth.Credentials, error) {
+	if a.envStore == nil {
+		return nil, fmt.Errorf("environments unavailable")
+	}
+	if accountId == "" {
+		env, _ := a.envStore.Active()
+		return a.switchTo(env.Id, "")
+	}
+	acc, ok := a.envStore.Account(accountId)
+	if !ok {
+		return nil, fmt.Errorf("account not found: %s", accountId)
+	}
+	return a.switchTo(acc.EnvironmentId, acc.Id)
+}
+
+// RemoveAccount deletes a stored account and its credentials; removing the active one logs out first
+func (a *App) RemoveAccount(accountId string) error {
+	if a.envStore == nil {
+		return fmt.Errorf("environments unavailable")
+	}
+	if _, active := a.envStore.Active(); active != nil && active.Id == accountId {
+		if err := a.Logout(); err != nil {
+			return err
+		}
+		a.authManager, a.authRefresher, a.entitlements = nil, nil, nil
+	}
+
+	if err := a.envStore.RemoveAccount(accountId); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(credentialsDir, accountId))
+}
+
+// switchTo tears down the BB-Core clients and rebuilds them for an environment and account ("" = logged out)
+func (a *App) switchTo(environmentId, accountId string) (*auth.Credentials, error) {
+	env, ok := a.envStore.Environment(environmentId)
+	if !ok {
+		return nil, fmt.Errorf("environment not found: %s", environmentId)
+	}
+	fmt.Printf("[App] Switching to environment %s (%s)...\n", env.Label, env.BaseURL)
+
+	a.teardownClients("Account switch")
+	if err := a.envStore.SetActive(environmentId, accountId); err != nil {
+		return nil, err
+	}
+	a.bbCoreURL, a.stompURL = env.BaseURL, env.STOMPURL()
+	if a.entitlements != nil {
+		a.entitlements.Clear()
+	}
+	a.authManager, a.authRefresher, a.entitlements = nil, nil, nil
+
+	if accountId == "" {
+		return nil, nil
+	}
+	if err := a.openVault(accountId); err != nil {
+		return nil, err
+	}
+	if err := a.restoreLogin(); err != nil {
+		return nil, err
+	}
+	return a.authManager.GetCredentials(), nil
+}
+
+// teardownClients stops all rooms and drops the BB-Core clients before the environment or account changes
+func (a *App) teardownClients(reason string) {
+	// Rooms first: stopping them still needs the API client
+	a.stopAllRooms(reason)
+
+	if a.authRefresher != nil {
+		a.authRefresher.Stop()
+	}
+	if a.stompClient != nil {
+		a.stompClient.Disconnect()
+		a.stompClient = nil
+	}
+	if a.apiClient != nil {
+		a.apiClient.OnTokensRefreshed(nil) // A late refresh must not land in the next account's vault
+		a.apiClient = nil
+	}
+}
+
+// stompEndpoint returns where sessions connect STOMP: the active environment's STOMP URL for its base URL
+func (a *App) stompEndpoint(bbCoreUrl string) string {
+	if a.stompURL != "" && strings.TrimSuffix(bbCoreUrl, "/") == a.bbCoreURL {
+		return a.stompURL
+	}
+	return bbCoreUrl
+}
+
+// initAccounts loads the environments, selects the active one and opens the vault of its account
+func (a *App) initAccounts(defaultURL string) error {
+	if err := a.initDevice(); err != nil {
+		return err
+	}
+
+	store, err := environment.NewStore(environmentsDir, defaultURL)
+	if err != nil {
+		return err
+	}
+	a.envStore = store
+
+	if err := a.migrateLegacyLogin(); err != nil {
+		fmt.Printf("[App] WARNING: Could not migrate the stored login: %v\n", err)
+	}
+
+	env, account := store.Active()
+	a.bbCoreURL, a.stompURL = env.BaseURL, env.STOMPURL()
+	fmt.Printf("[App] Environment: %s (%s)\n", env.Label, env.BaseURL)
+
+	if account == nil {
+		return nil
+	}
+	return a.openVault(account.Id)
+}
+
+// initDevice computes the device fingerprint, keeping the stored one when only a little changed
+func (a *App) initDevice() error {
+	fp, err := fingerprint.Generate(fingerprintPath)
+	if err != nil {
+		fmt.Printf("[App] WARNING: Device fingerprint record unavailable: %v\n", err)
+		deviceHash, err := fingerprint.GenerateDeviceHash()
+		if err != nil {
+			return fmt.Errorf("device fingerprint: %w", err)
+		}
+		a.deviceHash = deviceHash
+		a.device = api.DeviceIdentity{Hash: deviceHash, FingerprintVersion: fingerprint.Version}
+		return nil
+	}
+
+	a.deviceHash = fp.Hash
+	a.device = api.DeviceIdentity{Hash: fp.Hash, PreviousHash: fp.PreviousHash, FingerprintVersion: fp.Version}
+	if fp.PreviousHash != "" {
+		fmt.Printf("[App] Device fingerprint migrating, reporting the previous hash to BB-Core\n")
+	}
+	return nil
+}
+
+// newAPIClient creates a BB-Core client that reports this device's fingerprint
+func (a *App) newAPIClient(baseURL, authToken string) *api.Client {
+	client := api.NewClient(baseURL, authToken)
+	client.SetDevice(a.device)
+	return client
+}
+
+// migrateLegacyLogin moves a login stored before accounts existed into an account of the default environment
+func (a *App) migrateLegacyLogin() error {
+	legacyPath := filepath.Join(credentialsDir, "credentials.enc")
+	if _, err := os.Stat(legacyPath); err != nil {
+		return nil
+	}
+
+	// The username is filled in once the login is decrypted (see restoreLogin)
+	account, err := a.envStore.UpsertAccount(environment.DefaultId, "", "")
+	if err != nil {
+		return err
+	}
+	accountDir := filepath.Join(credentialsDir, account.Id)
+	if err := os.MkdirAll(accountDir, 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(legacyPath, filepath.Join(accountDir, "credentials.enc")); err != nil {
+		return err
+	}
+
+	if env, active := a.envStore.Active(); env.Id == environment.DefaultId && active == nil {
+		return a.envStore.SetActive(environment.DefaultId, account.Id)
+	}
+	return nil
+}
+
+// openVault makes an account's encrypted login the current credential storage
+func (a *App) openVault(accountId string) error {
+	manager := auth.NewManager(a.deviceHash)
+	if err := manager.SetStorageDir(filepath.Join(credentialsDir, accountId)); err != nil {
+		return err
+	}
+	a.authManager = manager
+	a.authRefresher = auth.NewRefresher(manager, a.refreshLogin, a.onLoginExpired)
+	a.entitlements = auth.NewEntitlementCache(filepath.Join(credentialsDir, accountId), a.deviceHash, offlineGrace())
+	return nil
+}
+
+// activateAccount makes the account of a fresh login active in the current environment
+func (a *App) activateAccount(username, agencyName string) error {
+	env, current := a.envStore.Active()
+	if current != nil && current.Username == username {
+		return nil
+	}
+	if a.authRefresher != nil {
+		a.authRefresher.Stop()
+	}
+
+	account, err := a.envStore.UpsertAccount(env.Id, username, agencyName)
+	if err != nil {
+		return err
+	}
+	if err := a.envStore.SetActive(env.Id, account.Id); err != nil {
+		return err
+	}
+	return a.openVault(account.Id)
+}
+
+// forgetLogin clears the active account's stored login and cached entitlement. The account stays
+// stored so the user can log in to it again or switch back; RemoveAccount deletes it.
+func (a *App) forgetLogin() error {
+	if a.authManager != nil {
+		if err := a.authManager.ClearCredentials(); err != nil {
+			return fmt.Errorf("clear credentials: %w", err)
+		}
+	}
+	if a.entitlements != nil {
+		a.entitlements.Clear()
+	}
+	return nil
+}
+
+// GetLoginVaultStatus describes the stored login, e.g. whether a passphrase is needed to unlock it
+func (a *App) GetLoginVaultStatus() auth.VaultStatus {
+	if a.authManager == nil {
+		return auth.VaultStatus{}
+	}
+	return a.authManager.Status()
+}
+
+// UnlockStoredLogin opens a passphrase-protected stored login and logs in with it
+func (a *App) UnlockStoredLogin(passphrase string) (*auth.Credentials, error) {
+	if a.authManager == nil {
+		return nil, fmt.Errorf("no stored login")
+	}
+
+	a.authManager.SetPassphrase(passphrase)
+	if err := a.restoreLogin(); err != nil {
+		return nil, err
+	}
+	return a.authManager.GetCredentials(), nil
+}
+
+// SetLoginPassphrase protects the stored login with a passphrase ("" = this device's key only)
+func (a *App) SetLoginPassphrase(passphrase string) error {
+	if a.authManager == nil {
+		return fmt.Errorf("not logged in")
+	}
+	return a.authManager.ChangePassphrase(passphrase)
+}
+
+// restoreLogin logs in with the stored credentials, refreshing them first when they are about to expire.
+// Nothing stored is not an error; a locked login is kept until UnlockStoredLogin.
+func (a *App) restoreLogin() error {
+	if a.authManager == nil {
+		return nil // No account selected
+	}
+
+	creds, err := a.authManager.LoadCredentials()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if errors.Is(err, auth.ErrInvalidKey) && a.device.PreviousHash != "" {
+		// Stored under the fingerprint this device had before
+		if rekeyErr := a.authManager.Rekey(a.device.PreviousHash); rekeyErr == nil {
+			creds, err = a.authManager.LoadCredentials()
+		}
+	}
+	if errors.Is(err, auth.ErrPassphraseRequired) || errors.Is(err, auth.ErrInvalidKey) {
+		return err
+	}
+	if errors.Is(err, auth.ErrCorrupted) {
+		a.forgetLogin()
+		return fmt.Errorf("stored login unreadable, discarded: %w", err)
+	}
+	if err != nil {
+		// May be a passing I/O problem; the login is tried again on the next start
+		return fmt.Errorf("stored login could not be read: %w", err)
+	}
+
+	client := a.newAPIClient(a.bbCoreURL, creds.AccessToken)
+	client.SetTokens(creds.AccessToken, creds.RefreshToken)
+	a.setAPIClient(client)
+
+	if creds.NeedsRefresh() {
+		ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+		_, err := client.RefreshAccessTokenContext(ctx)
+		cancel()
+
+		if err != nil && api.IsAuthRejected(err) {
+			// BB-Core refused the refresh token; the user logs in again
+			a.forgetLogin()
+			client.SetTokens("", "")
+			return fmt.Errorf("stored login was rejected by BB-Core: %w", err)
+		}
+		if err != nil {
+			// Probably offline: keep the login for offline starts, the refresher keeps trying
+			fmt.Printf("[App] WARNING: Token refresh failed, keeping the stored login: %v\n", err)
+		}
+	}
+
+	creds = a.authManager.GetCredentials()
+	a.sessions.SetMaxRooms(creds.Agency.MaxRooms)
+	if a.envStore != nil {
+		if _, account := a.envStore.Active(); account != nil && account.Username == "" {
+			a.envStore.UpdateAccount(account.Id, creds.User.Username, creds.Agency.Name) // Migrated login
+		}
+	}
+
+	a.authRefresher.Start()
+	fmt.Printf("[App] ✓ Logged in as %s (stored login)\n", creds.User.Username)
+	return nil
+}
+
+// applyLogin makes a fresh login current: API client tokens, room quota, storage and background refresh
+func (a *App) applyLogin(authResp *api.AuthResponse) {
+	// The login becomes the active account of the current environment
+	if a.envStore != nil {
+		if err := a.activateAccount(authResp.User.Username, authResp.Agency.Name); err != nil {
+			fmt.Printf("[App] WARNING: Failed to store account: %v\n", err)
+		}
+	}
+
+	if a.apiClient == nil {
+		a.setAPIClient(a.newAPIClient(a.bbCoreURL, ""))
+	}
+	a.apiClient.SetTokens(authResp.AccessToken, authResp.RefreshToken)
+	a.persistLogin(authResp)
+
+	if a.authRefresher != nil {
+		a.authRefresher.Start()
+	}
+}
+
+// setAPIClient makes client the app's BB-Core client, persisting every token refresh it does
+func (a *App) setAPIClient(client *api.Client) {
+	if a.apiClient != nil && a.apiClient != client {
+		a.apiClient.OnTokensRefreshed(nil)
+	}
+	client.OnTokensRefreshed(a.persistLogin)
+	a.apiClient = client
+}
+
+// persistLogin stores new tokens, applies the agency's room quota and tells the frontend
+func (a *App) persistLogin(authResp *api.AuthResponse) {
+	// Apply the agency's concurrent room quota
+	a.sessions.SetMaxRooms(authResp.Agency.MaxRooms)
+
+	if a.authManager != nil {
+		if err := a.authManager.SaveCredentials(credentialsFromAuth(authResp)); err != nil {
+			fmt.Printf("[App] WARNING: Failed to store login: %v\n", err)
+		}
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "auth:tokens", authResp)
+	}
+}
+
+// refreshLogin is the auth.RefreshFunc of the background refresher
+func (a *App) refreshLogin(ctx context.Context) error {
+	if a.apiClient == nil {
+		return fmt.Errorf("API client not initialized")
+	}
+	_, err := a.apiClient.RefreshAccessTokenContext(ctx) // Saved through persistLogin
+	return err
+}
+
+// onLoginExpired tells the frontend that the user has to log in again once BB-Core rejects the
+// refresh token. Failures while offline are left to the refresher, which keeps retrying.
+func (a *App) onLoginExpired(err error) {
+	if !api.IsAuthRejected(err) {
+		return
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "auth:expired", err.Error())
+	}
+}
+
+// credentialsFromAuth converts a BB-Core auth response into storable credentials
+func credentialsFromAuth(authResp *api.AuthResponse) *auth.Credentials {
+	expiresAt := authResp.ExpiresAt
+	if expiresAt.IsZero() {
+		ttl := time.Duration(authResp.ExpiresIn) * time.Millisecond
+		if ttl <= 0 {
+			ttl = defaultTokenTTL
+		}
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	return &auth.Credentials{
+		AccessToken:  authResp.AccessToken,
+		RefreshToken: authResp.RefreshToken,
+		ExpiresAt:    expiresAt,
+		User: auth.User{
+			ID:        authResp.User.ID,
+			Username:  authResp.User.Username,
+			Email:     authResp.User.Email,
+			FirstName: authResp.User.FirstName,
+			LastName:  authResp.User.LastName,
+			RoleCode:  authResp.User.RoleCode,
+		},
+		Agency: auth.Agency{
+			ID:           authResp.Agency.ID,
+			Name:         authResp.Agency.Name,
+			Plan:         authResp.Agency.Plan,
+			Status:       authResp.Agency.Status,
+			MaxRooms:     authResp.Agency.MaxRooms,
+			CurrentRooms: authResp.Agency.CurrentRooms,
+			ExpiresAt:    authResp.Agency.ExpiresAt,
+		},
+	}
 }
 
 // Config and Validation Wails Bindings
@@ -1072,7 +2252,94 @@ func (a *App) ValidateTrial(streamers []api.ValidateTrialStreamer) (*api.Validat
 	if a.apiClient == nil {
 		return nil, fmt.Errorf("not connected to BB-Core")
 	}
-	return a.apiClient.ValidateTrial(streamers)
+	resp, _, err := a.validateTrial(context.Background(), streamers)
+	return resp, err
+}
+
+// validateTrial is the session.TrialValidator of every room: it asks BB-Core and caches the answer,
+// and while BB-Core is unreachable it answers from the cached entitlement within the grace period
+func (a *App) validateTrial(ctx context.Context, streamers []api.ValidateTrialStreamer) (*api.ValidateTrialResponse, bool, error) {
+	client, cache := a.apiClient, a.entitlements
+	if client == nil {
+		return nil, false, fmt.Errorf("not connected to BB-Core")
+	}
+
+	bigoIds := make([]string, 0, len(streamers))
+	for _, streamer := range streamers {
+		bigoIds = append(bigoIds, streamer.BigoId)
+	}
+
+	resp, err := client.ValidateTrialContext(ctx, streamers)
+	var apiErr *api.APIError
+	switch {
+	case err == nil:
+		if cache != nil {
+			a.recordEntitlement(cache, bigoIds, resp)
+		}
+		return resp, false, nil
+	case errors.As(err, &apiErr) || ctx.Err() != nil:
+		return nil, false, err // BB-Core answered, or the caller gave up
+	case cache == nil:
+		return nil, true, err
+	}
+
+	ent, cacheErr := cache.Covers(bigoIds, time.Now())
+	if cacheErr != nil {
+		return nil, true, fmt.Errorf("%w (offline start refused: %v)", err, cacheErr)
+	}
+	fmt.Printf("[App] BB-Core unreachable, using the entitlement validated at %s: %v\n", ent.ValidatedAt.Format(time.RFC3339), err)
+	return &api.ValidateTrialResponse{
+		Allowed: true,
+		Message: fmt.Sprintf("offline, last validated %s", ent.ValidatedAt.Format(time.RFC3339)),
+	}, true, nil
+}
+
+// recordEntitlement updates the cached entitlement with a BB-Core trial validation
+func (a *App) recordEntitlement(cache *auth.EntitlementCache, bigoIds []string, resp *api.ValidateTrialResponse) {
+	var err error
+	switch {
+	case resp.Allowed:
+		var agency auth.Agency
+		if a.authManager != nil {
+			if creds := a.authManager.GetCredentials(); creds != nil {
+				agency = creds.Agency
+			}
+		}
+		err = cache.Grant(bigoIds, agency)
+	case len(resp.BlockedBigoIds) > 0:
+		err = cache.Revoke(resp.BlockedBigoIds)
+	default:
+		err = cache.Revoke(bigoIds)
+	}
+	if err != nil {
+		fmt.Printf("[App] WARNING: Failed to cache trial validation: %v\n", err)
+	}
+}
+
+// onTrialRevoked stops a room that started offline once BB-Core refuses it
+func (a *App) onTrialRevoked(roomId, reason string) {
+	go func() {
+		if err := a.StopPKSession(roomId, reason); err != nil {
+			fmt.Printf("[App] WARNING: Failed to stop revoked session %s: %v\n", roomId, err)
+		}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "session:revoked", map[string]string{"roomId": roomId, "reason": reason})
+		}
+	}()
+}
+
+// offlineGrace reads BB_OFFLINE_GRACE, falling back to defaultOfflineGrace
+func offlineGrace() time.Duration {
+	value := os.Getenv("BB_OFFLINE_GRACE")
+	if value == "" {
+		return defaultOfflineGrace
+	}
+	grace, err := time.ParseDuration(value)
+	if err != nil || grace < 0 {
+		fmt.Printf("[App] WARNING: Invalid BB_OFFLINE_GRACE %q, using %s\n", value, defaultOfflineGrace)
+		return defaultOfflineGrace
+	}
+	return grace
 }
 
 // Bigo API Integration
@@ -1135,10 +2402,12 @@ func (a *App) SaveGiftLibrary(gifts []api.GiftDefinition) error {
 
 	a.giftLibrary = gifts
 
-	// Update active session if exists
-	if a.session != nil {
-		fmt.Println("[App] Push updated gift library to active session")
-		a.session.SetGiftLibrary(gifts)
+	// Update active sessions if any
+	for _, roomId := range a.sessions.RoomIds() {
+		if sess, ok := a.sessions.Get(roomId); ok {
+			fmt.Printf("[App] Push updated gift library to active session of room %s\n", roomId)
+			sess.SetGiftLibrary(gifts)
+		}
 	}
 
 	// Ensure data directory exists
@@ -0,0 +1,68 @@
+// Command bbcore-mock runs an in-memory BB-Core for rehearsing sessions without a backend.
+//
+//	go run ./cmd/bbcore-mock -addr :8080
+//	BB_CORE_URL=http://localhost:8080 wails dev
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"bbapp/internal/api"
+	"bbapp/internal/bbcoremock"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	username := flag.String("user", "demo", "login username")
+	password := flag.String("password", "demo123", "login password")
+	configFile := flag.String("config", "", "JSON file with an api.Config served for its roomId (default: a two-team demo config)")
+	pkInterval := flag.Duration("pk-interval", 0, "also publish PK_SYNC for rooms with an active script at this interval (0 = only after gifts)")
+	flag.Parse()
+
+	server := bbcoremock.NewServer(*username, *password)
+
+	if *configFile != "" {
+		cfg, err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatalf("load config: %v", err)
+		}
+		server.SetConfig(*cfg)
+	}
+
+	if *pkInterval > 0 {
+		go func() {
+			for range time.Tick(*pkInterval) {
+				for _, script := range server.Scripts() {
+					if script.Status == bbcoremock.StatusActive {
+						server.PublishPKSync(script.RoomId)
+					}
+				}
+			}
+		}()
+	}
+
+	fmt.Printf("[MockCore] BB-Core mock listening on %s (login %s / %s)\n", *addr, *username, *password)
+	fmt.Printf("[MockCore] STOMP: ws://localhost%s/ws (SockJS and plain WebSocket)\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
+
+func loadConfig(path string) (*api.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg api.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.RoomId == "" {
+		return nil, fmt.Errorf("config has no roomId")
+	}
+	return &cfg, nil
+}
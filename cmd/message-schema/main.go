@@ -0,0 +1,34 @@
+// Command message-schema writes the JSON Schema of the messages BBapp sends to BB-Core.
+//
+//	go generate ./internal/message
+//	go run ./cmd/message-schema -out docs/schema
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"bbapp/internal/message"
+)
+
+func main() {
+	out := flag.String("out", "docs/schema", "output directory")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatalf("create %s: %v", *out, err)
+	}
+	for name, schema := range message.Schemas() {
+		data, err := message.MarshalSchema(schema)
+		if err != nil {
+			log.Fatalf("marshal %s: %v", name, err)
+		}
+		path := filepath.Join(*out, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			log.Fatalf("write %s: %v", path, err)
+		}
+		log.Printf("wrote %s", path)
+	}
+}
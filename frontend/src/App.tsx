@@ -1,10 +1,13 @@
 import { useState, useEffect } from 'react';
 import { LoginPage } from './components/LoginPage';
+import { AccountSwitcher } from './components/AccountSwitcher';
 import { RegisterPage } from './components/RegisterPage';
 import { PKModeScene } from './scenes/pk-mode/ui/PKModeScene';
 import { OverlayApp } from './components/overlay/OverlayApp';
-import { RefreshAuthToken, InitializeBBCoreClient, GetBBCoreURL } from '../wailsjs/go/main/App';
+import { GetAuthSession, GetLoginVaultStatus, UnlockStoredLogin, SetLoginPassphrase, Logout, InitializeBBCoreClient, GetBBCoreURL } from '../wailsjs/go/main/App';
+import { EventsOn } from '../wailsjs/runtime/runtime';
 import type { User } from './shared/types';
+import type { auth } from '../wailsjs/go/models';
 
 import { Layout } from './components/layout/Layout';
 import { Button } from './components/ui/button';
@@ -33,24 +36,52 @@ function App() {
   const [activeTab, setActiveTab] = useState('pk-mode'); // Default to PK Mode for now
   const [sessionActive, setSessionActive] = useState(false);
 
-  // Token refresh timer
+  // Restore the login stored by the backend, so users stay logged in across launches
   useEffect(() => {
-    if (!isAuthenticated || !refreshToken) return;
-
-    const interval = setInterval(async () => {
-      try {
-        const response = await RefreshAuthToken(refreshToken);
-        setAccessToken(response.accessToken);
-        setRefreshToken(response.refreshToken);
-        console.log('Token refreshed successfully');
-      } catch (error) {
-        console.error('Token refresh failed:', error);
-        handleLogout();
+    const restore = async () => {
+      let session = await GetAuthSession();
+      if (!session) {
+        // A passphrase-protected login has to be unlocked first
+        const vault = await GetLoginVaultStatus();
+        if (vault.exists && vault.passphraseProtected && !vault.unlocked) {
+          const passphrase = window.prompt('Enter your passphrase to unlock the saved login');
+          if (passphrase) {
+            session = await UnlockStoredLogin(passphrase);
+          }
+        }
       }
-    }, 50 * 60 * 1000);
+      if (session && session.accessToken) {
+        handleLoginSuccess(session.accessToken, session.refreshToken, session.user as User);
+      }
+    };
+    restore().catch((error) => console.error('Failed to restore login:', error));
+  }, []);
+
+  // The backend refreshes tokens before they expire; keep ours in sync
+  useEffect(() => {
+    const offTokens = EventsOn('auth:tokens', (response) => {
+      localStorage.setItem('auth_token', response.accessToken);
+      localStorage.setItem('refresh_token', response.refreshToken);
+      setAccessToken(response.accessToken);
+      setRefreshToken(response.refreshToken);
+    });
+    const offExpired = EventsOn('auth:expired', (reason) => {
+      console.error('Login expired:', reason);
+      handleLogout();
+    });
+
+    // A session started on the cached entitlement was refused once BB-Core was reachable again
+    const offRevoked = EventsOn('session:revoked', (event) => {
+      setSessionActive(false);
+      window.alert(`Session in room ${event.roomId} stopped: ${event.reason}`);
+    });
 
-    return () => clearInterval(interval);
-  }, [isAuthenticated, refreshToken]);
+    return () => {
+      offTokens();
+      offExpired();
+      offRevoked();
+    };
+  }, []);
 
   const handleLoginSuccess = async (
     newAccessToken: string,
@@ -77,7 +108,20 @@ function App() {
   };
 
   const handleLogout = () => {
-    // Clear tokens
+    // Forget the login stored by the backend, then clear ours
+    Logout().catch((error) => console.error('Logout failed:', error));
+    clearSession();
+  };
+
+  // After switching environment or account the backend has already rebuilt its clients
+  const handleSwitched = (session: auth.Credentials | null) => {
+    clearSession();
+    if (session && session.accessToken) {
+      handleLoginSuccess(session.accessToken, session.refreshToken, session.user as User);
+    }
+  };
+
+  const clearSession = () => {
     localStorage.removeItem('auth_token');
     localStorage.removeItem('refresh_token');
 
@@ -89,6 +133,16 @@ function App() {
     setSessionActive(false);
   };
 
+  const handleSetPassphrase = async () => {
+    const passphrase = window.prompt('Passphrase for the saved login (leave empty to use this device only)');
+    if (passphrase === null) return;
+    try {
+      await SetLoginPassphrase(passphrase);
+    } catch (error) {
+      console.error('Failed to set passphrase:', error);
+    }
+  };
+
   const handleTabChange = async (newTab: string) => {
     if (sessionActive && activeTab === 'pk-mode' && newTab !== 'pk-mode') {
       const confirmed = window.confirm(
@@ -111,10 +165,15 @@ function App() {
       );
     }
     return (
-      <LoginPage
-        onLoginSuccess={handleLoginSuccess}
-        onSwitchToRegister={() => setAuthView('register')}
-      />
+      <>
+        <div className="fixed top-4 right-4 z-10">
+          <AccountSwitcher onSwitched={handleSwitched} />
+        </div>
+        <LoginPage
+          onLoginSuccess={handleLoginSuccess}
+          onSwitchToRegister={() => setAuthView('register')}
+        />
+      </>
     );
   }
 
@@ -130,7 +189,9 @@ function App() {
           </h1>
         </div>
         <div className="flex items-center gap-2">
+          <AccountSwitcher onSwitched={handleSwitched} sessionActive={sessionActive} />
           <span className="text-sm text-muted-foreground mr-2">Welcome, {user?.username}</span>
+          <Button variant="ghost" size="sm" onClick={handleSetPassphrase}>Passphrase</Button>
           <Button variant="outline" size="sm" onClick={handleLogout}>Logout</Button>
         </div>
       </div>
@@ -0,0 +1,86 @@
+import React, { useEffect, useState } from 'react';
+import { GetEnvironments, SwitchEnvironment, SwitchAccount } from '../../wailsjs/go/main/App';
+import { auth, environment } from '../../wailsjs/go/models';
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from "@/components/ui/select";
+
+// Value of the account entry that opens the login page to add another account
+const ADD_ACCOUNT = '__add__';
+
+interface AccountSwitcherProps {
+  // Called after a switch with the new login, or null when the user has to log in
+  onSwitched: (session: auth.Credentials | null) => void;
+  // Shows the account picker next to the environment picker (hidden on the login page)
+  showAccounts?: boolean;
+  // Asked before switching, since running rooms are stopped
+  sessionActive?: boolean;
+}
+
+export const AccountSwitcher: React.FC<AccountSwitcherProps> = ({ onSwitched, showAccounts = true, sessionActive = false }) => {
+  const [state, setState] = useState<environment.State | null>(null);
+
+  const reload = () => GetEnvironments().then(setState).catch((error) => console.error('Failed to load environments:', error));
+
+  useEffect(() => {
+    reload();
+  }, []);
+
+  const confirmSwitch = () =>
+    !sessionActive || window.confirm('Switching stops all running sessions. Continue?');
+
+  const handleEnvironment = async (id: string) => {
+    if (!state || id === state.activeEnvironmentId || !confirmSwitch()) return;
+    try {
+      onSwitched(await SwitchEnvironment(id));
+    } catch (error) {
+      console.error('Failed to switch environment:', error);
+      onSwitched(null);
+    }
+    reload();
+  };
+
+  const handleAccount = async (id: string) => {
+    if (!state || id === state.activeAccountId || !confirmSwitch()) return;
+    try {
+      onSwitched(await SwitchAccount(id === ADD_ACCOUNT ? '' : id));
+    } catch (error) {
+      console.error('Failed to switch account:', error);
+      onSwitched(null);
+    }
+    reload();
+  };
+
+  if (!state || !state.environments) return null;
+
+  const accounts = (state.accounts || []).filter((acc) => acc.environmentId === state.activeEnvironmentId);
+
+  return (
+    <div className="flex items-center gap-2">
+      <Select value={state.activeEnvironmentId} onValueChange={handleEnvironment}>
+        <SelectTrigger className="h-8 w-[150px]">
+          <SelectValue placeholder="Environment" />
+        </SelectTrigger>
+        <SelectContent>
+          {state.environments.map((env) => (
+            <SelectItem key={env.id} value={env.id}>{env.label}</SelectItem>
+          ))}
+        </SelectContent>
+      </Select>
+
+      {showAccounts && (
+        <Select value={state.activeAccountId || undefined} onValueChange={handleAccount}>
+          <SelectTrigger className="h-8 w-[180px]">
+            <SelectValue placeholder="Account" />
+          </SelectTrigger>
+          <SelectContent>
+            {accounts.map((acc) => (
+              <SelectItem key={acc.id} value={acc.id}>
+                {acc.username || 'Saved login'}{acc.agencyName ? ` (${acc.agencyName})` : ''}
+              </SelectItem>
+            ))}
+            <SelectItem value={ADD_ACCOUNT}>Add account…</SelectItem>
+          </SelectContent>
+        </Select>
+      )}
+    </div>
+  );
+};
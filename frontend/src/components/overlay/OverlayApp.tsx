@@ -25,6 +25,10 @@ export const OverlayApp: React.FC<OverlayAppProps> = () => {
     useEffect(() => { configRef.current = config; }, [config]);
     useEffect(() => { gameStateRef.current = gameState; }, [gameState]);
 
+    // Operator time extensions (TIMER_ADJUST) for the round identified by key
+    const timerAdjustRef = useRef<{ key: string; ms: number }>({ key: '', ms: 0 });
+    const roundKey = (session: any) => session ? `${session.sessionId}:${session.startTime}` : '';
+
     // Parse query params
     const params = new URLSearchParams(window.location.search);
     const bbCoreUrl = params.get('bbCoreUrl');
@@ -48,6 +52,17 @@ export const OverlayApp: React.FC<OverlayAppProps> = () => {
         console.log("[DataFlow] 5. Received Message:", msg?.type, msg);
         setMessages(prev => [msg, ...prev].slice(0, 50));
 
+        // Operator add_time: extend the running round, a new round starts without it
+        if (msg.type === 'TIMER_ADJUST') {
+            const key = roundKey(gameStateRef.current?.session || configRef.current?.session);
+            const adjust = timerAdjustRef.current;
+            timerAdjustRef.current = {
+                key,
+                ms: (adjust.key === key ? adjust.ms : 0) + (Number(msg.seconds) || 0) * 1000
+            };
+            return;
+        }
+
         // 0. Handle CONFIG_UPDATE (Priority)
         if (msg.type === 'CONFIG' || msg.type === 'CONFIG_UPDATE') {
             console.log("[DataFlow] Received CONFIG update via SSE", msg);
@@ -167,8 +182,8 @@ export const OverlayApp: React.FC<OverlayAppProps> = () => {
             // 1. Try Local Config first (persisted by Wails App, contains OverlaySettings)
             try {
                 // In dev (port 5173), hit localhost:3000. In prod (port 3000), hit relative path.
-                const localUrl = window.location.port === "5173" ? "http://localhost:3000/config" : "/config";
-                const localRes = await fetch(localUrl);
+                const localBase = window.location.port === "5173" ? "http://localhost:3000/config" : "/config";
+                const localRes = await fetch(`${localBase}?roomId=${encodeURIComponent(roomId)}`);
                 if (localRes.ok) {
                     const localData = await localRes.json();
                     console.log("[DataFlow] 1a. Loaded LOCAL config:", localData?.overlaySettings);
@@ -230,16 +245,24 @@ export const OverlayApp: React.FC<OverlayAppProps> = () => {
         loadLib();
 
         // Setup SSE for local events (Robust Gift Delivery)
-        const localUrl = window.location.port === "5173" ? "http://localhost:3000/events" : "/events";
+        const localBase = window.location.port === "5173" ? "http://localhost:3000/events" : "/events";
+        // Resume after the last event seen before a reload; the browser sends Last-Event-ID itself on reconnects
+        const lastEventKey = `bbapp:lastEventId:${roomId}`;
+        const lastEventId = sessionStorage.getItem(lastEventKey);
+        const resumeParam = lastEventId ? `&lastEventId=${encodeURIComponent(lastEventId)}` : "";
+        const localUrl = `${localBase}?roomId=${encodeURIComponent(roomId)}${resumeParam}`;
         console.log("[SSE] Connecting to:", localUrl);
         const eventSource = new EventSource(localUrl);
 
         eventSource.onopen = () => console.log("[SSE] Connected to local OverlayServer events");
 
-        eventSource.onmessage = (event) => {
+        const onLocalEvent = (event: MessageEvent) => {
+            if (event.lastEventId) {
+                sessionStorage.setItem(lastEventKey, event.lastEventId);
+            }
             try {
                 const data = JSON.parse(event.data);
-                // Filter by room if possible (though local server is usually 1 room active)
+                // Server already filters by room; keep the check for events broadcast to all rooms
                 if (data.roomId && data.roomId !== roomId) {
                     return;
                 }
@@ -249,6 +272,11 @@ export const OverlayApp: React.FC<OverlayAppProps> = () => {
                 console.error("[SSE] Failed to parse event", e);
             }
         };
+        // Events are named after their topic
+        ["gifts", "scores", "timer", "config", "events"].forEach((topic) =>
+            eventSource.addEventListener(topic, onLocalEvent)
+        );
+        eventSource.onmessage = onLocalEvent;
 
 
         // 2. Connect to WebSocket via SockJS/Stomp directly
@@ -435,7 +463,9 @@ export const OverlayApp: React.FC<OverlayAppProps> = () => {
             const now = Date.now();
             const startMs = startTime < 10000000000 ? startTime * 1000 : startTime;
             const durationMs = (durationMinutes || 0) * 60 * 1000;
-            const endTime = startMs + durationMs;
+            const adjust = timerAdjustRef.current;
+            const extraMs = adjust.key === roundKey(currentSession) ? adjust.ms : 0;
+            const endTime = startMs + durationMs + extraMs;
 
             const remaining = endTime - now;
 
@@ -120,8 +120,8 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
         const fetchStatus = async () => {
             try {
                 const [listener, stream] = await Promise.all([
-                    GetBigoListenerStatus(),
-                    GetBBCoreStreamStatus()
+                    GetBigoListenerStatus(roomId),
+                    GetBBCoreStreamStatus(roomId)
                 ]);
                 setListenerStatus(listener);
                 setStreamStatus(stream);
@@ -139,7 +139,7 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
         fetchStatus(); // Initial fetch
         const interval = setInterval(fetchStatus, 2000);
         return () => clearInterval(interval);
-    }, [onSessionActiveChange]);
+    }, [roomId, onSessionActiveChange]);
 
     // Generate Overlay URL with localstorage colors
     useEffect(() => {
@@ -188,7 +188,7 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
     const handleStartListener = async () => {
         try {
             setListenerLoading(true);
-            await StartBigoListener(config);
+            await StartBigoListener(roomId, config);
             toast({
                 title: "Listener Started",
                 description: "Connected to Bigo room successfully.",
@@ -207,7 +207,7 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
     const handleStopListener = async () => {
         try {
             setListenerLoading(true);
-            await StopBigoListener();
+            await StopBigoListener(roomId);
             toast({
                 title: "Listener Stopped",
                 description: "Bigo listener session ended.",
@@ -245,7 +245,7 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
     const handleStopStream = async () => {
         try {
             setStreamLoading(true);
-            await StopBBCoreStream("User requested");
+            await StopBBCoreStream(roomId, "User requested");
             toast({
                 title: "Streaming Stopped",
                 description: "BB-Core session ended.",
@@ -271,7 +271,7 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
 
         try {
             setListenerLoading(true);
-            await ResetSession();
+            await ResetSession(roomId);
             toast({
                 title: "Session Reset",
                 description: "Session manager has been forcibly reset.",
@@ -398,6 +398,17 @@ export function SessionControlPanel({ config, roomId, durationMinutes, onBack, o
                         </div>
                     )}
 
+                    {listenerStatus?.filterStats && Object.keys(listenerStatus.filterStats.hits || {}).length > 0 && (
+                        <div className="space-y-1 text-sm">
+                            <div className="text-muted-foreground">Filter Hits ({listenerStatus.filterStats.evaluated} evaluated)</div>
+                            <div className="flex flex-wrap gap-2">
+                                {Object.entries(listenerStatus.filterStats.hits).map(([reason, count]: [string, any]) => (
+                                    <Badge key={reason} variant="secondary">{reason}: {count}</Badge>
+                                ))}
+                            </div>
+                        </div>
+                    )}
+
                     {listenerStatus?.connections && listenerStatus.connections.length > 0 && (
                         <div className="space-y-2">
                             <div className="text-sm font-semibold">Connections:</div>
@@ -2,8 +2,17 @@
 // This file is automatically generated. DO NOT EDIT
 import {api} from '../models';
 import {profile} from '../models';
+import {rules} from '../models';
 import {listener} from '../models';
+import {auth} from '../models';
 import {session} from '../models';
+import {environment} from '../models';
+import {sinks} from '../models';
+import {filter} from '../models';
+import {goals} from '../models';
+import {leaderboard} from '../models';
+import {report} from '../models';
+import {dance} from '../models';
 
 export function AddStreamer(arg1:string,arg2:string,arg3:string):Promise<void>;
 
@@ -11,66 +20,138 @@ export function ConnectToCore(arg1:string,arg2:string,arg3:string):Promise<void>
 
 export function CreateProfile(arg1:string,arg2:string,arg3:api.Config):Promise<profile.Profile>;
 
+export function DeleteEnvironment(arg1:string):Promise<void>;
+
 export function DeleteProfile(arg1:string):Promise<void>;
 
+export function DryRunRules(arg1:rules.RuleSet,arg2:string,arg3:string):Promise<Array<rules.Firing>>;
+
 export function FetchBigoUser(arg1:string):Promise<listener.BigoUserInfo>;
 
 export function FetchConfig(arg1:string):Promise<api.Config>;
 
 export function FetchGlobalIdols():Promise<Array<api.GlobalIdol>>;
 
+export function GetAuthSession():Promise<auth.Credentials>;
+
 export function GetBBAppConfig(arg1:string):Promise<api.Config>;
 
-export function GetBBCoreStreamStatus():Promise<session.BBCoreStreamStatus>;
+export function GetBBCoreStreamStatus(arg1:string):Promise<session.BBCoreStreamStatus>;
 
 export function GetBBCoreURL():Promise<string>;
 
-export function GetBigoListenerStatus():Promise<session.BigoListenerStatus>;
+export function GetBigoListenerStatus(arg1:string):Promise<session.BigoListenerStatus>;
 
 export function GetConnections():Promise<Array<Record<string, string>>>;
 
+export function GetControlURL(arg1:string):Promise<string>;
+
+export function GetEnvironments():Promise<environment.State>;
+
+export function GetEventSinkHealth(arg1:string):Promise<Array<sinks.Health>>;
+
+export function GetFilterStats(arg1:string):Promise<filter.Stats>;
+
 export function GetGiftLibrary():Promise<Array<api.GiftDefinition>>;
 
+export function GetGoalProgress(arg1:string):Promise<Array<goals.Progress>>;
+
+export function GetLeaderboard(arg1:string,arg2:number):Promise<leaderboard.Snapshot>;
+
+export function GetLoginVaultStatus():Promise<auth.VaultStatus>;
+
 export function GetOverlayURL(arg1:string,arg2:string,arg3:string):Promise<string>;
 
-export function GetSessionStatus():Promise<session.Status>;
+export function GetReport(arg1:string,arg2:string):Promise<report.Report>;
+
+export function GetSessionStatus(arg1:string):Promise<session.Status>;
+
+export function GetStickerDanceState(arg1:string):Promise<dance.State>;
 
 export function InitializeBBCoreClient(arg1:string,arg2:string):Promise<void>;
 
 export function ListProfiles():Promise<Array<profile.Profile>>;
 
+export function ListReports(arg1:string):Promise<Array<report.Summary>>;
+
+export function ListSessionRooms():Promise<Array<string>>;
+
 export function LoadProfile(arg1:string):Promise<profile.Profile>;
 
 export function Login(arg1:string,arg2:string):Promise<api.AuthResponse>;
 
+export function Logout():Promise<void>;
+
+export function OpenReport(arg1:string,arg2:string,arg3:string):Promise<void>;
+
 export function RefreshAuthToken(arg1:string):Promise<api.AuthResponse>;
 
 export function Register(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<api.AuthResponse>;
 
-export function RemoveStreamer(arg1:string):Promise<void>;
+export function RemoveAccount(arg1:string):Promise<void>;
+
+export function RemoveStreamer(arg1:string,arg2:string):Promise<void>;
+
+export function ResetGoal(arg1:string,arg2:string):Promise<void>;
 
-export function ResetSession():Promise<void>;
+export function ResetSession(arg1:string):Promise<void>;
 
 export function SaveBBAppConfig(arg1:string,arg2:api.Config):Promise<void>;
 
+export function SaveEnvironment(arg1:environment.Environment):Promise<void>;
+
 export function SaveGiftLibrary(arg1:Array<api.GiftDefinition>):Promise<void>;
 
 export function SaveGlobalIdols(arg1:Array<api.GlobalIdol>):Promise<void>;
 
+export function SetEventSinks(arg1:string,arg2:sinks.Config):Promise<void>;
+
+export function SetFilterRules(arg1:string,arg2:filter.Rules):Promise<void>;
+
+export function SetLoginPassphrase(arg1:string):Promise<void>;
+
+export function SetRules(arg1:string,arg2:rules.RuleSet):Promise<void>;
+
+export function SkipStickerDance(arg1:string):Promise<void>;
+
 export function StartBBCoreStream(arg1:string,arg2:api.Config,arg3:number):Promise<void>;
 
-export function StartBigoListener(arg1:api.Config):Promise<void>;
+export function StartBigoListener(arg1:string,arg2:api.Config):Promise<void>;
+
+export function StartFreeMode(arg1:string,arg2:goals.Config):Promise<void>;
 
 export function StartPKSession(arg1:string,arg2:string,arg3:string,arg4:api.Config,arg5:number):Promise<void>;
 
-export function StopBBCoreStream(arg1:string):Promise<void>;
+export function StartStickerDance(arg1:string,arg2:api.Config,arg3:dance.Config):Promise<void>;
+
+export function StopBBCoreStream(arg1:string,arg2:string):Promise<void>;
+
+export function StopBigoListener(arg1:string):Promise<void>;
 
-export function StopBigoListener():Promise<void>;
+export function StopFreeMode(arg1:string):Promise<void>;
 
-export function StopPKSession(arg1:string):Promise<void>;
+export function StopPKSession(arg1:string,arg2:string):Promise<void>;
+
+export function StopStickerDance(arg1:string):Promise<void>;
+
+export function SwitchAccount(arg1:string):Promise<auth.Credentials>;
+
+export function SwitchEnvironment(arg1:string):Promise<auth.Credentials>;
+
+export function UnlockStoredLogin(arg1:string):Promise<auth.Credentials>;
 
 export function UpdateProfile(arg1:string,arg2:api.Config):Promise<profile.Profile>;
 
 export function UpdateProfileBigoInfo(arg1:string,arg2:string,arg3:string):Promise<profile.Profile>;
 
+export function UpdateProfileFilters(arg1:string,arg2:filter.Rules):Promise<profile.Profile>;
+
+export function UpdateProfileGoals(arg1:string,arg2:goals.Config):Promise<profile.Profile>;
+
+export function UpdateProfileRules(arg1:string,arg2:rules.RuleSet):Promise<profile.Profile>;
+
+export function UpdateProfileSinks(arg1:string,arg2:sinks.Config):Promise<profile.Profile>;
+
+export function UpdateProfileStickerDance(arg1:string,arg2:dance.Config):Promise<profile.Profile>;
+
 export function ValidateTrial(arg1:Array<api.ValidateTrialStreamer>):Promise<api.ValidateTrialResponse>;
@@ -14,10 +14,18 @@ export function CreateProfile(arg1, arg2, arg3) {
   return window['go']['main']['App']['CreateProfile'](arg1, arg2, arg3);
 }
 
+export function DeleteEnvironment(arg1) {
+  return window['go']['main']['App']['DeleteEnvironment'](arg1);
+}
+
 export function DeleteProfile(arg1) {
   return window['go']['main']['App']['DeleteProfile'](arg1);
 }
 
+export function DryRunRules(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DryRunRules'](arg1, arg2, arg3);
+}
+
 export function FetchBigoUser(arg1) {
   return window['go']['main']['App']['FetchBigoUser'](arg1);
 }
@@ -30,36 +38,76 @@ export function FetchGlobalIdols() {
   return window['go']['main']['App']['FetchGlobalIdols']();
 }
 
+export function GetAuthSession() {
+  return window['go']['main']['App']['GetAuthSession']();
+}
+
 export function GetBBAppConfig(arg1) {
   return window['go']['main']['App']['GetBBAppConfig'](arg1);
 }
 
-export function GetBBCoreStreamStatus() {
-  return window['go']['main']['App']['GetBBCoreStreamStatus']();
+export function GetBBCoreStreamStatus(arg1) {
+  return window['go']['main']['App']['GetBBCoreStreamStatus'](arg1);
 }
 
 export function GetBBCoreURL() {
   return window['go']['main']['App']['GetBBCoreURL']();
 }
 
-export function GetBigoListenerStatus() {
-  return window['go']['main']['App']['GetBigoListenerStatus']();
+export function GetBigoListenerStatus(arg1) {
+  return window['go']['main']['App']['GetBigoListenerStatus'](arg1);
 }
 
 export function GetConnections() {
   return window['go']['main']['App']['GetConnections']();
 }
 
+export function GetControlURL(arg1) {
+  return window['go']['main']['App']['GetControlURL'](arg1);
+}
+
+export function GetEnvironments() {
+  return window['go']['main']['App']['GetEnvironments']();
+}
+
+export function GetEventSinkHealth(arg1) {
+  return window['go']['main']['App']['GetEventSinkHealth'](arg1);
+}
+
+export function GetFilterStats(arg1) {
+  return window['go']['main']['App']['GetFilterStats'](arg1);
+}
+
 export function GetGiftLibrary() {
   return window['go']['main']['App']['GetGiftLibrary']();
 }
 
+export function GetGoalProgress(arg1) {
+  return window['go']['main']['App']['GetGoalProgress'](arg1);
+}
+
+export function GetLeaderboard(arg1, arg2) {
+  return window['go']['main']['App']['GetLeaderboard'](arg1, arg2);
+}
+
+export function GetLoginVaultStatus() {
+  return window['go']['main']['App']['GetLoginVaultStatus']();
+}
+
 export function GetOverlayURL(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetOverlayURL'](arg1, arg2, arg3);
 }
 
-export function GetSessionStatus() {
-  return window['go']['main']['App']['GetSessionStatus']();
+export function GetReport(arg1, arg2) {
+  return window['go']['main']['App']['GetReport'](arg1, arg2);
+}
+
+export function GetSessionStatus(arg1) {
+  return window['go']['main']['App']['GetSessionStatus'](arg1);
+}
+
+export function GetStickerDanceState(arg1) {
+  return window['go']['main']['App']['GetStickerDanceState'](arg1);
 }
 
 export function InitializeBBCoreClient(arg1, arg2) {
@@ -70,6 +118,14 @@ export function ListProfiles() {
   return window['go']['main']['App']['ListProfiles']();
 }
 
+export function ListReports(arg1) {
+  return window['go']['main']['App']['ListReports'](arg1);
+}
+
+export function ListSessionRooms() {
+  return window['go']['main']['App']['ListSessionRooms']();
+}
+
 export function LoadProfile(arg1) {
   return window['go']['main']['App']['LoadProfile'](arg1);
 }
@@ -78,6 +134,14 @@ export function Login(arg1, arg2) {
   return window['go']['main']['App']['Login'](arg1, arg2);
 }
 
+export function Logout() {
+  return window['go']['main']['App']['Logout']();
+}
+
+export function OpenReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['OpenReport'](arg1, arg2, arg3);
+}
+
 export function RefreshAuthToken(arg1) {
   return window['go']['main']['App']['RefreshAuthToken'](arg1);
 }
@@ -86,18 +150,30 @@ export function Register(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['Register'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
-export function RemoveStreamer(arg1) {
-  return window['go']['main']['App']['RemoveStreamer'](arg1);
+export function RemoveAccount(arg1) {
+  return window['go']['main']['App']['RemoveAccount'](arg1);
+}
+
+export function RemoveStreamer(arg1, arg2) {
+  return window['go']['main']['App']['RemoveStreamer'](arg1, arg2);
+}
+
+export function ResetGoal(arg1, arg2) {
+  return window['go']['main']['App']['ResetGoal'](arg1, arg2);
 }
 
-export function ResetSession() {
-  return window['go']['main']['App']['ResetSession']();
+export function ResetSession(arg1) {
+  return window['go']['main']['App']['ResetSession'](arg1);
 }
 
 export function SaveBBAppConfig(arg1, arg2) {
   return window['go']['main']['App']['SaveBBAppConfig'](arg1, arg2);
 }
 
+export function SaveEnvironment(arg1) {
+  return window['go']['main']['App']['SaveEnvironment'](arg1);
+}
+
 export function SaveGiftLibrary(arg1) {
   return window['go']['main']['App']['SaveGiftLibrary'](arg1);
 }
@@ -106,28 +182,76 @@ export function SaveGlobalIdols(arg1) {
   return window['go']['main']['App']['SaveGlobalIdols'](arg1);
 }
 
+export function SetEventSinks(arg1, arg2) {
+  return window['go']['main']['App']['SetEventSinks'](arg1, arg2);
+}
+
+export function SetFilterRules(arg1, arg2) {
+  return window['go']['main']['App']['SetFilterRules'](arg1, arg2);
+}
+
+export function SetLoginPassphrase(arg1) {
+  return window['go']['main']['App']['SetLoginPassphrase'](arg1);
+}
+
+export function SetRules(arg1, arg2) {
+  return window['go']['main']['App']['SetRules'](arg1, arg2);
+}
+
+export function SkipStickerDance(arg1) {
+  return window['go']['main']['App']['SkipStickerDance'](arg1);
+}
+
 export function StartBBCoreStream(arg1, arg2, arg3) {
   return window['go']['main']['App']['StartBBCoreStream'](arg1, arg2, arg3);
 }
 
-export function StartBigoListener(arg1) {
-  return window['go']['main']['App']['StartBigoListener'](arg1);
+export function StartBigoListener(arg1, arg2) {
+  return window['go']['main']['App']['StartBigoListener'](arg1, arg2);
+}
+
+export function StartFreeMode(arg1, arg2) {
+  return window['go']['main']['App']['StartFreeMode'](arg1, arg2);
 }
 
 export function StartPKSession(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['App']['StartPKSession'](arg1, arg2, arg3, arg4, arg5);
 }
 
-export function StopBBCoreStream(arg1) {
-  return window['go']['main']['App']['StopBBCoreStream'](arg1);
+export function StartStickerDance(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartStickerDance'](arg1, arg2, arg3);
+}
+
+export function StopBBCoreStream(arg1, arg2) {
+  return window['go']['main']['App']['StopBBCoreStream'](arg1, arg2);
+}
+
+export function StopBigoListener(arg1) {
+  return window['go']['main']['App']['StopBigoListener'](arg1);
 }
 
-export function StopBigoListener() {
-  return window['go']['main']['App']['StopBigoListener']();
+export function StopFreeMode(arg1) {
+  return window['go']['main']['App']['StopFreeMode'](arg1);
 }
 
-export function StopPKSession(arg1) {
-  return window['go']['main']['App']['StopPKSession'](arg1);
+export function StopPKSession(arg1, arg2) {
+  return window['go']['main']['App']['StopPKSession'](arg1, arg2);
+}
+
+export function StopStickerDance(arg1) {
+  return window['go']['main']['App']['StopStickerDance'](arg1);
+}
+
+export function SwitchAccount(arg1) {
+  return window['go']['main']['App']['SwitchAccount'](arg1);
+}
+
+export function SwitchEnvironment(arg1) {
+  return window['go']['main']['App']['SwitchEnvironment'](arg1);
+}
+
+export function UnlockStoredLogin(arg1) {
+  return window['go']['main']['App']['UnlockStoredLogin'](arg1);
 }
 
 export function UpdateProfile(arg1, arg2) {
@@ -138,6 +262,26 @@ export function UpdateProfileBigoInfo(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateProfileBigoInfo'](arg1, arg2, arg3);
 }
 
+export function UpdateProfileFilters(arg1, arg2) {
+  return window['go']['main']['App']['UpdateProfileFilters'](arg1, arg2);
+}
+
+export function UpdateProfileGoals(arg1, arg2) {
+  return window['go']['main']['App']['UpdateProfileGoals'](arg1, arg2);
+}
+
+export function UpdateProfileRules(arg1, arg2) {
+  return window['go']['main']['App']['UpdateProfileRules'](arg1, arg2);
+}
+
+export function UpdateProfileSinks(arg1, arg2) {
+  return window['go']['main']['App']['UpdateProfileSinks'](arg1, arg2);
+}
+
+export function UpdateProfileStickerDance(arg1, arg2) {
+  return window['go']['main']['App']['UpdateProfileStickerDance'](arg1, arg2);
+}
+
 export function ValidateTrial(arg1) {
   return window['go']['main']['App']['ValidateTrial'](arg1);
 }
@@ -346,100 +346,1408 @@ export namespace api {
 
 }
 
+export namespace auth {
+	
+	export class Agency {
+	    id: number;
+	    name: string;
+	    plan: string;
+	    status: string;
+	    maxRooms: number;
+	    currentRooms: number;
+	    // Go type: time
+	    expiresAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Agency(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.plan = source["plan"];
+	        this.status = source["status"];
+	        this.maxRooms = source["maxRooms"];
+	        this.currentRooms = source["currentRooms"];
+	        this.expiresAt = this.convertValues(source["expiresAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class User {
+	    id: number;
+	    username: string;
+	    email: string;
+	    firstName: string;
+	    lastName: string;
+	    roleCode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new User(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.username = source["username"];
+	        this.email = source["email"];
+	        this.firstName = source["firstName"];
+	        this.lastName = source["lastName"];
+	        this.roleCode = source["roleCode"];
+	    }
+	}
+	export class Credentials {
+	    accessToken: string;
+	    refreshToken: string;
+	    // Go type: time
+	    expiresAt: any;
+	    user: User;
+	    agency: Agency;
+	
+	    static createFrom(source: any = {}) {
+	        return new Credentials(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accessToken = source["accessToken"];
+	        this.refreshToken = source["refreshToken"];
+	        this.expiresAt = this.convertValues(source["expiresAt"], null);
+	        this.user = this.convertValues(source["user"], User);
+	        this.agency = this.convertValues(source["agency"], Agency);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class VaultStatus {
+	    exists: boolean;
+	    version: number;
+	    passphraseProtected: boolean;
+	    unlocked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new VaultStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.exists = source["exists"];
+	        this.version = source["version"];
+	        this.passphraseProtected = source["passphraseProtected"];
+	        this.unlocked = source["unlocked"];
+	    }
+	}
+
+}
+
+export namespace dance {
+	
+	export class Action {
+	    id: string;
+	    name: string;
+	    animation: string;
+	    durationSeconds: number;
+	    priority: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Action(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.animation = source["animation"];
+	        this.durationSeconds = source["durationSeconds"];
+	        this.priority = source["priority"];
+	    }
+	}
+	export class Mapping {
+	    giftId: string;
+	    giftName: string;
+	    actionId: string;
+	    cooldownSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Mapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.giftId = source["giftId"];
+	        this.giftName = source["giftName"];
+	        this.actionId = source["actionId"];
+	        this.cooldownSeconds = source["cooldownSeconds"];
+	    }
+	}
+	export class Config {
+	    actions: Action[];
+	    mappings: Mapping[];
+	    maxQueue: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.actions = this.convertValues(source["actions"], Action);
+	        this.mappings = this.convertValues(source["mappings"], Mapping);
+	        this.maxQueue = source["maxQueue"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Performance {
+	    id: string;
+	    actionId: string;
+	    actionName: string;
+	    animation: string;
+	    priority: number;
+	    durationSeconds: number;
+	    giftId: string;
+	    giftName: string;
+	    senderId: string;
+	    senderName: string;
+	    senderAvatar: string;
+	    // Go type: time
+	    queuedAt: any;
+	    // Go type: time
+	    startedAt?: any;
+	    // Go type: time
+	    endsAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Performance(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.actionId = source["actionId"];
+	        this.actionName = source["actionName"];
+	        this.animation = source["animation"];
+	        this.priority = source["priority"];
+	        this.durationSeconds = source["durationSeconds"];
+	        this.giftId = source["giftId"];
+	        this.giftName = source["giftName"];
+	        this.senderId = source["senderId"];
+	        this.senderName = source["senderName"];
+	        this.senderAvatar = source["senderAvatar"];
+	        this.queuedAt = this.convertValues(source["queuedAt"], null);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.endsAt = this.convertValues(source["endsAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class State {
+	    active: boolean;
+	    current?: Performance;
+	    upcoming: Performance[];
+	    performed: number;
+	    dropped: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new State(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.current = this.convertValues(source["current"], Performance);
+	        this.upcoming = this.convertValues(source["upcoming"], Performance);
+	        this.performed = source["performed"];
+	        this.dropped = source["dropped"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace environment {
+	
+	export class Account {
+	    id: string;
+	    environmentId: string;
+	    username: string;
+	    agencyName: string;
+	    // Go type: time
+	    lastUsedAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Account(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.environmentId = source["environmentId"];
+	        this.username = source["username"];
+	        this.agencyName = source["agencyName"];
+	        this.lastUsedAt = this.convertValues(source["lastUsedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Environment {
+	    id: string;
+	    label: string;
+	    baseUrl: string;
+	    stompUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Environment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.baseUrl = source["baseUrl"];
+	        this.stompUrl = source["stompUrl"];
+	    }
+	}
+	export class State {
+	    environments: Environment[];
+	    accounts: Account[];
+	    activeEnvironmentId: string;
+	    activeAccountId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new State(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.environments = this.convertValues(source["environments"], Environment);
+	        this.accounts = this.convertValues(source["accounts"], Account);
+	        this.activeEnvironmentId = source["activeEnvironmentId"];
+	        this.activeAccountId = source["activeAccountId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace filter {
+	
+	export class ChatRules {
+	    rateLimit: number;
+	    rateWindowSeconds: number;
+	    blockedKeywords: string[];
+	    blockedPatterns: string[];
+	    mask: boolean;
+	    sinks: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ChatRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rateLimit = source["rateLimit"];
+	        this.rateWindowSeconds = source["rateWindowSeconds"];
+	        this.blockedKeywords = source["blockedKeywords"];
+	        this.blockedPatterns = source["blockedPatterns"];
+	        this.mask = source["mask"];
+	        this.sinks = source["sinks"];
+	    }
+	}
+	export class GiftRules {
+	    minOverlayDiamonds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GiftRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.minOverlayDiamonds = source["minOverlayDiamonds"];
+	    }
+	}
+	export class SenderRules {
+	    blocked: string[];
+	    allowed: string[];
+	    sinks: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SenderRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.blocked = source["blocked"];
+	        this.allowed = source["allowed"];
+	        this.sinks = source["sinks"];
+	    }
+	}
+	export class Rules {
+	    senders: SenderRules;
+	    gifts: GiftRules;
+	    chat: ChatRules;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.senders = this.convertValues(source["senders"], SenderRules);
+	        this.gifts = this.convertValues(source["gifts"], GiftRules);
+	        this.chat = this.convertValues(source["chat"], ChatRules);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Stats {
+	    evaluated: number;
+	    hits: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Stats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.evaluated = source["evaluated"];
+	        this.hits = source["hits"];
+	    }
+	}
+
+}
+
+export namespace goals {
+	
+	export class Goal {
+	    id: string;
+	    name: string;
+	    giftId: string;
+	    giftName: string;
+	    metric: string;
+	    target: number;
+	    reward: string;
+	    milestones: number[];
+	    repeat: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Goal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.giftId = source["giftId"];
+	        this.giftName = source["giftName"];
+	        this.metric = source["metric"];
+	        this.target = source["target"];
+	        this.reward = source["reward"];
+	        this.milestones = source["milestones"];
+	        this.repeat = source["repeat"];
+	    }
+	}
+	export class Config {
+	    goals: Goal[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.goals = this.convertValues(source["goals"], Goal);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Progress {
+	    goal: Goal;
+	    current: number;
+	    percent: number;
+	    completions: number;
+	    // Go type: time
+	    reachedAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Progress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.goal = this.convertValues(source["goal"], Goal);
+	        this.current = source["current"];
+	        this.percent = source["percent"];
+	        this.completions = source["completions"];
+	        this.reachedAt = this.convertValues(source["reachedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace leaderboard {
+	
+	export class Entry {
+	    senderId: string;
+	    senderName: string;
+	    avatar: string;
+	    diamonds: number;
+	    giftCount: number;
+	    // Go type: time
+	    firstGiftAt: any;
+	    // Go type: time
+	    lastGiftAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.senderId = source["senderId"];
+	        this.senderName = source["senderName"];
+	        this.avatar = source["avatar"];
+	        this.diamonds = source["diamonds"];
+	        this.giftCount = source["giftCount"];
+	        this.firstGiftAt = this.convertValues(source["firstGiftAt"], null);
+	        this.lastGiftAt = this.convertValues(source["lastGiftAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Snapshot {
+	    overall: Entry[];
+	    teams: Record<string, Array<Entry>>;
+	    streamers: Record<string, Array<Entry>>;
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.overall = this.convertValues(source["overall"], Entry);
+	        this.teams = this.convertValues(source["teams"], Array<Entry>, true);
+	        this.streamers = this.convertValues(source["streamers"], Array<Entry>, true);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace listener {
 	
-	export class BigoGift {
-	    SenderId: string;
-	    SenderName: string;
-	    SenderAvatar: string;
-	    SenderLevel: number;
-	    StreamerId: string;
-	    StreamerName: string;
-	    StreamerAvatar: string;
-	    GiftId: string;
-	    GiftName: string;
-	    GiftCount: number;
-	    Diamonds: number;
-	    GiftImageUrl: string;
-	    Timestamp: number;
-	    BigoRoomId: string;
-	    RoomTotalDiamonds: number;
-	    TeamId: string;
+	export class BigoGift {
+	    SenderId: string;
+	    SenderName: string;
+	    SenderAvatar: string;
+	    SenderLevel: number;
+	    StreamerId: string;
+	    StreamerName: string;
+	    StreamerAvatar: string;
+	    GiftId: string;
+	    GiftName: string;
+	    GiftCount: number;
+	    Diamonds: number;
+	    GiftImageUrl: string;
+	    Timestamp: number;
+	    BigoRoomId: string;
+	    RoomTotalDiamonds: number;
+	    TeamId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BigoGift(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.SenderId = source["SenderId"];
+	        this.SenderName = source["SenderName"];
+	        this.SenderAvatar = source["SenderAvatar"];
+	        this.SenderLevel = source["SenderLevel"];
+	        this.StreamerId = source["StreamerId"];
+	        this.StreamerName = source["StreamerName"];
+	        this.StreamerAvatar = source["StreamerAvatar"];
+	        this.GiftId = source["GiftId"];
+	        this.GiftName = source["GiftName"];
+	        this.GiftCount = source["GiftCount"];
+	        this.Diamonds = source["Diamonds"];
+	        this.GiftImageUrl = source["GiftImageUrl"];
+	        this.Timestamp = source["Timestamp"];
+	        this.BigoRoomId = source["BigoRoomId"];
+	        this.RoomTotalDiamonds = source["RoomTotalDiamonds"];
+	        this.TeamId = source["TeamId"];
+	    }
+	}
+	export class BigoUserInfo {
+	    avatar: string;
+	    nick_name: string;
+	    yyuid: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BigoUserInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.avatar = source["avatar"];
+	        this.nick_name = source["nick_name"];
+	        this.yyuid = source["yyuid"];
+	    }
+	}
+
+}
+
+export namespace profile {
+	
+	export class Profile {
+	    id: string;
+	    name: string;
+	    roomId: string;
+	    environmentId: string;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	    // Go type: time
+	    lastUsedAt?: any;
+	    bigoAvatar: string;
+	    bigoNickName: string;
+	    config: api.Config;
+	    filters: filter.Rules;
+	    stickerDance: dance.Config;
+	    goals: goals.Config;
+	    rules: rules.RuleSet;
+	    sinks: sinks.Config;
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.roomId = source["roomId"];
+	        this.environmentId = source["environmentId"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	        this.lastUsedAt = this.convertValues(source["lastUsedAt"], null);
+	        this.bigoAvatar = source["bigoAvatar"];
+	        this.bigoNickName = source["bigoNickName"];
+	        this.config = this.convertValues(source["config"], api.Config);
+	        this.filters = this.convertValues(source["filters"], filter.Rules);
+	        this.stickerDance = this.convertValues(source["stickerDance"], dance.Config);
+	        this.goals = this.convertValues(source["goals"], goals.Config);
+	        this.rules = this.convertValues(source["rules"], rules.RuleSet);
+	        this.sinks = this.convertValues(source["sinks"], sinks.Config);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace report {
+	
+	export class ChatRecord {
+	    // Go type: time
+	    at: any;
+	    bigoRoomId: string;
+	    senderId: string;
+	    senderName: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChatRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.at = this.convertValues(source["at"], null);
+	        this.bigoRoomId = source["bigoRoomId"];
+	        this.senderId = source["senderId"];
+	        this.senderName = source["senderName"];
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ChatVolume {
+	    total: number;
+	    byRoom: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChatVolume(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.byRoom = source["byRoom"];
+	    }
+	}
+	export class ConnectionError {
+	    // Go type: time
+	    at: any;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.at = this.convertValues(source["at"], null);
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ConnectionSummary {
+	    bigoRoomId: string;
+	    idolName: string;
+	    finalStatus: string;
+	    uptimeSeconds: number;
+	    uptimePercent: number;
+	    connects: number;
+	    errors: ConnectionError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bigoRoomId = source["bigoRoomId"];
+	        this.idolName = source["idolName"];
+	        this.finalStatus = source["finalStatus"];
+	        this.uptimeSeconds = source["uptimeSeconds"];
+	        this.uptimePercent = source["uptimePercent"];
+	        this.connects = source["connects"];
+	        this.errors = this.convertValues(source["errors"], ConnectionError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GiftRecord {
+	    // Go type: time
+	    at: any;
+	    bigoRoomId: string;
+	    senderId: string;
+	    senderName: string;
+	    giftName: string;
+	    giftCount: number;
+	    diamonds: number;
+	    teamId: string;
+	    streamerId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GiftRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.at = this.convertValues(source["at"], null);
+	        this.bigoRoomId = source["bigoRoomId"];
+	        this.senderId = source["senderId"];
+	        this.senderName = source["senderName"];
+	        this.giftName = source["giftName"];
+	        this.giftCount = source["giftCount"];
+	        this.diamonds = source["diamonds"];
+	        this.teamId = source["teamId"];
+	        this.streamerId = source["streamerId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StreamerTotal {
+	    streamerId: string;
+	    name: string;
+	    teamId: string;
+	    diamonds: number;
+	    gifts: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StreamerTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.streamerId = source["streamerId"];
+	        this.name = source["name"];
+	        this.teamId = source["teamId"];
+	        this.diamonds = source["diamonds"];
+	        this.gifts = source["gifts"];
+	    }
+	}
+	export class TeamTotal {
+	    teamId: string;
+	    name: string;
+	    diamonds: number;
+	    gifts: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TeamTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.teamId = source["teamId"];
+	        this.name = source["name"];
+	        this.diamonds = source["diamonds"];
+	        this.gifts = source["gifts"];
+	    }
+	}
+	export class TimelineBucket {
+	    // Go type: time
+	    minute: any;
+	    diamonds: number;
+	    gifts: number;
+	    chats: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimelineBucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.minute = this.convertValues(source["minute"], null);
+	        this.diamonds = source["diamonds"];
+	        this.gifts = source["gifts"];
+	        this.chats = source["chats"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Totals {
+	    diamonds: number;
+	    gifts: number;
+	    chats: number;
+	    senders: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Totals(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.diamonds = source["diamonds"];
+	        this.gifts = source["gifts"];
+	        this.chats = source["chats"];
+	        this.senders = source["senders"];
+	    }
+	}
+	export class Report {
+	    roomId: string;
+	    sessionId: string;
+	    // Go type: time
+	    startedAt: any;
+	    // Go type: time
+	    endedAt: any;
+	    stopReason: string;
+	    totals: Totals;
+	    timeline: TimelineBucket[];
+	    teams: TeamTotal[];
+	    streamers: StreamerTotal[];
+	    topGifters: leaderboard.Entry[];
+	    chat: ChatVolume;
+	    connections: ConnectionSummary[];
+	    bbcoreFinal?: Record<string, any>;
+	    gifts: GiftRecord[];
+	    chats: ChatRecord[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roomId = source["roomId"];
+	        this.sessionId = source["sessionId"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.endedAt = this.convertValues(source["endedAt"], null);
+	        this.stopReason = source["stopReason"];
+	        this.totals = this.convertValues(source["totals"], Totals);
+	        this.timeline = this.convertValues(source["timeline"], TimelineBucket);
+	        this.teams = this.convertValues(source["teams"], TeamTotal);
+	        this.streamers = this.convertValues(source["streamers"], StreamerTotal);
+	        this.topGifters = this.convertValues(source["topGifters"], leaderboard.Entry);
+	        this.chat = this.convertValues(source["chat"], ChatVolume);
+	        this.connections = this.convertValues(source["connections"], ConnectionSummary);
+	        this.bbcoreFinal = source["bbcoreFinal"];
+	        this.gifts = this.convertValues(source["gifts"], GiftRecord);
+	        this.chats = this.convertValues(source["chats"], ChatRecord);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Summary {
+	    roomId: string;
+	    sessionId: string;
+	    // Go type: time
+	    startedAt: any;
+	    // Go type: time
+	    endedAt: any;
+	    diamonds: number;
+	    jsonPath: string;
+	    csvPath: string;
+	    htmlPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roomId = source["roomId"];
+	        this.sessionId = source["sessionId"];
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.endedAt = this.convertValues(source["endedAt"], null);
+	        this.diamonds = source["diamonds"];
+	        this.jsonPath = source["jsonPath"];
+	        this.csvPath = source["csvPath"];
+	        this.htmlPath = source["htmlPath"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+
+}
+
+export namespace rules {
+	
+	export class Action {
+	    type: string;
+	    event: string;
+	    sound: string;
+	    destination: string;
+	    url: string;
+	    bonus: number;
+	    teamId: string;
+	    payload: Record<string, any>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Action(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.event = source["event"];
+	        this.sound = source["sound"];
+	        this.destination = source["destination"];
+	        this.url = source["url"];
+	        this.bonus = source["bonus"];
+	        this.teamId = source["teamId"];
+	        this.payload = source["payload"];
+	    }
+	}
+	export class Condition {
+	    eventType: string;
+	    giftIds: string[];
+	    giftNames: string[];
+	    senderIds: string[];
+	    teamIds: string[];
+	    minDiamonds: number;
+	    maxDiamonds: number;
+	    chatPattern: string;
+	    timeFrom: string;
+	    timeTo: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new BigoGift(source);
+	        return new Condition(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.SenderId = source["SenderId"];
-	        this.SenderName = source["SenderName"];
-	        this.SenderAvatar = source["SenderAvatar"];
-	        this.SenderLevel = source["SenderLevel"];
-	        this.StreamerId = source["StreamerId"];
-	        this.StreamerName = source["StreamerName"];
-	        this.StreamerAvatar = source["StreamerAvatar"];
-	        this.GiftId = source["GiftId"];
-	        this.GiftName = source["GiftName"];
-	        this.GiftCount = source["GiftCount"];
-	        this.Diamonds = source["Diamonds"];
-	        this.GiftImageUrl = source["GiftImageUrl"];
-	        this.Timestamp = source["Timestamp"];
-	        this.BigoRoomId = source["BigoRoomId"];
-	        this.RoomTotalDiamonds = source["RoomTotalDiamonds"];
-	        this.TeamId = source["TeamId"];
+	        this.eventType = source["eventType"];
+	        this.giftIds = source["giftIds"];
+	        this.giftNames = source["giftNames"];
+	        this.senderIds = source["senderIds"];
+	        this.teamIds = source["teamIds"];
+	        this.minDiamonds = source["minDiamonds"];
+	        this.maxDiamonds = source["maxDiamonds"];
+	        this.chatPattern = source["chatPattern"];
+	        this.timeFrom = source["timeFrom"];
+	        this.timeTo = source["timeTo"];
 	    }
 	}
-	export class BigoUserInfo {
-	    avatar: string;
-	    nick_name: string;
-	    yyuid: string;
+	export class Event {
+	    type: string;
+	    bigoRoomId: string;
+	    senderId: string;
+	    senderName: string;
+	    teamId: string;
+	    streamerId: string;
+	    giftId: string;
+	    giftName: string;
+	    giftCount: number;
+	    diamonds: number;
+	    message: string;
+	    // Go type: time
+	    at: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new BigoUserInfo(source);
+	        return new Event(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.avatar = source["avatar"];
-	        this.nick_name = source["nick_name"];
-	        this.yyuid = source["yyuid"];
+	        this.type = source["type"];
+	        this.bigoRoomId = source["bigoRoomId"];
+	        this.senderId = source["senderId"];
+	        this.senderName = source["senderName"];
+	        this.teamId = source["teamId"];
+	        this.streamerId = source["streamerId"];
+	        this.giftId = source["giftId"];
+	        this.giftName = source["giftName"];
+	        this.giftCount = source["giftCount"];
+	        this.diamonds = source["diamonds"];
+	        this.message = source["message"];
+	        this.at = this.convertValues(source["at"], null);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-
-}
-
-export namespace profile {
+	export class Firing {
+	    ruleId: string;
+	    ruleName: string;
+	    action: Action;
+	    event: Event;
 	
-	export class Profile {
+	    static createFrom(source: any = {}) {
+	        return new Firing(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ruleId = source["ruleId"];
+	        this.ruleName = source["ruleName"];
+	        this.action = this.convertValues(source["action"], Action);
+	        this.event = this.convertValues(source["event"], Event);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Rule {
 	    id: string;
 	    name: string;
-	    roomId: string;
-	    // Go type: time
-	    createdAt: any;
-	    // Go type: time
-	    updatedAt: any;
-	    // Go type: time
-	    lastUsedAt?: any;
-	    bigoAvatar: string;
-	    bigoNickName: string;
-	    config: api.Config;
+	    enabled: boolean;
+	    when: Condition;
+	    actions: Action[];
+	    cooldownSeconds: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new Profile(source);
+	        return new Rule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.name = source["name"];
-	        this.roomId = source["roomId"];
-	        this.createdAt = this.convertValues(source["createdAt"], null);
-	        this.updatedAt = this.convertValues(source["updatedAt"], null);
-	        this.lastUsedAt = this.convertValues(source["lastUsedAt"], null);
-	        this.bigoAvatar = source["bigoAvatar"];
-	        this.bigoNickName = source["bigoNickName"];
-	        this.config = this.convertValues(source["config"], api.Config);
+	        this.enabled = source["enabled"];
+	        this.when = this.convertValues(source["when"], Condition);
+	        this.actions = this.convertValues(source["actions"], Action);
+	        this.cooldownSeconds = source["cooldownSeconds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RuleSet {
+	    rules: Rule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RuleSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rules = this.convertValues(source["rules"], Rule);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -470,6 +1778,10 @@ export namespace session {
 	    sessionId: string;
 	    roomId: string;
 	    deviceHash: string;
+	    connection?: stomp.ConnectionState;
+	    queuedMessages: number;
+	    droppedMessages: number;
+	    trialOffline: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new BBCoreStreamStatus(source);
@@ -481,7 +1793,29 @@ export namespace session {
 	        this.sessionId = source["sessionId"];
 	        this.roomId = source["roomId"];
 	        this.deviceHash = source["deviceHash"];
+	        this.connection = this.convertValues(source["connection"], stomp.ConnectionState);
+	        this.queuedMessages = source["queuedMessages"];
+	        this.droppedMessages = source["droppedMessages"];
+	        this.trialOffline = source["trialOffline"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class BigoConnection {
 	    bigoRoomId: string;
@@ -541,6 +1875,7 @@ export namespace session {
 	    bufferedEvents: number;
 	    connections: BigoConnection[];
 	    recentGifts: listener.BigoGift[];
+	    filterStats?: filter.Stats;
 	
 	    static createFrom(source: any = {}) {
 	        return new BigoListenerStatus(source);
@@ -555,6 +1890,7 @@ export namespace session {
 	        this.bufferedEvents = source["bufferedEvents"];
 	        this.connections = this.convertValues(source["connections"], BigoConnection);
 	        this.recentGifts = this.convertValues(source["recentGifts"], listener.BigoGift);
+	        this.filterStats = this.convertValues(source["filterStats"], filter.Stats);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -618,3 +1954,188 @@ export namespace session {
 
 }
 
+export namespace sinks {
+	
+	export class SinkConfig {
+	    id: string;
+	    name: string;
+	    type: string;
+	    enabled: boolean;
+	    eventTypes: string[];
+	    url: string;
+	    secret: string;
+	    batchSize: number;
+	    flushIntervalMs: number;
+	    maxRetries: number;
+	    path: string;
+	    maxSizeMB: number;
+	    maxFiles: number;
+	    brokerUrl: string;
+	    token: string;
+	    destination: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SinkConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.enabled = source["enabled"];
+	        this.eventTypes = source["eventTypes"];
+	        this.url = source["url"];
+	        this.secret = source["secret"];
+	        this.batchSize = source["batchSize"];
+	        this.flushIntervalMs = source["flushIntervalMs"];
+	        this.maxRetries = source["maxRetries"];
+	        this.path = source["path"];
+	        this.maxSizeMB = source["maxSizeMB"];
+	        this.maxFiles = source["maxFiles"];
+	        this.brokerUrl = source["brokerUrl"];
+	        this.token = source["token"];
+	        this.destination = source["destination"];
+	    }
+	}
+	export class Config {
+	    sinks: SinkConfig[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sinks = this.convertValues(source["sinks"], SinkConfig);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Health {
+	    id: string;
+	    name: string;
+	    type: string;
+	    healthy: boolean;
+	    lastError?: string;
+	    // Go type: time
+	    lastErrorAt: any;
+	    // Go type: time
+	    lastSuccessAt: any;
+	    sent: number;
+	    failed: number;
+	    dropped: number;
+	    queued: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Health(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.healthy = source["healthy"];
+	        this.lastError = source["lastError"];
+	        this.lastErrorAt = this.convertValues(source["lastErrorAt"], null);
+	        this.lastSuccessAt = this.convertValues(source["lastSuccessAt"], null);
+	        this.sent = source["sent"];
+	        this.failed = source["failed"];
+	        this.dropped = source["dropped"];
+	        this.queued = source["queued"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace stomp {
+	
+	export class ConnectionState {
+	    state: string;
+	    healthy: boolean;
+	    attempt: number;
+	    maxAttempts: number;
+	    reconnects: number;
+	    failedAttempts: number;
+	    lastError?: string;
+	    // Go type: time
+	    lastErrorAt: any;
+	    // Go type: time
+	    connectedAt: any;
+	    // Go type: time
+	    nextRetryAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.state = source["state"];
+	        this.healthy = source["healthy"];
+	        this.attempt = source["attempt"];
+	        this.maxAttempts = source["maxAttempts"];
+	        this.reconnects = source["reconnects"];
+	        this.failedAttempts = source["failedAttempts"];
+	        this.lastError = source["lastError"];
+	        this.lastErrorAt = this.convertValues(source["lastErrorAt"], null);
+	        this.connectedAt = this.convertValues(source["connectedAt"], null);
+	        this.nextRetryAt = this.convertValues(source["nextRetryAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
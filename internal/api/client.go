@@ -2,11 +2,14 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -15,6 +18,10 @@ type Client struct {
 	authToken    string
 	refreshToken string
 	httpClient   *http.Client
+	tokenMutex   sync.RWMutex  // Guards authToken and refreshToken, which are read by STOMP reconnects
+	refreshLock  chan struct{} // Serializes refreshes so a rotated refresh token is used only once; waiting honours the context
+	onRefresh    func(*AuthResponse)
+	device       DeviceIdentity // Sent as headers with every request
 }
 
 func NewClient(baseURL, authToken string) *Client {
@@ -24,34 +31,103 @@ func NewClient(baseURL, authToken string) *Client {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		refreshLock: make(chan struct{}, 1),
 	}
 }
 
 // SetTokens updates both access and refresh tokens
 func (c *Client) SetTokens(accessToken, refreshToken string) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
 	c.authToken = accessToken
 	c.refreshToken = refreshToken
 }
 
 // GetAccessToken returns the current access token
 func (c *Client) GetAccessToken() string {
+	c.tokenMutex.RLock()
+	defer c.tokenMutex.RUnlock()
 	return c.authToken
 }
 
+// RefreshAccessToken exchanges the stored refresh token for new tokens and returns the new access token.
+// If another caller refreshed while this one waited, its token is returned without a second refresh.
+func (c *Client) RefreshAccessToken() (string, error) {
+	return c.RefreshAccessTokenContext(context.Background())
+}
+
+// RefreshAccessTokenContext is like RefreshAccessToken but stops waiting when ctx is done
+func (c *Client) RefreshAccessTokenContext(ctx context.Context) (string, error) {
+	stale := c.GetAccessToken()
+
+	select {
+	case c.refreshLock <- struct{}{}:
+		defer func() { <-c.refreshLock }()
+	case <-ctx.Done():
+		return "", fmt.Errorf("refresh access token: %w", ctx.Err())
+	}
+
+	c.tokenMutex.RLock()
+	current, refreshToken := c.authToken, c.refreshToken
+	c.tokenMutex.RUnlock()
+
+	if current != stale {
+		return current, nil
+	}
+	if refreshToken == "" {
+		return "", fmt.Errorf("no refresh token available")
+	}
+
+	authResp, err := c.RefreshTokenContext(ctx, refreshToken)
+	if err != nil {
+		return "", fmt.Errorf("refresh access token: %w", err)
+	}
+
+	c.SetTokens(authResp.AccessToken, authResp.RefreshToken)
+
+	c.tokenMutex.RLock()
+	onRefresh := c.onRefresh
+	c.tokenMutex.RUnlock()
+	if onRefresh != nil {
+		onRefresh(authResp)
+	}
+	return authResp.AccessToken, nil
+}
+
+// SetDevice sets the device fingerprint reported to BB-Core with every request
+func (c *Client) SetDevice(device DeviceIdentity) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	c.device = device
+}
+
+// OnTokensRefreshed registers a callback for every refresh done by RefreshAccessToken,
+// including the automatic one after an expired token, so rotated tokens can be persisted
+func (c *Client) OnTokensRefreshed(callback func(*AuthResponse)) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	c.onRefresh = callback
+}
+
 func (c *Client) GetConfig(roomId string) (*Config, error) {
+	return c.GetConfigContext(context.Background(), roomId)
+}
+
+// GetConfigContext is like GetConfig but stops waiting when ctx is done
+func (c *Client) GetConfigContext(ctx context.Context, roomId string) (*Config, error) {
 	// Migrated to official BB-Core API endpoint
 	url := fmt.Sprintf("%s/api/v1/external/config?roomId=%s", c.baseURL, roomId)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	var config Config
-	if err := c.doRequest(req, &config); err != nil {
+	if err := c.doRequest(ctx, req, &config); err != nil {
 		return nil, err
 	}
 
@@ -60,6 +136,11 @@ func (c *Client) GetConfig(roomId string) (*Config, error) {
 
 // SaveConfig saves room configuration to BB-Core
 func (c *Client) SaveConfig(roomId string, config *Config) error {
+	return c.SaveConfigContext(context.Background(), roomId, config)
+}
+
+// SaveConfigContext is like SaveConfig but stops waiting when ctx is done
+func (c *Client) SaveConfigContext(ctx context.Context, roomId string, config *Config) error {
 	// Force the config RoomID to match the requested RoomID
 	// This prevents issues where the config object has "default" or mismatching IDs
 	config.RoomId = roomId
@@ -86,7 +167,7 @@ func (c *Client) SaveConfig(roomId string, config *Config) error {
 	fmt.Printf("[SaveConfig] Payload: %s\n", string(jsonData))
 
 	// Use the same pattern as other POST methods
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -96,15 +177,20 @@ func (c *Client) SaveConfig(roomId string, config *Config) error {
 		return io.NopCloser(bytes.NewReader(jsonData)), nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp map[string]interface{}
-	return c.doRequest(req, &resp)
+	return c.doRequest(ctx, req, &resp)
 }
 
 // StartSession starts a new PK script session using /api/v1/scripts/start
 func (c *Client) StartSession(roomId string, durationMinutes int, scriptPayload map[string]interface{}) (*StartScriptResponse, error) {
+	return c.StartSessionContext(context.Background(), roomId, durationMinutes, scriptPayload)
+}
+
+// StartSessionContext is like StartSession but stops waiting when ctx is done
+func (c *Client) StartSessionContext(ctx context.Context, roomId string, durationMinutes int, scriptPayload map[string]interface{}) (*StartScriptResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/scripts/start", c.baseURL)
 
 	reqBody := StartScriptRequest{
@@ -118,7 +204,7 @@ func (c *Client) StartSession(roomId string, durationMinutes int, scriptPayload
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -128,11 +214,11 @@ func (c *Client) StartSession(roomId string, durationMinutes int, scriptPayload
 		return io.NopCloser(bytes.NewReader(jsonData)), nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp StartScriptResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -141,6 +227,11 @@ func (c *Client) StartSession(roomId string, durationMinutes int, scriptPayload
 
 // StopSession stops an active script session using /api/v1/scripts/stop
 func (c *Client) StopSession(sessionId string) (*StopScriptResponse, error) {
+	return c.StopSessionContext(context.Background(), sessionId)
+}
+
+// StopSessionContext is like StopSession but stops waiting when ctx is done
+func (c *Client) StopSessionContext(ctx context.Context, sessionId string) (*StopScriptResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/scripts/stop", c.baseURL)
 
 	reqBody := StopScriptRequest{SessionId: sessionId}
@@ -149,7 +240,7 @@ func (c *Client) StopSession(sessionId string) (*StopScriptResponse, error) {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -159,19 +250,91 @@ func (c *Client) StopSession(sessionId string) (*StopScriptResponse, error) {
 		return io.NopCloser(bytes.NewReader(jsonData)), nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp StopScriptResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
 	return &resp, nil
 }
 
+// PauseSession pauses an active script session using /api/v1/scripts/pause
+func (c *Client) PauseSession(sessionId string) (*StartScriptResponse, error) {
+	return c.PauseSessionContext(context.Background(), sessionId)
+}
+
+// PauseSessionContext is like PauseSession but stops waiting when ctx is done
+func (c *Client) PauseSessionContext(ctx context.Context, sessionId string) (*StartScriptResponse, error) {
+	var resp StartScriptResponse
+	if err := c.postScript(ctx, "/api/v1/scripts/pause", StopScriptRequest{SessionId: sessionId}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResumeSession resumes a paused script session using /api/v1/scripts/resume
+func (c *Client) ResumeSession(sessionId string) (*StartScriptResponse, error) {
+	return c.ResumeSessionContext(context.Background(), sessionId)
+}
+
+// ResumeSessionContext is like ResumeSession but stops waiting when ctx is done
+func (c *Client) ResumeSessionContext(ctx context.Context, sessionId string) (*StartScriptResponse, error) {
+	var resp StartScriptResponse
+	if err := c.postScript(ctx, "/api/v1/scripts/resume", StopScriptRequest{SessionId: sessionId}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NextRound advances a PK script session to its next round (the overlay uses the same endpoint)
+func (c *Client) NextRound(sessionId string) (*NextRoundResponse, error) {
+	return c.NextRoundContext(context.Background(), sessionId)
+}
+
+// NextRoundContext is like NextRound but stops waiting when ctx is done
+func (c *Client) NextRoundContext(ctx context.Context, sessionId string) (*NextRoundResponse, error) {
+	var resp NextRoundResponse
+	if err := c.postScript(ctx, fmt.Sprintf("/api/v1/scripts/%s/pk/next-round", sessionId), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// postScript sends an authenticated POST to a script endpoint; body may be nil
+func (c *Client) postScript(ctx context.Context, path string, body interface{}, out interface{}) error {
+	var jsonData []byte
+	if body != nil {
+		var err error
+		if jsonData, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	// Enable request body recreation for retries
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(jsonData)), nil
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
+	req.Header.Set("Content-Type", "application/json")
+	return c.doRequest(ctx, req, out)
+}
+
 // ValidateTrial validates if streamers can be used for trial accounts
 func (c *Client) ValidateTrial(streamers []ValidateTrialStreamer) (*ValidateTrialResponse, error) {
+	return c.ValidateTrialContext(context.Background(), streamers)
+}
+
+// ValidateTrialContext is like ValidateTrial but stops waiting when ctx is done
+func (c *Client) ValidateTrialContext(ctx context.Context, streamers []ValidateTrialStreamer) (*ValidateTrialResponse, error) {
 	url := fmt.Sprintf("%s/api/v1/external/validate-trial", c.baseURL)
 
 	reqBody := ValidateTrialRequest{Streamers: streamers}
@@ -180,7 +343,7 @@ func (c *Client) ValidateTrial(streamers []ValidateTrialStreamer) (*ValidateTria
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -190,11 +353,11 @@ func (c *Client) ValidateTrial(streamers []ValidateTrialStreamer) (*ValidateTria
 		return io.NopCloser(bytes.NewReader(jsonData)), nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp ValidateTrialResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -202,6 +365,11 @@ func (c *Client) ValidateTrial(streamers []ValidateTrialStreamer) (*ValidateTria
 }
 
 func (c *Client) SendHeartbeat(status HeartbeatRequest) error {
+	return c.SendHeartbeatContext(context.Background(), status)
+}
+
+// SendHeartbeatContext is like SendHeartbeat but stops waiting when ctx is done
+func (c *Client) SendHeartbeatContext(ctx context.Context, status HeartbeatRequest) error {
 	// Migrated to official BB-Core API endpoint
 	url := fmt.Sprintf("%s/api/v1/external/heartbeat", c.baseURL)
 
@@ -210,7 +378,7 @@ func (c *Client) SendHeartbeat(status HeartbeatRequest) error {
 		return fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -220,15 +388,20 @@ func (c *Client) SendHeartbeat(status HeartbeatRequest) error {
 		return io.NopCloser(bytes.NewReader(jsonData)), nil
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp map[string]interface{}
-	return c.doRequest(req, &resp)
+	return c.doRequest(ctx, req, &resp)
 }
 
 // Login authenticates with BB-Core
 func (c *Client) Login(username, password string) (*AuthResponse, error) {
+	return c.LoginContext(context.Background(), username, password)
+}
+
+// LoginContext is like Login but stops waiting when ctx is done
+func (c *Client) LoginContext(ctx context.Context, username, password string) (*AuthResponse, error) {
 	if strings.TrimSpace(username) == "" {
 		return nil, fmt.Errorf("username cannot be empty")
 	}
@@ -247,7 +420,7 @@ func (c *Client) Login(username, password string) (*AuthResponse, error) {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -260,7 +433,7 @@ func (c *Client) Login(username, password string) (*AuthResponse, error) {
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp AuthResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -269,6 +442,11 @@ func (c *Client) Login(username, password string) (*AuthResponse, error) {
 
 // Register creates a new user account with BB-Core
 func (c *Client) Register(username, email, password, agencyName, firstName, lastName string) (*AuthResponse, error) {
+	return c.RegisterContext(context.Background(), username, email, password, agencyName, firstName, lastName)
+}
+
+// RegisterContext is like Register but stops waiting when ctx is done
+func (c *Client) RegisterContext(ctx context.Context, username, email, password, agencyName, firstName, lastName string) (*AuthResponse, error) {
 	if strings.TrimSpace(username) == "" {
 		return nil, fmt.Errorf("username cannot be empty")
 	}
@@ -297,7 +475,7 @@ func (c *Client) Register(username, email, password, agencyName, firstName, last
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -310,7 +488,7 @@ func (c *Client) Register(username, email, password, agencyName, firstName, last
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp AuthResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
@@ -319,6 +497,11 @@ func (c *Client) Register(username, email, password, agencyName, firstName, last
 
 // RefreshToken gets a new access token
 func (c *Client) RefreshToken(refreshToken string) (*AuthResponse, error) {
+	return c.RefreshTokenContext(context.Background(), refreshToken)
+}
+
+// RefreshTokenContext is like RefreshToken but stops waiting when ctx is done
+func (c *Client) RefreshTokenContext(ctx context.Context, refreshToken string) (*AuthResponse, error) {
 	if strings.TrimSpace(refreshToken) == "" {
 		return nil, fmt.Errorf("refresh token cannot be empty")
 	}
@@ -331,7 +514,7 @@ func (c *Client) RefreshToken(refreshToken string) (*AuthResponse, error) {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -344,18 +527,37 @@ func (c *Client) RefreshToken(refreshToken string) (*AuthResponse, error) {
 	req.Header.Set("Content-Type", "application/json")
 
 	var resp AuthResponse
-	if err := c.doRequest(req, &resp); err != nil {
+	if err := c.doRequest(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 
 	return &resp, nil
 }
 
-func (c *Client) doRequest(req *http.Request, result interface{}) error {
+// setDeviceHeaders adds the device fingerprint; the previous hash only while BB-Core migrates bindings to the new one
+func (c *Client) setDeviceHeaders(req *http.Request) {
+	c.tokenMutex.RLock()
+	device := c.device
+	c.tokenMutex.RUnlock()
+
+	if device.Hash == "" {
+		return
+	}
+	req.Header.Set(HeaderDeviceHash, device.Hash)
+	req.Header.Set(HeaderFingerprintVersion, strconv.Itoa(device.FingerprintVersion))
+	if device.PreviousHash != "" {
+		req.Header.Set(HeaderPreviousDeviceHash, device.PreviousHash)
+	}
+}
+
+// doRequest sends a request with retries. ctx bounds the whole call, including backoff and token refresh.
+func (c *Client) doRequest(ctx context.Context, req *http.Request, result interface{}) error {
 	// Retry logic with exponential backoff
 	maxRetries := 3
 	baseDelay := 1 * time.Second
 
+	c.setDeviceHeaders(req)
+
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		// Recreate request body for retries if GetBody is available
 		if attempt > 0 && req.GetBody != nil {
@@ -368,8 +570,13 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("request canceled: %w", ctx.Err())
+			}
 			if attempt < maxRetries-1 {
-				time.Sleep(baseDelay * time.Duration(1<<attempt))
+				if err := sleepContext(ctx, baseDelay*time.Duration(1<<attempt)); err != nil {
+					return fmt.Errorf("request canceled: %w", err)
+				}
 				continue
 			}
 			return fmt.Errorf("request failed: %w", err)
@@ -383,26 +590,25 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 
 		if resp.StatusCode >= 400 {
 			if attempt < maxRetries-1 && resp.StatusCode >= 500 {
-				time.Sleep(baseDelay * time.Duration(1<<attempt))
+				if err := sleepContext(ctx, baseDelay*time.Duration(1<<attempt)); err != nil {
+					return fmt.Errorf("request canceled: %w", err)
+				}
 				continue
 			}
 
 			// Try to parse as APIError
 			var apiErr APIError
 			if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.ErrorCode != 0 {
+				apiErr.HTTPStatus = resp.StatusCode
 				// Check for token expired (401 with error code 2003)
-				// Only attempt auto-refresh if we have a refresh token
-				if resp.StatusCode == 401 && apiErr.ErrorCode == 2003 && c.refreshToken != "" {
+				// Only attempt auto-refresh for authenticated requests (not the refresh call itself)
+				if resp.StatusCode == 401 && apiErr.ErrorCode == 2003 && req.Header.Get("Authorization") != "" {
 					// Attempt token refresh
-					authResp, refreshErr := c.RefreshToken(c.refreshToken)
-					if refreshErr != nil {
+					if _, refreshErr := c.RefreshAccessTokenContext(ctx); refreshErr != nil {
 						// Refresh failed, return original error
 						return &apiErr
 					}
 
-					// Update tokens
-					c.SetTokens(authResp.AccessToken, authResp.RefreshToken)
-
 					// Retry original request with new token
 					// Recreate request body if available
 					if req.GetBody != nil {
@@ -414,7 +620,7 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 					}
 
 					// Update Authorization header with new token
-					req.Header.Set("Authorization", "Bearer "+c.authToken)
+					req.Header.Set("Authorization", "Bearer "+c.GetAccessToken())
 
 					// Retry the request once
 					retryResp, retryErr := c.httpClient.Do(req)
@@ -433,6 +639,7 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 						// Parse error from retry
 						var retryApiErr APIError
 						if err := json.Unmarshal(retryBody, &retryApiErr); err == nil && retryApiErr.ErrorCode != 0 {
+							retryApiErr.HTTPStatus = retryResp.StatusCode
 							return &retryApiErr
 						}
 						return fmt.Errorf("HTTP %d after refresh: %s", retryResp.StatusCode, string(retryBody))
@@ -451,7 +658,7 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 			}
 
 			// Fallback to generic error
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+			return &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
 
 		if result != nil {
@@ -465,3 +672,16 @@ func (c *Client) doRequest(req *http.Request, result interface{}) error {
 
 	return fmt.Errorf("max retries exceeded")
 }
+
+// sleepContext waits for d, returning early with ctx's error if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,10 +2,15 @@ package api_test
 
 import (
 	"bbapp/internal/api"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestClient_GetConfig(t *testing.T) {
@@ -776,6 +781,10 @@ func TestClient_RefreshToken_InvalidToken(t *testing.T) {
 	if apiErr.Status != "UNAUTHORIZED" {
 		t.Errorf("Expected status 'UNAUTHORIZED', got %s", apiErr.Status)
 	}
+
+	if !api.IsAuthRejected(fmt.Errorf("refresh access token: %w", err)) {
+		t.Error("Expected a 401 refresh to be reported as an auth rejection")
+	}
 }
 
 // TestClient_RefreshToken_TokenExpired tests refresh with expired token
@@ -931,6 +940,10 @@ func TestClient_RefreshToken_ServerError(t *testing.T) {
 	if apiErr.ErrorCode != 5000 {
 		t.Errorf("Expected error code 5000, got %d", apiErr.ErrorCode)
 	}
+
+	if api.IsAuthRejected(err) {
+		t.Error("Expected a server error not to be reported as an auth rejection")
+	}
 }
 
 // TestClient_RefreshToken_EmptyToken tests validation of empty token
@@ -1162,3 +1175,135 @@ func TestClient_SendHeartbeat_WithError(t *testing.T) {
 		t.Fatalf("SendHeartbeat failed: %v", err)
 	}
 }
+
+// TestClient_RefreshAccessToken tests that concurrent callers share a single refresh
+func TestClient_RefreshAccessToken(t *testing.T) {
+	var mutex sync.Mutex
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		refreshes++
+		mutex.Unlock()
+		time.Sleep(50 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"accessToken": "new-access", "refreshToken": "new-refresh"}`))
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "old-access")
+	if _, err := client.RefreshAccessToken(); err == nil {
+		t.Error("Expected error without a refresh token")
+	}
+
+	client.SetTokens("old-access", "old-refresh")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := client.RefreshAccessToken()
+			if err != nil || token != "new-access" {
+				t.Errorf("RefreshAccessToken = %q, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if refreshes != 1 {
+		t.Errorf("Expected 1 refresh request, got %d", refreshes)
+	}
+	if client.GetAccessToken() != "new-access" {
+		t.Errorf("Expected stored access token to be updated, got %s", client.GetAccessToken())
+	}
+}
+
+// TestClient_ContextCancelsRetries ensures a deadline cuts short the retry backoff of a failing BB-Core
+func TestClient_ContextCancelsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.StopSessionContext(ctx, "session-1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected StopSessionContext to return at the deadline, took %s", elapsed)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := client.SendHeartbeatContext(canceled, api.HeartbeatRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled, got %v", err)
+	}
+}
+
+// TestClient_OnTokensRefreshed ensures the automatic refresh after an expired token is reported, so it can be persisted
+func TestClient_OnTokensRefreshed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/auth/refresh-token" {
+			w.Write([]byte(`{"accessToken": "new-access", "refreshToken": "new-refresh"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer new-access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errorCode": 2003, "message": "Token expired"}`))
+			return
+		}
+		w.Write([]byte(`{"roomId": "test-room"}`))
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "")
+	client.SetTokens("old-access", "old-refresh")
+
+	var refreshed *api.AuthResponse
+	client.OnTokensRefreshed(func(resp *api.AuthResponse) { refreshed = resp })
+
+	if _, err := client.GetConfig("test-room"); err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	if refreshed == nil || refreshed.RefreshToken != "new-refresh" {
+		t.Errorf("Expected the refresh to be reported, got %+v", refreshed)
+	}
+}
+
+func TestClient_SendsDeviceHeaders(t *testing.T) {
+	headers := make(chan http.Header, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"allowed": true}`))
+	}))
+	defer server.Close()
+
+	client := api.NewClient(server.URL, "test-token")
+	client.SetDevice(api.DeviceIdentity{Hash: "new-hash", PreviousHash: "old-hash", FingerprintVersion: 2})
+	if _, err := client.ValidateTrial(nil); err != nil {
+		t.Fatalf("ValidateTrial() error = %v", err)
+	}
+
+	h := <-headers
+	if h.Get(api.HeaderDeviceHash) != "new-hash" || h.Get(api.HeaderPreviousDeviceHash) != "old-hash" || h.Get(api.HeaderFingerprintVersion) != "2" {
+		t.Errorf("Unexpected device headers: %v", h)
+	}
+
+	// Once migrated, only the current hash is sent
+	client.SetDevice(api.DeviceIdentity{Hash: "new-hash", FingerprintVersion: 2})
+	if _, err := client.ValidateTrial(nil); err != nil {
+		t.Fatalf("ValidateTrial() error = %v", err)
+	}
+	if h := <-headers; h.Get(api.HeaderPreviousDeviceHash) != "" {
+		t.Error("Expected no previous hash after migration")
+	}
+}
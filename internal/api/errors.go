@@ -1,6 +1,10 @@
 package api
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 // APIError represents a standardized error response from BB-Core API.
 // It implements the Go error interface for idiomatic error handling.
@@ -14,6 +18,7 @@ type APIError struct {
 	Message    string            `json:"message"`             // Human-readable error message
 	Details    string            `json:"details,omitempty"`   // Additional error details (optional)
 	SubErrors  []ValidationError `json:"subErrors,omitempty"` // Field-level validation errors (optional)
+	HTTPStatus int               `json:"-"`                   // HTTP status code of the response
 }
 
 // ValidationError represents a field-level validation error.
@@ -30,3 +35,28 @@ type ValidationError struct {
 func (e APIError) Error() string {
 	return fmt.Sprintf("[%d] %s", e.ErrorCode, e.Message)
 }
+
+// HTTPError is an error response that is not in the APIError format
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// IsAuthRejected reports whether BB-Core refused the credentials of a request (HTTP 401 or 403),
+// as opposed to network errors and server failures, which may go away on a retry
+func IsAuthRejected(err error) bool {
+	status := 0
+	var apiErr *APIError
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatus
+	case errors.As(err, &httpErr):
+		status = httpErr.StatusCode
+	}
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
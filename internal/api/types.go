@@ -91,6 +91,13 @@ type StopScriptResponse struct {
 	FinalData  map[string]interface{} `json:"finalData,omitempty"`
 }
 
+// NextRoundResponse is the round a script session advanced to
+type NextRoundResponse struct {
+	RoundNumber int `json:"roundNumber"`
+	TotalRounds int `json:"totalRounds"`
+	CurrentRank int `json:"currentRank"`
+}
+
 type HeartbeatRequest struct {
 	Connections []ConnectionStatus `json:"connections"`
 }
@@ -159,6 +166,20 @@ type Agency struct {
 	ExpiresAt    time.Time `json:"expiresAt"`
 }
 
+// Device fingerprint headers sent with every request
+const (
+	HeaderDeviceHash         = "X-Device-Hash"
+	HeaderFingerprintVersion = "X-Device-Fingerprint-Version"
+	HeaderPreviousDeviceHash = "X-Previous-Device-Hash" // Lets BB-Core move trial and device bindings to the new hash
+)
+
+// DeviceIdentity is the device fingerprint reported to BB-Core
+type DeviceIdentity struct {
+	Hash               string
+	PreviousHash       string // Set while migrating from an older fingerprint
+	FingerprintVersion int
+}
+
 // ValidateTrial Types
 
 // ValidateTrialStreamer represents a streamer to validate
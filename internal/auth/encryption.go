@@ -9,7 +9,7 @@ import (
 	"io"
 )
 
-// deriveKey creates a 32-byte key from input (device hash + salt)
+// deriveKey creates a 32-byte key from input (device hash + salt). Only used to read v1 files, see vault.go
 func deriveKey(input string) []byte {
 	hash := sha256.Sum256([]byte(input + "bbapp-salt-v1"))
 	return hash[:]
@@ -17,6 +17,16 @@ func deriveKey(input string) []byte {
 
 // encrypt encrypts plaintext using AES-256-GCM
 func encrypt(plaintext, key []byte) ([]byte, error) {
+	return encryptAAD(plaintext, key, nil)
+}
+
+// decrypt decrypts ciphertext using AES-256-GCM
+func decrypt(ciphertext, key []byte) ([]byte, error) {
+	return decryptAAD(ciphertext, key, nil)
+}
+
+// encryptAAD encrypts plaintext using AES-256-GCM, authenticating additionalData alongside it
+func encryptAAD(plaintext, key, additionalData []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("create cipher: %w", err)
@@ -32,12 +42,12 @@ func encrypt(plaintext, key []byte) ([]byte, error) {
 		return nil, fmt.Errorf("generate nonce: %w", err)
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, additionalData)
 	return ciphertext, nil
 }
 
-// decrypt decrypts ciphertext using AES-256-GCM
-func decrypt(ciphertext, key []byte) ([]byte, error) {
+// decryptAAD decrypts ciphertext using AES-256-GCM, checking the additionalData it was sealed with
+func decryptAAD(ciphertext, key, additionalData []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, fmt.Errorf("create cipher: %w", err)
@@ -54,7 +64,7 @@ func decrypt(ciphertext, key []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, fmt.Errorf("decrypt: %w", err)
 	}
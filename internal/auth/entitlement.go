@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotEntitled means the cached entitlement cannot stand in for a trial validation
+var ErrNotEntitled = errors.New("no valid cached entitlement")
+
+// entitlementAAD binds the cache file to its purpose, so a vault key can't open it and vice versa
+var entitlementAAD = []byte("bbapp-entitlement-v1")
+
+// Entitlement is what BB-Core last confirmed for this account
+type Entitlement struct {
+	AllowedIds  []string  `json:"allowedIds"` // Bigo IDs BB-Core allowed in trial validations
+	Plan        string    `json:"plan"`
+	ExpiresAt   time.Time `json:"expiresAt"` // Agency plan expiry; zero = none
+	ValidatedAt time.Time `json:"validatedAt"`
+}
+
+// EntitlementCache keeps the last successful trial validation encrypted on disk,
+// so a stream can start while BB-Core is unreachable for up to the grace period
+type EntitlementCache struct {
+	storageDir string
+	key        []byte
+	grace      time.Duration
+	mu         sync.Mutex
+}
+
+// NewEntitlementCache creates a cache in storageDir. A grace of 0 disables offline starts.
+func NewEntitlementCache(storageDir, deviceHash string, grace time.Duration) *EntitlementCache {
+	key := sha256.Sum256([]byte(deviceHash + "bbapp-entitlement-v1"))
+	return &EntitlementCache{storageDir: storageDir, key: key[:], grace: grace}
+}
+
+func (c *EntitlementCache) path() string {
+	return filepath.Join(c.storageDir, "entitlement.enc")
+}
+
+// Grant records Bigo IDs BB-Core allowed, refreshing the plan and validation time
+func (c *EntitlementCache) Grant(bigoIds []string, agency Agency) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ent, err := c.loadLocked()
+	if err != nil {
+		ent = &Entitlement{} // Missing or unreadable (e.g. new fingerprint): start over
+	}
+
+	ent.AllowedIds = mergeIds(ent.AllowedIds, bigoIds, nil)
+	ent.Plan, ent.ExpiresAt = agency.Plan, agency.ExpiresAt
+	ent.ValidatedAt = time.Now()
+	return c.saveLocked(ent)
+}
+
+// Revoke forgets Bigo IDs BB-Core blocked
+func (c *EntitlementCache) Revoke(bigoIds []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ent, err := c.loadLocked()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ent.AllowedIds = mergeIds(ent.AllowedIds, nil, bigoIds)
+	return c.saveLocked(ent)
+}
+
+// Covers returns the cached entitlement if it allows all bigoIds at now, or an error wrapping ErrNotEntitled
+func (c *EntitlementCache) Covers(bigoIds []string, now time.Time) (*Entitlement, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.grace <= 0 {
+		return nil, fmt.Errorf("%w: offline starts are disabled", ErrNotEntitled)
+	}
+
+	ent, err := c.loadLocked()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotEntitled, err)
+	}
+	if now.After(ent.ValidatedAt.Add(c.grace)) {
+		return nil, fmt.Errorf("%w: last validated %s, grace period is %s", ErrNotEntitled, ent.ValidatedAt.Format(time.RFC3339), c.grace)
+	}
+	if !ent.ExpiresAt.IsZero() && now.After(ent.ExpiresAt) {
+		return nil, fmt.Errorf("%w: %s plan expired", ErrNotEntitled, ent.Plan)
+	}
+
+	allowed := make(map[string]bool, len(ent.AllowedIds))
+	for _, id := range ent.AllowedIds {
+		allowed[id] = true
+	}
+	for _, id := range bigoIds {
+		if !allowed[id] {
+			return nil, fmt.Errorf("%w: %s was never validated", ErrNotEntitled, id)
+		}
+	}
+	return ent, nil
+}
+
+// Clear removes the cache, e.g. on logout
+func (c *EntitlementCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.path()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove entitlement: %w", err)
+	}
+	return nil
+}
+
+func (c *EntitlementCache) loadLocked() (*Entitlement, error) {
+	encrypted, err := os.ReadFile(c.path())
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := decryptAAD(encrypted, c.key, entitlementAAD)
+	if err != nil {
+		return nil, err
+	}
+
+	var ent Entitlement
+	if err := json.Unmarshal(decrypted, &ent); err != nil {
+		return nil, fmt.Errorf("unmarshal entitlement: %w", err)
+	}
+	return &ent, nil
+}
+
+// saveLocked writes the cache atomically. Caller must hold mu.
+func (c *EntitlementCache) saveLocked(ent *Entitlement) error {
+	jsonData, err := json.Marshal(ent)
+	if err != nil {
+		return fmt.Errorf("marshal entitlement: %w", err)
+	}
+
+	encrypted, err := encryptAAD(jsonData, c.key, entitlementAAD)
+	if err != nil {
+		return fmt.Errorf("encrypt entitlement: %w", err)
+	}
+
+	if err := os.MkdirAll(c.storageDir, 0700); err != nil {
+		return fmt.Errorf("create storage directory: %w", err)
+	}
+
+	tempPath := c.path() + ".tmp"
+	if err := os.WriteFile(tempPath, encrypted, 0600); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, c.path()); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
+
+// mergeIds returns ids plus added minus removed, sorted and without duplicates
+func mergeIds(ids, added, removed []string) []string {
+	set := make(map[string]bool, len(ids)+len(added))
+	for _, id := range ids {
+		set[id] = true
+	}
+	for _, id := range added {
+		set[id] = true
+	}
+	for _, id := range removed {
+		delete(set, id)
+	}
+
+	merged := make([]string, 0, len(set))
+	for id := range set {
+		merged = append(merged, id)
+	}
+	sort.Strings(merged)
+	return merged
+}
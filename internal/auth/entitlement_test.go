@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEntitlementCache_Covers(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewEntitlementCache(dir, "device-a", time.Hour)
+
+	if _, err := cache.Covers([]string{"idol-1"}, time.Now()); !errors.Is(err, ErrNotEntitled) {
+		t.Fatalf("Expected ErrNotEntitled without a validation, got %v", err)
+	}
+
+	agency := Agency{Plan: "TRIAL", ExpiresAt: time.Now().Add(24 * time.Hour)}
+	if err := cache.Grant([]string{"idol-1", "idol-2"}, agency); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if err := cache.Grant([]string{"idol-3"}, agency); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+
+	now := time.Now()
+	if _, err := cache.Covers([]string{"idol-1", "idol-3"}, now); err != nil {
+		t.Errorf("Covers() error = %v", err)
+	}
+	if _, err := cache.Covers([]string{"idol-4"}, now); !errors.Is(err, ErrNotEntitled) {
+		t.Error("Expected an unvalidated ID to be refused")
+	}
+	if _, err := cache.Covers([]string{"idol-1"}, now.Add(2*time.Hour)); !errors.Is(err, ErrNotEntitled) {
+		t.Error("Expected the grace period to end")
+	}
+
+	if err := cache.Revoke([]string{"idol-1"}); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := cache.Covers([]string{"idol-1"}, now); !errors.Is(err, ErrNotEntitled) {
+		t.Error("Expected a revoked ID to be refused")
+	}
+
+	// Another device cannot read the cache
+	other := NewEntitlementCache(dir, "device-b", time.Hour)
+	if _, err := other.Covers([]string{"idol-2"}, now); !errors.Is(err, ErrNotEntitled) {
+		t.Error("Expected the cache to be bound to the device")
+	}
+}
+
+func TestEntitlementCache_PlanExpiry(t *testing.T) {
+	cache := NewEntitlementCache(t.TempDir(), "device-a", 72*time.Hour)
+	if err := cache.Grant([]string{"idol-1"}, Agency{Plan: "TRIAL", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.Covers([]string{"idol-1"}, time.Now().Add(2*time.Hour)); !errors.Is(err, ErrNotEntitled) {
+		t.Error("Expected an expired plan to be refused within the grace period")
+	}
+
+	disabled := NewEntitlementCache(t.TempDir(), "device-a", 0)
+	disabled.Grant([]string{"idol-1"}, Agency{})
+	if _, err := disabled.Covers([]string{"idol-1"}, time.Now()); !errors.Is(err, ErrNotEntitled) {
+		t.Error("Expected a zero grace period to disable offline starts")
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,15 +12,26 @@ import (
 // Manager handles authentication and credential storage
 type Manager struct {
 	deviceHash  string
+	passphrase  string    // Optional, mixed into the key (see vault.go)
+	kdf         kdfParams // Cost of deriving the key for new files
 	storageDir  string
 	credentials *Credentials
 	mu          sync.RWMutex
 }
 
+// VaultStatus describes the stored login without decrypting it
+type VaultStatus struct {
+	Exists              bool `json:"exists"`
+	Version             int  `json:"version"`
+	PassphraseProtected bool `json:"passphraseProtected"`
+	Unlocked            bool `json:"unlocked"` // Credentials are loaded
+}
+
 // NewManager creates a new auth manager
 func NewManager(deviceHash string) *Manager {
 	return &Manager{
 		deviceHash: deviceHash,
+		kdf:        defaultKDF,
 		storageDir: "", // Will be set by SetStorageDir
 	}
 }
@@ -37,26 +49,101 @@ func (m *Manager) SetStorageDir(dir string) error {
 	return nil
 }
 
+// SetPassphrase sets the passphrase used to open and save credentials ("" = device key only).
+// Use ChangePassphrase to re-encrypt stored credentials under a new one.
+func (m *Manager) SetPassphrase(passphrase string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.passphrase = passphrase
+}
+
+// ChangePassphrase re-encrypts the loaded credentials under a new passphrase ("" removes it)
+func (m *Manager) ChangePassphrase(passphrase string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.credentials == nil && m.fileExists() {
+		return fmt.Errorf("stored login must be unlocked first")
+	}
+
+	m.passphrase = passphrase
+	if m.credentials == nil {
+		return nil
+	}
+	return m.writeLocked(m.credentials)
+}
+
+// Rekey re-encrypts credentials stored under a previous device hash with the current one,
+// so a fingerprint change does not lose the stored login
+func (m *Manager) Rekey(previousDeviceHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	encrypted, err := os.ReadFile(m.credentialsPath())
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	decrypted, _, err := openAny(encrypted, previousDeviceHash, m.passphrase)
+	if err != nil {
+		return fmt.Errorf("decrypt with previous fingerprint: %w", err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(decrypted, &creds); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	if err := m.writeLocked(&creds); err != nil {
+		return err
+	}
+	fmt.Println("[Auth] ✓ Stored login re-encrypted for the new device fingerprint")
+	return nil
+}
+
+// Status describes the stored login without decrypting it
+func (m *Manager) Status() VaultStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := VaultStatus{Unlocked: m.credentials != nil}
+	data, err := os.ReadFile(m.credentialsPath())
+	if err != nil {
+		return status
+	}
+
+	status.Exists = true
+	status.Version = 1
+	if h, err := parseVaultHeader(data); err == nil {
+		status.Version = int(h.Version)
+		status.PassphraseProtected = h.Flags&flagPassphrase != 0
+	}
+	return status
+}
+
 // SaveCredentials encrypts and saves credentials to disk
 func (m *Manager) SaveCredentials(creds *Credentials) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.writeLocked(creds)
+}
 
+// writeLocked encrypts creds into a new vault file and caches them. Caller must hold mu.
+func (m *Manager) writeLocked(creds *Credentials) error {
 	// Marshal to JSON
 	jsonData, err := json.Marshal(creds)
 	if err != nil {
 		return fmt.Errorf("marshal credentials: %w", err)
 	}
 
-	// Encrypt
-	key := deriveKey(m.deviceHash)
-	encrypted, err := encrypt(jsonData, key)
+	// Encrypt with a fresh salt, so every save also rotates the key
+	encrypted, err := sealVault(jsonData, m.deviceHash, m.passphrase, m.kdf)
 	if err != nil {
 		return fmt.Errorf("encrypt: %w", err)
 	}
 
 	// Write to file (atomic)
-	credPath := filepath.Join(m.storageDir, "credentials.enc")
+	credPath := m.credentialsPath()
 	tempPath := credPath + ".tmp"
 
 	if err := os.WriteFile(tempPath, encrypted, 0600); err != nil {
@@ -72,32 +159,41 @@ func (m *Manager) SaveCredentials(creds *Credentials) error {
 	return nil
 }
 
-// LoadCredentials loads and decrypts credentials from disk
+// LoadCredentials loads and decrypts credentials from disk, caching them like SaveCredentials
 func (m *Manager) LoadCredentials() (*Credentials, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	credPath := filepath.Join(m.storageDir, "credentials.enc")
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	// Read encrypted file
-	encrypted, err := os.ReadFile(credPath)
+	encrypted, err := os.ReadFile(m.credentialsPath())
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
 
 	// Decrypt
-	key := deriveKey(m.deviceHash)
-	decrypted, err := decrypt(encrypted, key)
-	if err != nil {
+	decrypted, version, err := openAny(encrypted, m.deviceHash, m.passphrase)
+	if errors.Is(err, ErrPassphraseRequired) || errors.Is(err, ErrInvalidKey) {
 		return nil, fmt.Errorf("decrypt: %w", err)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w: %w", ErrCorrupted, err)
+	}
 
 	// Unmarshal
 	var creds Credentials
 	if err := json.Unmarshal(decrypted, &creds); err != nil {
-		return nil, fmt.Errorf("unmarshal: %w", err)
+		return nil, fmt.Errorf("unmarshal: %w: %w", ErrCorrupted, err)
 	}
 
+	// Migrate older formats to the current vault
+	if version < vaultVersion {
+		if err := m.writeLocked(&creds); err != nil {
+			return nil, fmt.Errorf("migrate from v%d: %w", version, err)
+		}
+		fmt.Printf("[Auth] ✓ Stored login migrated from v%d to v%d\n", version, vaultVersion)
+	}
+
+	m.credentials = &creds
 	return &creds, nil
 }
 
@@ -113,11 +209,19 @@ func (m *Manager) ClearCredentials() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	credPath := filepath.Join(m.storageDir, "credentials.enc")
-	if err := os.Remove(credPath); err != nil && !os.IsNotExist(err) {
+	if err := os.Remove(m.credentialsPath()); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("remove file: %w", err)
 	}
 
 	m.credentials = nil
 	return nil
 }
+
+func (m *Manager) credentialsPath() string {
+	return filepath.Join(m.storageDir, "credentials.enc")
+}
+
+func (m *Manager) fileExists() bool {
+	_, err := os.Stat(m.credentialsPath())
+	return err == nil
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -72,3 +73,25 @@ func TestManager_LoadCredentials_NotFound(t *testing.T) {
 		t.Error("LoadCredentials() should return error when file doesn't exist")
 	}
 }
+
+func TestManager_LoadCredentials_CorruptedVsUnreadable(t *testing.T) {
+	mgr := NewManager("test-device-hash")
+	mgr.storageDir = t.TempDir()
+
+	// A truncated vault can never be read
+	if err := os.WriteFile(mgr.credentialsPath(), []byte(vaultMagic+"x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.LoadCredentials(); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("LoadCredentials() = %v, want ErrCorrupted", err)
+	}
+
+	// A read error is not a reason to discard the login
+	os.Remove(mgr.credentialsPath())
+	if err := os.Mkdir(mgr.credentialsPath(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.LoadCredentials(); err == nil || errors.Is(err, ErrCorrupted) {
+		t.Errorf("LoadCredentials() = %v, want a read error that is not ErrCorrupted", err)
+	}
+}
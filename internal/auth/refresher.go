@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// refreshRetryDelay is how long the refresher waits after a failed refresh before trying again
+const refreshRetryDelay = 30 * time.Second
+
+// RefreshFunc refreshes the tokens at BB-Core. The new credentials must be saved through the
+// Manager, usually by the API client's refresh callback, which also covers refreshes done on demand.
+type RefreshFunc func(ctx context.Context) error
+
+// Refresher refreshes the stored credentials shortly before they expire, so long sessions never
+// run into an expired token
+type Refresher struct {
+	manager    *Manager
+	refresh    RefreshFunc
+	onExpired  func(error)
+	retryDelay time.Duration
+	cancel     context.CancelFunc
+	done       chan struct{}
+	mu         sync.Mutex
+}
+
+// NewRefresher creates a refresher for the manager's credentials.
+// onExpired (optional) is called when the token expired without a successful refresh.
+func NewRefresher(manager *Manager, refresh RefreshFunc, onExpired func(error)) *Refresher {
+	return &Refresher{
+		manager:    manager,
+		refresh:    refresh,
+		onExpired:  onExpired,
+		retryDelay: refreshRetryDelay,
+	}
+}
+
+// Start begins refreshing in the background; it is a no-op if already running.
+// The refresher stops by itself once the credentials are cleared.
+func (r *Refresher) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done != nil {
+		select {
+		case <-r.done: // Stopped by itself after a logout, start again
+		default:
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+}
+
+// Stop ends background refreshing and waits for an in-flight refresh to be abandoned
+func (r *Refresher) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (r *Refresher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		creds := r.manager.GetCredentials()
+		if creds == nil {
+			return // Logged out
+		}
+
+		// Wake up when the token enters the refresh window (see NeedsRefresh)
+		if !sleep(ctx, time.Until(creds.ExpiresAt.Add(-refreshWindow))) {
+			return
+		}
+		if r.manager.GetCredentials() != creds {
+			continue // Refreshed or replaced while waiting
+		}
+
+		err := r.refresh(ctx)
+		if err == nil && r.manager.GetCredentials() == creds {
+			err = fmt.Errorf("refreshed credentials were not saved")
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		fmt.Printf("[Auth] WARNING: Token refresh failed: %v\n", err)
+		if creds.IsExpired() && r.onExpired != nil {
+			r.onExpired(err)
+		}
+		if !sleep(ctx, r.retryDelay) {
+			return
+		}
+	}
+}
+
+// sleep waits for d and reports whether ctx is still live
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T, expiresIn time.Duration) *Manager {
+	t.Helper()
+	mgr := newVaultManager(t, "test-device-hash", t.TempDir())
+	if err := mgr.SaveCredentials(&Credentials{AccessToken: "access-1", RefreshToken: "refresh-1", ExpiresAt: time.Now().Add(expiresIn)}); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	return mgr
+}
+
+func TestRefresher_RefreshesBeforeExpiry(t *testing.T) {
+	mgr := newTestManager(t, 5*time.Minute) // Inside the refresh window
+
+	refreshed := make(chan struct{}, 1)
+	refresher := NewRefresher(mgr, func(ctx context.Context) error {
+		refreshed <- struct{}{}
+		return mgr.SaveCredentials(&Credentials{AccessToken: "access-2", RefreshToken: "refresh-2", ExpiresAt: time.Now().Add(time.Hour)})
+	}, nil)
+	refresher.Start()
+	defer refresher.Stop()
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a refresh")
+	}
+
+	// The new token is far from expiry, so no second refresh follows
+	select {
+	case <-refreshed:
+		t.Error("Expected a single refresh")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	loaded, err := mgr.LoadCredentials()
+	if err != nil || loaded.AccessToken != "access-2" {
+		t.Errorf("LoadCredentials() = %+v, %v", loaded, err)
+	}
+}
+
+func TestRefresher_ReportsExpiredLogin(t *testing.T) {
+	mgr := newTestManager(t, -time.Minute)
+
+	var attempts atomic.Int32
+	expired := make(chan error, 1)
+	refresher := NewRefresher(mgr, func(ctx context.Context) error {
+		attempts.Add(1)
+		return errors.New("BB-Core unreachable")
+	}, func(err error) {
+		select {
+		case expired <- err:
+		default:
+		}
+	})
+	refresher.retryDelay = 10 * time.Millisecond
+	refresher.Start()
+
+	select {
+	case err := <-expired:
+		if err == nil || err.Error() != "BB-Core unreachable" {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected onExpired to be called")
+	}
+
+	// Retries continue until logout, which also ends the refresher
+	waitFor(t, func() bool { return attempts.Load() > 1 })
+	if err := mgr.ClearCredentials(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		select {
+		case <-refresher.done:
+			return true
+		default:
+			return false
+		}
+	})
+	refresher.Stop()
+}
+
+func TestRefresher_RestartsAfterLogout(t *testing.T) {
+	mgr := newVaultManager(t, "test-device-hash", t.TempDir())
+
+	refresher := NewRefresher(mgr, func(ctx context.Context) error { return nil }, nil)
+	refresher.Start() // No credentials: ends at once
+	waitFor(t, func() bool {
+		select {
+		case <-refresher.done:
+			return true
+		default:
+			return false
+		}
+	})
+
+	if err := mgr.SaveCredentials(&Credentials{AccessToken: "access-1", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	refresher.Start()
+	select {
+	case <-refresher.done:
+		t.Error("Expected the refresher to run again after login")
+	case <-time.After(50 * time.Millisecond):
+	}
+	refresher.Stop()
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -39,7 +39,10 @@ func (c *Credentials) IsExpired() bool {
 	return time.Now().After(c.ExpiresAt)
 }
 
+// refreshWindow is how long before expiry a token is refreshed
+const refreshWindow = 10 * time.Minute
+
 // NeedsRefresh checks if token expires within 10 minutes
 func (c *Credentials) NeedsRefresh() bool {
-	return time.Until(c.ExpiresAt) < 10*time.Minute
+	return time.Until(c.ExpiresAt) < refreshWindow
 }
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Vault file format (v2):
+//
+//	magic "BBVAULT" | version (1) | flags (1) | argon2 time (4) | memory KiB (4) | threads (1) | salt (16) | nonce + AES-GCM ciphertext
+//
+// The header is authenticated as additional data, so its KDF parameters and flags cannot be altered.
+// v1 files have no header: nonce + ciphertext under deriveKey(deviceHash).
+const (
+	vaultMagic      = "BBVAULT"
+	vaultVersion    = 2
+	vaultSaltSize   = 16
+	vaultHeaderSize = len(vaultMagic) + 1 + 1 + 4 + 4 + 1 + vaultSaltSize
+
+	flagPassphrase = 1 << 0 // The key mixes in a user passphrase
+)
+
+var (
+	// ErrPassphraseRequired is returned when the stored login is protected by a passphrase that was not set
+	ErrPassphraseRequired = errors.New("stored login is protected by a passphrase")
+	// ErrInvalidKey is returned when the stored login cannot be decrypted: wrong passphrase or changed fingerprint
+	ErrInvalidKey = errors.New("wrong passphrase or device fingerprint")
+	// ErrCorrupted is returned when the stored login is malformed; unlike read errors it won't go away
+	ErrCorrupted = errors.New("stored login is corrupted")
+)
+
+// kdfParams are the argon2id cost parameters, stored in the header so they can be raised later
+type kdfParams struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+}
+
+// Bounds of the KDF parameters accepted from a vault header, so a crafted file cannot
+// make key derivation run for minutes or allocate gigabytes
+const (
+	maxKDFTime   = 10
+	maxKDFMemory = 1024 * 1024 // KiB (1 GiB)
+)
+
+// defaultKDF follows the argon2id recommendation of RFC 9106 for memory-constrained machines
+var defaultKDF = kdfParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+type vaultHeader struct {
+	Version byte
+	Flags   byte
+	KDF     kdfParams
+	Salt    [vaultSaltSize]byte
+}
+
+func (h vaultHeader) marshal() []byte {
+	buf := make([]byte, 0, vaultHeaderSize)
+	buf = append(buf, vaultMagic...)
+	buf = append(buf, h.Version, h.Flags)
+	buf = binary.BigEndian.AppendUint32(buf, h.KDF.Time)
+	buf = binary.BigEndian.AppendUint32(buf, h.KDF.Memory)
+	buf = append(buf, h.KDF.Threads)
+	return append(buf, h.Salt[:]...)
+}
+
+// isVault reports whether data has a versioned header (v2+), as opposed to a v1 file
+func isVault(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(vaultMagic))
+}
+
+func parseVaultHeader(data []byte) (vaultHeader, error) {
+	var h vaultHeader
+	if !isVault(data) || len(data) < vaultHeaderSize {
+		return h, fmt.Errorf("not a vault file")
+	}
+
+	rest := data[len(vaultMagic):]
+	h.Version, h.Flags = rest[0], rest[1]
+	if h.Version != vaultVersion {
+		return h, fmt.Errorf("unsupported vault version %d", h.Version)
+	}
+	h.KDF.Time = binary.BigEndian.Uint32(rest[2:6])
+	h.KDF.Memory = binary.BigEndian.Uint32(rest[6:10])
+	h.KDF.Threads = rest[10]
+	copy(h.Salt[:], rest[11:11+vaultSaltSize])
+	if err := h.KDF.check(); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// check rejects KDF parameters outside the bounds argon2id is used with
+func (p kdfParams) check() error {
+	switch {
+	case p.Time < 1 || p.Time > maxKDFTime:
+		return fmt.Errorf("argon2 time %d out of range 1..%d", p.Time, maxKDFTime)
+	case p.Memory < 1 || p.Memory > maxKDFMemory:
+		return fmt.Errorf("argon2 memory %d KiB out of range 1..%d", p.Memory, maxKDFMemory)
+	case p.Threads < 1:
+		return fmt.Errorf("argon2 threads must be at least 1")
+	}
+	return nil
+}
+
+// deriveVaultKey derives the AES key from the device hash and optional passphrase with argon2id
+func deriveVaultKey(deviceHash, passphrase string, h vaultHeader) []byte {
+	secret := deviceHash + "\x00" + passphrase
+	return argon2.IDKey([]byte(secret), h.Salt[:], h.KDF.Time, h.KDF.Memory, h.KDF.Threads, 32)
+}
+
+// sealVault encrypts plaintext into a v2 vault file with a fresh random salt
+func sealVault(plaintext []byte, deviceHash, passphrase string, params kdfParams) ([]byte, error) {
+	h := vaultHeader{Version: vaultVersion, KDF: params}
+	if passphrase != "" {
+		h.Flags |= flagPassphrase
+	}
+	if _, err := io.ReadFull(rand.Reader, h.Salt[:]); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	header := h.marshal()
+	sealed, err := encryptAAD(plaintext, deriveVaultKey(deviceHash, passphrase, h), header)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, sealed...), nil
+}
+
+// openVault decrypts a v2 vault file
+func openVault(data []byte, deviceHash, passphrase string) ([]byte, error) {
+	h, err := parseVaultHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.Flags&flagPassphrase != 0 && passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+	if h.Flags&flagPassphrase == 0 {
+		passphrase = "" // Not part of this file's key
+	}
+
+	header := data[:vaultHeaderSize]
+	plaintext, err := decryptAAD(data[vaultHeaderSize:], deriveVaultKey(deviceHash, passphrase, h), header)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return plaintext, nil
+}
+
+// openAny decrypts a vault or v1 file and returns its format version
+func openAny(data []byte, deviceHash, passphrase string) ([]byte, int, error) {
+	if isVault(data) {
+		plaintext, err := openVault(data, deviceHash, passphrase)
+		return plaintext, vaultVersion, err
+	}
+
+	plaintext, err := decrypt(data, deriveKey(deviceHash))
+	if err != nil {
+		return nil, 1, ErrInvalidKey
+	}
+	return plaintext, 1, nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testKDF keeps key derivation cheap in tests
+var testKDF = kdfParams{Time: 1, Memory: 64, Threads: 1}
+
+func newVaultManager(t *testing.T, deviceHash, dir string) *Manager {
+	t.Helper()
+	mgr := NewManager(deviceHash)
+	mgr.storageDir = dir
+	mgr.kdf = testKDF
+	return mgr
+}
+
+func testCredentials() *Credentials {
+	return &Credentials{AccessToken: "access-1", RefreshToken: "refresh-1", ExpiresAt: time.Now().Add(time.Hour), User: User{Username: "testuser"}}
+}
+
+func TestVault_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+	mgr := newVaultManager(t, "device-a", dir)
+	mgr.SetPassphrase("correct horse")
+	if err := mgr.SaveCredentials(testCredentials()); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+
+	status := mgr.Status()
+	if !status.Exists || status.Version != vaultVersion || !status.PassphraseProtected {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// Same device without the passphrase
+	locked := newVaultManager(t, "device-a", dir)
+	if _, err := locked.LoadCredentials(); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+	locked.SetPassphrase("wrong")
+	if _, err := locked.LoadCredentials(); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+	locked.SetPassphrase("correct horse")
+	if creds, err := locked.LoadCredentials(); err != nil || creds.AccessToken != "access-1" {
+		t.Fatalf("LoadCredentials() = %+v, %v", creds, err)
+	}
+
+	// Removing the passphrase re-encrypts with the device key only
+	if err := locked.ChangePassphrase(""); err != nil {
+		t.Fatalf("ChangePassphrase() error = %v", err)
+	}
+	if _, err := newVaultManager(t, "device-a", dir).LoadCredentials(); err != nil {
+		t.Errorf("Expected the device key alone to open the vault, got %v", err)
+	}
+}
+
+func TestVault_FreshSaltPerSave(t *testing.T) {
+	mgr := newVaultManager(t, "device-a", t.TempDir())
+	path := filepath.Join(mgr.storageDir, "credentials.enc")
+
+	mgr.SaveCredentials(testCredentials())
+	first, _ := os.ReadFile(path)
+	mgr.SaveCredentials(testCredentials())
+	second, _ := os.ReadFile(path)
+
+	h1, _ := parseVaultHeader(first)
+	h2, _ := parseVaultHeader(second)
+	if h1.Salt == h2.Salt {
+		t.Error("Expected a new salt for every save")
+	}
+}
+
+func TestVault_HeaderIsAuthenticated(t *testing.T) {
+	mgr := newVaultManager(t, "device-a", t.TempDir())
+	mgr.SetPassphrase("secret")
+	mgr.SaveCredentials(testCredentials())
+
+	// Clearing the passphrase flag must not yield a file the device key alone can open
+	path := filepath.Join(mgr.storageDir, "credentials.enc")
+	data, _ := os.ReadFile(path)
+	data[len(vaultMagic)+1] &^= flagPassphrase
+	os.WriteFile(path, data, 0600)
+
+	if _, err := newVaultManager(t, "device-a", mgr.storageDir).LoadCredentials(); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for a tampered header, got %v", err)
+	}
+}
+
+func TestVault_RejectsUnsafeKDFParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch func(kdf []byte) // time (4) | memory KiB (4) | threads (1)
+	}{
+		{"zero time", func(kdf []byte) { binary.BigEndian.PutUint32(kdf[0:4], 0) }},
+		{"huge time", func(kdf []byte) { binary.BigEndian.PutUint32(kdf[0:4], 1<<30) }},
+		{"huge memory", func(kdf []byte) { binary.BigEndian.PutUint32(kdf[4:8], 0xFFFFFFFF) }},
+		{"zero threads", func(kdf []byte) { kdf[8] = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := newVaultManager(t, "device-a", t.TempDir())
+			mgr.SaveCredentials(testCredentials())
+
+			path := filepath.Join(mgr.storageDir, "credentials.enc")
+			data, _ := os.ReadFile(path)
+			tt.patch(data[len(vaultMagic)+2:])
+			os.WriteFile(path, data, 0600)
+
+			if _, err := newVaultManager(t, "device-a", mgr.storageDir).LoadCredentials(); !errors.Is(err, ErrCorrupted) {
+				t.Errorf("Expected ErrCorrupted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVault_MigratesV1(t *testing.T) {
+	dir := t.TempDir()
+	v1, err := encrypt([]byte(`{"accessToken":"legacy-access","refreshToken":"legacy-refresh"}`), deriveKey("device-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "credentials.enc"), v1, 0600)
+
+	mgr := newVaultManager(t, "device-a", dir)
+	if status := mgr.Status(); status.Version != 1 {
+		t.Errorf("Expected a v1 file, got %+v", status)
+	}
+
+	creds, err := mgr.LoadCredentials()
+	if err != nil || creds.AccessToken != "legacy-access" {
+		t.Fatalf("LoadCredentials() = %+v, %v", creds, err)
+	}
+	if status := mgr.Status(); status.Version != vaultVersion {
+		t.Errorf("Expected the file to be migrated, got %+v", status)
+	}
+	if _, err := newVaultManager(t, "device-a", dir).LoadCredentials(); err != nil {
+		t.Errorf("Migrated file unreadable: %v", err)
+	}
+}
+
+func TestVault_RekeyAfterFingerprintChange(t *testing.T) {
+	dir := t.TempDir()
+	old := newVaultManager(t, "device-a", dir)
+	old.SaveCredentials(testCredentials())
+
+	mgr := newVaultManager(t, "device-b", dir)
+	if _, err := mgr.LoadCredentials(); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Expected ErrInvalidKey after a fingerprint change, got %v", err)
+	}
+	if err := mgr.Rekey("device-a"); err != nil {
+		t.Fatalf("Rekey() error = %v", err)
+	}
+	if creds, err := mgr.LoadCredentials(); err != nil || creds.AccessToken != "access-1" {
+		t.Errorf("LoadCredentials() after Rekey = %+v, %v", creds, err)
+	}
+	if _, err := newVaultManager(t, "device-a", dir).LoadCredentials(); err == nil {
+		t.Error("Expected the old fingerprint to no longer open the vault")
+	}
+}
@@ -0,0 +1,452 @@
+package bbcoremock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Interval of the heart-beats sent to clients, which keep their read deadlines from expiring
+const brokerHeartBeat = 10 * time.Second
+
+// Number of activities kept per room for PK_SYNC
+const maxActivities = 10
+
+// Message is a STOMP SEND received by the broker
+type Message struct {
+	Destination string          `json:"destination"`
+	Type        string          `json:"type"` // "type" field of the JSON body (GIFT, CHAT, STATUS...)
+	Body        json.RawMessage `json:"body"`
+	ReceivedAt  time.Time       `json:"receivedAt"`
+}
+
+// TeamScore is a team's entry in PK_SYNC
+type TeamScore struct {
+	TeamId   string `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Score    int64  `json:"score"`
+	Avatar   string `json:"avatar"`
+}
+
+// Activity is a feed entry in PK_SYNC
+type Activity struct {
+	Timestamp int64                  `json:"timestamp"`
+	Type      string                 `json:"type"`
+	Message   string                 `json:"message"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{"v12.stomp", "v11.stomp", "v10.stomp"},
+	CheckOrigin:  func(r *http.Request) bool { return true },
+}
+
+// handleWebSocket serves STOMP over a plain WebSocket
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	s.serveStomp(ws, false, r.URL.Query().Get("token"))
+}
+
+// handleSockJS serves the SockJS /info probe and the websocket transport (/ws/{server}/{session}/websocket).
+// XHR transports are not implemented; /info advertises WebSocket support so clients use it.
+func (s *Server) handleSockJS(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/ws/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "info":
+		writeJSON(w, map[string]interface{}{"websocket": true, "cookie_needed": false, "origins": []string{"*:*"}, "entropy": time.Now().UnixNano() % 1e9})
+	case len(parts) == 3 && parts[2] == "websocket":
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.serveStomp(ws, true, r.URL.Query().Get("token"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Messages returns the SENDs received for a destination, such as /app/room/{roomId}/bigo
+func (s *Server) Messages(destination string) []Message {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var messages []Message
+	for _, msg := range s.messages {
+		if msg.Destination == destination {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// WaitForMessages waits until at least n SENDs were received for a destination
+func (s *Server) WaitForMessages(destination string, n int, timeout time.Duration) ([]Message, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		messages := s.Messages(destination)
+		if len(messages) >= n {
+			return messages, nil
+		}
+		if time.Now().After(deadline) {
+			return messages, fmt.Errorf("got %d of %d messages for %s", len(messages), n, destination)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Connections returns the number of open STOMP connections
+func (s *Server) Connections() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.conns)
+}
+
+// DropConnections closes every STOMP connection without a DISCONNECT, like a broker restart
+func (s *Server) DropConnections() {
+	s.mutex.Lock()
+	conns := make([]*stompConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mutex.Unlock()
+
+	for _, c := range conns {
+		c.ws.Close()
+	}
+}
+
+// PublishPKSync sends the current scores of a room to /topic/room/{roomId}/pk
+func (s *Server) PublishPKSync(roomId string) {
+	s.mutex.RLock()
+	teamScores := s.teamScoresLocked(roomId)
+	activities := append([]Activity{}, s.activities[roomId]...)
+	s.mutex.RUnlock()
+
+	var leader string
+	var total, best int64
+	for _, ts := range teamScores {
+		total += ts.Score
+		if ts.Score > best {
+			best, leader = ts.Score, ts.TeamId
+		}
+	}
+
+	s.publish(fmt.Sprintf("/topic/room/%s/pk", roomId), map[string]interface{}{
+		"type": "PK_SYNC",
+		"payload": map[string]interface{}{
+			"teamScores": teamScores,
+			"leader":     leader,
+			"totalScore": total,
+			"activities": activities,
+		},
+	})
+}
+
+// teamScoresLocked returns the scores of every configured team, highest first. Caller must hold mutex.
+func (s *Server) teamScoresLocked(roomId string) []TeamScore {
+	cfg, ok := s.configs[roomId]
+	if !ok {
+		cfg = DemoConfig(roomId)
+	}
+
+	scores := s.scores[roomId]
+	teamScores := make([]TeamScore, 0, len(cfg.Teams))
+	for _, team := range cfg.Teams {
+		teamScores = append(teamScores, TeamScore{TeamId: team.TeamId, TeamName: team.Name, Score: scores[team.TeamId], Avatar: team.Avatar})
+	}
+	sort.SliceStable(teamScores, func(i, j int) bool { return teamScores[i].Score > teamScores[j].Score })
+	return teamScores
+}
+
+// publish sends a MESSAGE frame to every subscription of a destination
+func (s *Server) publish(destination string, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.nextMessage++
+	messageId := strconv.Itoa(s.nextMessage)
+	conns := make([]*stompConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mutex.Unlock()
+
+	for _, c := range conns {
+		for _, id := range c.subscriptionsTo(destination) {
+			c.send(frame{
+				command: "MESSAGE",
+				headers: [][2]string{{"destination", destination}, {"subscription", id}, {"message-id", messageId}, {"content-type", "application/json"}},
+				body:    body,
+			})
+		}
+	}
+}
+
+// record stores a SEND and scores gifts sent to /app/room/{roomId}/bigo
+func (s *Server) record(destination string, body []byte) {
+	var fields map[string]interface{}
+	json.Unmarshal(body, &fields)
+	msgType, _ := fields["type"].(string)
+
+	s.mutex.Lock()
+	s.messages = append(s.messages, Message{Destination: destination, Type: msgType, Body: append(json.RawMessage(nil), body...), ReceivedAt: time.Now()})
+	s.mutex.Unlock()
+
+	roomId, ok := bigoRoom(destination)
+	if !ok || msgType != "GIFT" {
+		return
+	}
+
+	teamId, _ := fields["teamId"].(string)
+	diamonds, _ := fields["diamonds"].(float64)
+	sender, _ := fields["senderName"].(string)
+	giftName, _ := fields["giftName"].(string)
+	count, _ := fields["giftCount"].(float64)
+
+	s.mutex.Lock()
+	if s.scores[roomId] == nil {
+		s.scores[roomId] = make(map[string]int64)
+	}
+	s.scores[roomId][teamId] += int64(diamonds)
+	activity := Activity{
+		Timestamp: time.Now().UnixMilli(),
+		Type:      "GIFT",
+		Message:   fmt.Sprintf("%s sent %s x%d", sender, giftName, int(count)),
+		Metadata:  map[string]interface{}{"giftName": giftName, "diamonds": int64(diamonds)},
+	}
+	activities := append(s.activities[roomId], activity)
+	if len(activities) > maxActivities {
+		activities = activities[len(activities)-maxActivities:]
+	}
+	s.activities[roomId] = activities
+	s.mutex.Unlock()
+
+	s.PublishPKSync(roomId)
+}
+
+// bigoRoom extracts roomId from /app/room/{roomId}/bigo
+func bigoRoom(destination string) (string, bool) {
+	if !strings.HasPrefix(destination, "/app/room/") || !strings.HasSuffix(destination, "/bigo") {
+		return "", false
+	}
+	roomId := strings.TrimSuffix(strings.TrimPrefix(destination, "/app/room/"), "/bigo")
+	return roomId, roomId != "" && !strings.Contains(roomId, "/")
+}
+
+// frame is a STOMP frame
+type frame struct {
+	command string
+	headers [][2]string
+	body    []byte
+}
+
+func (f frame) header(name string) string {
+	for _, h := range f.headers {
+		if h[0] == name {
+			return h[1]
+		}
+	}
+	return ""
+}
+
+func (f frame) encode() string {
+	var b strings.Builder
+	b.WriteString(f.command + "\n")
+	for _, h := range f.headers {
+		b.WriteString(h[0] + ":" + h[1] + "\n")
+	}
+	b.WriteString("\n")
+	b.Write(f.body)
+	b.WriteByte(0)
+	return b.String()
+}
+
+// parseFrames removes the complete frames from buf. Heart-beat newlines between frames are skipped.
+func parseFrames(buf *bytes.Buffer) []frame {
+	var frames []frame
+	for {
+		data := bytes.TrimLeft(buf.Bytes(), "\r\n")
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return frames
+		}
+
+		head, body, _ := bytes.Cut(data[:end], []byte("\n\n"))
+		lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+		f := frame{command: lines[0], body: append([]byte(nil), body...)}
+		for _, line := range lines[1:] {
+			if k, v, ok := strings.Cut(line, ":"); ok {
+				f.headers = append(f.headers, [2]string{k, v})
+			}
+		}
+		frames = append(frames, f)
+
+		rest := append([]byte(nil), data[end+1:]...)
+		buf.Reset()
+		buf.Write(rest)
+	}
+}
+
+// stompConn is one client connection over a plain or SockJS WebSocket
+type stompConn struct {
+	ws            *websocket.Conn
+	sockJS        bool
+	queryToken    string
+	writeMutex    sync.Mutex
+	subscriptions map[string]string // subscription id -> destination
+	subMutex      sync.Mutex
+}
+
+func (s *Server) serveStomp(ws *websocket.Conn, sockJS bool, queryToken string) {
+	c := &stompConn{ws: ws, sockJS: sockJS, queryToken: queryToken, subscriptions: make(map[string]string)}
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, c)
+		s.mutex.Unlock()
+		ws.Close()
+	}()
+
+	if sockJS {
+		c.write("o")
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	var inbox bytes.Buffer
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if sockJS {
+			var messages []string
+			if err := json.Unmarshal(data, &messages); err != nil {
+				continue
+			}
+			for _, msg := range messages {
+				inbox.WriteString(msg)
+			}
+		} else {
+			inbox.Write(data)
+		}
+
+		for _, f := range parseFrames(&inbox) {
+			if !s.handleFrame(c, f, done) {
+				return
+			}
+		}
+	}
+}
+
+// handleFrame processes a client frame and reports whether the connection stays open
+func (s *Server) handleFrame(c *stompConn, f frame, done chan struct{}) bool {
+	switch f.command {
+	case "CONNECT", "STOMP":
+		token := f.header("login")
+		if token == "" {
+			token = strings.TrimPrefix(f.header("Authorization"), "Bearer ")
+		}
+		if token == "" {
+			token = c.queryToken
+		}
+		if code, message := s.checkToken(token); code != 0 {
+			c.send(frame{command: "ERROR", headers: [][2]string{{"message", message}}, body: []byte(fmt.Sprintf("[%d] %s", code, message))})
+			return false
+		}
+
+		c.send(frame{command: "CONNECTED", headers: [][2]string{{"version", "1.2"}, {"heart-beat", fmt.Sprintf("%d,0", brokerHeartBeat.Milliseconds())}}})
+		s.mutex.Lock()
+		s.conns[c] = true
+		s.mutex.Unlock()
+		go c.heartBeat(done)
+	case "SUBSCRIBE":
+		c.subMutex.Lock()
+		c.subscriptions[f.header("id")] = f.header("destination")
+		c.subMutex.Unlock()
+	case "UNSUBSCRIBE":
+		c.subMutex.Lock()
+		delete(c.subscriptions, f.header("id"))
+		c.subMutex.Unlock()
+	case "SEND":
+		s.record(f.header("destination"), f.body)
+	case "DISCONNECT":
+		if receipt := f.header("receipt"); receipt != "" {
+			c.send(frame{command: "RECEIPT", headers: [][2]string{{"receipt-id", receipt}}})
+		}
+		return false
+	}
+
+	if receipt := f.header("receipt"); receipt != "" {
+		c.send(frame{command: "RECEIPT", headers: [][2]string{{"receipt-id", receipt}}})
+	}
+	return true
+}
+
+func (c *stompConn) subscriptionsTo(destination string) []string {
+	c.subMutex.Lock()
+	defer c.subMutex.Unlock()
+
+	var ids []string
+	for id, d := range c.subscriptions {
+		if d == destination {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// send writes a frame, wrapped in a SockJS array frame when needed
+func (c *stompConn) send(f frame) error {
+	if !c.sockJS {
+		return c.write(f.encode())
+	}
+	data, err := json.Marshal([]string{f.encode()})
+	if err != nil {
+		return err
+	}
+	return c.write("a" + string(data))
+}
+
+func (c *stompConn) write(data string) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return c.ws.WriteMessage(websocket.TextMessage, []byte(data))
+}
+
+// heartBeat sends an EOL (or a SockJS "h" frame) every brokerHeartBeat until the connection ends
+func (c *stompConn) heartBeat(done chan struct{}) {
+	ticker := time.NewTicker(brokerHeartBeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			beat := "\n"
+			if c.sockJS {
+				beat = "h"
+			}
+			if c.write(beat) != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,487 @@
+// Package bbcoremock is an in-memory stand-in for BB-Core. It serves the REST endpoints BBapp
+// uses (auth, external config, trial validation, heartbeat, scripts) and a STOMP broker over
+// WebSocket/SockJS that records what BBapp publishes and answers gifts with PK_SYNC.
+package bbcoremock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"bbapp/internal/api"
+)
+
+// Token lifetimes reported to clients
+const (
+	accessTokenTTL  = 24 * time.Hour
+	refreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// Script statuses
+const (
+	StatusActive    = "ACTIVE"
+	StatusPaused    = "PAUSED"
+	StatusCompleted = "COMPLETED"
+)
+
+// Script is a script session started through /api/v1/scripts/start
+type Script struct {
+	SessionId       string                 `json:"sessionId"`
+	RoomId          string                 `json:"roomId"`
+	ScriptType      string                 `json:"scriptType"`
+	Status          string                 `json:"status"`
+	StartedAt       int64                  `json:"startedAt"`
+	EndsAt          int64                  `json:"endsAt"`
+	EndedAt         int64                  `json:"endedAt,omitempty"`
+	DurationMinutes int                    `json:"durationMinutes"`
+	FinalData       map[string]interface{} `json:"finalData,omitempty"`
+}
+
+// Server is a mock BB-Core. It implements http.Handler; serve it with http.ListenAndServe or httptest.NewServer.
+type Server struct {
+	mux   *http.ServeMux
+	mutex sync.RWMutex
+
+	users         map[string]string // username -> password
+	accessTokens  map[string]string // access token -> username
+	refreshTokens map[string]string // refresh token -> username
+	expired       map[string]bool   // access tokens rejected with "token expired"
+
+	configs     map[string]api.Config // roomId -> config
+	scripts     map[string]*Script    // sessionId -> script
+	heartbeats  []api.HeartbeatRequest
+	blockedIds  map[string]bool // Bigo IDs rejected by validate-trial
+	scores      map[string]map[string]int64
+	activities  map[string][]Activity
+	messages    []Message
+	conns       map[*stompConn]bool
+	nextMessage int
+}
+
+// NewServer creates a mock BB-Core with one user (username/password)
+func NewServer(username, password string) *Server {
+	s := &Server{
+		mux:           http.NewServeMux(),
+		users:         map[string]string{username: password},
+		accessTokens:  make(map[string]string),
+		refreshTokens: make(map[string]string),
+		expired:       make(map[string]bool),
+		configs:       make(map[string]api.Config),
+		scripts:       make(map[string]*Script),
+		blockedIds:    make(map[string]bool),
+		scores:        make(map[string]map[string]int64),
+		activities:    make(map[string][]Activity),
+		conns:         make(map[*stompConn]bool),
+	}
+	s.setupRoutes()
+	return s
+}
+
+func (s *Server) setupRoutes() {
+	s.mux.HandleFunc("/api/v1/auth/health", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Auth service is running")
+	})
+	s.mux.HandleFunc("/api/v1/auth/login", s.handleLogin)
+	s.mux.HandleFunc("/api/v1/auth/register", s.handleRegister)
+	s.mux.HandleFunc("/api/v1/auth/refresh-token", s.handleRefresh)
+
+	s.mux.HandleFunc("/api/v1/external/config", s.authenticated(s.handleConfig))
+	s.mux.HandleFunc("/api/v1/external/validate-trial", s.authenticated(s.handleValidateTrial))
+	s.mux.HandleFunc("/api/v1/external/heartbeat", s.authenticated(s.handleHeartbeat))
+
+	s.mux.HandleFunc("/api/v1/scripts/start", s.authenticated(s.handleScriptStart))
+	s.mux.HandleFunc("/api/v1/scripts/stop", s.authenticated(s.handleScriptStatus(StatusCompleted)))
+	s.mux.HandleFunc("/api/v1/scripts/pause", s.authenticated(s.handleScriptStatus(StatusPaused)))
+	s.mux.HandleFunc("/api/v1/scripts/resume", s.authenticated(s.handleScriptStatus(StatusActive)))
+	s.mux.HandleFunc("/api/v1/scripts/", s.authenticated(s.handleScriptGet))
+
+	// STOMP: raw WebSocket on /ws, SockJS under /ws/
+	s.mux.HandleFunc("/ws", s.handleWebSocket)
+	s.mux.HandleFunc("/ws/", s.handleSockJS)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetConfig replaces the config served for a room
+func (s *Server) SetConfig(cfg api.Config) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.configs[cfg.RoomId] = cfg
+}
+
+// BlockBigoIds makes validate-trial reject streamers with these Bigo IDs
+func (s *Server) BlockBigoIds(ids ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		s.blockedIds[id] = true
+	}
+}
+
+// ExpireTokens makes every issued access token fail with "token expired" (error code 2003)
+func (s *Server) ExpireTokens() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for token := range s.accessTokens {
+		s.expired[token] = true
+	}
+}
+
+// Scripts returns the script sessions in no particular order
+func (s *Server) Scripts() []Script {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	scripts := make([]Script, 0, len(s.scripts))
+	for _, script := range s.scripts {
+		scripts = append(scripts, *script)
+	}
+	return scripts
+}
+
+// Heartbeats returns the heartbeats received so far
+func (s *Server) Heartbeats() []api.HeartbeatRequest {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]api.HeartbeatRequest(nil), s.heartbeats...)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req api.LoginRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	password, ok := s.users[req.Username]
+	if !ok || password != req.Password {
+		s.mutex.Unlock()
+		writeError(w, http.StatusUnauthorized, 2002, "Invalid credentials")
+		return
+	}
+	resp := s.issueTokensLocked(req.Username)
+	s.mutex.Unlock()
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req api.RegisterRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+	if req.Username == "" || req.Password == "" || req.AgencyName == "" {
+		writeError(w, http.StatusBadRequest, 1003, "Validation failed")
+		return
+	}
+
+	s.mutex.Lock()
+	if _, exists := s.users[req.Username]; exists {
+		s.mutex.Unlock()
+		writeError(w, http.StatusConflict, 1005, "Username already exists")
+		return
+	}
+	s.users[req.Username] = req.Password
+	resp := s.issueTokensLocked(req.Username)
+	s.mutex.Unlock()
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req api.RefreshTokenRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	username, ok := s.refreshTokens[req.RefreshToken]
+	if !ok {
+		s.mutex.Unlock()
+		writeError(w, http.StatusUnauthorized, 2003, "Refresh token expired")
+		return
+	}
+	delete(s.refreshTokens, req.RefreshToken) // Refresh tokens are single use
+	resp := s.issueTokensLocked(username)
+	s.mutex.Unlock()
+
+	writeJSON(w, resp)
+}
+
+// issueTokensLocked creates a token pair. Caller must hold mutex.
+func (s *Server) issueTokensLocked(username string) api.AuthResponse {
+	access, refresh := "mock-access-"+randomHex(12), "mock-refresh-"+randomHex(12)
+	s.accessTokens[access] = username
+	s.refreshTokens[refresh] = username
+
+	now := time.Now()
+	return api.AuthResponse{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    accessTokenTTL.Milliseconds(),
+		ExpiresAt:    now.Add(accessTokenTTL),
+		User:         api.User{ID: 1, Username: username, RoleCode: "OWNER"},
+		Agency: api.Agency{
+			ID:        1,
+			Name:      "Mock Agency",
+			Plan:      "PAID",
+			Status:    "ACTIVE",
+			MaxRooms:  5,
+			ExpiresAt: now.Add(refreshTokenTTL),
+		},
+	}
+}
+
+// checkToken validates an access token and returns the BB-Core error code when it is not accepted
+func (s *Server) checkToken(token string) (int, string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, ok := s.accessTokens[token]; !ok {
+		return 2002, "Invalid token"
+	}
+	if s.expired[token] {
+		return 2003, "Token expired"
+	}
+	return 0, ""
+}
+
+// authenticated rejects requests without a valid bearer token
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if code, message := s.checkToken(token); code != 0 {
+			writeError(w, http.StatusUnauthorized, code, message)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	roomId := r.URL.Query().Get("roomId")
+	if roomId == "" {
+		roomId = "default"
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, s.roomConfig(roomId))
+	case http.MethodPost:
+		var req api.SaveConfigRequest
+		if !decodePost(w, r, &req) {
+			return
+		}
+		req.ConfigData.RoomId = roomId
+		s.SetConfig(req.ConfigData)
+		writeJSON(w, req.ConfigData)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, 1004, "Method not allowed")
+	}
+}
+
+// roomConfig returns the stored config of a room, or a two-team demo config
+func (s *Server) roomConfig(roomId string) api.Config {
+	s.mutex.RLock()
+	cfg, ok := s.configs[roomId]
+	s.mutex.RUnlock()
+	if ok {
+		return cfg
+	}
+	return DemoConfig(roomId)
+}
+
+// DemoConfig is the config served for rooms without a stored one
+func DemoConfig(roomId string) api.Config {
+	return api.Config{
+		RoomId:   roomId,
+		AgencyId: 1,
+		Teams: []api.Team{
+			{
+				TeamId:      "team-red",
+				Name:        "Team Red",
+				BindingGift: "Kiss",
+				Streamers:   []api.Streamer{{StreamerId: "1", BigoId: "red-idol", BigoRoomId: "1001", Name: "Red Idol", BindingGift: "Rose"}},
+			},
+			{
+				TeamId:      "team-blue",
+				Name:        "Team Blue",
+				BindingGift: "Heart",
+				Streamers:   []api.Streamer{{StreamerId: "2", BigoId: "blue-idol", BigoRoomId: "1002", Name: "Blue Idol", BindingGift: "Lollipop"}},
+			},
+		},
+	}
+}
+
+func (s *Server) handleValidateTrial(w http.ResponseWriter, r *http.Request) {
+	var req api.ValidateTrialRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+
+	s.mutex.RLock()
+	blocked := []string{}
+	for _, streamer := range req.Streamers {
+		if s.blockedIds[streamer.BigoId] {
+			blocked = append(blocked, streamer.BigoId)
+		}
+	}
+	s.mutex.RUnlock()
+
+	if len(blocked) > 0 {
+		writeJSON(w, api.ValidateTrialResponse{
+			Message:        fmt.Sprintf("Bigo ID %s has already been used in a trial", blocked[0]),
+			BlockedBigoIds: blocked,
+			Reason:         "TRIAL_BIGO_ID_USED",
+		})
+		return
+	}
+	writeJSON(w, api.ValidateTrialResponse{Allowed: true, Message: "All streamers validated", BlockedBigoIds: blocked})
+}
+
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req api.HeartbeatRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+
+	s.mutex.Lock()
+	s.heartbeats = append(s.heartbeats, req)
+	s.mutex.Unlock()
+
+	writeJSON(w, map[string]bool{"acknowledged": true})
+}
+
+func (s *Server) handleScriptStart(w http.ResponseWriter, r *http.Request) {
+	var req api.StartScriptRequest
+	if !decodePost(w, r, &req) {
+		return
+	}
+	if req.RoomId == "" || req.ScriptType == "" {
+		writeError(w, http.StatusBadRequest, 1003, "roomId and scriptType are required")
+		return
+	}
+
+	now := time.Now()
+	script := &Script{
+		SessionId:       newUUID(),
+		RoomId:          req.RoomId,
+		ScriptType:      req.ScriptType,
+		Status:          StatusActive,
+		StartedAt:       now.UnixMilli(),
+		EndsAt:          now.Add(time.Duration(req.DurationMinutes) * time.Minute).UnixMilli(),
+		DurationMinutes: req.DurationMinutes,
+	}
+
+	s.mutex.Lock()
+	s.scripts[script.SessionId] = script
+	s.scores[req.RoomId] = make(map[string]int64)
+	s.activities[req.RoomId] = nil
+	s.mutex.Unlock()
+
+	fmt.Printf("[MockCore] Script %s started for room %s (%s, %dm)\n", script.SessionId, script.RoomId, script.ScriptType, script.DurationMinutes)
+	writeJSON(w, script)
+}
+
+// handleScriptStatus moves a script to a new status (stop, pause, resume)
+func (s *Server) handleScriptStatus(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.StopScriptRequest
+		if !decodePost(w, r, &req) {
+			return
+		}
+
+		s.mutex.Lock()
+		script, ok := s.scripts[req.SessionId]
+		if !ok {
+			s.mutex.Unlock()
+			writeError(w, http.StatusNotFound, 1001, "Script session not found")
+			return
+		}
+		if script.Status == StatusCompleted {
+			s.mutex.Unlock()
+			writeError(w, http.StatusBadRequest, 1004, "Script session already completed")
+			return
+		}
+		script.Status = status
+		if status == StatusCompleted {
+			script.EndedAt = time.Now().UnixMilli()
+			script.FinalData = map[string]interface{}{"teamScores": s.teamScoresLocked(script.RoomId)}
+		}
+		result := *script
+		s.mutex.Unlock()
+
+		fmt.Printf("[MockCore] Script %s is now %s\n", result.SessionId, result.Status)
+		writeJSON(w, result)
+	}
+}
+
+func (s *Server) handleScriptGet(w http.ResponseWriter, r *http.Request) {
+	sessionId := strings.TrimPrefix(r.URL.Path, "/api/v1/scripts/")
+
+	s.mutex.RLock()
+	script, ok := s.scripts[sessionId]
+	var result Script
+	if ok {
+		result = *script
+	}
+	s.mutex.RUnlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, 1001, "Script session not found")
+		return
+	}
+	writeJSON(w, result)
+}
+
+// decodePost rejects non-POST requests and decodes the JSON body
+func decodePost(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, 1004, "Method not allowed")
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, 1003, "Invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a BB-Core error response (see api.APIError)
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.APIError{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Status:    strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+		ErrorCode: code,
+		Message:   message,
+	})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
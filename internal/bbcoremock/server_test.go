@@ -0,0 +1,146 @@
+package bbcoremock_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bbapp/internal/api"
+	"bbapp/internal/bbcoremock"
+	"bbapp/internal/stomp"
+)
+
+func newMock(t *testing.T) (*bbcoremock.Server, *httptest.Server, *api.Client) {
+	t.Helper()
+	mock := bbcoremock.NewServer("demo", "demo123")
+	ts := httptest.NewServer(mock)
+	t.Cleanup(ts.Close)
+
+	client := api.NewClient(ts.URL, "")
+	auth, err := client.Login("demo", "demo123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	client.SetTokens(auth.AccessToken, auth.RefreshToken)
+	return mock, ts, client
+}
+
+func TestServer_Login(t *testing.T) {
+	_, ts, _ := newMock(t)
+
+	_, err := api.NewClient(ts.URL, "").Login("demo", "wrong")
+	apiErr, ok := err.(*api.APIError)
+	if !ok || apiErr.ErrorCode != 2002 {
+		t.Errorf("Expected invalid credentials error, got %v", err)
+	}
+}
+
+func TestServer_ScriptLifecycle(t *testing.T) {
+	mock, _, client := newMock(t)
+
+	cfg, err := client.GetConfig("room-1")
+	if err != nil || cfg.RoomId != "room-1" || len(cfg.Teams) != 2 {
+		t.Fatalf("GetConfig() = %+v, %v", cfg, err)
+	}
+
+	started, err := client.StartSession("room-1", 30, map[string]interface{}{"minTeams": 2})
+	if err != nil || started.Status != bbcoremock.StatusActive || started.SessionId == "" {
+		t.Fatalf("StartSession() = %+v, %v", started, err)
+	}
+
+	stopped, err := client.StopSession(started.SessionId)
+	if err != nil || stopped.Status != bbcoremock.StatusCompleted || stopped.FinalData["teamScores"] == nil {
+		t.Fatalf("StopSession() = %+v, %v", stopped, err)
+	}
+
+	if _, err := client.StopSession(started.SessionId); err == nil {
+		t.Error("Expected stopping a completed script to fail")
+	}
+	if scripts := mock.Scripts(); len(scripts) != 1 || scripts[0].EndedAt == 0 {
+		t.Errorf("Unexpected scripts: %+v", scripts)
+	}
+}
+
+func TestServer_ValidateTrialAndHeartbeat(t *testing.T) {
+	mock, _, client := newMock(t)
+	mock.BlockBigoIds("red-idol")
+
+	resp, err := client.ValidateTrial([]api.ValidateTrialStreamer{{BigoId: "red-idol"}, {BigoId: "blue-idol"}})
+	if err != nil || resp.Allowed || len(resp.BlockedBigoIds) != 1 {
+		t.Errorf("ValidateTrial() = %+v, %v", resp, err)
+	}
+
+	if err := client.SendHeartbeat(api.HeartbeatRequest{Connections: []api.ConnectionStatus{{BigoId: "red-idol", Status: "CONNECTED"}}}); err != nil {
+		t.Fatalf("SendHeartbeat() error = %v", err)
+	}
+	if beats := mock.Heartbeats(); len(beats) != 1 || beats[0].Connections[0].BigoId != "red-idol" {
+		t.Errorf("Unexpected heartbeats: %+v", beats)
+	}
+}
+
+func TestServer_ExpiredTokenIsRefreshed(t *testing.T) {
+	mock, _, client := newMock(t)
+	before := client.GetAccessToken()
+
+	mock.ExpireTokens()
+	if _, err := client.GetConfig("room-1"); err != nil {
+		t.Fatalf("GetConfig() after expiry error = %v", err)
+	}
+	if client.GetAccessToken() == before {
+		t.Error("Expected the client to refresh its access token")
+	}
+}
+
+func TestServer_StompRecordsAndPublishesPKSync(t *testing.T) {
+	mock, ts, client := newMock(t)
+
+	// The URL form BBapp uses: SockJS under /ws
+	stompClient, err := stomp.NewClient(ts.URL+"/ws", client.GetAccessToken(), "")
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer stompClient.Disconnect()
+
+	syncs := make(chan []byte, 4)
+	if err := stompClient.Subscribe("/topic/room/room-1/pk", func(msg []byte) { syncs <- msg }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	gift := map[string]interface{}{"type": "GIFT", "roomId": "room-1", "teamId": "team-blue", "giftName": "Heart", "diamonds": 500, "giftCount": 1}
+	if err := stompClient.Publish("/app/room/room-1/bigo", gift); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	messages, err := mock.WaitForMessages("/app/room/room-1/bigo", 1, 5*time.Second)
+	if err != nil || messages[0].Type != "GIFT" {
+		t.Fatalf("WaitForMessages() = %+v, %v", messages, err)
+	}
+
+	select {
+	case data := <-syncs:
+		var sync struct {
+			Type    string `json:"type"`
+			Payload struct {
+				TeamScores []bbcoremock.TeamScore `json:"teamScores"`
+				Leader     string                 `json:"leader"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &sync); err != nil {
+			t.Fatalf("Invalid PK_SYNC: %v", err)
+		}
+		if sync.Type != "PK_SYNC" || sync.Payload.Leader != "team-blue" || sync.Payload.TeamScores[0].Score != 500 {
+			t.Errorf("Unexpected PK_SYNC: %s", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No PK_SYNC received")
+	}
+}
+
+func TestServer_StompRejectsUnknownToken(t *testing.T) {
+	_, ts, _ := newMock(t)
+
+	if _, err := stomp.NewClient(ts.URL+"/ws", "not-a-token", ""); err == nil {
+		t.Error("Expected CONNECT with an unknown token to fail")
+	}
+}
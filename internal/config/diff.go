@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"bbapp/internal/api"
+)
+
+// Diff describes what changed between two configs of a room
+type Diff struct {
+	AddedStreamers   []api.Streamer
+	RemovedStreamers []api.Streamer
+	ChangedBindings  []BindingChange
+	AddedBigoRooms   []string // Bigo rooms that need a listener
+	RemovedBigoRooms []string // Bigo rooms whose listener can stop
+	Changed          bool     // Anything changed, including team names or overlay settings
+}
+
+// BindingChange is a binding gift that was set, changed or cleared
+type BindingChange struct {
+	TeamId     string `json:"teamId"`
+	StreamerId string `json:"streamerId,omitempty"` // "" = the team's binding gift
+	Old        string `json:"old"`
+	New        string `json:"new"`
+}
+
+// Compare returns the changes from old to updated. A nil old config counts as empty.
+func Compare(old, updated *api.Config) Diff {
+	if old == nil {
+		old = &api.Config{}
+	}
+	if updated == nil {
+		updated = &api.Config{}
+	}
+
+	diff := Diff{Changed: !reflect.DeepEqual(old, updated)}
+	if !diff.Changed {
+		return diff
+	}
+
+	oldStreamers, newStreamers := streamersByKey(old), streamersByKey(updated)
+	for key, streamer := range newStreamers {
+		if _, ok := oldStreamers[key]; !ok {
+			diff.AddedStreamers = append(diff.AddedStreamers, streamer)
+		}
+	}
+	for key, streamer := range oldStreamers {
+		if _, ok := newStreamers[key]; !ok {
+			diff.RemovedStreamers = append(diff.RemovedStreamers, streamer)
+		}
+	}
+	sortStreamers(diff.AddedStreamers)
+	sortStreamers(diff.RemovedStreamers)
+
+	oldBindings, newBindings := bindings(old), bindings(updated)
+	for key, change := range newBindings {
+		if previous, ok := oldBindings[key]; !ok || !strings.EqualFold(previous.New, change.New) {
+			change.Old = previous.New
+			diff.ChangedBindings = append(diff.ChangedBindings, change)
+		}
+	}
+	for key, previous := range oldBindings {
+		if _, ok := newBindings[key]; !ok {
+			diff.ChangedBindings = append(diff.ChangedBindings, BindingChange{TeamId: previous.TeamId, StreamerId: previous.StreamerId, Old: previous.New})
+		}
+	}
+	sort.Slice(diff.ChangedBindings, func(i, j int) bool {
+		a, b := diff.ChangedBindings[i], diff.ChangedBindings[j]
+		return a.TeamId+"/"+a.StreamerId < b.TeamId+"/"+b.StreamerId
+	})
+
+	oldRooms, newRooms := bigoRooms(old), bigoRooms(updated)
+	for room := range newRooms {
+		if !oldRooms[room] {
+			diff.AddedBigoRooms = append(diff.AddedBigoRooms, room)
+		}
+	}
+	for room := range oldRooms {
+		if !newRooms[room] {
+			diff.RemovedBigoRooms = append(diff.RemovedBigoRooms, room)
+		}
+	}
+	sort.Strings(diff.AddedBigoRooms)
+	sort.Strings(diff.RemovedBigoRooms)
+
+	return diff
+}
+
+// Empty reports whether the configs were identical
+func (d Diff) Empty() bool {
+	return !d.Changed
+}
+
+// String summarizes the diff for logs
+func (d Diff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+	return fmt.Sprintf("%d streamers added, %d removed, %d bindings changed, %d listeners to start, %d to stop",
+		len(d.AddedStreamers), len(d.RemovedStreamers), len(d.ChangedBindings), len(d.AddedBigoRooms), len(d.RemovedBigoRooms))
+}
+
+// StreamerKey identifies a streamer across config versions: StreamerId, else BigoId, else BigoRoomId
+func StreamerKey(s api.Streamer) string {
+	switch {
+	case s.StreamerId != "":
+		return s.StreamerId
+	case s.BigoId != "":
+		return "bigo:" + s.BigoId
+	default:
+		return "room:" + s.BigoRoomId
+	}
+}
+
+func streamersByKey(cfg *api.Config) map[string]api.Streamer {
+	streamers := make(map[string]api.Streamer)
+	for _, team := range cfg.Teams {
+		for _, streamer := range team.Streamers {
+			streamers[StreamerKey(streamer)] = streamer
+		}
+	}
+	return streamers
+}
+
+// bindings lists non-empty binding gifts by team and streamer; New holds the gift
+func bindings(cfg *api.Config) map[string]BindingChange {
+	result := make(map[string]BindingChange)
+	for _, team := range cfg.Teams {
+		if team.BindingGift != "" {
+			result[team.TeamId+"/"] = BindingChange{TeamId: team.TeamId, New: team.BindingGift}
+		}
+		for _, streamer := range team.Streamers {
+			if streamer.BindingGift != "" {
+				key := StreamerKey(streamer)
+				result[team.TeamId+"/"+key] = BindingChange{TeamId: team.TeamId, StreamerId: key, New: streamer.BindingGift}
+			}
+		}
+	}
+	return result
+}
+
+func bigoRooms(cfg *api.Config) map[string]bool {
+	rooms := make(map[string]bool)
+	for _, team := range cfg.Teams {
+		for _, streamer := range team.Streamers {
+			if streamer.BigoRoomId != "" {
+				rooms[streamer.BigoRoomId] = true
+			}
+		}
+	}
+	return rooms
+}
+
+func sortStreamers(streamers []api.Streamer) {
+	sort.Slice(streamers, func(i, j int) bool {
+		return StreamerKey(streamers[i]) < StreamerKey(streamers[j])
+	})
+}
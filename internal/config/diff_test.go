@@ -0,0 +1,61 @@
+package config_test
+
+import (
+	"testing"
+
+	"bbapp/internal/api"
+	"bbapp/internal/config"
+)
+
+func diffConfig() *api.Config {
+	return &api.Config{
+		RoomId: "test-room",
+		Teams: []api.Team{
+			{TeamId: "red", BindingGift: "Rose", Streamers: []api.Streamer{
+				{StreamerId: "s1", BigoId: "alice", BigoRoomId: "1001"},
+			}},
+			{TeamId: "blue", Streamers: []api.Streamer{
+				{StreamerId: "s2", BigoId: "bob", BigoRoomId: "1002", BindingGift: "Heart"},
+			}},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if diff := config.Compare(diffConfig(), diffConfig()); !diff.Empty() {
+		t.Errorf("Expected no changes, got %s", diff)
+	}
+
+	updated := diffConfig()
+	updated.Teams[0].BindingGift = "Castle"
+	updated.Teams[1].Streamers = []api.Streamer{{StreamerId: "s3", BigoId: "carol", BigoRoomId: "1003"}}
+
+	diff := config.Compare(diffConfig(), updated)
+	if len(diff.AddedStreamers) != 1 || diff.AddedStreamers[0].StreamerId != "s3" {
+		t.Errorf("AddedStreamers = %+v", diff.AddedStreamers)
+	}
+	if len(diff.RemovedStreamers) != 1 || diff.RemovedStreamers[0].StreamerId != "s2" {
+		t.Errorf("RemovedStreamers = %+v", diff.RemovedStreamers)
+	}
+	if len(diff.AddedBigoRooms) != 1 || diff.AddedBigoRooms[0] != "1003" || len(diff.RemovedBigoRooms) != 1 || diff.RemovedBigoRooms[0] != "1002" {
+		t.Errorf("Bigo rooms: added %v, removed %v", diff.AddedBigoRooms, diff.RemovedBigoRooms)
+	}
+
+	// The team binding changed and the removed streamer's binding is gone
+	if len(diff.ChangedBindings) != 2 {
+		t.Fatalf("ChangedBindings = %+v", diff.ChangedBindings)
+	}
+	if c := diff.ChangedBindings[0]; c.TeamId != "blue" || c.StreamerId != "s2" || c.Old != "Heart" || c.New != "" {
+		t.Errorf("Unexpected binding change: %+v", c)
+	}
+	if c := diff.ChangedBindings[1]; c.TeamId != "red" || c.Old != "Rose" || c.New != "Castle" {
+		t.Errorf("Unexpected binding change: %+v", c)
+	}
+}
+
+func TestCompare_FromNothing(t *testing.T) {
+	diff := config.Compare(nil, diffConfig())
+	if len(diff.AddedStreamers) != 2 || len(diff.AddedBigoRooms) != 2 || len(diff.ChangedBindings) != 2 {
+		t.Errorf("Unexpected diff: %+v", diff)
+	}
+}
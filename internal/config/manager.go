@@ -2,36 +2,103 @@ package config
 
 import (
 	"fmt"
+	"strings"
+
 	"bbapp/internal/api"
 )
 
+// Manager indexes a room config for attribution. It is immutable; build a new one for a new config.
+// Keys are case-insensitive and empty keys are never indexed.
 type Manager struct {
 	config          *api.Config
 	bigoRoomIndex   map[string]*api.Streamer
 	streamerIdIndex map[string]*api.Streamer
+	bigoIdIndex     map[string]*api.Streamer
+	teamIndex       map[string]*api.Team
+	streamerTeam    map[*api.Streamer]*api.Team
+	streamerGifts   map[string]*api.Streamer // Binding gift name or ID -> streamer
+	teamGifts       map[string]*api.Team     // Binding gift name or ID -> team
 }
 
+// NewManager indexes cfg
 func NewManager(cfg *api.Config) *Manager {
+	return NewManagerWithGifts(cfg, nil)
+}
+
+// NewManagerWithGifts indexes cfg; binding gifts are also indexed by the library's other
+// identifier, so a gift bound by name matches events that carry only its ID and vice versa
+func NewManagerWithGifts(cfg *api.Config, library []api.GiftDefinition) *Manager {
 	m := &Manager{
 		config:          cfg,
 		bigoRoomIndex:   make(map[string]*api.Streamer),
 		streamerIdIndex: make(map[string]*api.Streamer),
+		bigoIdIndex:     make(map[string]*api.Streamer),
+		teamIndex:       make(map[string]*api.Team),
+		streamerTeam:    make(map[*api.Streamer]*api.Team),
+		streamerGifts:   make(map[string]*api.Streamer),
+		teamGifts:       make(map[string]*api.Team),
 	}
 
-	// Build indexes
+	// First entry wins, matching the order the former linear scans used
 	for i := range cfg.Teams {
-		for j := range cfg.Teams[i].Streamers {
-			streamer := &cfg.Teams[i].Streamers[j]
-			m.bigoRoomIndex[streamer.BigoRoomId] = streamer
-			m.streamerIdIndex[streamer.StreamerId] = streamer
+		team := &cfg.Teams[i]
+		addIndex(m.teamIndex, team.TeamId, team)
+		for _, key := range giftKeys(team.BindingGift, library) {
+			addIndex(m.teamGifts, key, team)
+		}
+
+		for j := range team.Streamers {
+			streamer := &team.Streamers[j]
+			m.streamerTeam[streamer] = team
+			addIndex(m.bigoRoomIndex, streamer.BigoRoomId, streamer)
+			addIndex(m.streamerIdIndex, streamer.StreamerId, streamer)
+			addIndex(m.bigoIdIndex, streamer.BigoId, streamer)
+			for _, key := range giftKeys(streamer.BindingGift, library) {
+				addIndex(m.streamerGifts, key, streamer)
+			}
 		}
 	}
 
 	return m
 }
 
+func addIndex[T any](index map[string]*T, key string, value *T) {
+	key = normalize(key)
+	if key == "" {
+		return
+	}
+	if _, exists := index[key]; !exists {
+		index[key] = value
+	}
+}
+
+func normalize(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// giftKeys returns the binding gift plus its name or ID from the library
+func giftKeys(bindingGift string, library []api.GiftDefinition) []string {
+	if bindingGift == "" {
+		return nil
+	}
+	keys := []string{bindingGift}
+	if gift, ok := findGift(bindingGift, library); ok {
+		keys = append(keys, gift.Name, gift.ID)
+	}
+	return keys
+}
+
+func findGift(nameOrId string, library []api.GiftDefinition) (api.GiftDefinition, bool) {
+	for _, gift := range library {
+		if strings.EqualFold(gift.Name, nameOrId) || (gift.ID != "" && strings.EqualFold(gift.ID, nameOrId)) {
+			return gift, true
+		}
+	}
+	return api.GiftDefinition{}, false
+}
+
 func (m *Manager) LookupStreamerByBigoRoom(bigoRoomId string) (*api.Streamer, error) {
-	streamer, ok := m.bigoRoomIndex[bigoRoomId]
+	streamer, ok := m.bigoRoomIndex[normalize(bigoRoomId)]
 	if !ok {
 		return nil, fmt.Errorf("no streamer found for Bigo room %s", bigoRoomId)
 	}
@@ -39,17 +106,68 @@ func (m *Manager) LookupStreamerByBigoRoom(bigoRoomId string) (*api.Streamer, er
 }
 
 func (m *Manager) LookupStreamerById(streamerId string) (*api.Streamer, error) {
-	streamer, ok := m.streamerIdIndex[streamerId]
+	streamer, ok := m.streamerIdIndex[normalize(streamerId)]
 	if !ok {
 		return nil, fmt.Errorf("no streamer found with ID %s", streamerId)
 	}
 	return streamer, nil
 }
 
+// LookupStreamerByBigoId finds a streamer by Bigo user ID
+func (m *Manager) LookupStreamerByBigoId(bigoId string) (*api.Streamer, error) {
+	streamer, ok := m.bigoIdIndex[normalize(bigoId)]
+	if !ok {
+		return nil, fmt.Errorf("no streamer found with Bigo ID %s", bigoId)
+	}
+	return streamer, nil
+}
+
+// LookupStreamerByAnyBigoId finds a streamer by Bigo user ID or room ID, since events carry either
+func (m *Manager) LookupStreamerByAnyBigoId(id string) (*api.Streamer, error) {
+	if streamer, ok := m.bigoIdIndex[normalize(id)]; ok {
+		return streamer, nil
+	}
+	return m.LookupStreamerByBigoRoom(id)
+}
+
+// LookupTeam finds a team by ID
+func (m *Manager) LookupTeam(teamId string) (*api.Team, error) {
+	team, ok := m.teamIndex[normalize(teamId)]
+	if !ok {
+		return nil, fmt.Errorf("no team found with ID %s", teamId)
+	}
+	return team, nil
+}
+
+// TeamOf returns the team of a streamer returned by this manager
+func (m *Manager) TeamOf(streamer *api.Streamer) *api.Team {
+	return m.streamerTeam[streamer]
+}
+
+// LookupStreamerBinding finds the streamer a gift is bound to, by gift name or ID
+func (m *Manager) LookupStreamerBinding(giftName, giftId string) (*api.Streamer, error) {
+	for _, key := range []string{giftName, giftId} {
+		if streamer, ok := m.streamerGifts[normalize(key)]; ok {
+			return streamer, nil
+		}
+	}
+	return nil, fmt.Errorf("no streamer bound to gift %s", giftName)
+}
+
+// LookupTeamBinding finds the team a gift is bound to, by gift name or ID
+func (m *Manager) LookupTeamBinding(giftName, giftId string) (*api.Team, error) {
+	for _, key := range []string{giftName, giftId} {
+		if team, ok := m.teamGifts[normalize(key)]; ok {
+			return team, nil
+		}
+	}
+	return nil, fmt.Errorf("no team bound to gift %s", giftName)
+}
+
 func (m *Manager) GetAllBigoRoomIds() []string {
 	rooms := make([]string, 0, len(m.bigoRoomIndex))
-	for roomId := range m.bigoRoomIndex {
-		rooms = append(rooms, roomId)
+	for _, streamer := range m.bigoRoomIndex {
+		rooms = append(rooms, streamer.BigoRoomId)
 	}
 	return rooms
 }
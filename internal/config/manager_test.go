@@ -1,9 +1,9 @@
 package config_test
 
 import (
-	"testing"
-	"bbapp/internal/config"
 	"bbapp/internal/api"
+	"bbapp/internal/config"
+	"testing"
 )
 
 func TestManager_LookupStreamer(t *testing.T) {
@@ -36,3 +36,59 @@ func TestManager_LookupStreamer(t *testing.T) {
 		t.Errorf("Expected Alice, got %s", streamer.Name)
 	}
 }
+
+func TestManager_Indexes(t *testing.T) {
+	cfg := &api.Config{
+		Teams: []api.Team{
+			{
+				TeamId:      "team1",
+				BindingGift: "Rose",
+				Streamers: []api.Streamer{
+					{StreamerId: "s1", BigoRoomId: "111", BigoId: "alice", BindingGift: "Heart"},
+					{StreamerId: "", BigoRoomId: "", Name: "Incomplete"},
+				},
+			},
+			{
+				TeamId: "team2",
+				Streamers: []api.Streamer{
+					{StreamerId: "s2", BigoRoomId: "222", BigoId: "Bob"},
+				},
+			},
+		},
+	}
+	library := []api.GiftDefinition{{ID: "7001", Name: "Heart"}}
+	manager := config.NewManagerWithGifts(cfg, library)
+
+	streamer, err := manager.LookupStreamerByBigoId("bob")
+	if err != nil || streamer.StreamerId != "s2" {
+		t.Fatalf("LookupStreamerByBigoId(bob) = %v, %v", streamer, err)
+	}
+	if team := manager.TeamOf(streamer); team == nil || team.TeamId != "team2" {
+		t.Errorf("TeamOf(s2) = %v, want team2", team)
+	}
+	if streamer, err := manager.LookupStreamerByAnyBigoId("111"); err != nil || streamer.StreamerId != "s1" {
+		t.Errorf("LookupStreamerByAnyBigoId(111) = %v, %v", streamer, err)
+	}
+	if team, err := manager.LookupTeam("TEAM1"); err != nil || team.TeamId != "team1" {
+		t.Errorf("LookupTeam(TEAM1) = %v, %v", team, err)
+	}
+
+	// A gift bound by name also matches events carrying only its library ID
+	if streamer, err := manager.LookupStreamerBinding("", "7001"); err != nil || streamer.StreamerId != "s1" {
+		t.Errorf("LookupStreamerBinding by ID = %v, %v", streamer, err)
+	}
+	if team, err := manager.LookupTeamBinding("rose", ""); err != nil || team.TeamId != "team1" {
+		t.Errorf("LookupTeamBinding(rose) = %v, %v", team, err)
+	}
+
+	// Empty IDs are never indexed, so they can't collide
+	if _, err := manager.LookupStreamerByBigoRoom(""); err == nil {
+		t.Error("Expected no streamer for an empty Bigo room ID")
+	}
+	if _, err := manager.LookupStreamerById(""); err == nil {
+		t.Error("Expected no streamer for an empty streamer ID")
+	}
+	if rooms := manager.GetAllBigoRoomIds(); len(rooms) != 2 {
+		t.Errorf("GetAllBigoRoomIds() = %v, want 2 rooms", rooms)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"bbapp/internal/api"
+)
+
+// ValidationError is one problem in a config; Field is a path like teams[0].streamers[1].bigoRoomId
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists every problem found by Validate
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, ve := range e {
+		messages[i] = fmt.Sprintf("%s: %s", ve.Field, ve.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks a config before it is saved or a session starts: IDs must be set, a streamer may
+// appear only once, a binding gift may belong to one team only, and binding gifts must exist in
+// the gift library (skipped while the library is empty). It returns ValidationErrors or nil.
+func Validate(cfg *api.Config, library []api.GiftDefinition) error {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if cfg == nil {
+		return ValidationErrors{{Field: "config", Message: "config is missing"}}
+	}
+
+	// Where each ID and binding gift was first seen, to report the clashing entry
+	teams := make(map[string]string)
+	streamerIds := make(map[string]string)
+	rooms := make(map[string]string)
+	bigoIds := make(map[string]string)
+	type binding struct {
+		field  string
+		teamId string
+	}
+	gifts := make(map[string]binding)
+
+	checkGift := func(field, gift string, b binding) {
+		if gift == "" {
+			return
+		}
+		if len(library) > 0 {
+			if _, ok := findGift(gift, library); !ok {
+				add(field, "binding gift %q is not in the gift library", gift)
+			}
+		}
+
+		// Within a team a gift may be shared (the idol editor copies the team's gift to new idols),
+		// but a gift bound in two teams would score for whichever is found first
+		key := normalize(gift)
+		if first, seen := gifts[key]; !seen {
+			gifts[key] = b
+		} else if first.teamId != b.teamId {
+			add(field, "binding gift %q is already bound in team %s (%s)", gift, first.teamId, first.field)
+		}
+	}
+
+	for i, team := range cfg.Teams {
+		teamField := fmt.Sprintf("teams[%d]", i)
+		if strings.TrimSpace(team.TeamId) == "" {
+			add(teamField+".teamId", "team ID is empty")
+		} else if first, ok := teams[normalize(team.TeamId)]; ok {
+			add(teamField+".teamId", "team ID %s is also used by %s", team.TeamId, first)
+		} else {
+			teams[normalize(team.TeamId)] = teamField
+		}
+		checkGift(teamField+".bindingGift", team.BindingGift, binding{field: teamField + ".bindingGift", teamId: team.TeamId})
+
+		for j, streamer := range team.Streamers {
+			field := fmt.Sprintf("%s.streamers[%d]", teamField, j)
+			if strings.TrimSpace(streamer.StreamerId) == "" {
+				add(field+".id", "streamer ID is empty")
+			} else if first, ok := streamerIds[normalize(streamer.StreamerId)]; ok {
+				add(field+".id", "streamer ID %s is already used by %s", streamer.StreamerId, first)
+			} else {
+				streamerIds[normalize(streamer.StreamerId)] = field
+			}
+			if strings.TrimSpace(streamer.BigoRoomId) == "" {
+				add(field+".bigoRoomId", "Bigo room ID is empty")
+			} else if first, ok := rooms[normalize(streamer.BigoRoomId)]; ok {
+				add(field+".bigoRoomId", "Bigo room %s is already used by %s", streamer.BigoRoomId, first)
+			} else {
+				rooms[normalize(streamer.BigoRoomId)] = field
+			}
+			if streamer.BigoId != "" {
+				if first, ok := bigoIds[normalize(streamer.BigoId)]; ok {
+					add(field+".bigoId", "Bigo ID %s is already used by %s", streamer.BigoId, first)
+				} else {
+					bigoIds[normalize(streamer.BigoId)] = field
+				}
+			}
+			checkGift(field+".bindingGift", streamer.BindingGift, binding{field: field + ".bindingGift", teamId: team.TeamId})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"bbapp/internal/api"
+	"bbapp/internal/config"
+)
+
+func TestValidate(t *testing.T) {
+	library := []api.GiftDefinition{{ID: "1", Name: "Rose"}, {ID: "2", Name: "Heart"}}
+
+	valid := &api.Config{
+		Teams: []api.Team{
+			{TeamId: "t1", BindingGift: "Rose", Streamers: []api.Streamer{
+				{StreamerId: "s1", BigoRoomId: "111", BindingGift: "Rose"},
+				{StreamerId: "s2", BigoRoomId: "222", BindingGift: "Rose"},
+			}},
+			{TeamId: "t2", BindingGift: "Heart", Streamers: []api.Streamer{
+				{StreamerId: "s3", BigoRoomId: "333"},
+			}},
+		},
+	}
+	if err := config.Validate(valid, library); err != nil {
+		t.Fatalf("Validate(valid) = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		cfg   *api.Config
+		field string
+	}{
+		{"empty team ID", &api.Config{Teams: []api.Team{{TeamId: ""}}}, "teams[0].teamId"},
+		{"empty streamer ID", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", Streamers: []api.Streamer{{BigoRoomId: "111"}}},
+		}}, "teams[0].streamers[0].id"},
+		{"empty Bigo room", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", Streamers: []api.Streamer{{StreamerId: "s1"}}},
+		}}, "teams[0].streamers[0].bigoRoomId"},
+		{"streamer in two teams", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", Streamers: []api.Streamer{{StreamerId: "s1", BigoRoomId: "111"}}},
+			{TeamId: "t2", Streamers: []api.Streamer{{StreamerId: "s2", BigoRoomId: "111"}}},
+		}}, "teams[1].streamers[0].bigoRoomId"},
+		{"duplicate streamer ID", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", Streamers: []api.Streamer{{StreamerId: "s1", BigoRoomId: "111"}}},
+			{TeamId: "t2", Streamers: []api.Streamer{{StreamerId: "S1", BigoRoomId: "222"}}},
+		}}, "teams[1].streamers[0].id"},
+		{"duplicate Bigo ID", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", Streamers: []api.Streamer{{StreamerId: "s1", BigoRoomId: "111", BigoId: "alice"}}},
+			{TeamId: "t2", Streamers: []api.Streamer{{StreamerId: "s2", BigoRoomId: "222", BigoId: "Alice"}}},
+		}}, "teams[1].streamers[0].bigoId"},
+		{"gift bound in two teams", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", BindingGift: "Rose"},
+			{TeamId: "t2", Streamers: []api.Streamer{{StreamerId: "s1", BigoRoomId: "111", BindingGift: "rose"}}},
+		}}, "teams[1].streamers[0].bindingGift"},
+		{"gift not in library", &api.Config{Teams: []api.Team{
+			{TeamId: "t1", BindingGift: "Lion"},
+		}}, "teams[0].bindingGift"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.Validate(tt.cfg, library)
+			var errs config.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() = %v, want ValidationErrors", err)
+			}
+			for _, ve := range errs {
+				if ve.Field == tt.field {
+					return
+				}
+			}
+			t.Errorf("Validate() = %v, want an error on %s", err, tt.field)
+		})
+	}
+
+	// An empty library skips the library check
+	if err := config.Validate(&api.Config{Teams: []api.Team{{TeamId: "t1", BindingGift: "Lion"}}}, nil); err != nil {
+		t.Errorf("Validate() without a library = %v", err)
+	}
+}
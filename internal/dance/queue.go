@@ -0,0 +1,287 @@
+package dance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bbapp/internal/listener"
+)
+
+const defaultMaxQueue = 50
+
+// Queue runs dance performances one at a time, ordered by priority then arrival
+type Queue struct {
+	actions   map[string]Action
+	mappings  []Mapping
+	maxQueue  int
+	current   *Performance
+	upcoming  []Performance
+	lastFired map[string]time.Time // mapping key -> last trigger
+	timer     *time.Timer
+	active    bool
+	nextId    int64
+	performed int64
+	dropped   int64
+	callbacks []func(Event)
+	mutex     sync.Mutex
+}
+
+// NewQueue validates the config and creates an active queue
+func NewQueue(cfg Config) (*Queue, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	maxQueue := cfg.MaxQueue
+	if maxQueue <= 0 {
+		maxQueue = defaultMaxQueue
+	}
+
+	actions := make(map[string]Action, len(cfg.Actions))
+	for _, a := range cfg.Actions {
+		actions[a.Id] = a
+	}
+
+	return &Queue{
+		actions:   actions,
+		mappings:  cfg.Mappings,
+		maxQueue:  maxQueue,
+		lastFired: make(map[string]time.Time),
+		active:    true,
+	}, nil
+}
+
+// Validate checks that every mapping references a known action and durations are positive
+func Validate(cfg Config) error {
+	actions := make(map[string]bool, len(cfg.Actions))
+	for _, a := range cfg.Actions {
+		if a.Id == "" {
+			return fmt.Errorf("dance action %q has no id", a.Name)
+		}
+		if actions[a.Id] {
+			return fmt.Errorf("duplicate dance action id: %s", a.Id)
+		}
+		if a.DurationSeconds <= 0 {
+			return fmt.Errorf("dance action %s must have a positive duration", a.Id)
+		}
+		actions[a.Id] = true
+	}
+
+	for _, m := range cfg.Mappings {
+		if m.GiftId == "" && m.GiftName == "" {
+			return fmt.Errorf("dance mapping for action %s has no gift", m.ActionId)
+		}
+		if !actions[m.ActionId] {
+			return fmt.Errorf("dance mapping for gift %s%s references unknown action %s", m.GiftName, m.GiftId, m.ActionId)
+		}
+		if m.CooldownSeconds < 0 {
+			return fmt.Errorf("dance mapping for gift %s%s has a negative cooldown", m.GiftName, m.GiftId)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a callback for queue events
+func (q *Queue) Subscribe(callback func(Event)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.callbacks = append(q.callbacks, callback)
+}
+
+// HandleGift queues the dance mapped to a gift. Returns false if unmapped, cooling down or the queue is full.
+func (q *Queue) HandleGift(gift listener.BigoGift) (Performance, bool) {
+	var events []Event
+	defer func() { q.notify(events) }() // Runs after unlock
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.active {
+		return Performance{}, false
+	}
+
+	mapping, ok := q.match(gift)
+	if !ok {
+		return Performance{}, false
+	}
+
+	now := time.Now()
+	key := mappingKey(mapping)
+	if mapping.CooldownSeconds > 0 {
+		if last, ok := q.lastFired[key]; ok && now.Sub(last) < time.Duration(mapping.CooldownSeconds)*time.Second {
+			q.dropped++
+			return Performance{}, false
+		}
+	}
+	if len(q.upcoming) >= q.maxQueue {
+		q.dropped++
+		return Performance{}, false
+	}
+	q.lastFired[key] = now
+
+	action := q.actions[mapping.ActionId]
+	q.nextId++
+	perf := Performance{
+		Id:           fmt.Sprintf("dance-%d", q.nextId),
+		ActionId:     action.Id,
+		ActionName:   action.Name,
+		Animation:    action.Animation,
+		Priority:     action.Priority,
+		Duration:     action.DurationSeconds,
+		GiftId:       gift.GiftId,
+		GiftName:     gift.GiftName,
+		SenderId:     gift.SenderId,
+		SenderName:   gift.SenderName,
+		SenderAvatar: gift.SenderAvatar,
+		QueuedAt:     now,
+	}
+
+	// Insert after all performances of equal or higher priority (FIFO within a priority)
+	idx := sort.Search(len(q.upcoming), func(i int) bool {
+		return q.upcoming[i].Priority < perf.Priority
+	})
+	q.upcoming = append(q.upcoming, Performance{})
+	copy(q.upcoming[idx+1:], q.upcoming[idx:])
+	q.upcoming[idx] = perf
+	events = append(events, Event{Type: EventQueued, Performance: perf})
+
+	if q.current == nil {
+		events = append(events, q.startNextLocked()...)
+	}
+	return perf, true
+}
+
+// Skip ends the current performance early and starts the next one
+func (q *Queue) Skip() error {
+	var events []Event
+	defer func() { q.notify(events) }()
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.current == nil {
+		return fmt.Errorf("no performance running")
+	}
+	events = q.finishLocked(q.current.Id, true)
+	return nil
+}
+
+// Stop ends the current performance and clears the queue
+func (q *Queue) Stop() {
+	var events []Event
+	defer func() { q.notify(events) }()
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if q.current != nil {
+		events = append(events, Event{Type: EventEnd, Performance: *q.current, Skipped: true})
+		q.current = nil
+	}
+	q.upcoming = nil
+	q.active = false
+}
+
+// State returns the current and upcoming performances
+func (q *Queue) State() State {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	state := State{
+		Active:    q.active,
+		Upcoming:  append([]Performance{}, q.upcoming...),
+		Performed: q.performed,
+		Dropped:   q.dropped,
+	}
+	if q.current != nil {
+		current := *q.current
+		state.Current = &current
+	}
+	return state
+}
+
+// finishLocked ends the performance with the given id (ignored if it is no longer current)
+func (q *Queue) finishLocked(id string, skipped bool) []Event {
+	if q.current == nil || q.current.Id != id {
+		return nil
+	}
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+
+	events := []Event{{Type: EventEnd, Performance: *q.current, Skipped: skipped}}
+	q.current = nil
+	q.performed++
+	return append(events, q.startNextLocked()...)
+}
+
+// startNextLocked starts the next upcoming performance, if any
+func (q *Queue) startNextLocked() []Event {
+	if !q.active || len(q.upcoming) == 0 {
+		return nil
+	}
+
+	perf := q.upcoming[0]
+	q.upcoming = q.upcoming[1:]
+
+	duration := time.Duration(perf.Duration) * time.Second
+	perf.StartedAt = time.Now()
+	perf.EndsAt = perf.StartedAt.Add(duration)
+	q.current = &perf
+
+	id := perf.Id
+	q.timer = time.AfterFunc(duration, func() {
+		var events []Event
+		q.mutex.Lock()
+		events = q.finishLocked(id, false)
+		q.mutex.Unlock()
+		q.notify(events)
+	})
+
+	return []Event{{Type: EventStart, Performance: perf}}
+}
+
+// match finds the mapping of a gift (gift ID first, then case-insensitive name)
+func (q *Queue) match(gift listener.BigoGift) (Mapping, bool) {
+	for _, m := range q.mappings {
+		if m.GiftId != "" && m.GiftId == gift.GiftId {
+			return m, true
+		}
+	}
+	for _, m := range q.mappings {
+		if m.GiftName != "" && strings.EqualFold(m.GiftName, gift.GiftName) {
+			return m, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// notify delivers events in order. Must be called without holding the lock.
+func (q *Queue) notify(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	q.mutex.Lock()
+	callbacks := make([]func(Event), len(q.callbacks))
+	copy(callbacks, q.callbacks)
+	q.mutex.Unlock()
+
+	for _, event := range events {
+		for _, cb := range callbacks {
+			cb(event)
+		}
+	}
+}
+
+func mappingKey(m Mapping) string {
+	if m.GiftId != "" {
+		return "id:" + m.GiftId
+	}
+	return "name:" + strings.ToLower(m.GiftName)
+}
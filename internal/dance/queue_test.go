@@ -0,0 +1,113 @@
+package dance_test
+
+import (
+	"testing"
+
+	"bbapp/internal/dance"
+	"bbapp/internal/listener"
+)
+
+func testConfig() dance.Config {
+	return dance.Config{
+		Actions: []dance.Action{
+			{Id: "wave", Name: "Wave", DurationSeconds: 60, Priority: 1},
+			{Id: "spin", Name: "Spin", DurationSeconds: 60, Priority: 5},
+		},
+		Mappings: []dance.Mapping{
+			{GiftName: "Rose", ActionId: "wave"},
+			{GiftId: "100", ActionId: "spin", CooldownSeconds: 60},
+		},
+	}
+}
+
+func TestQueue_PriorityAndSkip(t *testing.T) {
+	q, err := dance.NewQueue(testConfig())
+	if err != nil {
+		t.Fatalf("NewQueue failed: %v", err)
+	}
+	defer q.Stop()
+
+	var events []dance.Event
+	q.Subscribe(func(e dance.Event) { events = append(events, e) })
+
+	q.HandleGift(listener.BigoGift{GiftName: "rose", SenderName: "A"})
+	q.HandleGift(listener.BigoGift{GiftName: "Rose", SenderName: "B"})
+	q.HandleGift(listener.BigoGift{GiftId: "100", GiftName: "Car", SenderName: "C"})
+
+	state := q.State()
+	if state.Current == nil || state.Current.SenderName != "A" {
+		t.Fatalf("Expected A to perform first, got %+v", state.Current)
+	}
+	if len(state.Upcoming) != 2 || state.Upcoming[0].SenderName != "C" {
+		t.Fatalf("Expected higher priority C to be next, got %+v", state.Upcoming)
+	}
+
+	if err := q.Skip(); err != nil {
+		t.Fatalf("Skip failed: %v", err)
+	}
+	state = q.State()
+	if state.Current == nil || state.Current.ActionId != "spin" {
+		t.Errorf("Expected spin to start after skip, got %+v", state.Current)
+	}
+	if state.Performed != 1 {
+		t.Errorf("Expected 1 performed, got %d", state.Performed)
+	}
+
+	want := []string{dance.EventQueued, dance.EventStart, dance.EventQueued, dance.EventQueued, dance.EventEnd, dance.EventStart}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, typ := range want {
+		if events[i].Type != typ {
+			t.Errorf("Event %d: expected %s, got %s", i, typ, events[i].Type)
+		}
+	}
+	if !events[4].Skipped {
+		t.Error("Expected end event to be marked as skipped")
+	}
+}
+
+func TestQueue_Cooldown(t *testing.T) {
+	q, _ := dance.NewQueue(testConfig())
+	defer q.Stop()
+
+	if _, ok := q.HandleGift(listener.BigoGift{GiftId: "100"}); !ok {
+		t.Fatal("Expected first gift to be queued")
+	}
+	if _, ok := q.HandleGift(listener.BigoGift{GiftId: "100"}); ok {
+		t.Error("Expected second gift within cooldown to be dropped")
+	}
+	if _, ok := q.HandleGift(listener.BigoGift{GiftName: "Unknown"}); ok {
+		t.Error("Expected unmapped gift to be ignored")
+	}
+	if dropped := q.State().Dropped; dropped != 1 {
+		t.Errorf("Expected 1 dropped, got %d", dropped)
+	}
+}
+
+func TestQueue_MaxQueue(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxQueue = 1
+	q, _ := dance.NewQueue(cfg)
+	defer q.Stop()
+
+	q.HandleGift(listener.BigoGift{GiftName: "Rose"}) // starts immediately
+	q.HandleGift(listener.BigoGift{GiftName: "Rose"}) // fills the queue
+	if _, ok := q.HandleGift(listener.BigoGift{GiftName: "Rose"}); ok {
+		t.Error("Expected gift to be dropped when queue is full")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := testConfig()
+	cfg.Mappings = append(cfg.Mappings, dance.Mapping{GiftName: "Kiss", ActionId: "missing"})
+	if err := dance.Validate(cfg); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+
+	cfg = testConfig()
+	cfg.Actions[0].DurationSeconds = 0
+	if err := dance.Validate(cfg); err == nil {
+		t.Error("Expected error for zero duration")
+	}
+}
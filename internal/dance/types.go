@@ -0,0 +1,68 @@
+package dance
+
+import "time"
+
+// Event types broadcast to the overlay
+const (
+	EventQueued = "DANCE_QUEUED"
+	EventStart  = "DANCE_START"
+	EventEnd    = "DANCE_END"
+)
+
+// Action is a dance the streamer performs
+type Action struct {
+	Id              string `json:"id"`
+	Name            string `json:"name"`
+	Animation       string `json:"animation"`       // Overlay animation/asset key
+	DurationSeconds int    `json:"durationSeconds"` // How long the performance lasts
+	Priority        int    `json:"priority"`        // Higher priority performances jump the queue
+}
+
+// Mapping binds a gift or sticker to a dance action
+type Mapping struct {
+	GiftId          string `json:"giftId"`
+	GiftName        string `json:"giftName"`
+	ActionId        string `json:"actionId"`
+	CooldownSeconds int    `json:"cooldownSeconds"` // Minimum time between triggers of this gift (0 = none)
+}
+
+// Config is the Sticker Dance setup of a room
+type Config struct {
+	Actions  []Action  `json:"actions"`
+	Mappings []Mapping `json:"mappings"`
+	MaxQueue int       `json:"maxQueue"` // Upcoming performances kept (0 = default)
+}
+
+// Performance is a queued or running dance triggered by a gift
+type Performance struct {
+	Id           string    `json:"id"`
+	ActionId     string    `json:"actionId"`
+	ActionName   string    `json:"actionName"`
+	Animation    string    `json:"animation"`
+	Priority     int       `json:"priority"`
+	Duration     int       `json:"durationSeconds"`
+	GiftId       string    `json:"giftId"`
+	GiftName     string    `json:"giftName"`
+	SenderId     string    `json:"senderId"`
+	SenderName   string    `json:"senderName"`
+	SenderAvatar string    `json:"senderAvatar"`
+	QueuedAt     time.Time `json:"queuedAt"`
+	StartedAt    time.Time `json:"startedAt,omitempty"`
+	EndsAt       time.Time `json:"endsAt,omitempty"`
+}
+
+// Event is a queue change delivered to subscribers
+type Event struct {
+	Type        string      `json:"type"` // DANCE_QUEUED, DANCE_START, DANCE_END
+	Performance Performance `json:"performance"`
+	Skipped     bool        `json:"skipped,omitempty"` // DANCE_END caused by the operator
+}
+
+// State is the operator view of the queue
+type State struct {
+	Active    bool          `json:"active"`
+	Current   *Performance  `json:"current"`
+	Upcoming  []Performance `json:"upcoming"`
+	Performed int64         `json:"performed"`
+	Dropped   int64         `json:"dropped"` // Rejected by cooldown or full queue
+}
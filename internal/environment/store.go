@@ -0,0 +1,348 @@
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store persists environments and accounts in environments.json
+type Store struct {
+	storageDir string
+	state      State
+	mu         sync.RWMutex
+}
+
+// NewStore loads the store. The default environment is created or updated with defaultURL.
+func NewStore(storageDir, defaultURL string) (*Store, error) {
+	s := &Store{storageDir: storageDir}
+
+	data, err := os.ReadFile(s.path())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return nil, fmt.Errorf("unmarshal environments: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("read environments file: %w", err)
+	}
+
+	// The default environment always follows BB_CORE_URL, so .env keeps working as before
+	defaultURL = strings.TrimSuffix(defaultURL, "/")
+	changed := false
+	found := false
+	for i, env := range s.state.Environments {
+		if env.Id == DefaultId {
+			found = true
+			if env.BaseURL != defaultURL {
+				s.state.Environments[i].BaseURL = defaultURL
+				changed = true
+			}
+		}
+	}
+	if !found {
+		s.state.Environments = append([]Environment{{Id: DefaultId, Label: "Default", BaseURL: defaultURL}}, s.state.Environments...)
+		changed = true
+	}
+	if !s.hasEnvironmentLocked(s.state.ActiveEnvironmentId) {
+		s.state.ActiveEnvironmentId, s.state.ActiveAccountId = DefaultId, ""
+		changed = true
+	}
+
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.storageDir, "environments.json")
+}
+
+// State returns a copy of the environments, accounts and current selection
+func (s *Store) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return State{
+		Environments:        append([]Environment(nil), s.state.Environments...),
+		Accounts:            append([]Account(nil), s.state.Accounts...),
+		ActiveEnvironmentId: s.state.ActiveEnvironmentId,
+		ActiveAccountId:     s.state.ActiveAccountId,
+	}
+}
+
+// Environment returns an environment by Id
+func (s *Store) Environment(id string) (Environment, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, env := range s.state.Environments {
+		if env.Id == id {
+			return env, true
+		}
+	}
+	return Environment{}, false
+}
+
+// Account returns an account by Id
+func (s *Store) Account(id string) (Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, acc := range s.state.Accounts {
+		if acc.Id == id {
+			return acc, true
+		}
+	}
+	return Account{}, false
+}
+
+// Active returns the selected environment and account (nil when logged out)
+func (s *Store) Active() (Environment, *Account) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var env Environment
+	for _, e := range s.state.Environments {
+		if e.Id == s.state.ActiveEnvironmentId {
+			env = e
+		}
+	}
+	for _, acc := range s.state.Accounts {
+		if acc.Id == s.state.ActiveAccountId {
+			return env, &acc
+		}
+	}
+	return env, nil
+}
+
+// SaveEnvironment adds or updates an environment
+func (s *Store) SaveEnvironment(env Environment) error {
+	env.Id = strings.TrimSpace(env.Id)
+	env.Label = strings.TrimSpace(env.Label)
+	env.BaseURL = strings.TrimSuffix(strings.TrimSpace(env.BaseURL), "/")
+	env.StompURL = strings.TrimSpace(env.StompURL)
+
+	if env.Id == "" {
+		return fmt.Errorf("environment id cannot be empty")
+	}
+	if env.Label == "" {
+		env.Label = env.Id
+	}
+	if err := checkURL(env.BaseURL, "http", "https"); err != nil {
+		return fmt.Errorf("base URL: %w", err)
+	}
+	if env.StompURL != "" {
+		if err := checkURL(env.StompURL, "http", "https", "ws", "wss"); err != nil {
+			return fmt.Errorf("STOMP URL: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := append([]Environment(nil), s.state.Environments...)
+	replaced := false
+	for i := range s.state.Environments {
+		if s.state.Environments[i].Id == env.Id {
+			s.state.Environments[i] = env
+			replaced = true
+		}
+	}
+	if !replaced {
+		s.state.Environments = append(s.state.Environments, env)
+	}
+
+	if err := s.saveLocked(); err != nil {
+		s.state.Environments = previous
+		return err
+	}
+	return nil
+}
+
+// DeleteEnvironment removes an environment that is not active and has no accounts
+func (s *Store) DeleteEnvironment(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == s.state.ActiveEnvironmentId || id == DefaultId {
+		return fmt.Errorf("cannot delete the active or default environment")
+	}
+	for _, acc := range s.state.Accounts {
+		if acc.EnvironmentId == id {
+			return fmt.Errorf("environment %s still has accounts", id)
+		}
+	}
+
+	previous := s.state.Environments
+	kept := make([]Environment, 0, len(previous))
+	for _, env := range previous {
+		if env.Id != id {
+			kept = append(kept, env)
+		}
+	}
+	if len(kept) == len(previous) {
+		return fmt.Errorf("environment not found: %s", id)
+	}
+
+	s.state.Environments = kept
+	if err := s.saveLocked(); err != nil {
+		s.state.Environments = previous
+		return err
+	}
+	return nil
+}
+
+// UpsertAccount returns the account of username in an environment, creating it if needed
+func (s *Store) UpsertAccount(environmentId, username, agencyName string) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasEnvironmentLocked(environmentId) {
+		return Account{}, fmt.Errorf("environment not found: %s", environmentId)
+	}
+
+	for i, acc := range s.state.Accounts {
+		if acc.EnvironmentId == environmentId && acc.Username == username {
+			if agencyName == "" || acc.AgencyName == agencyName {
+				return acc, nil
+			}
+			s.state.Accounts[i].AgencyName = agencyName
+			return s.state.Accounts[i], s.saveLocked()
+		}
+	}
+
+	acc := Account{Id: uuid.New().String(), EnvironmentId: environmentId, Username: username, AgencyName: agencyName}
+	s.state.Accounts = append(s.state.Accounts, acc)
+	if err := s.saveLocked(); err != nil {
+		s.state.Accounts = s.state.Accounts[:len(s.state.Accounts)-1]
+		return Account{}, err
+	}
+	return acc, nil
+}
+
+// UpdateAccount sets the user and agency of an account, e.g. once a migrated login has been decrypted
+func (s *Store) UpdateAccount(id, username, agencyName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, acc := range s.state.Accounts {
+		if acc.Id == id {
+			previous := acc
+			s.state.Accounts[i].Username, s.state.Accounts[i].AgencyName = username, agencyName
+			if err := s.saveLocked(); err != nil {
+				s.state.Accounts[i] = previous
+				return err
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("account not found: %s", id)
+}
+
+// RemoveAccount forgets an account; the active account is logged out
+func (s *Store) RemoveAccount(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, active := s.state.Accounts, s.state.ActiveAccountId
+	kept := make([]Account, 0, len(previous))
+	for _, acc := range previous {
+		if acc.Id != id {
+			kept = append(kept, acc)
+		}
+	}
+	if len(kept) == len(previous) {
+		return fmt.Errorf("account not found: %s", id)
+	}
+
+	s.state.Accounts = kept
+	if active == id {
+		s.state.ActiveAccountId = ""
+	}
+	if err := s.saveLocked(); err != nil {
+		s.state.Accounts, s.state.ActiveAccountId = previous, active
+		return err
+	}
+	return nil
+}
+
+// SetActive selects an environment and one of its accounts ("" = logged out)
+func (s *Store) SetActive(environmentId, accountId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasEnvironmentLocked(environmentId) {
+		return fmt.Errorf("environment not found: %s", environmentId)
+	}
+
+	index := -1
+	for i, acc := range s.state.Accounts {
+		if acc.Id == accountId {
+			index = i
+		}
+	}
+	if accountId != "" && (index < 0 || s.state.Accounts[index].EnvironmentId != environmentId) {
+		return fmt.Errorf("account %s not found in environment %s", accountId, environmentId)
+	}
+
+	s.state.ActiveEnvironmentId, s.state.ActiveAccountId = environmentId, accountId
+	if index >= 0 {
+		now := time.Now()
+		s.state.Accounts[index].LastUsedAt = &now
+	}
+	return s.saveLocked()
+}
+
+func (s *Store) hasEnvironmentLocked(id string) bool {
+	for _, env := range s.state.Environments {
+		if env.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// saveLocked writes the state atomically. Caller must hold mu.
+func (s *Store) saveLocked() error {
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal environments: %w", err)
+	}
+
+	if err := os.MkdirAll(s.storageDir, 0755); err != nil {
+		return fmt.Errorf("create storage directory: %w", err)
+	}
+
+	tempPath := s.path() + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, s.path()); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
+
+func checkURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme && u.Host != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must be an absolute %s URL", raw, strings.Join(schemes, "/"))
+}
@@ -0,0 +1,90 @@
+package environment_test
+
+import (
+	"testing"
+
+	"bbapp/internal/environment"
+)
+
+func TestStore_DefaultFollowsURL(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := environment.NewStore(dir, "http://localhost:8080/")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	env, account := store.Active()
+	if env.Id != environment.DefaultId || env.BaseURL != "http://localhost:8080" || account != nil {
+		t.Fatalf("Active() = %+v, %v", env, account)
+	}
+	if env.STOMPURL() != "http://localhost:8080/ws" {
+		t.Errorf("STOMPURL() = %s", env.STOMPURL())
+	}
+
+	// A changed BB_CORE_URL updates the default environment on the next launch
+	store, err = environment.NewStore(dir, "https://core.example.com")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if env, _ := store.Environment(environment.DefaultId); env.BaseURL != "https://core.example.com" {
+		t.Errorf("Default BaseURL = %s", env.BaseURL)
+	}
+}
+
+func TestStore_EnvironmentsAndAccounts(t *testing.T) {
+	dir := t.TempDir()
+	store, err := environment.NewStore(dir, "http://localhost:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SaveEnvironment(environment.Environment{Id: "staging", BaseURL: "not a url"}); err == nil {
+		t.Error("Expected an invalid base URL to be rejected")
+	}
+	staging := environment.Environment{Id: "staging", Label: "Staging", BaseURL: "https://staging.example.com/", StompURL: "wss://stomp.staging.example.com/ws"}
+	if err := store.SaveEnvironment(staging); err != nil {
+		t.Fatalf("SaveEnvironment() error = %v", err)
+	}
+
+	alice, err := store.UpsertAccount("staging", "alice", "Agency A")
+	if err != nil {
+		t.Fatalf("UpsertAccount() error = %v", err)
+	}
+	again, _ := store.UpsertAccount("staging", "alice", "")
+	if again.Id != alice.Id {
+		t.Error("Expected the same account for the same user and environment")
+	}
+	if _, err := store.UpsertAccount("production", "alice", ""); err == nil {
+		t.Error("Expected an unknown environment to be rejected")
+	}
+
+	if err := store.SetActive(environment.DefaultId, alice.Id); err == nil {
+		t.Error("Expected an account of another environment to be rejected")
+	}
+	if err := store.SetActive("staging", alice.Id); err != nil {
+		t.Fatalf("SetActive() error = %v", err)
+	}
+	if err := store.DeleteEnvironment("staging"); err == nil {
+		t.Error("Expected the active environment not to be deletable")
+	}
+
+	// Everything survives a restart
+	reloaded, err := environment.NewStore(dir, "http://localhost:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, account := reloaded.Active()
+	if env.BaseURL != "https://staging.example.com" || env.STOMPURL() != "wss://stomp.staging.example.com/ws" {
+		t.Errorf("Active environment = %+v", env)
+	}
+	if account == nil || account.Username != "alice" || account.LastUsedAt == nil {
+		t.Fatalf("Active account = %+v", account)
+	}
+
+	if err := reloaded.RemoveAccount(alice.Id); err != nil {
+		t.Fatalf("RemoveAccount() error = %v", err)
+	}
+	if _, account := reloaded.Active(); account != nil {
+		t.Error("Expected removing the active account to log out")
+	}
+}
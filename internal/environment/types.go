@@ -0,0 +1,40 @@
+package environment
+
+import "time"
+
+// DefaultId is the environment seeded from BB_CORE_URL on first launch
+const DefaultId = "default"
+
+// Environment is a BB-Core deployment, e.g. staging or production
+type Environment struct {
+	Id       string `json:"id"`
+	Label    string `json:"label"`
+	BaseURL  string `json:"baseUrl"`  // REST API, e.g. https://core.example.com
+	StompURL string `json:"stompUrl"` // Optional; defaults to BaseURL + "/ws"
+}
+
+// STOMPURL returns the STOMP endpoint of the environment
+func (e Environment) STOMPURL() string {
+	if e.StompURL != "" {
+		return e.StompURL
+	}
+	return e.BaseURL + "/ws"
+}
+
+// Account is a BB-Core login stored for an environment. Its tokens live in an encrypted
+// vault under the account's Id (see auth.Manager), never in this file.
+type Account struct {
+	Id            string     `json:"id"` // UUID, also the vault directory name
+	EnvironmentId string     `json:"environmentId"`
+	Username      string     `json:"username"`
+	AgencyName    string     `json:"agencyName"`
+	LastUsedAt    *time.Time `json:"lastUsedAt"`
+}
+
+// State is the persisted set of environments and accounts, and the current selection
+type State struct {
+	Environments        []Environment `json:"environments"`
+	Accounts            []Account     `json:"accounts"`
+	ActiveEnvironmentId string        `json:"activeEnvironmentId"`
+	ActiveAccountId     string        `json:"activeAccountId"` // "" = logged out
+}
@@ -0,0 +1,16 @@
+package filter
+
+import "time"
+
+// SetClock replaces the rate limit clock and returns a function restoring it
+func SetClock(now func() time.Time) func() {
+	timeNow = now
+	return func() { timeNow = time.Now }
+}
+
+// TrackedSenders returns how many senders the chat rate limit remembers
+func (f *Filter) TrackedSenders() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.chatTimes)
+}
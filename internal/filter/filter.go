@@ -0,0 +1,277 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"bbapp/internal/listener"
+)
+
+// allSinks lists every sink an event can be delivered to
+var allSinks = []string{SinkBBCore, SinkOverlay}
+
+// timeNow is the clock of the chat rate limit
+var timeNow = time.Now
+
+// Filter applies Rules to gift and chat events and counts hits
+type Filter struct {
+	rules    Rules
+	blocked  map[string]bool
+	allowed  map[string]bool
+	keywords []*regexp.Regexp // case-insensitive literal matchers
+	patterns []*regexp.Regexp
+	window   time.Duration
+
+	chatTimes map[string][]time.Time // senderId -> recent chat timestamps
+	lastPrune time.Time              // Last time senders without recent chats were dropped from chatTimes
+	stats     Stats
+	mutex     sync.Mutex
+}
+
+// Result holds the per-sink outcome of filtering one event
+type Result struct {
+	events map[string]interface{} // sink -> event to deliver (missing = dropped)
+}
+
+// For returns the event to deliver to a sink, or false if the sink should not receive it
+func (r Result) For(sink string) (interface{}, bool) {
+	event, ok := r.events[sink]
+	return event, ok
+}
+
+// NewFilter compiles rules into a filter. Invalid regular expressions and unknown sinks are reported as errors.
+func NewFilter(rules Rules) (*Filter, error) {
+	if err := checkSinks("sender", rules.Senders.Sinks); err != nil {
+		return nil, err
+	}
+	if err := checkSinks("chat", rules.Chat.Sinks); err != nil {
+		return nil, err
+	}
+
+	f := &Filter{
+		rules:     rules,
+		blocked:   toSet(rules.Senders.Blocked),
+		allowed:   toSet(rules.Senders.Allowed),
+		window:    time.Duration(rules.Chat.RateWindowSeconds) * time.Second,
+		chatTimes: make(map[string][]time.Time),
+		stats:     Stats{Hits: make(map[string]int64)},
+	}
+	if f.window <= 0 {
+		f.window = 10 * time.Second
+	}
+
+	for _, kw := range rules.Chat.BlockedKeywords {
+		kw = strings.TrimSpace(kw)
+		if kw == "" {
+			continue
+		}
+		f.keywords = append(f.keywords, regexp.MustCompile("(?i)"+regexp.QuoteMeta(kw)))
+	}
+
+	for _, p := range rules.Chat.BlockedPatterns {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat pattern %q: %w", p, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+
+	return f, nil
+}
+
+// Rules returns the rules the filter was built from
+func (f *Filter) Rules() Rules {
+	return f.rules
+}
+
+// Apply evaluates an event once and returns what each sink should receive.
+// Unknown event types pass through to every sink untouched.
+func (f *Filter) Apply(event interface{}) Result {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.stats.Evaluated++
+	result := Result{events: make(map[string]interface{}, len(allSinks))}
+	for _, sink := range allSinks {
+		result.events[sink] = event
+	}
+
+	switch e := event.(type) {
+	case listener.BigoGift:
+		f.applySenderRules(e.SenderId, result)
+		if f.rules.Gifts.MinOverlayDiamonds > 0 && e.Diamonds < f.rules.Gifts.MinOverlayDiamonds {
+			if _, ok := result.events[SinkOverlay]; ok {
+				delete(result.events, SinkOverlay)
+				f.stats.Hits[ReasonMinDiamonds]++
+			}
+		}
+
+	case listener.BigoChat:
+		f.applySenderRules(e.SenderId, result)
+		f.applyChatRules(e, result)
+	}
+
+	return result
+}
+
+// Stats returns a copy of the hit counters
+func (f *Filter) Stats() Stats {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	hits := make(map[string]int64, len(f.stats.Hits))
+	for k, v := range f.stats.Hits {
+		hits[k] = v
+	}
+	return Stats{Evaluated: f.stats.Evaluated, Hits: hits}
+}
+
+// applySenderRules drops the event for the configured sinks if the sender is blocked or not allowed
+func (f *Filter) applySenderRules(senderId string, result Result) {
+	reason := ""
+	if f.blocked[senderId] {
+		reason = ReasonBlockedSender
+	} else if len(f.allowed) > 0 && !f.allowed[senderId] {
+		reason = ReasonNotAllowed
+	}
+	if reason == "" {
+		return
+	}
+
+	if f.drop(result, f.rules.Senders.Sinks) {
+		f.stats.Hits[reason]++
+	}
+}
+
+// applyChatRules enforces rate limits and keyword/pattern blocklists on a chat message
+func (f *Filter) applyChatRules(chat listener.BigoChat, result Result) {
+	rules := f.rules.Chat
+
+	// Rate limit is counted once per message, regardless of sinks
+	if rules.RateLimit > 0 && chat.SenderId != "" {
+		now := timeNow()
+		cutoff := now.Add(-f.window)
+		f.pruneChatTimes(now, cutoff)
+		recent := f.chatTimes[chat.SenderId][:0]
+		for _, t := range f.chatTimes[chat.SenderId] {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+		recent = append(recent, now)
+		f.chatTimes[chat.SenderId] = recent
+
+		if len(recent) > rules.RateLimit {
+			if f.drop(result, rules.Sinks) {
+				f.stats.Hits[ReasonChatRateLimit]++
+			}
+			return
+		}
+	}
+
+	reason := ""
+	masked := chat.Message
+	for _, re := range f.keywords {
+		if re.MatchString(masked) {
+			reason = ReasonBlockedKeyword
+			masked = re.ReplaceAllStringFunc(masked, mask)
+		}
+	}
+	for _, re := range f.patterns {
+		if re.MatchString(masked) {
+			if reason == "" {
+				reason = ReasonBlockedPattern
+			}
+			masked = re.ReplaceAllStringFunc(masked, mask)
+		}
+	}
+	if reason == "" {
+		return
+	}
+
+	if !rules.Mask {
+		if f.drop(result, rules.Sinks) {
+			f.stats.Hits[reason]++
+		}
+		return
+	}
+
+	chat.Message = masked
+	replaced := false
+	for _, sink := range targetSinks(rules.Sinks) {
+		if _, ok := result.events[sink]; ok {
+			result.events[sink] = chat
+			replaced = true
+		}
+	}
+	if replaced {
+		f.stats.Hits[ReasonMasked]++
+	}
+}
+
+// pruneChatTimes forgets senders without chats in the rate window, at most once per window,
+// so chatTimes doesn't grow with every sender of a long session
+func (f *Filter) pruneChatTimes(now, cutoff time.Time) {
+	if now.Sub(f.lastPrune) < f.window {
+		return
+	}
+	f.lastPrune = now
+	for sender, times := range f.chatTimes {
+		if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+			delete(f.chatTimes, sender)
+		}
+	}
+}
+
+// drop removes the event from the given sinks; returns true if any sink was affected
+func (f *Filter) drop(result Result, sinks []string) bool {
+	dropped := false
+	for _, sink := range targetSinks(sinks) {
+		if _, ok := result.events[sink]; ok {
+			delete(result.events, sink)
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// targetSinks returns the sinks a rule applies to (all sinks when none are configured)
+func targetSinks(sinks []string) []string {
+	if len(sinks) == 0 {
+		return allSinks
+	}
+	return sinks
+}
+
+// checkSinks rejects sink names a rule can't apply to
+func checkSinks(rule string, sinks []string) error {
+	for _, sink := range sinks {
+		if !slices.Contains(allSinks, sink) {
+			return fmt.Errorf("unknown %s rule sink %q (expected %s)", rule, sink, strings.Join(allSinks, " or "))
+		}
+	}
+	return nil
+}
+
+// mask replaces every rune of s with '*'
+func mask(s string) string {
+	return strings.Repeat("*", len([]rune(s)))
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
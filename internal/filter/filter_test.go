@@ -0,0 +1,169 @@
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"bbapp/internal/filter"
+	"bbapp/internal/listener"
+)
+
+func TestFilter_BlockedSender(t *testing.T) {
+	f, err := filter.NewFilter(filter.Rules{
+		Senders: filter.SenderRules{Blocked: []string{"spammer"}},
+	})
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+
+	result := f.Apply(listener.BigoGift{SenderId: "spammer", Diamonds: 100})
+	if _, ok := result.For(filter.SinkBBCore); ok {
+		t.Error("Expected blocked sender to be dropped for BB-Core")
+	}
+	if _, ok := result.For(filter.SinkOverlay); ok {
+		t.Error("Expected blocked sender to be dropped for overlay")
+	}
+
+	result = f.Apply(listener.BigoGift{SenderId: "fan", Diamonds: 100})
+	if _, ok := result.For(filter.SinkBBCore); !ok {
+		t.Error("Expected other senders to pass")
+	}
+
+	if hits := f.Stats().Hits[filter.ReasonBlockedSender]; hits != 1 {
+		t.Errorf("Expected 1 blocked sender hit, got %d", hits)
+	}
+}
+
+func TestFilter_AllowList(t *testing.T) {
+	f, _ := filter.NewFilter(filter.Rules{
+		Senders: filter.SenderRules{Allowed: []string{"vip"}},
+	})
+
+	if _, ok := f.Apply(listener.BigoChat{SenderId: "vip", Message: "hi"}).For(filter.SinkOverlay); !ok {
+		t.Error("Expected allowed sender to pass")
+	}
+	if _, ok := f.Apply(listener.BigoChat{SenderId: "other", Message: "hi"}).For(filter.SinkOverlay); ok {
+		t.Error("Expected non-allowed sender to be dropped")
+	}
+}
+
+func TestFilter_MinOverlayDiamonds(t *testing.T) {
+	f, _ := filter.NewFilter(filter.Rules{
+		Gifts: filter.GiftRules{MinOverlayDiamonds: 10},
+	})
+
+	result := f.Apply(listener.BigoGift{SenderId: "fan", Diamonds: 1})
+	if _, ok := result.For(filter.SinkBBCore); !ok {
+		t.Error("Expected small gift to still reach BB-Core")
+	}
+	if _, ok := result.For(filter.SinkOverlay); ok {
+		t.Error("Expected small gift to be hidden from overlay")
+	}
+}
+
+func TestFilter_ChatRateLimit(t *testing.T) {
+	f, _ := filter.NewFilter(filter.Rules{
+		Chat: filter.ChatRules{RateLimit: 2, RateWindowSeconds: 60},
+	})
+
+	chat := listener.BigoChat{SenderId: "chatty", Message: "hello"}
+	for i := 0; i < 2; i++ {
+		if _, ok := f.Apply(chat).For(filter.SinkBBCore); !ok {
+			t.Fatalf("Expected message %d to pass", i+1)
+		}
+	}
+	if _, ok := f.Apply(chat).For(filter.SinkBBCore); ok {
+		t.Error("Expected third message to be rate limited")
+	}
+	if hits := f.Stats().Hits[filter.ReasonChatRateLimit]; hits != 1 {
+		t.Errorf("Expected 1 rate limit hit, got %d", hits)
+	}
+}
+
+func TestFilter_KeywordMaskPerSink(t *testing.T) {
+	f, err := filter.NewFilter(filter.Rules{
+		Chat: filter.ChatRules{
+			BlockedKeywords: []string{"badword"},
+			BlockedPatterns: []string{`\d{6,}`},
+			Mask:            true,
+			Sinks:           []string{filter.SinkOverlay},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewFilter failed: %v", err)
+	}
+
+	result := f.Apply(listener.BigoChat{SenderId: "fan", Message: "BadWord call 1234567"})
+
+	overlayEvent, ok := result.For(filter.SinkOverlay)
+	if !ok {
+		t.Fatal("Expected masked message to reach overlay")
+	}
+	if got := overlayEvent.(listener.BigoChat).Message; got != "******* call *******" {
+		t.Errorf("Expected masked message, got %q", got)
+	}
+
+	coreEvent, ok := result.For(filter.SinkBBCore)
+	if !ok {
+		t.Fatal("Expected message to reach BB-Core")
+	}
+	if got := coreEvent.(listener.BigoChat).Message; got != "BadWord call 1234567" {
+		t.Errorf("Expected original message for BB-Core, got %q", got)
+	}
+}
+
+func TestFilter_KeywordDrop(t *testing.T) {
+	f, _ := filter.NewFilter(filter.Rules{
+		Chat: filter.ChatRules{BlockedKeywords: []string{"spam"}},
+	})
+
+	if _, ok := f.Apply(listener.BigoChat{SenderId: "fan", Message: "SPAM here"}).For(filter.SinkBBCore); ok {
+		t.Error("Expected message with blocked keyword to be dropped")
+	}
+	if hits := f.Stats().Hits[filter.ReasonBlockedKeyword]; hits != 1 {
+		t.Errorf("Expected 1 keyword hit, got %d", hits)
+	}
+}
+
+func TestNewFilter_InvalidPattern(t *testing.T) {
+	_, err := filter.NewFilter(filter.Rules{
+		Chat: filter.ChatRules{BlockedPatterns: []string{"("}},
+	})
+	if err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestFilter_ChatRateLimitForgetsIdleSenders(t *testing.T) {
+	now := time.Now()
+	defer filter.SetClock(func() time.Time { return now })()
+
+	f, _ := filter.NewFilter(filter.Rules{
+		Chat: filter.ChatRules{RateLimit: 2, RateWindowSeconds: 10},
+	})
+	for _, sender := range []string{"a", "b", "c"} {
+		f.Apply(listener.BigoChat{SenderId: sender, Message: "hi"})
+	}
+	if got := f.TrackedSenders(); got != 3 {
+		t.Fatalf("Expected 3 tracked senders, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	f.Apply(listener.BigoChat{SenderId: "d", Message: "hi"})
+	if got := f.TrackedSenders(); got != 1 {
+		t.Errorf("Expected idle senders to be forgotten, %d still tracked", got)
+	}
+}
+
+func TestNewFilter_UnknownSink(t *testing.T) {
+	if _, err := filter.NewFilter(filter.Rules{
+		Senders: filter.SenderRules{Blocked: []string{"spammer"}, Sinks: []string{"overlays"}},
+	}); err == nil {
+		t.Error("Expected error for unknown sender sink")
+	}
+	if _, err := filter.NewFilter(filter.Rules{
+		Chat: filter.ChatRules{RateLimit: 1, Sinks: []string{filter.SinkOverlay, "stomp"}},
+	}); err == nil {
+		t.Error("Expected error for unknown chat sink")
+	}
+}
@@ -0,0 +1,53 @@
+package filter
+
+// Sink names identify where a filtered event is delivered
+const (
+	SinkBBCore  = "bbcore"  // STOMP forwarding to BB-Core
+	SinkOverlay = "overlay" // Local overlay server (SSE)
+)
+
+// Hit reasons counted in Stats
+const (
+	ReasonBlockedSender  = "blockedSender"
+	ReasonNotAllowed     = "notAllowedSender"
+	ReasonMinDiamonds    = "minDiamonds"
+	ReasonChatRateLimit  = "chatRateLimit"
+	ReasonBlockedKeyword = "blockedKeyword"
+	ReasonBlockedPattern = "blockedPattern"
+	ReasonMasked         = "masked"
+)
+
+// Rules is the configurable filter set for one room, stored with the profile
+type Rules struct {
+	Senders SenderRules `json:"senders"`
+	Gifts   GiftRules   `json:"gifts"`
+	Chat    ChatRules   `json:"chat"`
+}
+
+// SenderRules blocks or allows senders by Bigo ID
+type SenderRules struct {
+	Blocked []string `json:"blocked"` // Sender Bigo IDs that are always dropped
+	Allowed []string `json:"allowed"` // When non-empty, only these senders pass
+	Sinks   []string `json:"sinks"`   // Sinks the rules apply to (empty = all sinks)
+}
+
+// GiftRules controls which gifts raise overlay alerts
+type GiftRules struct {
+	MinOverlayDiamonds int64 `json:"minOverlayDiamonds"` // Gifts below this value are hidden from the overlay only
+}
+
+// ChatRules limits chat spam and offensive content
+type ChatRules struct {
+	RateLimit         int      `json:"rateLimit"`         // Max messages per sender per window (0 = unlimited)
+	RateWindowSeconds int      `json:"rateWindowSeconds"` // Rate limit window (defaults to 10s)
+	BlockedKeywords   []string `json:"blockedKeywords"`   // Case-insensitive substrings
+	BlockedPatterns   []string `json:"blockedPatterns"`   // Go regular expressions
+	Mask              bool     `json:"mask"`              // Mask matches with '*' instead of dropping the message
+	Sinks             []string `json:"sinks"`             // Sinks the rules apply to (empty = all sinks)
+}
+
+// Stats counts filter hits by reason since the filter was created
+type Stats struct {
+	Evaluated int64            `json:"evaluated"`
+	Hits      map[string]int64 `json:"hits"`
+}
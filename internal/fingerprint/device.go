@@ -2,29 +2,206 @@ package fingerprint
 
 import (
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"os"
-	"runtime"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-var cachedHash string
+// Version of the fingerprint scheme. v1 hashed hostname + GOOS/GOARCH only.
+const Version = 2
 
+// migrationPeriod is how long the previous hash is reported to BB-Core after the fingerprint changed
+const migrationPeriod = 30 * 24 * time.Hour
+
+// Fingerprint identifies this device. It is also the record kept on disk between launches.
+type Fingerprint struct {
+	Version      int               `json:"version"`
+	Hash         string            `json:"hash"`
+	PreviousHash string            `json:"previousHash,omitempty"` // Hash before the last change, while migrating
+	MigratedAt   *time.Time        `json:"migratedAt,omitempty"`
+	Sources      map[string]string `json:"sources"` // Source name -> digest
+}
+
+var (
+	cachedHash string
+	cacheMutex sync.Mutex
+)
+
+// GenerateDeviceHash returns the device hash. After Generate it is the stored, drift-tolerant
+// hash; otherwise it is computed from the current sources without a record.
 func GenerateDeviceHash() (string, error) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
 	if cachedHash != "" {
 		return cachedHash, nil
 	}
 
-	// Collect machine identifiers
-	hostname, _ := os.Hostname()
-	osInfo := runtime.GOOS + runtime.GOARCH
+	sources := Collect()
+	if len(sources) == 0 {
+		return "", fmt.Errorf("no device identifiers available")
+	}
+	cachedHash = Resolve(nil, sources, time.Now()).Hash
+	return cachedHash, nil
+}
 
-	// TODO: Add MAC address if needed
-	// For Windows, could use: wmic csproduct get UUID
-	// For now, use hostname + OS info
+// Generate computes the fingerprint and keeps it stable across small hardware or OS changes
+// using the record at recordPath. The result is what GenerateDeviceHash returns afterwards.
+func Generate(recordPath string) (*Fingerprint, error) {
+	sources := Collect()
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no device identifiers available")
+	}
 
-	data := fmt.Sprintf("%s|%s", hostname, osInfo)
-	hash := sha256.Sum256([]byte(data))
+	stored, err := readRecord(recordPath)
+	if err != nil {
+		fmt.Printf("[Fingerprint] WARNING: Ignoring unreadable record: %v\n", err)
+		stored = nil
+	}
 
-	cachedHash = fmt.Sprintf("%x", hash)
-	return cachedHash, nil
+	fp := Resolve(stored, sources, time.Now())
+	if stored == nil || !sameRecord(stored, fp) {
+		if err := writeRecord(recordPath, fp); err != nil {
+			return nil, err
+		}
+	}
+	if stored != nil && stored.Hash != fp.Hash {
+		fmt.Printf("[Fingerprint] Device fingerprint changed (%d sources differ)\n", len(changedSources(stored.Sources, sources)))
+	}
+
+	cacheMutex.Lock()
+	cachedHash = fp.Hash
+	cacheMutex.Unlock()
+	return fp, nil
+}
+
+// Resolve decides the fingerprint for the current sources given the stored record (nil = none).
+// The stored hash is kept when at most one source changed and the unchanged sources carry at
+// least half of the stored weight; otherwise a new hash is derived and the old one becomes
+// PreviousHash. Without a record, the v1 hash (the host source) is the previous hash.
+func Resolve(stored *Fingerprint, sources []Source, now time.Time) *Fingerprint {
+	current := make(map[string]string, len(sources))
+	for _, src := range sources {
+		current[src.Name] = src.Digest
+	}
+
+	if stored != nil && stored.Version == Version && tolerated(stored.Sources, sources) {
+		fp := &Fingerprint{Version: Version, Hash: stored.Hash, PreviousHash: stored.PreviousHash, MigratedAt: stored.MigratedAt, Sources: current}
+		if fp.MigratedAt != nil && now.Sub(*fp.MigratedAt) > migrationPeriod {
+			fp.PreviousHash, fp.MigratedAt = "", nil
+		}
+		return fp
+	}
+
+	fp := &Fingerprint{Version: Version, Hash: deriveHash(sources), Sources: current}
+	previous := current[sourceHost]
+	if stored != nil {
+		previous = stored.Hash
+	}
+	if previous != "" && previous != fp.Hash {
+		migratedAt := now
+		fp.PreviousHash, fp.MigratedAt = previous, &migratedAt
+	}
+	return fp
+}
+
+// tolerated reports whether the sources are close enough to the stored ones to keep the hash
+func tolerated(stored map[string]string, sources []Source) bool {
+	if len(changedSources(stored, sources)) > 1 {
+		return false
+	}
+
+	total, matched := 0, 0
+	for name := range stored {
+		total += weights[name]
+	}
+	for _, src := range sources {
+		if digest, ok := stored[src.Name]; ok && digest == src.Digest {
+			matched += weights[src.Name]
+		}
+	}
+	return matched > 0 && matched*2 >= total
+}
+
+// changedSources lists stored sources that are now missing or different. New sources don't count.
+func changedSources(stored map[string]string, sources []Source) []string {
+	current := make(map[string]string, len(sources))
+	for _, src := range sources {
+		current[src.Name] = src.Digest
+	}
+
+	var changed []string
+	for name, digest := range stored {
+		if current[name] != digest {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// deriveHash combines all sources, so two machines differ as long as any identifier differs
+func deriveHash(sources []Source) string {
+	parts := make([]string, 0, len(sources))
+	for _, src := range sources {
+		parts = append(parts, src.Name+"="+src.Digest)
+	}
+	sort.Strings(parts)
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("v%d|%s", Version, strings.Join(parts, "|"))))
+	return fmt.Sprintf("%x", hash)
+}
+
+func sameRecord(a, b *Fingerprint) bool {
+	if a.Version != b.Version || a.Hash != b.Hash || a.PreviousHash != b.PreviousHash || len(a.Sources) != len(b.Sources) {
+		return false
+	}
+	for name, digest := range a.Sources {
+		if b.Sources[name] != digest {
+			return false
+		}
+	}
+	return true
+}
+
+func readRecord(path string) (*Fingerprint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read record: %w", err)
+	}
+
+	var fp Fingerprint
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return nil, fmt.Errorf("unmarshal record: %w", err)
+	}
+	return &fp, nil
+}
+
+// writeRecord saves the record atomically. It holds the device hash, so only the user may read it.
+func writeRecord(path string, fp *Fingerprint) error {
+	data, err := json.MarshalIndent(fp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create record directory: %w", err)
+	}
+
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
 }
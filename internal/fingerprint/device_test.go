@@ -1,7 +1,10 @@
 package fingerprint_test
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
+
 	"bbapp/internal/fingerprint"
 )
 
@@ -25,3 +28,101 @@ func TestGenerateDeviceHash(t *testing.T) {
 		t.Errorf("Expected same hash, got %s and %s", hash1, hash2)
 	}
 }
+
+func testSources(machineID, mac, host string) []fingerprint.Source {
+	return []fingerprint.Source{
+		{Name: "machine-id", Digest: machineID},
+		{Name: "mac", Digest: mac},
+		{Name: "host", Digest: host},
+	}
+}
+
+func TestResolve_ToleratesOneChangedSource(t *testing.T) {
+	now := time.Now()
+	first := fingerprint.Resolve(nil, testSources("m1", "aa", "legacy"), now)
+	if first.Version != fingerprint.Version || first.PreviousHash != "legacy" {
+		t.Fatalf("Expected the v1 hash as previous hash, got %+v", first)
+	}
+
+	// Renaming the PC only changes the host source
+	renamed := fingerprint.Resolve(first, testSources("m1", "aa", "renamed"), now)
+	if renamed.Hash != first.Hash {
+		t.Error("Expected the hash to survive a hostname change")
+	}
+	if renamed.Sources["host"] != "renamed" {
+		t.Error("Expected the record to follow the changed source")
+	}
+
+	// A second change on top is absorbed too, since the record was updated
+	newNIC := fingerprint.Resolve(renamed, testSources("m1", "bb", "renamed"), now)
+	if newNIC.Hash != first.Hash {
+		t.Error("Expected the hash to survive a network adapter change")
+	}
+
+	// Two sources changing at once is a different device
+	moved := fingerprint.Resolve(newNIC, testSources("m2", "cc", "renamed"), now)
+	if moved.Hash == first.Hash || moved.PreviousHash != first.Hash || moved.MigratedAt == nil {
+		t.Errorf("Expected a new hash reporting the old one, got %+v", moved)
+	}
+}
+
+func TestResolve_WeightedSources(t *testing.T) {
+	stored := fingerprint.Resolve(nil, []fingerprint.Source{
+		{Name: "machine-id", Digest: "m1"},
+		{Name: "host", Digest: "h1"},
+	}, time.Now())
+
+	// The machine id outweighs everything else that is left
+	changed := fingerprint.Resolve(stored, []fingerprint.Source{
+		{Name: "machine-id", Digest: "m2"},
+		{Name: "host", Digest: "h1"},
+	}, time.Now())
+	if changed.Hash == stored.Hash {
+		t.Error("Expected a new hash when the heaviest source changed")
+	}
+}
+
+func TestResolve_IdenticalMachinesDiffer(t *testing.T) {
+	// Lab machines with the same hostname and OS still differ in machine id and MAC
+	a := fingerprint.Resolve(nil, testSources("m1", "aa", "lab-pc"), time.Now())
+	b := fingerprint.Resolve(nil, testSources("m2", "bb", "lab-pc"), time.Now())
+	if a.Hash == b.Hash {
+		t.Error("Expected different hashes for different machines")
+	}
+}
+
+func TestResolve_MigrationPeriodEnds(t *testing.T) {
+	start := time.Now()
+	fp := fingerprint.Resolve(nil, testSources("m1", "aa", "legacy"), start)
+
+	fp = fingerprint.Resolve(fp, testSources("m1", "aa", "legacy"), start.Add(24*time.Hour))
+	if fp.PreviousHash != "legacy" {
+		t.Error("Expected the previous hash to be reported during migration")
+	}
+
+	fp = fingerprint.Resolve(fp, testSources("m1", "aa", "legacy"), start.Add(60*24*time.Hour))
+	if fp.PreviousHash != "" || fp.MigratedAt != nil {
+		t.Errorf("Expected the migration to end, got %+v", fp)
+	}
+}
+
+func TestGenerate_PersistsRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprint.json")
+
+	first, err := fingerprint.Generate(path)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	second, err := fingerprint.Generate(path)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if first.Hash != second.Hash || second.PreviousHash != first.PreviousHash {
+		t.Errorf("Expected a stable fingerprint, got %+v and %+v", first, second)
+	}
+
+	hash, _ := fingerprint.GenerateDeviceHash()
+	if hash != first.Hash {
+		t.Error("Expected GenerateDeviceHash to return the stored hash")
+	}
+}
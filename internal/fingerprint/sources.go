@@ -0,0 +1,219 @@
+package fingerprint
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Source names
+const (
+	sourceMachineID = "machine-id"
+	sourceBoard     = "board"
+	sourceDisk      = "disk"
+	sourceMAC       = "mac"
+	sourceHost      = "host"
+)
+
+// weights says how much each source counts when deciding if a changed device is still the same
+var weights = map[string]int{
+	sourceMachineID: 4, // OS install id, survives renames and network changes
+	sourceBoard:     3, // Firmware UUID or serial
+	sourceDisk:      2,
+	sourceMAC:       2,
+	sourceHost:      1, // Hostname changes easily
+}
+
+// commandTimeout bounds each OS tool called to read an identifier
+const commandTimeout = 3 * time.Second
+
+// Source is one device identifier; only its digest is kept
+type Source struct {
+	Name   string
+	Digest string // sha256 hex of the raw value
+}
+
+// Collect reads the identifiers available on this device. Missing ones are skipped.
+// The host source is the v1 fingerprint input, so its digest equals the v1 device hash.
+func Collect() []Source {
+	readers := []struct {
+		name string
+		read func() string
+	}{
+		{sourceMachineID, machineID},
+		{sourceBoard, boardID},
+		{sourceDisk, diskSerials},
+		{sourceMAC, macAddresses},
+		{sourceHost, hostInfo},
+	}
+
+	sources := make([]Source, 0, len(readers))
+	for _, r := range readers {
+		if value := strings.TrimSpace(r.read()); value != "" {
+			sources = append(sources, Source{Name: r.name, Digest: digest(value)})
+		}
+	}
+	return sources
+}
+
+func digest(value string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))
+}
+
+func hostInfo() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s|%s", hostname, runtime.GOOS+runtime.GOARCH)
+}
+
+func machineID() string {
+	switch runtime.GOOS {
+	case "linux":
+		return firstFile("/etc/machine-id", "/var/lib/dbus/machine-id")
+	case "darwin":
+		return ioregValue("IOPlatformUUID")
+	case "windows":
+		out := command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid")
+		if fields := strings.Fields(lastLine(out)); len(fields) == 3 && fields[0] == "MachineGuid" {
+			return fields[2]
+		}
+	}
+	return ""
+}
+
+func boardID() string {
+	switch runtime.GOOS {
+	case "linux":
+		// Usually readable by root only; then the other sources carry the fingerprint
+		return firstFile("/sys/class/dmi/id/product_uuid", "/sys/class/dmi/id/board_serial", "/sys/class/dmi/id/product_serial")
+	case "darwin":
+		return ioregValue("IOPlatformSerialNumber")
+	case "windows":
+		if values := wmicValues("csproduct", "get", "UUID"); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func diskSerials() string {
+	var serials []string
+	switch runtime.GOOS {
+	case "linux":
+		paths, _ := filepath.Glob("/sys/block/*/device/serial")
+		for _, path := range paths {
+			device := filepath.Base(filepath.Dir(filepath.Dir(path)))
+			if strings.HasPrefix(device, "loop") || strings.HasPrefix(device, "zram") || strings.HasPrefix(device, "dm-") {
+				continue
+			}
+			if serial := firstFile(path); serial != "" {
+				serials = append(serials, serial)
+			}
+		}
+	case "windows":
+		serials = wmicValues("diskdrive", "get", "SerialNumber")
+	}
+	sort.Strings(serials)
+	return strings.Join(serials, ",")
+}
+
+// virtualInterface matches adapters that come and go: containers, VMs, VPNs and tunnels
+var virtualInterface = regexp.MustCompile(`^(docker|veth|br-|virbr|vmnet|vboxnet|utun|tun|tap|wg|zt|tailscale|awdl|llw|bridge)`)
+
+// macAddresses lists the burned-in MACs of physical adapters, whether up or not
+func macAddresses() string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+
+	var macs []string
+	for _, iface := range interfaces {
+		mac := iface.HardwareAddr
+		if iface.Flags&net.FlagLoopback != 0 || len(mac) != 6 || virtualInterface.MatchString(iface.Name) {
+			continue
+		}
+		if mac[0]&0x02 != 0 || mac.String() == "00:00:00:00:00:00" {
+			continue // Locally administered (randomized or virtual) or unset
+		}
+		macs = append(macs, mac.String())
+	}
+	sort.Strings(macs)
+	return strings.Join(macs, ",")
+}
+
+// ioregValue reads a property of the macOS platform expert device
+func ioregValue(key string) string {
+	out := command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice")
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, `"`+key+`"`) {
+			if parts := strings.Split(line, `"`); len(parts) >= 4 {
+				return parts[3]
+			}
+		}
+	}
+	return ""
+}
+
+func firstFile(paths ...string) string {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if value := usable(string(data)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// usable drops vendor placeholders that many machines share
+func usable(value string) string {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "", "none", "default string", "to be filled by o.e.m.", "not specified", "system serial number",
+		"03000200-0400-0500-0006-000700080009", "ffffffff-ffff-ffff-ffff-ffffffffffff", "00000000-0000-0000-0000-000000000000":
+		return ""
+	}
+	if strings.Trim(value, "0-") == "" {
+		return ""
+	}
+	return value
+}
+
+func command(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.ReplaceAll(string(out), "\r", "")
+}
+
+// wmicValues returns the usable values of a single-column wmic query, without the header
+func wmicValues(args ...string) []string {
+	lines := strings.Split(command("wmic", args...), "\n")
+	var values []string
+	for _, line := range lines[1:] {
+		if value := usable(line); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func lastLine(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,225 @@
+package goals
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bbapp/internal/listener"
+)
+
+// goalState tracks one goal's progress
+type goalState struct {
+	goal        Goal
+	current     int64
+	completions int
+	reachedAt   *time.Time
+	announced   map[int]bool // milestones already announced in the current round
+}
+
+// Tracker updates goal progress from live gifts
+type Tracker struct {
+	goals     []*goalState
+	callbacks []func(Event)
+	mutex     sync.Mutex
+}
+
+// NewTracker validates the config and creates a tracker with zero progress
+func NewTracker(cfg Config) (*Tracker, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	t := &Tracker{}
+	for _, g := range cfg.Goals {
+		if g.Metric == "" {
+			g.Metric = MetricDiamonds
+		}
+		g.Milestones = normalizeMilestones(g.Milestones)
+		t.goals = append(t.goals, &goalState{goal: g, announced: make(map[int]bool)})
+	}
+	return t, nil
+}
+
+// Validate checks goal IDs, metrics, targets and milestones
+func Validate(cfg Config) error {
+	seen := make(map[string]bool, len(cfg.Goals))
+	for _, g := range cfg.Goals {
+		if g.Id == "" {
+			return fmt.Errorf("goal %q has no id", g.Name)
+		}
+		if seen[g.Id] {
+			return fmt.Errorf("duplicate goal id: %s", g.Id)
+		}
+		seen[g.Id] = true
+
+		if g.Metric != "" && g.Metric != MetricDiamonds && g.Metric != MetricCount {
+			return fmt.Errorf("goal %s has unknown metric %q", g.Id, g.Metric)
+		}
+		if g.Target <= 0 {
+			return fmt.Errorf("goal %s must have a positive target", g.Id)
+		}
+		for _, m := range g.Milestones {
+			if m <= 0 || m > 100 {
+				return fmt.Errorf("goal %s has invalid milestone %d%%", g.Id, m)
+			}
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a callback for goal events
+func (t *Tracker) Subscribe(callback func(Event)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.callbacks = append(t.callbacks, callback)
+}
+
+// HandleGift adds a gift to every matching goal and announces progress and milestones
+func (t *Tracker) HandleGift(gift listener.BigoGift) {
+	var events []Event
+	defer func() { t.notify(events) }() // Runs after unlock
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, state := range t.goals {
+		if !matches(state.goal, gift) {
+			continue
+		}
+
+		count := int64(gift.GiftCount)
+		if count <= 0 {
+			count = 1
+		}
+		if state.goal.Metric == MetricCount {
+			state.current += count
+		} else {
+			state.current += gift.Diamonds
+		}
+
+		events = append(events, t.advanceLocked(state, gift.SenderName)...)
+	}
+}
+
+// Reset sets a goal's progress back to zero
+func (t *Tracker) Reset(goalId string) error {
+	var events []Event
+	defer func() { t.notify(events) }()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, state := range t.goals {
+		if state.goal.Id == goalId {
+			state.current = 0
+			state.announced = make(map[int]bool)
+			events = append(events, Event{Type: EventProgress, Progress: state.progress()})
+			return nil
+		}
+	}
+	return fmt.Errorf("goal not found: %s", goalId)
+}
+
+// Progress returns the state of all goals in configuration order
+func (t *Tracker) Progress() []Progress {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	progress := make([]Progress, 0, len(t.goals))
+	for _, state := range t.goals {
+		progress = append(progress, state.progress())
+	}
+	return progress
+}
+
+// advanceLocked announces milestones crossed by the latest gift and handles repeating goals
+func (t *Tracker) advanceLocked(state *goalState, senderName string) []Event {
+	var events []Event
+
+	for {
+		percent := state.current * 100 / state.goal.Target
+		for _, m := range state.goal.Milestones {
+			if int64(m) <= percent && !state.announced[m] {
+				state.announced[m] = true
+				if m == 100 {
+					now := time.Now()
+					state.reachedAt = &now
+					state.completions++
+				}
+				events = append(events, Event{Type: EventMilestone, Progress: state.progress(), Milestone: m, SenderName: senderName})
+			}
+		}
+
+		// Repeating goals start the next round with the overflow (which may complete it again)
+		if !state.goal.Repeat || state.current < state.goal.Target {
+			break
+		}
+		state.current -= state.goal.Target
+		state.announced = make(map[int]bool)
+	}
+
+	return append(events, Event{Type: EventProgress, Progress: state.progress(), SenderName: senderName})
+}
+
+func (s *goalState) progress() Progress {
+	percent := float64(s.current) / float64(s.goal.Target) * 100
+	if percent > 100 {
+		percent = 100
+	}
+
+	var reachedAt *time.Time
+	if s.reachedAt != nil {
+		at := *s.reachedAt
+		reachedAt = &at
+	}
+	return Progress{
+		Goal:        s.goal,
+		Current:     s.current,
+		Percent:     percent,
+		Completions: s.completions,
+		ReachedAt:   reachedAt,
+	}
+}
+
+// notify delivers events in order. Must be called without holding the lock.
+func (t *Tracker) notify(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	callbacks := make([]func(Event), len(t.callbacks))
+	copy(callbacks, t.callbacks)
+	t.mutex.Unlock()
+
+	for _, event := range events {
+		for _, cb := range callbacks {
+			cb(event)
+		}
+	}
+}
+
+func matches(g Goal, gift listener.BigoGift) bool {
+	if g.GiftId == "" && g.GiftName == "" {
+		return true
+	}
+	if g.GiftId != "" && g.GiftId == gift.GiftId {
+		return true
+	}
+	return g.GiftName != "" && strings.EqualFold(g.GiftName, gift.GiftName)
+}
+
+// normalizeMilestones sorts milestones, removes duplicates and always includes 100%
+func normalizeMilestones(milestones []int) []int {
+	seen := map[int]bool{100: true}
+	result := []int{100}
+	for _, m := range milestones {
+		if !seen[m] {
+			seen[m] = true
+			result = append(result, m)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
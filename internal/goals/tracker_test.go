@@ -0,0 +1,76 @@
+package goals_test
+
+import (
+	"testing"
+
+	"bbapp/internal/goals"
+	"bbapp/internal/listener"
+)
+
+func TestTracker_Milestones(t *testing.T) {
+	tracker, err := goals.NewTracker(goals.Config{Goals: []goals.Goal{
+		{Id: "kiss", Name: "Kiss goal", GiftName: "Kiss", Target: 1000, Reward: "dance", Milestones: []int{50}},
+	}})
+	if err != nil {
+		t.Fatalf("NewTracker failed: %v", err)
+	}
+
+	var milestones []int
+	tracker.Subscribe(func(e goals.Event) {
+		if e.Type == goals.EventMilestone {
+			milestones = append(milestones, e.Milestone)
+		}
+	})
+
+	tracker.HandleGift(listener.BigoGift{GiftName: "kiss", Diamonds: 600})
+	tracker.HandleGift(listener.BigoGift{GiftName: "Rose", Diamonds: 1000}) // Not counted
+	tracker.HandleGift(listener.BigoGift{GiftName: "Kiss", Diamonds: 500})
+
+	if len(milestones) != 2 || milestones[0] != 50 || milestones[1] != 100 {
+		t.Errorf("Expected milestones [50 100], got %v", milestones)
+	}
+
+	progress := tracker.Progress()[0]
+	if progress.Current != 1100 || progress.Percent != 100 || progress.Completions != 1 || progress.ReachedAt == nil {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+}
+
+func TestTracker_RepeatAndCount(t *testing.T) {
+	tracker, _ := goals.NewTracker(goals.Config{Goals: []goals.Goal{
+		{Id: "any", Metric: goals.MetricCount, Target: 3, Repeat: true},
+	}})
+
+	tracker.HandleGift(listener.BigoGift{GiftName: "Rose", GiftCount: 7})
+
+	progress := tracker.Progress()[0]
+	if progress.Completions != 2 || progress.Current != 1 {
+		t.Errorf("Expected 2 completions and 1 carried over, got %+v", progress)
+	}
+
+	if err := tracker.Reset("any"); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if current := tracker.Progress()[0].Current; current != 0 {
+		t.Errorf("Expected progress reset to 0, got %d", current)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []goals.Goal{
+		{Id: "", Target: 10},
+		{Id: "a", Target: 0},
+		{Id: "a", Target: 10, Metric: "likes"},
+		{Id: "a", Target: 10, Milestones: []int{150}},
+	}
+	for _, g := range tests {
+		if err := goals.Validate(goals.Config{Goals: []goals.Goal{g}}); err == nil {
+			t.Errorf("Expected error for goal %+v", g)
+		}
+	}
+
+	dup := goals.Config{Goals: []goals.Goal{{Id: "a", Target: 1}, {Id: "a", Target: 2}}}
+	if err := goals.Validate(dup); err == nil {
+		t.Error("Expected error for duplicate goal ids")
+	}
+}
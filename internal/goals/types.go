@@ -0,0 +1,50 @@
+package goals
+
+import "time"
+
+// Goal metrics
+const (
+	MetricDiamonds = "diamonds" // Sum of gift diamonds
+	MetricCount    = "count"    // Number of gifts
+)
+
+// Event types broadcast to the overlay and UI
+const (
+	EventProgress  = "GOAL_PROGRESS"
+	EventMilestone = "GOAL_MILESTONE"
+)
+
+// Goal is a gift target such as "1000 Kiss diamonds -> dance"
+type Goal struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	GiftId     string `json:"giftId"`     // Empty GiftId and GiftName count every gift
+	GiftName   string `json:"giftName"`   // Matched case-insensitively
+	Metric     string `json:"metric"`     // diamonds (default) or count
+	Target     int64  `json:"target"`     // Value to reach
+	Reward     string `json:"reward"`     // What the streamer does when reached, e.g. "dance"
+	Milestones []int  `json:"milestones"` // Percentages announced on the way (100 is always announced)
+	Repeat     bool   `json:"repeat"`     // Start over when reached, carrying the overflow
+}
+
+// Config is the Free Mode setup of a room
+type Config struct {
+	Goals []Goal `json:"goals"`
+}
+
+// Progress is the live state of one goal
+type Progress struct {
+	Goal        Goal       `json:"goal"`
+	Current     int64      `json:"current"`
+	Percent     float64    `json:"percent"`
+	Completions int        `json:"completions"`
+	ReachedAt   *time.Time `json:"reachedAt"` // Last time the target was reached
+}
+
+// Event is a goal change delivered to subscribers
+type Event struct {
+	Type       string   `json:"type"` // GOAL_PROGRESS, GOAL_MILESTONE
+	Progress   Progress `json:"progress"`
+	Milestone  int      `json:"milestone,omitempty"` // Percentage reached (GOAL_MILESTONE only)
+	SenderName string   `json:"senderName"`          // Sender of the gift that caused the change
+}
@@ -0,0 +1,198 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"bbapp/internal/listener"
+)
+
+// Entry is one sender's aggregated gifting within a scope (overall, team or streamer)
+type Entry struct {
+	SenderId    string    `json:"senderId"`
+	SenderName  string    `json:"senderName"`
+	Avatar      string    `json:"avatar"`
+	Diamonds    int64     `json:"diamonds"`
+	GiftCount   int64     `json:"giftCount"`
+	FirstGiftAt time.Time `json:"firstGiftAt"`
+	LastGiftAt  time.Time `json:"lastGiftAt"`
+}
+
+// Snapshot is a ranked view of all leaderboards
+type Snapshot struct {
+	Overall   []Entry            `json:"overall"`
+	Teams     map[string][]Entry `json:"teams"`     // teamId -> ranked entries
+	Streamers map[string][]Entry `json:"streamers"` // streamerId -> ranked entries
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+// Delta describes the entries changed by a single gift, for incremental overlay updates
+type Delta struct {
+	TeamId     string `json:"teamId,omitempty"`
+	StreamerId string `json:"streamerId,omitempty"`
+	Overall    Entry  `json:"overall"`
+	Team       *Entry `json:"team,omitempty"`
+	Streamer   *Entry `json:"streamer,omitempty"`
+	Rank       int    `json:"rank"` // 1-based overall rank after the update
+}
+
+// Board aggregates gifts by sender overall, per team and per streamer
+type Board struct {
+	overall   map[string]*Entry
+	teams     map[string]map[string]*Entry
+	streamers map[string]map[string]*Entry
+	updatedAt time.Time
+	mutex     sync.RWMutex
+}
+
+// NewBoard creates an empty leaderboard
+func NewBoard() *Board {
+	return &Board{
+		overall:   make(map[string]*Entry),
+		teams:     make(map[string]map[string]*Entry),
+		streamers: make(map[string]map[string]*Entry),
+	}
+}
+
+// Record adds a gift to the leaderboards. teamId and streamerId may be empty if unresolved.
+func (b *Board) Record(gift listener.BigoGift, teamId, streamerId string) Delta {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	senderId := gift.SenderId
+	if senderId == "" {
+		senderId = gift.SenderName
+	}
+
+	at := time.Now()
+	if gift.Timestamp > 0 {
+		at = time.UnixMilli(gift.Timestamp)
+	}
+	b.updatedAt = at
+
+	delta := Delta{TeamId: teamId, StreamerId: streamerId}
+	delta.Overall = *addTo(b.overall, senderId, gift, at)
+
+	if teamId != "" {
+		if b.teams[teamId] == nil {
+			b.teams[teamId] = make(map[string]*Entry)
+		}
+		entry := *addTo(b.teams[teamId], senderId, gift, at)
+		delta.Team = &entry
+	}
+
+	if streamerId != "" {
+		if b.streamers[streamerId] == nil {
+			b.streamers[streamerId] = make(map[string]*Entry)
+		}
+		entry := *addTo(b.streamers[streamerId], senderId, gift, at)
+		delta.Streamer = &entry
+	}
+
+	delta.Rank = 1
+	for id, e := range b.overall {
+		if id != senderId && ranksBefore(*e, delta.Overall) {
+			delta.Rank++
+		}
+	}
+
+	return delta
+}
+
+// Snapshot returns ranked leaderboards limited to the top N entries per scope (limit <= 0 means all)
+func (b *Board) Snapshot(limit int) Snapshot {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	snap := Snapshot{
+		Overall:   ranked(b.overall, limit),
+		Teams:     make(map[string][]Entry, len(b.teams)),
+		Streamers: make(map[string][]Entry, len(b.streamers)),
+		UpdatedAt: b.updatedAt,
+	}
+	for teamId, entries := range b.teams {
+		snap.Teams[teamId] = ranked(entries, limit)
+	}
+	for streamerId, entries := range b.streamers {
+		snap.Streamers[streamerId] = ranked(entries, limit)
+	}
+	return snap
+}
+
+// Save writes the full leaderboard snapshot as JSON, creating parent directories
+func (b *Board) Save(path string) error {
+	data, err := json.MarshalIndent(b.Snapshot(0), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal leaderboard: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create leaderboard directory: %w", err)
+	}
+
+	// Write to temp file first (atomic write pattern)
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
+
+// addTo accumulates a gift into a sender entry of the given scope
+func addTo(scope map[string]*Entry, senderId string, gift listener.BigoGift, at time.Time) *Entry {
+	entry, ok := scope[senderId]
+	if !ok {
+		entry = &Entry{SenderId: senderId, FirstGiftAt: at}
+		scope[senderId] = entry
+	}
+
+	// Keep the latest display info for the sender
+	if gift.SenderName != "" {
+		entry.SenderName = gift.SenderName
+	}
+	if gift.SenderAvatar != "" {
+		entry.Avatar = gift.SenderAvatar
+	}
+
+	count := int64(gift.GiftCount)
+	if count <= 0 {
+		count = 1
+	}
+	entry.Diamonds += gift.Diamonds
+	entry.GiftCount += count
+	entry.LastGiftAt = at
+	return entry
+}
+
+// ranked returns entries sorted by diamonds desc (ties: earlier first gift wins)
+func ranked(scope map[string]*Entry, limit int) []Entry {
+	entries := make([]Entry, 0, len(scope))
+	for _, e := range scope {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return ranksBefore(entries[i], entries[j])
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+func ranksBefore(a, b Entry) bool {
+	if a.Diamonds != b.Diamonds {
+		return a.Diamonds > b.Diamonds
+	}
+	if !a.FirstGiftAt.Equal(b.FirstGiftAt) {
+		return a.FirstGiftAt.Before(b.FirstGiftAt)
+	}
+	return a.SenderId < b.SenderId
+}
@@ -0,0 +1,87 @@
+package leaderboard_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bbapp/internal/leaderboard"
+	"bbapp/internal/listener"
+)
+
+func TestBoard_Record(t *testing.T) {
+	board := leaderboard.NewBoard()
+
+	board.Record(listener.BigoGift{SenderId: "a", SenderName: "Alice", Diamonds: 10, GiftCount: 1, Timestamp: 1000}, "team1", "s1")
+	board.Record(listener.BigoGift{SenderId: "b", SenderName: "Bob", Diamonds: 50, GiftCount: 5, Timestamp: 2000}, "team2", "s2")
+	delta := board.Record(listener.BigoGift{SenderId: "a", SenderName: "Alice", SenderAvatar: "a.png", Diamonds: 100, GiftCount: 2, Timestamp: 3000}, "team1", "s1")
+
+	if delta.Rank != 1 {
+		t.Errorf("Expected Alice to rank 1 after big gift, got %d", delta.Rank)
+	}
+	if delta.Team == nil || delta.Team.Diamonds != 110 {
+		t.Errorf("Expected team entry with 110 diamonds, got %+v", delta.Team)
+	}
+
+	snap := board.Snapshot(0)
+	if len(snap.Overall) != 2 {
+		t.Fatalf("Expected 2 overall entries, got %d", len(snap.Overall))
+	}
+
+	top := snap.Overall[0]
+	if top.SenderId != "a" || top.Diamonds != 110 || top.GiftCount != 3 {
+		t.Errorf("Unexpected top entry: %+v", top)
+	}
+	if top.Avatar != "a.png" {
+		t.Errorf("Expected latest avatar, got %q", top.Avatar)
+	}
+	if top.FirstGiftAt.UnixMilli() != 1000 || top.LastGiftAt.UnixMilli() != 3000 {
+		t.Errorf("Unexpected first/last gift times: %v / %v", top.FirstGiftAt, top.LastGiftAt)
+	}
+
+	if len(snap.Teams["team2"]) != 1 || snap.Teams["team2"][0].SenderId != "b" {
+		t.Errorf("Unexpected team2 board: %+v", snap.Teams["team2"])
+	}
+	if len(snap.Streamers["s1"]) != 1 {
+		t.Errorf("Unexpected streamer s1 board: %+v", snap.Streamers["s1"])
+	}
+}
+
+func TestBoard_SnapshotLimit(t *testing.T) {
+	board := leaderboard.NewBoard()
+	for i, id := range []string{"a", "b", "c"} {
+		board.Record(listener.BigoGift{SenderId: id, Diamonds: int64(i + 1)}, "", "")
+	}
+
+	snap := board.Snapshot(2)
+	if len(snap.Overall) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(snap.Overall))
+	}
+	if snap.Overall[0].SenderId != "c" {
+		t.Errorf("Expected c first, got %s", snap.Overall[0].SenderId)
+	}
+}
+
+func TestBoard_Save(t *testing.T) {
+	board := leaderboard.NewBoard()
+	board.Record(listener.BigoGift{SenderId: "a", Diamonds: 10}, "team1", "")
+
+	path := filepath.Join(t.TempDir(), "room", "board.json")
+	if err := board.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	var snap leaderboard.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(snap.Overall) != 1 || snap.Overall[0].Diamonds != 10 {
+		t.Errorf("Unexpected saved snapshot: %+v", snap)
+	}
+}
@@ -0,0 +1,73 @@
+package message
+
+import (
+	"time"
+
+	"bbapp/internal/listener"
+)
+
+// NewGift builds the GIFT message for a listener gift. The streamer and team are left as
+// received; callers that resolve them against the room config overwrite them.
+func NewGift(roomId, deviceHash string, gift listener.BigoGift) *GiftMessage {
+	return &GiftMessage{
+		SchemaVersion:  SchemaVersion,
+		Type:           TypeGift,
+		RoomID:         roomId,
+		BigoRoomID:     gift.BigoRoomId,
+		SenderID:       gift.SenderId,
+		SenderName:     gift.SenderName,
+		SenderAvatar:   gift.SenderAvatar,
+		SenderLevel:    gift.SenderLevel,
+		StreamerID:     gift.StreamerId,
+		StreamerName:   gift.StreamerName,
+		StreamerAvatar: gift.StreamerAvatar,
+		TeamID:         gift.TeamId,
+		GiftID:         gift.GiftId,
+		GiftName:       gift.GiftName,
+		GiftCount:      gift.GiftCount,
+		Diamonds:       gift.Diamonds,
+		GiftImageURL:   gift.GiftImageUrl,
+		Timestamp:      timestamp(gift.Timestamp),
+		DeviceHash:     deviceHash,
+	}
+}
+
+// NewChat builds the CHAT message for a listener chat
+func NewChat(roomId, deviceHash string, chat listener.BigoChat) *ChatMessage {
+	return &ChatMessage{
+		SchemaVersion: SchemaVersion,
+		Type:          TypeChat,
+		RoomID:        roomId,
+		BigoRoomID:    chat.BigoRoomId,
+		SenderID:      chat.SenderId,
+		SenderName:    chat.SenderName,
+		SenderAvatar:  chat.SenderAvatar,
+		SenderLevel:   chat.SenderLevel,
+		Message:       chat.Message,
+		Timestamp:     timestamp(chat.Timestamp),
+		DeviceHash:    deviceHash,
+	}
+}
+
+// NewStatus builds a STATUS message; errorMessage is only sent when set
+func NewStatus(roomId, deviceHash, bigoRoomId, streamerId, status, errorMessage string) *StatusMessage {
+	return &StatusMessage{
+		SchemaVersion: SchemaVersion,
+		Type:          TypeStatus,
+		RoomID:        roomId,
+		StreamerID:    streamerId,
+		BigoRoomID:    bigoRoomId,
+		Status:        status,
+		ErrorMessage:  errorMessage,
+		Timestamp:     time.Now().UnixMilli(),
+		DeviceHash:    deviceHash,
+	}
+}
+
+// timestamp falls back to now for events the listener didn't stamp
+func timestamp(ms int64) int64 {
+	if ms == 0 {
+		return time.Now().UnixMilli()
+	}
+	return ms
+}
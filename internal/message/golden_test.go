@@ -0,0 +1,109 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bbapp/internal/listener"
+)
+
+// Run with -update after a deliberate payload change, and bump SchemaVersion if BB-Core must know
+var update = flag.Bool("update", false, "rewrite golden files and docs/schema")
+
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v (run go test -update)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestGoldenPayloads(t *testing.T) {
+	gift := NewGift("room-1", "device-1", listener.BigoGift{
+		SenderId: "859856177", SenderName: "horse", SenderAvatar: "https://example.com/a.jpg", SenderLevel: 30,
+		StreamerId: "829454322", StreamerName: "Bella",
+		GiftId: "10086", GiftName: "Kiss", GiftCount: 1, Diamonds: 655931, GiftImageUrl: "https://example.com/kiss.png",
+		Timestamp: 1735214400000, BigoRoomId: "7478500464273093441", TeamId: "team-red",
+	})
+	chat := NewChat("room-1", "device-1", listener.BigoChat{
+		SenderId: "818823724", SenderName: "fan", SenderLevel: 30, Message: "wow",
+		Timestamp: 1735214500000, BigoRoomId: "7269255640400014299",
+	})
+	status := NewStatus("room-1", "device-1", "7478500464273093441", "829454322", StatusError, "websocket closed")
+	status.Timestamp = 1735214600000
+
+	for name, msg := range map[string]Message{"gift": gift, "chat": chat, "status": status} {
+		t.Run(name, func(t *testing.T) {
+			if err := msg.Validate(); err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+			data, err := json.MarshalIndent(msg, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join("testdata", name+".golden.json"), append(data, '\n'))
+		})
+	}
+}
+
+func TestGeneratedSchemas(t *testing.T) {
+	for name, schema := range Schemas() {
+		data, err := MarshalSchema(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, filepath.Join("..", "..", "docs", "schema", name), data)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *GiftMessage {
+		return NewGift("room-1", "device-1", listener.BigoGift{SenderId: "s1", StreamerId: "st1", GiftName: "Rose", GiftCount: 1, Timestamp: 1})
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*GiftMessage)
+		want   string
+	}{
+		{"missing fields", func(m *GiftMessage) { m.SenderID, m.DeviceHash = "", "" }, "missing senderId, deviceHash"},
+		{"no streamer or team", func(m *GiftMessage) { m.StreamerID = "" }, "streamerId or teamId"},
+		{"wrong type", func(m *GiftMessage) { m.Type = TypeChat }, `type is "CHAT"`},
+		{"old schema version", func(m *GiftMessage) { m.SchemaVersion = 0 }, "missing schemaVersion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := valid()
+			tt.modify(msg)
+			if err := msg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	// Rule bonuses are credited to a team without a streamer
+	bonus := valid()
+	bonus.StreamerID, bonus.TeamID = "", "team-red"
+	if err := bonus.Validate(); err != nil {
+		t.Errorf("Validate() of a team gift = %v", err)
+	}
+
+	status := NewStatus("room-1", "device-1", "123", "", "LOST", "")
+	if err := status.Validate(); err == nil || !strings.Contains(err.Error(), "must be one of") {
+		t.Errorf("Validate() = %v, want an enum error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+//go:generate go run ../../cmd/message-schema -out ../../docs/schema
+
+// Schemas returns the JSON Schema of each message type, keyed by file name
+// (e.g. "gift.schema.json"). docs/schema holds the generated files for BB-Core.
+func Schemas() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"gift.schema.json":   Schema(&GiftMessage{SchemaVersion: SchemaVersion, Type: TypeGift}),
+		"chat.schema.json":   Schema(&ChatMessage{SchemaVersion: SchemaVersion, Type: TypeChat}),
+		"status.schema.json": Schema(&StatusMessage{SchemaVersion: SchemaVersion, Type: TypeStatus}),
+	}
+}
+
+// Schema describes a message struct as a JSON Schema. Fields without omitempty are required,
+// required:"true" fields must also be non-empty, and schema:"const" fields take the value in msg.
+func Schema(msg interface{}) map[string]interface{} {
+	v := reflect.ValueOf(msg).Elem()
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for _, field := range fields(v.Type()) {
+		property := map[string]interface{}{"type": jsonType(field.kind)}
+		switch {
+		case field.constant:
+			property["const"] = v.FieldByIndex(field.index).Interface()
+		case len(field.enum) > 0:
+			property["enum"] = field.enum
+		case field.required && field.kind == reflect.String:
+			property["minLength"] = 1
+		case field.required:
+			property["minimum"] = 1
+		}
+		properties[field.name] = property
+
+		if !field.omitempty {
+			required = append(required, field.name)
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                fmt.Sprintf("BBapp %s message v%d", v.FieldByName("Type").String(), SchemaVersion),
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// MarshalSchema formats a schema the way the files in docs/schema are written
+func MarshalSchema(schema map[string]interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "object"
+	}
+}
@@ -1,38 +1,78 @@
 package message
 
+// SchemaVersion is sent with every message; bump it when a field is added, renamed or removed
+const SchemaVersion = 1
+
+// Message types, sent in the "type" field
+const (
+	TypeGift   = "GIFT"
+	TypeChat   = "CHAT"
+	TypeStatus = "STATUS"
+)
+
+// Connection states of a STATUS message
+const (
+	StatusConnected    = "CONNECTED"
+	StatusDisconnected = "DISCONNECTED"
+	StatusError        = "ERROR"
+)
+
+// Fields without omitempty are always sent. Tags used by Validate and Schema:
+//   required:"true" - must not be empty or zero
+//   schema:"const"  - fixed per message type (type, schemaVersion)
+//   enum:"A,B"      - allowed values
+
 // GiftMessage represents a gift event sent to BB-Core via STOMP.
-// Matches the specification from docs/BBAPP_INTEGRATION_GUIDE.md
+// Matches the specification from docs/BBAPP_API_REFERENCE.md
 type GiftMessage struct {
-	Type           string `json:"type"` // "GIFT"
-	RoomID         string `json:"roomId"`
+	SchemaVersion  int    `json:"schemaVersion" required:"true" schema:"const"`
+	Type           string `json:"type" required:"true" schema:"const"` // "GIFT"
+	RoomID         string `json:"roomId" required:"true"`
 	BigoRoomID     string `json:"bigoRoomId"`
-	SenderID       string `json:"senderId"`
+	SenderID       string `json:"senderId" required:"true"`
 	SenderName     string `json:"senderName"`
 	SenderAvatar   string `json:"senderAvatar,omitempty"`
 	SenderLevel    int    `json:"senderLevel,omitempty"`
-	StreamerID     string `json:"streamerId"`
+	StreamerID     string `json:"streamerId"` // Empty only for gifts credited to a team, e.g. rule bonuses
 	StreamerName   string `json:"streamerName"`
 	StreamerAvatar string `json:"streamerAvatar,omitempty"`
+	TeamID         string `json:"teamId,omitempty"`
 	GiftID         string `json:"giftId"`
-	GiftName       string `json:"giftName"`
-	GiftCount      int    `json:"giftCount"`
+	GiftName       string `json:"giftName" required:"true"`
+	GiftCount      int    `json:"giftCount" required:"true"`
 	Diamonds       int64  `json:"diamonds"`
 	GiftImageURL   string `json:"giftImageUrl,omitempty"`
-	Timestamp      int64  `json:"timestamp"`
-	DeviceHash     string `json:"deviceHash"`
+	Timestamp      int64  `json:"timestamp" required:"true"`
+	DeviceHash     string `json:"deviceHash" required:"true"`
 }
 
 // ChatMessage represents a chat message sent to BB-Core via STOMP.
-// Matches the specification from docs/BBAPP_INTEGRATION_GUIDE.md
+// Matches the specification from docs/BBAPP_API_REFERENCE.md
 type ChatMessage struct {
-	Type         string `json:"type"` // "CHAT"
-	RoomID       string `json:"roomId"`
-	BigoRoomID   string `json:"bigoRoomId"`
-	SenderID     string `json:"senderId"`
-	SenderName   string `json:"senderName"`
-	SenderAvatar string `json:"senderAvatar,omitempty"`
-	SenderLevel  int    `json:"senderLevel,omitempty"`
-	Message      string `json:"message"`
-	Timestamp    int64  `json:"timestamp"`
-	DeviceHash   string `json:"deviceHash"`
+	SchemaVersion int    `json:"schemaVersion" required:"true" schema:"const"`
+	Type          string `json:"type" required:"true" schema:"const"` // "CHAT"
+	RoomID        string `json:"roomId" required:"true"`
+	BigoRoomID    string `json:"bigoRoomId" required:"true"`
+	SenderID      string `json:"senderId" required:"true"`
+	SenderName    string `json:"senderName"`
+	SenderAvatar  string `json:"senderAvatar,omitempty"`
+	SenderLevel   int    `json:"senderLevel,omitempty"`
+	TeamID        string `json:"teamId,omitempty"`
+	Message       string `json:"message" required:"true"`
+	Timestamp     int64  `json:"timestamp" required:"true"`
+	DeviceHash    string `json:"deviceHash" required:"true"`
+}
+
+// StatusMessage reports the connection state of a streamer's Bigo room to BB-Core.
+// Matches the specification from docs/BBAPP_API_REFERENCE.md
+type StatusMessage struct {
+	SchemaVersion int    `json:"schemaVersion" required:"true" schema:"const"`
+	Type          string `json:"type" required:"true" schema:"const"` // "STATUS"
+	RoomID        string `json:"roomId" required:"true"`
+	StreamerID    string `json:"streamerId"`
+	BigoRoomID    string `json:"bigoRoomId" required:"true"`
+	Status        string `json:"status" required:"true" enum:"CONNECTED,DISCONNECTED,ERROR"`
+	ErrorMessage  string `json:"errorMessage,omitempty"`
+	Timestamp     int64  `json:"timestamp" required:"true"`
+	DeviceHash    string `json:"deviceHash" required:"true"`
 }
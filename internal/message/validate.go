@@ -0,0 +1,97 @@
+package message
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Message is an outbound BB-Core message; Validate is called before every publish
+type Message interface {
+	Validate() error
+}
+
+// Validate checks the required fields and the type and schema version of a GIFT message.
+// A gift must be credited to a streamer or, for rule bonuses, directly to a team.
+func (m *GiftMessage) Validate() error {
+	if err := validate(m, TypeGift); err != nil {
+		return err
+	}
+	if m.StreamerID == "" && m.TeamID == "" {
+		return fmt.Errorf("invalid %s message: streamerId or teamId is required", TypeGift)
+	}
+	return nil
+}
+
+// Validate checks the required fields and the type and schema version of a CHAT message
+func (m *ChatMessage) Validate() error {
+	return validate(m, TypeChat)
+}
+
+// Validate checks the required fields, the status value and the type and schema version of a STATUS message
+func (m *StatusMessage) Validate() error {
+	return validate(m, TypeStatus)
+}
+
+// validate applies the required and enum tags of msg, a pointer to a message struct
+func validate(msg interface{}, msgType string) error {
+	v := reflect.ValueOf(msg).Elem()
+	var missing []string
+	for _, field := range fields(v.Type()) {
+		value := v.FieldByIndex(field.index)
+		if field.required && value.IsZero() {
+			missing = append(missing, field.name)
+			continue
+		}
+		if len(field.enum) > 0 && !value.IsZero() && !slices.Contains(field.enum, value.String()) {
+			return fmt.Errorf("invalid %s message: %s must be one of %s, got %q", msgType, field.name, strings.Join(field.enum, ", "), value.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("invalid %s message: missing %s", msgType, strings.Join(missing, ", "))
+	}
+
+	if got := v.FieldByName("Type").String(); got != msgType {
+		return fmt.Errorf("invalid %s message: type is %q", msgType, got)
+	}
+	if got := v.FieldByName("SchemaVersion").Int(); got != SchemaVersion {
+		return fmt.Errorf("invalid %s message: schema version %d, want %d", msgType, got, SchemaVersion)
+	}
+	return nil
+}
+
+// field is a message struct field as described by its tags
+type field struct {
+	index     []int
+	name      string // JSON name
+	kind      reflect.Kind
+	omitempty bool
+	required  bool
+	constant  bool
+	enum      []string
+}
+
+func fields(t reflect.Type) []field {
+	result := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		f := field{
+			index:     sf.Index,
+			name:      name,
+			kind:      sf.Type.Kind(),
+			omitempty: strings.Contains(opts, "omitempty"),
+			required:  sf.Tag.Get("required") == "true",
+			constant:  sf.Tag.Get("schema") == "const",
+		}
+		if enum := sf.Tag.Get("enum"); enum != "" {
+			f.enum = strings.Split(enum, ",")
+		}
+		result = append(result, f)
+	}
+	return result
+}
@@ -0,0 +1,421 @@
+package overlayserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Topics a control connection can subscribe to. "alerts" carries the gifts topic paced by acks.
+const (
+	TopicGifts  = "gifts"
+	TopicAlerts = "alerts"
+	TopicScores = "scores"
+	TopicTimer  = "timer"
+	TopicConfig = "config"
+	TopicEvents = "events" // Everything else: dance, goals, rules, connection state
+)
+
+var topics = []string{TopicGifts, TopicAlerts, TopicScores, TopicTimer, TopicConfig, TopicEvents}
+
+// Permissions of a control connection
+const (
+	PermSubscribe = "subscribe"
+	PermAck       = "ack"
+	PermPause     = "pause" // pause and resume
+	PermAddTime   = "add_time"
+	PermNextRound = "next_round"
+)
+
+// viewerPermissions are granted to connections without a token, e.g. OBS overlays
+var viewerPermissions = []string{PermSubscribe, PermAck}
+
+// OperatorPermissions grant every command, for the control panel of the streamer's machine
+var OperatorPermissions = []string{PermSubscribe, PermAck, PermPause, PermAddTime, PermNextRound}
+
+// Operator commands
+const (
+	CommandPause     = "pause"
+	CommandResume    = "resume"
+	CommandAddTime   = "add_time"
+	CommandNextRound = "next_round"
+)
+
+// commandPermissions maps each command to the permission it requires
+var commandPermissions = map[string]string{
+	CommandPause:     PermPause,
+	CommandResume:    PermPause,
+	CommandAddTime:   PermAddTime,
+	CommandNextRound: PermNextRound,
+}
+
+// alertAckTimeout is how long an unacknowledged alert holds back the next one
+var alertAckTimeout = 30 * time.Second
+
+// maxQueuedAlerts bounds a connection's alert queue; the oldest alerts are dropped beyond it
+const maxQueuedAlerts = 100
+
+// Command is an operator command received on the control channel
+type Command struct {
+	Name    string `json:"command"`
+	RoomId  string `json:"roomId"`            // Defaults to the connection's room
+	Seconds int    `json:"seconds,omitempty"` // add_time
+}
+
+// CommandHandler executes an operator command; the result is sent back to the operator
+type CommandHandler func(cmd Command) (interface{}, error)
+
+// controlRequest is a message from a control client
+type controlRequest struct {
+	Action    string   `json:"action"` // subscribe, unsubscribe, ack, command
+	RequestId string   `json:"requestId,omitempty"`
+	Topics    []string `json:"topics,omitempty"`
+	Id        int64    `json:"id,omitempty"` // Alert being acknowledged
+	Command
+}
+
+// controlMessage is a message to a control client
+type controlMessage struct {
+	Type        string          `json:"type"` // WELCOME, EVENT, ALERT, RESULT
+	Topic       string          `json:"topic,omitempty"`
+	Id          int64           `json:"id,omitempty"`
+	Event       json.RawMessage `json:"event,omitempty"`
+	RequestId   string          `json:"requestId,omitempty"`
+	Ok          *bool           `json:"ok,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Data        interface{}     `json:"data,omitempty"`
+	Topics      []string        `json:"topics,omitempty"`
+	Permissions []string        `json:"permissions,omitempty"`
+}
+
+// alert is a paced event waiting for the previous one to be acknowledged
+type alert struct {
+	id    int64
+	event json.RawMessage
+}
+
+// controlConn is one WebSocket control connection
+type controlConn struct {
+	roomId      string // "" = all rooms
+	permissions []string
+	send        chan []byte
+
+	mu       sync.Mutex
+	topics   map[string]bool
+	alerts   []alert
+	inFlight int64 // Alert awaiting an ack (0 = none)
+	lastId   int64
+	ackTimer *time.Timer
+	closed   bool
+}
+
+var upgrader = websocket.Upgrader{
+	// Overlays are loaded from OBS and the local dev server; commands are guarded by tokens instead
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// SetControlToken grants the given permissions to control connections presenting token (?token=)
+func (s *Server) SetControlToken(token string, permissions []string) {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+	s.controlTokens[token] = permissions
+}
+
+// SetCommandHandler sets who executes operator commands
+func (s *Server) SetCommandHandler(handler CommandHandler) {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+	s.commandHandler = handler
+}
+
+// handleControl upgrades a request to a control connection (?roomId= filters to a room)
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	permissions := viewerPermissions
+	if token := r.URL.Query().Get("token"); token != "" {
+		s.controlMutex.RLock()
+		granted, ok := s.controlTokens[token]
+		s.controlMutex.RUnlock()
+		if !ok {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+		permissions = granted
+	}
+
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[OverlayServer] Control upgrade failed: %v", err)
+		return
+	}
+
+	conn := &controlConn{
+		roomId:      r.URL.Query().Get("roomId"),
+		permissions: permissions,
+		send:        make(chan []byte, 64),
+		topics:      make(map[string]bool),
+	}
+
+	s.controlMutex.Lock()
+	s.controlConns[conn] = true
+	s.controlMutex.Unlock()
+	log.Printf("[OverlayServer] Control client connected (room: %q, permissions: %v)", conn.roomId, permissions)
+
+	go conn.writeLoop(ws)
+	conn.write(controlMessage{Type: "WELCOME", Topics: topics, Permissions: permissions})
+	s.readControl(ws, conn)
+
+	s.controlMutex.Lock()
+	delete(s.controlConns, conn)
+	s.controlMutex.Unlock()
+	conn.close()
+	log.Printf("[OverlayServer] Control client disconnected")
+}
+
+// readControl handles requests until the connection closes
+func (s *Server) readControl(ws *websocket.Conn, conn *controlConn) {
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req controlRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			conn.result("", nil, fmt.Errorf("invalid message: %w", err))
+			continue
+		}
+
+		switch req.Action {
+		case "subscribe", "unsubscribe":
+			if !conn.can(PermSubscribe) {
+				conn.result(req.RequestId, nil, fmt.Errorf("permission %s required", PermSubscribe))
+				continue
+			}
+			conn.result(req.RequestId, nil, conn.subscribe(req.Topics, req.Action == "subscribe"))
+
+		case "ack":
+			if !conn.can(PermAck) {
+				conn.result(req.RequestId, nil, fmt.Errorf("permission %s required", PermAck))
+				continue
+			}
+			conn.ack(req.Id)
+
+		case "command":
+			data, err := s.runCommand(conn, req.Command)
+			conn.result(req.RequestId, data, err)
+
+		default:
+			conn.result(req.RequestId, nil, fmt.Errorf("unknown action %q", req.Action))
+		}
+	}
+}
+
+// runCommand checks an operator command against the connection's permissions and executes it
+func (s *Server) runCommand(conn *controlConn, cmd Command) (interface{}, error) {
+	permission, ok := commandPermissions[cmd.Name]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", cmd.Name)
+	}
+	if !conn.can(permission) {
+		return nil, fmt.Errorf("permission %s required", permission)
+	}
+	if cmd.RoomId == "" {
+		cmd.RoomId = conn.roomId
+	}
+	if cmd.RoomId == "" {
+		return nil, fmt.Errorf("roomId required")
+	}
+	if conn.roomId != "" && cmd.RoomId != conn.roomId {
+		return nil, fmt.Errorf("connection is limited to room %s", conn.roomId)
+	}
+
+	s.controlMutex.RLock()
+	handler := s.commandHandler
+	s.controlMutex.RUnlock()
+	if handler == nil {
+		return nil, fmt.Errorf("commands are not available")
+	}
+
+	log.Printf("[OverlayServer] Command %s for room %s", cmd.Name, cmd.RoomId)
+	return handler(cmd)
+}
+
+// broadcastControl delivers an event to control connections subscribed to its topic
+func (s *Server) broadcastControl(roomId, topic string, data []byte) {
+	s.controlMutex.RLock()
+	defer s.controlMutex.RUnlock()
+	for conn := range s.controlConns {
+		if roomId != "" && conn.roomId != "" && conn.roomId != roomId {
+			continue
+		}
+		conn.deliver(topic, data)
+	}
+}
+
+// eventTopic returns the topic of a marshalled overlay event
+func eventTopic(data []byte) string {
+	var header struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(data, &header)
+	return topicOf(header.Type)
+}
+
+// topicOf maps an overlay event type to its topic
+func topicOf(eventType string) string {
+	switch {
+	case eventType == "GIFT":
+		return TopicGifts
+	case eventType == "LEADERBOARD_UPDATE" || eventType == "SCORE_BONUS" || eventType == "PK_SYNC":
+		return TopicScores
+	case strings.HasPrefix(eventType, "TIMER"):
+		return TopicTimer
+	case eventType == "CONFIG_UPDATE":
+		return TopicConfig
+	default:
+		return TopicEvents
+	}
+}
+
+func (c *controlConn) can(permission string) bool {
+	return slices.Contains(c.permissions, permission)
+}
+
+func (c *controlConn) subscribe(names []string, on bool) error {
+	for _, name := range names {
+		if !slices.Contains(topics, name) {
+			return fmt.Errorf("unknown topic %q", name)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range names {
+		if on {
+			c.topics[name] = true
+		} else {
+			delete(c.topics, name)
+		}
+	}
+	return nil
+}
+
+// deliver sends an event on its topic and, for gifts, queues it as a paced alert
+func (c *controlConn) deliver(topic string, data []byte) {
+	c.mu.Lock()
+	subscribed, alerts := c.topics[topic], topic == TopicGifts && c.topics[TopicAlerts]
+	c.mu.Unlock()
+
+	if subscribed {
+		c.write(controlMessage{Type: "EVENT", Topic: topic, Event: data})
+	}
+	if alerts {
+		c.queueAlert(data)
+	}
+}
+
+func (c *controlConn) queueAlert(data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.alerts = append(c.alerts, alert{event: data})
+	if len(c.alerts) > maxQueuedAlerts {
+		c.alerts = c.alerts[len(c.alerts)-maxQueuedAlerts:]
+	}
+	if c.inFlight == 0 {
+		c.nextAlertLocked()
+	}
+}
+
+// ack releases the next alert once the overlay has finished showing alert id
+func (c *controlConn) ack(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id != 0 && id == c.inFlight {
+		c.nextAlertLocked()
+	}
+}
+
+// nextAlertLocked sends the next queued alert, if any. Caller must hold mu.
+func (c *controlConn) nextAlertLocked() {
+	if c.ackTimer != nil {
+		c.ackTimer.Stop()
+		c.ackTimer = nil
+	}
+	c.inFlight = 0
+	if len(c.alerts) == 0 || c.closed {
+		return
+	}
+
+	next := c.alerts[0]
+	c.alerts = c.alerts[1:]
+	c.lastId++
+	next.id = c.lastId
+
+	c.inFlight = next.id
+	c.writeLocked(controlMessage{Type: "ALERT", Topic: TopicAlerts, Id: next.id, Event: next.event})
+
+	// An overlay that never acks (e.g. closed mid-animation) must not stall the queue
+	c.ackTimer = time.AfterFunc(alertAckTimeout, func() { c.ack(next.id) })
+}
+
+func (c *controlConn) result(requestId string, data interface{}, err error) {
+	ok := err == nil
+	msg := controlMessage{Type: "RESULT", RequestId: requestId, Ok: &ok, Data: data}
+	if err != nil {
+		msg.Error = err.Error()
+	}
+	c.write(msg)
+}
+
+func (c *controlConn) write(msg controlMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeLocked(msg)
+}
+
+// writeLocked queues a message for the write loop, dropping it if the client is too slow. Caller must hold mu.
+func (c *controlConn) writeLocked(msg controlMessage) {
+	if c.closed {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("[OverlayServer] Failed to marshal control message: %v", err)
+		return
+	}
+	select {
+	case c.send <- data:
+	default:
+		log.Printf("[OverlayServer] Control client blocked, skipping %s", msg.Type)
+	}
+}
+
+func (c *controlConn) writeLoop(ws *websocket.Conn) {
+	defer ws.Close()
+	for data := range c.send {
+		if err := ws.WriteMessage(websocket.TextMessage, data); err != nil {
+			return
+		}
+	}
+}
+
+func (c *controlConn) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if c.ackTimer != nil {
+		c.ackTimer.Stop()
+	}
+	close(c.send)
+}
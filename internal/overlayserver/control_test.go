@@ -0,0 +1,128 @@
+package overlayserver
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func startTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.mux)
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+func dialControl(t *testing.T, ts *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?"+query, nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	if msg := readControl(t, ws); msg.Type != "WELCOME" {
+		t.Fatalf("Expected WELCOME, got %+v", msg)
+	}
+	return ws
+}
+
+func readControl(t *testing.T, ws *websocket.Conn) controlMessage {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg controlMessage
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return msg
+}
+
+func request(t *testing.T, ws *websocket.Conn, req map[string]interface{}) controlMessage {
+	t.Helper()
+	if err := ws.WriteJSON(req); err != nil {
+		t.Fatal(err)
+	}
+	return readControl(t, ws)
+}
+
+func TestControl_TopicsAndAlerts(t *testing.T) {
+	s, ts := startTestServer(t)
+
+	ws := dialControl(t, ts, "roomId=room-1")
+	if res := request(t, ws, map[string]interface{}{"action": "subscribe", "topics": []string{"scores", "alerts"}}); !*res.Ok {
+		t.Fatalf("subscribe failed: %s", res.Error)
+	}
+
+	s.BroadcastRoomEvent("room-2", map[string]interface{}{"type": "LEADERBOARD_UPDATE"}) // Other room
+	s.BroadcastRoomEvent("room-1", map[string]interface{}{"type": "LEADERBOARD_UPDATE"})
+	if msg := readControl(t, ws); msg.Type != "EVENT" || msg.Topic != TopicScores {
+		t.Fatalf("Expected a scores EVENT, got %+v", msg)
+	}
+
+	// Gifts are paced: the second alert waits for the first to be acknowledged
+	s.BroadcastRoomEvent("room-1", map[string]interface{}{"type": "GIFT", "giftName": "Rose"})
+	s.BroadcastRoomEvent("room-1", map[string]interface{}{"type": "GIFT", "giftName": "Heart"})
+	first := readControl(t, ws)
+	if first.Type != "ALERT" || !strings.Contains(string(first.Event), "Rose") {
+		t.Fatalf("Expected the Rose alert, got %+v", first)
+	}
+	ws.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := ws.ReadMessage(); err == nil {
+		t.Fatal("Expected the next alert to wait for the ack")
+	}
+}
+
+func TestControl_AckReleasesNextAlert(t *testing.T) {
+	s, ts := startTestServer(t)
+
+	ws := dialControl(t, ts, "")
+	request(t, ws, map[string]interface{}{"action": "subscribe", "topics": []string{"alerts"}})
+
+	s.BroadcastRoomEvent("room-1", map[string]interface{}{"type": "GIFT", "giftName": "Rose"})
+	s.BroadcastRoomEvent("room-1", map[string]interface{}{"type": "GIFT", "giftName": "Heart"})
+	first := readControl(t, ws)
+
+	if err := ws.WriteJSON(map[string]interface{}{"action": "ack", "id": first.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if second := readControl(t, ws); second.Type != "ALERT" || !strings.Contains(string(second.Event), "Heart") {
+		t.Fatalf("Expected the Heart alert after the ack, got %+v", second)
+	}
+}
+
+func TestControl_CommandPermissions(t *testing.T) {
+	s, ts := startTestServer(t)
+	s.SetControlToken("operator-token", []string{PermSubscribe, PermPause})
+	var got Command
+	s.SetCommandHandler(func(cmd Command) (interface{}, error) {
+		got = cmd
+		return map[string]string{"status": "PAUSED"}, nil
+	})
+
+	viewer := dialControl(t, ts, "roomId=room-1")
+	if res := request(t, viewer, map[string]interface{}{"action": "command", "command": "pause"}); *res.Ok {
+		t.Error("Expected a viewer to be refused")
+	}
+
+	operator := dialControl(t, ts, "roomId=room-1&token=operator-token")
+	res := request(t, operator, map[string]interface{}{"action": "command", "command": "pause", "requestId": "r1"})
+	if !*res.Ok || res.RequestId != "r1" || got.Name != CommandPause || got.RoomId != "room-1" {
+		t.Errorf("pause = %+v, handler got %+v", res, got)
+	}
+	if res := request(t, operator, map[string]interface{}{"action": "command", "command": "add_time", "seconds": 60}); *res.Ok {
+		t.Error("Expected add_time to need its own permission")
+	}
+	if res := request(t, operator, map[string]interface{}{"action": "command", "command": "pause", "roomId": "room-2"}); *res.Ok {
+		t.Error("Expected a room-bound connection to be refused other rooms")
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws?token=wrong", nil); err == nil || resp.StatusCode != 401 {
+		t.Errorf("Expected an unknown token to be rejected, got %v", err)
+	}
+}
@@ -5,16 +5,30 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 )
 
+// LeaderboardProvider returns the leaderboard of a room (limit <= 0 means all entries)
+type LeaderboardProvider func(roomId string, limit int) (interface{}, bool)
+
 type Server struct {
 	port          int
 	mux           *http.ServeMux
 	currentConfig interface{}
+	roomConfigs   map[string]interface{} // BB-Core roomId -> config
 	configMutex   sync.RWMutex
-	clients       map[chan []byte]bool
+	leaderboard   LeaderboardProvider
+	clients       map[*sseClient]bool
+	history       eventRing // Latest events, replayed to SSE clients resuming with Last-Event-ID
+	lastEventId   int64
 	clientsMutex  sync.RWMutex
+
+	// WebSocket control channel (see control.go)
+	controlConns   map[*controlConn]bool
+	controlTokens  map[string][]string // token -> permissions
+	commandHandler CommandHandler
+	controlMutex   sync.RWMutex
 }
 
 func NewServer() (*Server, error) {
@@ -25,9 +39,13 @@ func NewServer() (*Server, error) {
 	}
 
 	s := &Server{
-		port:    port,
-		mux:     http.NewServeMux(),
-		clients: make(map[chan []byte]bool),
+		port:        port,
+		mux:         http.NewServeMux(),
+		roomConfigs: make(map[string]interface{}),
+		clients:     make(map[*sseClient]bool),
+
+		controlConns:  make(map[*controlConn]bool),
+		controlTokens: make(map[string][]string),
 	}
 	s.setupRoutes()
 	return s, nil
@@ -45,12 +63,18 @@ func (s *Server) setupRoutes() {
 	// Serve data directory (for gifts.json)
 	s.mux.Handle("/data/", http.StripPrefix("/data/", http.FileServer(http.Dir("./data"))))
 
-	// Serve current local configuration
+	// Serve current local configuration (?roomId= selects a room)
 	s.mux.HandleFunc("/config", s.handleConfig)
 
-	// Server Sent Events for real-time local updates
+	// Top gifter leaderboards (?roomId= selects a room, ?limit= caps entries per board)
+	s.mux.HandleFunc("/leaderboard", s.handleLeaderboard)
+
+	// Server Sent Events for real-time local updates (?roomId= filters to a room, see sse.go)
 	s.mux.HandleFunc("/events", s.handleEvents)
 
+	// WebSocket control channel: topic subscriptions, alert acks and operator commands (?roomId=, ?token=)
+	s.mux.HandleFunc("/ws", s.handleControl)
+
 	s.mux.Handle("/", fs)
 }
 
@@ -71,14 +95,37 @@ func (s *Server) SetConfig(config interface{}) {
 	s.currentConfig = config
 }
 
+// SetRoomConfig updates the configuration for a specific room.
+// It also becomes the default config served when no roomId is requested.
+func (s *Server) SetRoomConfig(roomId string, config interface{}) {
+	s.configMutex.Lock()
+	defer s.configMutex.Unlock()
+	s.roomConfigs[roomId] = config
+	s.currentConfig = config
+}
+
+// ClearRoomConfig removes the configuration of a room that is no longer running
+func (s *Server) ClearRoomConfig(roomId string) {
+	s.configMutex.Lock()
+	defer s.configMutex.Unlock()
+	delete(s.roomConfigs, roomId)
+}
+
 // handleConfig serves the current configuration as JSON
 func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	// Enable CORS for development
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Content-Type", "application/json")
 
+	roomId := r.URL.Query().Get("roomId")
+
 	s.configMutex.RLock()
 	config := s.currentConfig
+	if roomId != "" {
+		if roomConfig, ok := s.roomConfigs[roomId]; ok {
+			config = roomConfig
+		}
+	}
 	s.configMutex.RUnlock()
 
 	if config == nil {
@@ -91,68 +138,67 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// BroadcastEvent sends a payload to all connected SSE clients
-func (s *Server) BroadcastEvent(payload interface{}) {
-	s.clientsMutex.RLock()
-	defer s.clientsMutex.RUnlock()
-
-	log.Printf("[OverlayServer] Broadcasting event to %d clients. Payload: %+v", len(s.clients), payload)
+// SetLeaderboardProvider sets the source used by the /leaderboard endpoint
+func (s *Server) SetLeaderboardProvider(provider LeaderboardProvider) {
+	s.configMutex.Lock()
+	defer s.configMutex.Unlock()
+	s.leaderboard = provider
+}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("[OverlayServer] Failed to marshal SSE payload: %v", err)
-		return
-	}
+// handleLeaderboard serves a room's leaderboard as JSON
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
 
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- data:
-		default:
-			log.Printf("[OverlayServer] Client channel blocked, skipping update")
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
 		}
+		limit = n
 	}
-}
 
-// handleEvents manages SSE connections
-func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
-	// CORS and SSE headers
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+	s.configMutex.RLock()
+	provider := s.leaderboard
+	s.configMutex.RUnlock()
 
-	clientChan := make(chan []byte, 10)
+	if provider == nil {
+		http.Error(w, "No leaderboard available", http.StatusNotFound)
+		return
+	}
 
-	s.clientsMutex.Lock()
-	s.clients[clientChan] = true
-	s.clientsMutex.Unlock()
+	board, ok := provider(r.URL.Query().Get("roomId"), limit)
+	if !ok {
+		http.Error(w, "No leaderboard available", http.StatusNotFound)
+		return
+	}
 
-	log.Printf("SSE Client connected. Total: %d", len(s.clients))
+	if err := json.NewEncoder(w).Encode(board); err != nil {
+		http.Error(w, "Failed to encode leaderboard", http.StatusInternalServerError)
+	}
+}
 
-	defer func() {
-		s.clientsMutex.Lock()
-		delete(s.clients, clientChan)
-		s.clientsMutex.Unlock()
-		close(clientChan)
-		log.Printf("SSE Client disconnected")
-	}()
+// BroadcastEvent sends a payload to all connected SSE clients
+func (s *Server) BroadcastEvent(payload interface{}) {
+	s.BroadcastRoomEvent("", payload)
+}
 
-	// Flush immediately to establish connection
-	if flusher, ok := w.(http.Flusher); ok {
-		flusher.Flush()
+// BroadcastRoomEvent sends a payload to SSE and control clients of one room.
+// Clients without a room filter receive events of every room; an empty roomId reaches all clients.
+func (s *Server) BroadcastRoomEvent(roomId string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("[OverlayServer] Failed to marshal SSE payload: %v", err)
+		return
 	}
+	topic := eventTopic(data)
 
-	notify := r.Context().Done()
+	s.clientsMutex.Lock()
+	log.Printf("[OverlayServer] Broadcasting event (room: %q) to %d clients. Payload: %+v", roomId, len(s.clients), payload)
+	s.recordEvent(roomId, topic, data)
+	s.clientsMutex.Unlock()
 
-	for {
-		select {
-		case <-notify:
-			return
-		case data := <-clientChan:
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
-		}
-	}
+	s.broadcastControl(roomId, topic, data)
 }
@@ -0,0 +1,167 @@
+package overlayserver
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// replaySize bounds the events kept for clients resuming with Last-Event-ID
+const replaySize = 512
+
+// keepAliveInterval is how often an idle SSE stream gets a comment, so proxies don't close it
+var keepAliveInterval = 15 * time.Second
+
+// sseEvent is a broadcast event as kept in the replay buffer
+type sseEvent struct {
+	id     int64
+	roomId string // "" = all rooms
+	name   string // SSE event name: the control channel topic of the payload
+	data   []byte
+}
+
+// eventRing is a fixed-size buffer of the latest events, oldest first
+type eventRing struct {
+	events []sseEvent
+	start  int // Index of the oldest event once the buffer is full
+}
+
+func (r *eventRing) add(event sseEvent) {
+	if len(r.events) < replaySize {
+		r.events = append(r.events, event)
+		return
+	}
+	r.events[r.start] = event
+	r.start = (r.start + 1) % replaySize
+}
+
+// since returns the buffered events after lastId that a client of roomId receives.
+// missed is true when events after lastId were already dropped from the buffer.
+func (r *eventRing) since(lastId int64, roomId string) (events []sseEvent, missed bool) {
+	for i := range r.events {
+		event := r.events[(r.start+i)%len(r.events)]
+		if event.id <= lastId {
+			continue
+		}
+		if i == 0 && event.id > lastId+1 {
+			missed = true
+		}
+		if roomId != "" && event.roomId != "" && event.roomId != roomId {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, missed
+}
+
+// sseClient is one SSE connection; wake is signalled when new events are buffered
+type sseClient struct {
+	roomId string // "" = all rooms
+	wake   chan struct{}
+}
+
+// recordEvent assigns the next event id, buffers the event and wakes the SSE clients.
+// Caller must hold clientsMutex for writing.
+func (s *Server) recordEvent(roomId, name string, data []byte) {
+	s.lastEventId++
+	s.history.add(sseEvent{id: s.lastEventId, roomId: roomId, name: name, data: data})
+
+	for client := range s.clients {
+		select {
+		case client.wake <- struct{}{}:
+		default: // Already woken; it reads everything buffered since its last event
+		}
+	}
+}
+
+// pendingEvents returns the events a client hasn't received yet and the id to resume from next
+func (s *Server) pendingEvents(client *sseClient, lastId int64) ([]sseEvent, int64) {
+	s.clientsMutex.RLock()
+	defer s.clientsMutex.RUnlock()
+
+	events, missed := s.history.since(lastId, client.roomId)
+	if missed {
+		log.Printf("[OverlayServer] SSE client (room: %q) fell behind the replay buffer, events after %d were lost", client.roomId, lastId)
+	}
+	return events, s.lastEventId
+}
+
+// handleEvents manages SSE connections. Clients resume after the Last-Event-ID header,
+// or ?lastEventId= for overlays that reloaded, from the replay buffer.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	// CORS and SSE headers
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = r.URL.Query().Get("lastEventId")
+	}
+
+	client := &sseClient{roomId: r.URL.Query().Get("roomId"), wake: make(chan struct{}, 1)}
+
+	s.clientsMutex.Lock()
+	s.clients[client] = true
+	lastId := s.lastEventId
+	if resume != "" {
+		// Ids ahead of ours are from before the app restarted: nothing to replay
+		if id, err := strconv.ParseInt(resume, 10, 64); err == nil && id >= 0 && id < lastId {
+			lastId = id
+		}
+	}
+	total := len(s.clients)
+	s.clientsMutex.Unlock()
+
+	log.Printf("SSE Client connected (room: %q, resuming after %d). Total: %d", client.roomId, lastId, total)
+
+	defer func() {
+		s.clientsMutex.Lock()
+		delete(s.clients, client)
+		s.clientsMutex.Unlock()
+		log.Printf("SSE Client disconnected")
+	}()
+
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	// Flush immediately to establish connection
+	flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	// Replay what the client missed, then wait for new events
+	select {
+	case client.wake <- struct{}{}:
+	default:
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flush()
+		case <-client.wake:
+			events, newest := s.pendingEvents(client, lastId)
+			for _, event := range events {
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.id, event.name, event.data); err != nil {
+					return
+				}
+			}
+			lastId = newest
+			flush()
+		}
+	}
+}
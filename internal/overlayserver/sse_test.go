@@ -0,0 +1,136 @@
+package overlayserver
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseFrame is one event or comment read from an SSE stream
+type sseFrame struct {
+	id, event, data, comment string
+}
+
+func openEvents(t *testing.T, ts *httptest.Server, query, lastEventId string) *bufio.Reader {
+	t.Helper()
+	req, err := http.NewRequest("GET", ts.URL+"/events?"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return bufio.NewReader(resp.Body)
+}
+
+func readFrame(t *testing.T, r *bufio.Reader) sseFrame {
+	t.Helper()
+	done := make(chan sseFrame, 1)
+	go func() {
+		var frame sseFrame
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(done)
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				done <- frame
+				return
+			case strings.HasPrefix(line, ":"):
+				frame.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "id: "):
+				frame.id = line[4:]
+			case strings.HasPrefix(line, "event: "):
+				frame.event = line[7:]
+			case strings.HasPrefix(line, "data: "):
+				frame.data = line[6:]
+			}
+		}
+	}()
+	select {
+	case frame, ok := <-done:
+		if !ok {
+			t.Fatal("SSE stream closed")
+		}
+		return frame
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for SSE frame")
+		return sseFrame{}
+	}
+}
+
+func TestEvents_IdsAndResume(t *testing.T) {
+	s, ts := startTestServer(t)
+
+	s.BroadcastRoomEvent("room-1", map[string]string{"type": "GIFT", "n": "1"})
+	s.BroadcastRoomEvent("room-2", map[string]string{"type": "GIFT", "n": "2"})
+	s.BroadcastRoomEvent("room-1", map[string]string{"type": "TIMER_START", "n": "3"})
+
+	// Resuming after the first event replays the rest of the room, skipping other rooms
+	stream := openEvents(t, ts, "roomId=room-1", "1")
+	frame := readFrame(t, stream)
+	if frame.id != "3" || frame.event != TopicTimer || !strings.Contains(frame.data, `"n":"3"`) {
+		t.Fatalf("Expected replay of event 3 on timer, got %+v", frame)
+	}
+
+	s.BroadcastRoomEvent("room-1", map[string]string{"type": "LEADERBOARD_UPDATE"})
+	if frame := readFrame(t, stream); frame.id != "4" || frame.event != TopicScores {
+		t.Fatalf("Expected live event 4 on scores, got %+v", frame)
+	}
+
+	// A new client without Last-Event-ID only gets new events
+	fresh := openEvents(t, ts, "roomId=room-1", "")
+	s.BroadcastRoomEvent("room-1", map[string]string{"type": "GIFT"})
+	if frame := readFrame(t, fresh); frame.id != "5" || frame.event != TopicGifts {
+		t.Fatalf("Expected event 5 on gifts, got %+v", frame)
+	}
+
+	// An id from before a restart is ignored instead of waiting for it
+	stale := openEvents(t, ts, "roomId=room-1&lastEventId=99", "")
+	s.BroadcastRoomEvent("room-1", map[string]string{"type": "GIFT"})
+	if frame := readFrame(t, stale); frame.id != "6" {
+		t.Fatalf("Expected event 6 after stale id, got %+v", frame)
+	}
+}
+
+func TestEvents_KeepAlive(t *testing.T) {
+	defer func(d time.Duration) { keepAliveInterval = d }(keepAliveInterval)
+	keepAliveInterval = 20 * time.Millisecond
+
+	_, ts := startTestServer(t)
+	stream := openEvents(t, ts, "", "")
+	if frame := readFrame(t, stream); frame.comment != "keep-alive" {
+		t.Fatalf("Expected keep-alive comment, got %+v", frame)
+	}
+}
+
+func TestEventRing_Overflow(t *testing.T) {
+	var ring eventRing
+	for id := int64(1); id <= replaySize+10; id++ {
+		ring.add(sseEvent{id: id})
+	}
+
+	events, missed := ring.since(5, "")
+	if !missed {
+		t.Error("Expected events after 5 to be reported as missed")
+	}
+	if len(events) != replaySize || events[0].id != 11 {
+		t.Fatalf("Expected %d events from id 11, got %d from %d", replaySize, len(events), events[0].id)
+	}
+
+	events, missed = ring.since(replaySize+8, "")
+	if missed || len(events) != 2 || events[1].id != replaySize+10 {
+		t.Fatalf("Expected the last 2 events, got %d (missed %v)", len(events), missed)
+	}
+}
@@ -10,6 +10,11 @@ import (
 	"time"
 
 	"bbapp/internal/api"
+	"bbapp/internal/dance"
+	"bbapp/internal/filter"
+	"bbapp/internal/goals"
+	"bbapp/internal/rules"
+	"bbapp/internal/sinks"
 
 	"github.com/google/uuid"
 )
@@ -258,6 +263,160 @@ func (m *Manager) UpdateProfileBigoInfo(id, avatar, nickname string) (*Profile,
 	return profile, nil
 }
 
+// UpdateProfileFilters updates a profile's event filter rules
+func (m *Manager) UpdateProfileFilters(id string, rules filter.Rules) (*Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find profile
+	profile, exists := m.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	// Update rules and timestamp
+	profile.Filters = rules
+	profile.UpdatedAt = time.Now()
+
+	// Save to disk
+	if err := m.saveProfilesLocked(); err != nil {
+		return nil, fmt.Errorf("save profiles: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfileStickerDance validates and updates a profile's Sticker Dance setup
+func (m *Manager) UpdateProfileStickerDance(id string, cfg dance.Config) (*Profile, error) {
+	if err := dance.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid sticker dance config: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find profile
+	profile, exists := m.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	// Update setup and timestamp
+	profile.StickerDance = cfg
+	profile.UpdatedAt = time.Now()
+
+	// Save to disk
+	if err := m.saveProfilesLocked(); err != nil {
+		return nil, fmt.Errorf("save profiles: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfileGoals validates and updates a profile's Free Mode goals
+func (m *Manager) UpdateProfileGoals(id string, cfg goals.Config) (*Profile, error) {
+	if err := goals.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid goals: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find profile
+	profile, exists := m.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	// Update goals and timestamp
+	profile.Goals = cfg
+	profile.UpdatedAt = time.Now()
+
+	// Save to disk
+	if err := m.saveProfilesLocked(); err != nil {
+		return nil, fmt.Errorf("save profiles: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfileRules validates and updates a profile's trigger/action rules
+func (m *Manager) UpdateProfileRules(id string, set rules.RuleSet) (*Profile, error) {
+	if err := rules.Validate(set); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find profile
+	profile, exists := m.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	// Update rules and timestamp
+	profile.Rules = set
+	profile.UpdatedAt = time.Now()
+
+	// Save to disk
+	if err := m.saveProfilesLocked(); err != nil {
+		return nil, fmt.Errorf("save profiles: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfileSinks validates and updates a profile's event sinks
+func (m *Manager) UpdateProfileSinks(id string, cfg sinks.Config) (*Profile, error) {
+	if err := sinks.Validate(cfg); err != nil {
+		return nil, fmt.Errorf("invalid sinks: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find profile
+	profile, exists := m.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	// Update sinks and timestamp
+	profile.Sinks = cfg
+	profile.UpdatedAt = time.Now()
+
+	// Save to disk
+	if err := m.saveProfilesLocked(); err != nil {
+		return nil, fmt.Errorf("save profiles: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateProfileEnvironment tags a profile with the BB-Core environment its room lives in
+func (m *Manager) UpdateProfileEnvironment(id, environmentID string) (*Profile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Find profile
+	profile, exists := m.profiles[id]
+	if !exists {
+		return nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	// Update environment and timestamp
+	profile.EnvironmentID = environmentID
+	profile.UpdatedAt = time.Now()
+
+	// Save to disk
+	if err := m.saveProfilesLocked(); err != nil {
+		return nil, fmt.Errorf("save profiles: %w", err)
+	}
+
+	return profile, nil
+}
+
 // DeleteProfile deletes a profile by ID
 func (m *Manager) DeleteProfile(id string) error {
 	m.mu.Lock()
@@ -6,6 +6,11 @@ import (
 	"testing"
 	"time"
 	"bbapp/internal/api"
+	"bbapp/internal/dance"
+	"bbapp/internal/filter"
+	"bbapp/internal/goals"
+	"bbapp/internal/rules"
+	"bbapp/internal/sinks"
 )
 
 func TestNewManager(t *testing.T) {
@@ -392,3 +397,193 @@ func TestManager_ListProfiles_NullLastUsedAtLast(t *testing.T) {
 		t.Errorf("Second profile should be p2 (without LastUsedAt), got %s", profiles[1].ID)
 	}
 }
+
+func TestManager_UpdateProfileFilters(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	created, err := mgr.CreateProfile("Test Profile", "room-123", testConfig())
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	rules := filter.Rules{
+		Senders: filter.SenderRules{Blocked: []string{"spammer"}},
+	}
+	if _, err := mgr.UpdateProfileFilters(created.ID, rules); err != nil {
+		t.Fatalf("UpdateProfileFilters() error = %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewManager(tmpDir)
+	loaded, err := reloaded.LoadProfile(created.ID)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if len(loaded.Filters.Senders.Blocked) != 1 || loaded.Filters.Senders.Blocked[0] != "spammer" {
+		t.Errorf("Filters.Senders.Blocked = %v, want [spammer]", loaded.Filters.Senders.Blocked)
+	}
+}
+
+func TestManager_UpdateProfileStickerDance(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	created, err := mgr.CreateProfile("Test Profile", "room-123", testConfig())
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	invalid := dance.Config{Mappings: []dance.Mapping{{GiftName: "Rose", ActionId: "missing"}}}
+	if _, err := mgr.UpdateProfileStickerDance(created.ID, invalid); err == nil {
+		t.Error("UpdateProfileStickerDance() expected error for unknown action")
+	}
+
+	cfg := dance.Config{
+		Actions:  []dance.Action{{Id: "wave", Name: "Wave", DurationSeconds: 10}},
+		Mappings: []dance.Mapping{{GiftName: "Rose", ActionId: "wave"}},
+	}
+	if _, err := mgr.UpdateProfileStickerDance(created.ID, cfg); err != nil {
+		t.Fatalf("UpdateProfileStickerDance() error = %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewManager(tmpDir)
+	loaded, err := reloaded.LoadProfile(created.ID)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if len(loaded.StickerDance.Mappings) != 1 || loaded.StickerDance.Mappings[0].ActionId != "wave" {
+		t.Errorf("StickerDance.Mappings = %v, want one mapping to wave", loaded.StickerDance.Mappings)
+	}
+}
+
+func TestManager_UpdateProfileGoals(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	created, err := mgr.CreateProfile("Test Profile", "room-123", testConfig())
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	if _, err := mgr.UpdateProfileGoals(created.ID, goals.Config{Goals: []goals.Goal{{Id: "kiss"}}}); err == nil {
+		t.Error("UpdateProfileGoals() expected error for missing target")
+	}
+
+	cfg := goals.Config{Goals: []goals.Goal{{Id: "kiss", GiftName: "Kiss", Target: 1000, Reward: "dance"}}}
+	if _, err := mgr.UpdateProfileGoals(created.ID, cfg); err != nil {
+		t.Fatalf("UpdateProfileGoals() error = %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewManager(tmpDir)
+	loaded, err := reloaded.LoadProfile(created.ID)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if len(loaded.Goals.Goals) != 1 || loaded.Goals.Goals[0].Target != 1000 {
+		t.Errorf("Goals = %v, want one goal with target 1000", loaded.Goals.Goals)
+	}
+}
+
+func TestManager_UpdateProfileRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	created, err := mgr.CreateProfile("Test Profile", "room-123", testConfig())
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	invalid := rules.RuleSet{Rules: []rules.Rule{{Id: "bad", When: rules.Condition{ChatPattern: "("}}}}
+	if _, err := mgr.UpdateProfileRules(created.ID, invalid); err == nil {
+		t.Error("UpdateProfileRules() expected error for invalid rule")
+	}
+
+	set := rules.RuleSet{Rules: []rules.Rule{{
+		Id:      "big",
+		Enabled: true,
+		When:    rules.Condition{MinDiamonds: 1000},
+		Actions: []rules.Action{{Type: rules.ActionOverlay, Event: "BIG_GIFT"}},
+	}}}
+	if _, err := mgr.UpdateProfileRules(created.ID, set); err != nil {
+		t.Fatalf("UpdateProfileRules() error = %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewManager(tmpDir)
+	loaded, err := reloaded.LoadProfile(created.ID)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if len(loaded.Rules.Rules) != 1 || loaded.Rules.Rules[0].When.MinDiamonds != 1000 {
+		t.Errorf("Rules = %v, want one rule with minDiamonds 1000", loaded.Rules.Rules)
+	}
+}
+
+func TestManager_UpdateProfileSinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	created, err := mgr.CreateProfile("Test Profile", "room-123", testConfig())
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+
+	invalid := sinks.Config{Sinks: []sinks.SinkConfig{{Id: "hook", Type: sinks.TypeWebhook}}}
+	if _, err := mgr.UpdateProfileSinks(created.ID, invalid); err == nil {
+		t.Error("UpdateProfileSinks() expected error for webhook without url")
+	}
+
+	cfg := sinks.Config{Sinks: []sinks.SinkConfig{{
+		Id:      "hook",
+		Type:    sinks.TypeWebhook,
+		Enabled: true,
+		URL:     "https://partner.example.com/gifts",
+		Secret:  "s3cret",
+	}}}
+	if _, err := mgr.UpdateProfileSinks(created.ID, cfg); err != nil {
+		t.Fatalf("UpdateProfileSinks() error = %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewManager(tmpDir)
+	loaded, err := reloaded.LoadProfile(created.ID)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if len(loaded.Sinks.Sinks) != 1 || loaded.Sinks.Sinks[0].Secret != "s3cret" {
+		t.Errorf("Sinks = %v, want one webhook sink with its secret", loaded.Sinks.Sinks)
+	}
+}
+
+func TestManager_UpdateProfileEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+
+	created, err := mgr.CreateProfile("Test Profile", "room-123", testConfig())
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	if created.EnvironmentID != "" {
+		t.Errorf("EnvironmentID = %q, want untagged", created.EnvironmentID)
+	}
+
+	if _, err := mgr.UpdateProfileEnvironment(created.ID, "staging"); err != nil {
+		t.Fatalf("UpdateProfileEnvironment() error = %v", err)
+	}
+	if _, err := mgr.UpdateProfileEnvironment("missing", "staging"); err == nil {
+		t.Error("UpdateProfileEnvironment() expected error for unknown profile")
+	}
+
+	// Reload from disk to verify persistence
+	reloaded := NewManager(tmpDir)
+	loaded, err := reloaded.LoadProfile(created.ID)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if loaded.EnvironmentID != "staging" {
+		t.Errorf("EnvironmentID = %q, want staging", loaded.EnvironmentID)
+	}
+}
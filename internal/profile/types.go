@@ -2,18 +2,29 @@ package profile
 
 import (
 	"bbapp/internal/api"
+	"bbapp/internal/dance"
+	"bbapp/internal/filter"
+	"bbapp/internal/goals"
+	"bbapp/internal/rules"
+	"bbapp/internal/sinks"
 	"time"
 )
 
 // Profile represents a saved configuration profile
 type Profile struct {
-	ID           string     `json:"id"`           // UUID
-	Name         string     `json:"name"`         // User-friendly name
-	RoomID       string     `json:"roomId"`       // BB-Core room ID
-	CreatedAt    time.Time  `json:"createdAt"`    // Creation timestamp
-	UpdatedAt    time.Time  `json:"updatedAt"`    // Last update timestamp
-	LastUsedAt   *time.Time `json:"lastUsedAt"`   // Last time profile was loaded (nullable)
-	BigoAvatar   string     `json:"bigoAvatar"`   // Bigo Room Avatar
-	BigoNickName string     `json:"bigoNickName"` // Bigo Room Nickname
-	Config       api.Config `json:"config"`       // Cached BB-Core config
+	ID            string        `json:"id"`            // UUID
+	Name          string        `json:"name"`          // User-friendly name
+	RoomID        string        `json:"roomId"`        // BB-Core room ID
+	EnvironmentID string        `json:"environmentId"` // BB-Core environment the room lives in ("" = from before environments)
+	CreatedAt     time.Time     `json:"createdAt"`     // Creation timestamp
+	UpdatedAt     time.Time     `json:"updatedAt"`     // Last update timestamp
+	LastUsedAt    *time.Time    `json:"lastUsedAt"`    // Last time profile was loaded (nullable)
+	BigoAvatar    string        `json:"bigoAvatar"`    // Bigo Room Avatar
+	BigoNickName  string        `json:"bigoNickName"`  // Bigo Room Nickname
+	Config        api.Config    `json:"config"`        // Cached BB-Core config
+	Filters       filter.Rules  `json:"filters"`       // Sender/anti-spam rules for the event pipeline
+	StickerDance  dance.Config  `json:"stickerDance"`  // Gift -> dance action setup for Sticker Dance mode
+	Goals         goals.Config  `json:"goals"`         // Gift goals for Free Mode
+	Rules         rules.RuleSet `json:"rules"`         // Trigger/action rules
+	Sinks         sinks.Config  `json:"sinks"`         // Partner webhook/file/broker event sinks
 }
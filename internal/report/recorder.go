@@ -0,0 +1,302 @@
+package report
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"bbapp/internal/api"
+	"bbapp/internal/leaderboard"
+	"bbapp/internal/listener"
+)
+
+// Meta is the session information only known when the session stops
+type Meta struct {
+	RoomId      string
+	SessionId   string
+	StopReason  string
+	Config      *api.Config // Used for team/streamer names
+	TopGifters  []leaderboard.Entry
+	BBCoreFinal map[string]interface{}
+}
+
+// connectionTrack accumulates the uptime of one Bigo connection
+type connectionTrack struct {
+	idolName  string
+	status    string
+	upSince   time.Time // zero while not connected
+	uptime    time.Duration
+	connects  int
+	errors    []ConnectionError
+	firstSeen time.Time
+}
+
+// Recorder collects session activity for the end-of-session report
+type Recorder struct {
+	startedAt   time.Time
+	gifts       []GiftRecord
+	chats       []ChatRecord
+	chatMinutes map[int64]int64 // unix minute -> messages
+	chatByRoom  map[string]int64
+	chatTotal   int64
+	connections map[string]*connectionTrack
+	mutex       sync.Mutex
+}
+
+// NewRecorder creates a recorder for a session starting now
+func NewRecorder() *Recorder {
+	return &Recorder{
+		startedAt:   time.Now(),
+		chatMinutes: make(map[int64]int64),
+		chatByRoom:  make(map[string]int64),
+		connections: make(map[string]*connectionTrack),
+	}
+}
+
+// RecordGift adds a gift attributed to a team and streamer (either may be empty)
+func (r *Recorder) RecordGift(gift listener.BigoGift, teamId, streamerId string) {
+	at := time.Now()
+	if gift.Timestamp > 0 {
+		at = time.UnixMilli(gift.Timestamp)
+	}
+
+	count := gift.GiftCount
+	if count <= 0 {
+		count = 1
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.gifts = append(r.gifts, GiftRecord{
+		At:         at,
+		BigoRoomId: gift.BigoRoomId,
+		SenderId:   gift.SenderId,
+		SenderName: gift.SenderName,
+		GiftName:   gift.GiftName,
+		GiftCount:  count,
+		Diamonds:   gift.Diamonds,
+		TeamId:     teamId,
+		StreamerId: streamerId,
+	})
+}
+
+// RecordChat counts a chat message
+func (r *Recorder) RecordChat(chat listener.BigoChat) {
+	at := time.Now()
+	if chat.Timestamp > 0 {
+		at = time.UnixMilli(chat.Timestamp)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.chatTotal++
+	r.chatByRoom[chat.BigoRoomId]++
+	r.chatMinutes[at.Unix()/60]++
+	r.chats = append(r.chats, ChatRecord{
+		At:         at,
+		BigoRoomId: chat.BigoRoomId,
+		SenderId:   chat.SenderId,
+		SenderName: chat.SenderName,
+		Message:    chat.Message,
+	})
+}
+
+// RecordConnection tracks a status change (CONNECTING, CONNECTED, DISCONNECTED, ERROR) of a Bigo connection
+func (r *Recorder) RecordConnection(key, idolName, status, errorMsg string) {
+	now := time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	track, ok := r.connections[key]
+	if !ok {
+		track = &connectionTrack{firstSeen: now}
+		r.connections[key] = track
+	}
+	if idolName != "" {
+		track.idolName = idolName
+	}
+
+	if status == "CONNECTED" {
+		if track.upSince.IsZero() {
+			track.upSince = now
+			track.connects++
+		}
+	} else if !track.upSince.IsZero() {
+		track.uptime += now.Sub(track.upSince)
+		track.upSince = time.Time{}
+	}
+
+	if errorMsg != "" {
+		track.errors = append(track.errors, ConnectionError{At: now, Message: errorMsg})
+	}
+	track.status = status
+}
+
+// Build assembles the report at the end of the session
+func (r *Recorder) Build(meta Meta) Report {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	endedAt := time.Now()
+	rep := Report{
+		RoomId:      meta.RoomId,
+		SessionId:   meta.SessionId,
+		StartedAt:   r.startedAt,
+		EndedAt:     endedAt,
+		StopReason:  meta.StopReason,
+		TopGifters:  meta.TopGifters,
+		BBCoreFinal: meta.BBCoreFinal,
+		Gifts:       append([]GiftRecord{}, r.gifts...),
+		Chats:       append([]ChatRecord{}, r.chats...),
+		Chat:        ChatVolume{Total: r.chatTotal, ByRoom: make(map[string]int64, len(r.chatByRoom))},
+	}
+	for room, n := range r.chatByRoom {
+		rep.Chat.ByRoom[room] = n
+	}
+	if rep.TopGifters == nil {
+		rep.TopGifters = []leaderboard.Entry{}
+	}
+
+	rep.Timeline = r.timeline()
+	rep.Teams, rep.Streamers = r.totals(meta.Config)
+	rep.Connections = r.connectionSummaries(endedAt)
+
+	senders := make(map[string]bool)
+	for _, g := range r.gifts {
+		rep.Totals.Diamonds += g.Diamonds
+		rep.Totals.Gifts += int64(g.GiftCount)
+		senders[g.SenderId] = true
+	}
+	rep.Totals.Chats = r.chatTotal
+	rep.Totals.Senders = len(senders)
+
+	return rep
+}
+
+// timeline buckets gifts and chats per minute. Caller must hold the lock.
+func (r *Recorder) timeline() []TimelineBucket {
+	buckets := make(map[int64]*TimelineBucket)
+	bucket := func(minute int64) *TimelineBucket {
+		b, ok := buckets[minute]
+		if !ok {
+			b = &TimelineBucket{Minute: time.Unix(minute*60, 0)}
+			buckets[minute] = b
+		}
+		return b
+	}
+
+	for _, g := range r.gifts {
+		b := bucket(g.At.Unix() / 60)
+		b.Diamonds += g.Diamonds
+		b.Gifts += int64(g.GiftCount)
+	}
+	for minute, n := range r.chatMinutes {
+		bucket(minute).Chats += n
+	}
+
+	timeline := make([]TimelineBucket, 0, len(buckets))
+	for _, b := range buckets {
+		timeline = append(timeline, *b)
+	}
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Minute.Before(timeline[j].Minute)
+	})
+	return timeline
+}
+
+// totals sums diamonds per team and streamer, listing every configured team/streamer. Caller must hold the lock.
+func (r *Recorder) totals(cfg *api.Config) ([]TeamTotal, []StreamerTotal) {
+	teams := make(map[string]*TeamTotal)
+	streamers := make(map[string]*StreamerTotal)
+	var teamOrder, streamerOrder []string
+
+	addTeam := func(id, name string) *TeamTotal {
+		if t, ok := teams[id]; ok {
+			return t
+		}
+		t := &TeamTotal{TeamId: id, Name: name}
+		teams[id] = t
+		teamOrder = append(teamOrder, id)
+		return t
+	}
+	addStreamer := func(id, name, teamId string) *StreamerTotal {
+		if s, ok := streamers[id]; ok {
+			return s
+		}
+		s := &StreamerTotal{StreamerId: id, Name: name, TeamId: teamId}
+		streamers[id] = s
+		streamerOrder = append(streamerOrder, id)
+		return s
+	}
+
+	if cfg != nil {
+		for _, team := range cfg.Teams {
+			addTeam(team.TeamId, team.Name)
+			for _, s := range team.Streamers {
+				addStreamer(s.StreamerId, s.Name, team.TeamId)
+			}
+		}
+	}
+
+	for _, g := range r.gifts {
+		if g.TeamId != "" {
+			t := addTeam(g.TeamId, g.TeamId)
+			t.Diamonds += g.Diamonds
+			t.Gifts += int64(g.GiftCount)
+		}
+		if g.StreamerId != "" {
+			s := addStreamer(g.StreamerId, g.StreamerId, g.TeamId)
+			s.Diamonds += g.Diamonds
+			s.Gifts += int64(g.GiftCount)
+		}
+	}
+
+	teamTotals := make([]TeamTotal, 0, len(teamOrder))
+	for _, id := range teamOrder {
+		teamTotals = append(teamTotals, *teams[id])
+	}
+	streamerTotals := make([]StreamerTotal, 0, len(streamerOrder))
+	for _, id := range streamerOrder {
+		streamerTotals = append(streamerTotals, *streamers[id])
+	}
+	return teamTotals, streamerTotals
+}
+
+// connectionSummaries closes open uptime intervals at endedAt. Caller must hold the lock.
+func (r *Recorder) connectionSummaries(endedAt time.Time) []ConnectionSummary {
+	keys := make([]string, 0, len(r.connections))
+	for key := range r.connections {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	summaries := make([]ConnectionSummary, 0, len(keys))
+	for _, key := range keys {
+		track := r.connections[key]
+		uptime := track.uptime
+		if !track.upSince.IsZero() {
+			uptime += endedAt.Sub(track.upSince)
+		}
+
+		var percent float64
+		if span := endedAt.Sub(track.firstSeen); span > 0 {
+			percent = float64(uptime) / float64(span) * 100
+		}
+
+		errors := track.errors
+		if errors == nil {
+			errors = []ConnectionError{}
+		}
+		summaries = append(summaries, ConnectionSummary{
+			BigoRoomId:    key,
+			IdolName:      track.idolName,
+			FinalStatus:   track.status,
+			UptimeSeconds: int64(uptime / time.Second),
+			UptimePercent: percent,
+			Connects:      track.connects,
+			Errors:        errors,
+		})
+	}
+	return summaries
+}
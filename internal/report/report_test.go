@@ -0,0 +1,132 @@
+package report_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bbapp/internal/api"
+	"bbapp/internal/listener"
+	"bbapp/internal/report"
+)
+
+func newTestReport() report.Report {
+	rec := report.NewRecorder()
+	rec.RecordConnection("idol1", "Idol One", "CONNECTING", "")
+	rec.RecordConnection("idol1", "", "CONNECTED", "")
+	rec.RecordConnection("idol2", "Idol Two", "ERROR", "browser crashed")
+
+	rec.RecordGift(listener.BigoGift{SenderId: "a", SenderName: "Alice", GiftName: "Rose", GiftCount: 2, Diamonds: 20, Timestamp: 60000}, "team1", "s1")
+	rec.RecordGift(listener.BigoGift{SenderId: "b", SenderName: "Bob", GiftName: "Car", GiftCount: 1, Diamonds: 100, Timestamp: 120000}, "team2", "")
+	rec.RecordChat(listener.BigoChat{BigoRoomId: "idol1", Message: "hi", Timestamp: 60000})
+
+	cfg := &api.Config{Teams: []api.Team{
+		{TeamId: "team1", Name: "Red", Streamers: []api.Streamer{{StreamerId: "s1", Name: "Streamer One"}}},
+		{TeamId: "team2", Name: "Blue"},
+	}}
+
+	return rec.Build(report.Meta{
+		RoomId:      "room1",
+		SessionId:   "sess-1",
+		StopReason:  "manual",
+		Config:      cfg,
+		BBCoreFinal: map[string]interface{}{"winner": "team2"},
+	})
+}
+
+func TestRecorder_Build(t *testing.T) {
+	rep := newTestReport()
+
+	if rep.Totals.Diamonds != 120 || rep.Totals.Gifts != 3 || rep.Totals.Chats != 1 || rep.Totals.Senders != 2 {
+		t.Errorf("Unexpected totals: %+v", rep.Totals)
+	}
+
+	if len(rep.Teams) != 2 || rep.Teams[0].Name != "Red" || rep.Teams[0].Diamonds != 20 || rep.Teams[1].Diamonds != 100 {
+		t.Errorf("Unexpected team totals: %+v", rep.Teams)
+	}
+	if len(rep.Streamers) != 1 || rep.Streamers[0].Diamonds != 20 {
+		t.Errorf("Unexpected streamer totals: %+v", rep.Streamers)
+	}
+
+	if len(rep.Timeline) != 2 || rep.Timeline[0].Chats != 1 || rep.Timeline[1].Diamonds != 100 {
+		t.Errorf("Unexpected timeline: %+v", rep.Timeline)
+	}
+
+	if len(rep.Chats) != 1 || rep.Chats[0].Message != "hi" {
+		t.Errorf("Expected chat message to be recorded, got %+v", rep.Chats)
+	}
+
+	if len(rep.Connections) != 2 {
+		t.Fatalf("Expected 2 connections, got %d", len(rep.Connections))
+	}
+	if rep.Connections[0].Connects != 1 || rep.Connections[0].FinalStatus != "CONNECTED" {
+		t.Errorf("Unexpected idol1 summary: %+v", rep.Connections[0])
+	}
+	if len(rep.Connections[1].Errors) != 1 || rep.Connections[1].Errors[0].Message != "browser crashed" {
+		t.Errorf("Expected idol2 error to be recorded, got %+v", rep.Connections[1].Errors)
+	}
+}
+
+func TestWriteAndList(t *testing.T) {
+	baseDir := t.TempDir()
+	rep := newTestReport()
+
+	summary, err := report.Write(baseDir+"/room1", rep)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	csvData, err := os.ReadFile(summary.CSVPath)
+	if err != nil {
+		t.Fatalf("ReadFile CSV failed: %v", err)
+	}
+	if lines := strings.Count(string(csvData), "\n"); lines != 3 {
+		t.Errorf("Expected header + 2 gift rows, got %d lines", lines)
+	}
+
+	htmlData, err := os.ReadFile(summary.HTMLPath)
+	if err != nil {
+		t.Fatalf("ReadFile HTML failed: %v", err)
+	}
+	if !strings.Contains(string(htmlData), "Streamer One") {
+		t.Error("Expected HTML report to contain streamer name")
+	}
+
+	summaries, err := report.List(baseDir, "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].SessionId != "sess-1" || summaries[0].Diamonds != 120 {
+		t.Errorf("Unexpected summaries: %+v", summaries)
+	}
+
+	loaded, err := report.Load(summaries[0].JSONPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.BBCoreFinal["winner"] != "team2" {
+		t.Errorf("Expected BB-Core final data to round-trip, got %+v", loaded.BBCoreFinal)
+	}
+}
+
+func TestList_RejectsRoomIdsOutsideBaseDir(t *testing.T) {
+	root := t.TempDir()
+	baseDir := filepath.Join(root, "reports")
+	if _, err := report.Write(filepath.Join(root, "other"), newTestReport()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	for _, roomId := range []string{"..", "../other", `..\other`, "*"} {
+		if summaries, err := report.List(baseDir, roomId); err == nil {
+			t.Errorf("List(%q) = %+v, want an error", roomId, summaries)
+		}
+	}
+}
+
+func TestFileName_Sanitizes(t *testing.T) {
+	name := report.FileName(report.Report{SessionId: "../evil/id"})
+	if strings.Contains(name, "/") || strings.Contains(name, "..") {
+		t.Errorf("Expected sanitized file name, got %q", name)
+	}
+}
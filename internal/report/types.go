@@ -0,0 +1,116 @@
+package report
+
+import (
+	"time"
+
+	"bbapp/internal/leaderboard"
+)
+
+// Report is the end-of-session summary written to ./reports/<room>/<session>.{json,csv,html}
+type Report struct {
+	RoomId      string                 `json:"roomId"`
+	SessionId   string                 `json:"sessionId"`
+	StartedAt   time.Time              `json:"startedAt"`
+	EndedAt     time.Time              `json:"endedAt"`
+	StopReason  string                 `json:"stopReason"`
+	Totals      Totals                 `json:"totals"`
+	Timeline    []TimelineBucket       `json:"timeline"`
+	Teams       []TeamTotal            `json:"teams"`
+	Streamers   []StreamerTotal        `json:"streamers"`
+	TopGifters  []leaderboard.Entry    `json:"topGifters"`
+	Chat        ChatVolume             `json:"chat"`
+	Connections []ConnectionSummary    `json:"connections"`
+	BBCoreFinal map[string]interface{} `json:"bbcoreFinal,omitempty"` // finalData returned by BB-Core on stop
+	Gifts       []GiftRecord           `json:"gifts"`
+	Chats       []ChatRecord           `json:"chats"`
+}
+
+// Totals are the headline numbers of a session
+type Totals struct {
+	Diamonds int64 `json:"diamonds"`
+	Gifts    int64 `json:"gifts"`
+	Chats    int64 `json:"chats"`
+	Senders  int   `json:"senders"`
+}
+
+// TimelineBucket aggregates activity per minute of the session
+type TimelineBucket struct {
+	Minute   time.Time `json:"minute"`
+	Diamonds int64     `json:"diamonds"`
+	Gifts    int64     `json:"gifts"`
+	Chats    int64     `json:"chats"`
+}
+
+// TeamTotal is the diamond total of a team
+type TeamTotal struct {
+	TeamId   string `json:"teamId"`
+	Name     string `json:"name"`
+	Diamonds int64  `json:"diamonds"`
+	Gifts    int64  `json:"gifts"`
+}
+
+// StreamerTotal is the diamond total of a streamer
+type StreamerTotal struct {
+	StreamerId string `json:"streamerId"`
+	Name       string `json:"name"`
+	TeamId     string `json:"teamId"`
+	Diamonds   int64  `json:"diamonds"`
+	Gifts      int64  `json:"gifts"`
+}
+
+// ChatVolume counts chat messages overall and per Bigo room
+type ChatVolume struct {
+	Total  int64            `json:"total"`
+	ByRoom map[string]int64 `json:"byRoom"` // bigoRoomId -> messages
+}
+
+// ConnectionSummary describes the uptime and errors of one Bigo connection
+type ConnectionSummary struct {
+	BigoRoomId    string            `json:"bigoRoomId"`
+	IdolName      string            `json:"idolName"`
+	FinalStatus   string            `json:"finalStatus"`
+	UptimeSeconds int64             `json:"uptimeSeconds"`
+	UptimePercent float64           `json:"uptimePercent"`
+	Connects      int               `json:"connects"`
+	Errors        []ConnectionError `json:"errors"`
+}
+
+// ConnectionError is an error reported by a Bigo connection
+type ConnectionError struct {
+	At      time.Time `json:"at"`
+	Message string    `json:"message"`
+}
+
+// GiftRecord is a single gift as counted in the report
+type GiftRecord struct {
+	At         time.Time `json:"at"`
+	BigoRoomId string    `json:"bigoRoomId"`
+	SenderId   string    `json:"senderId"`
+	SenderName string    `json:"senderName"`
+	GiftName   string    `json:"giftName"`
+	GiftCount  int       `json:"giftCount"`
+	Diamonds   int64     `json:"diamonds"`
+	TeamId     string    `json:"teamId"`
+	StreamerId string    `json:"streamerId"`
+}
+
+// ChatRecord is a single chat message as received
+type ChatRecord struct {
+	At         time.Time `json:"at"`
+	BigoRoomId string    `json:"bigoRoomId"`
+	SenderId   string    `json:"senderId"`
+	SenderName string    `json:"senderName"`
+	Message    string    `json:"message"`
+}
+
+// Summary describes a report on disk, as returned by List
+type Summary struct {
+	RoomId    string    `json:"roomId"`
+	SessionId string    `json:"sessionId"`
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+	Diamonds  int64     `json:"diamonds"`
+	JSONPath  string    `json:"jsonPath"`
+	CSVPath   string    `json:"csvPath"`
+	HTMLPath  string    `json:"htmlPath"`
+}
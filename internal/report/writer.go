@@ -0,0 +1,222 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName returns the base file name (without extension) used for a report
+func FileName(rep Report) string {
+	name := rep.SessionId
+	if name == "" {
+		name = "local-" + rep.StartedAt.Format("20060102-150405")
+	}
+	// Keep session IDs from escaping the room directory
+	return strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(name)
+}
+
+// Write stores the report as JSON, CSV and HTML in dir/<session>.{json,csv,html}
+func Write(dir string, rep Report) (Summary, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Summary{}, fmt.Errorf("create report directory: %w", err)
+	}
+
+	base := filepath.Join(dir, FileName(rep))
+	summary := summarize(rep, base)
+
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return Summary{}, fmt.Errorf("marshal report: %w", err)
+	}
+	if err := os.WriteFile(summary.JSONPath, data, 0644); err != nil {
+		return Summary{}, fmt.Errorf("write JSON report: %w", err)
+	}
+
+	if err := writeCSV(summary.CSVPath, rep); err != nil {
+		return Summary{}, err
+	}
+	if err := writeHTML(summary.HTMLPath, rep); err != nil {
+		return Summary{}, err
+	}
+	return summary, nil
+}
+
+// Load reads a JSON report
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+
+	var rep Report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, fmt.Errorf("unmarshal report: %w", err)
+	}
+	return &rep, nil
+}
+
+// ValidRoomId reports whether roomId names a single directory under the reports directory
+func ValidRoomId(roomId string) bool {
+	return roomId != "" && !strings.ContainsAny(roomId, `/\*?[`) && !strings.Contains(roomId, "..")
+}
+
+// List returns the reports under baseDir, newest first. An empty roomId lists all rooms.
+func List(baseDir, roomId string) ([]Summary, error) {
+	pattern := filepath.Join(baseDir, "*", "*.json")
+	if roomId != "" {
+		if !ValidRoomId(roomId) {
+			return nil, fmt.Errorf("invalid room ID %q", roomId)
+		}
+		pattern = filepath.Join(baseDir, roomId, "*.json")
+	}
+
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("list reports: %w", err)
+	}
+
+	summaries := make([]Summary, 0, len(paths))
+	for _, path := range paths {
+		rep, err := Load(path)
+		if err != nil {
+			fmt.Printf("[Report] WARNING: Skipping unreadable report %s: %v\n", path, err)
+			continue
+		}
+		summaries = append(summaries, summarize(*rep, strings.TrimSuffix(path, ".json")))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].EndedAt.After(summaries[j].EndedAt)
+	})
+	return summaries, nil
+}
+
+func summarize(rep Report, base string) Summary {
+	return Summary{
+		RoomId:    rep.RoomId,
+		SessionId: rep.SessionId,
+		StartedAt: rep.StartedAt,
+		EndedAt:   rep.EndedAt,
+		Diamonds:  rep.Totals.Diamonds,
+		JSONPath:  base + ".json",
+		CSVPath:   base + ".csv",
+		HTMLPath:  base + ".html",
+	}
+}
+
+// writeCSV writes one row per gift so the report can be opened in a spreadsheet
+func writeCSV(path string, rep Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create CSV report: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"time", "bigoRoomId", "senderId", "senderName", "giftName", "giftCount", "diamonds", "teamId", "streamerId"})
+	for _, g := range rep.Gifts {
+		w.Write([]string{
+			g.At.Format(time.RFC3339),
+			g.BigoRoomId,
+			g.SenderId,
+			g.SenderName,
+			g.GiftName,
+			strconv.Itoa(g.GiftCount),
+			strconv.FormatInt(g.Diamonds, 10),
+			g.TeamId,
+			g.StreamerId,
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("write CSV report: %w", err)
+	}
+	return nil
+}
+
+func writeHTML(path string, rep Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create HTML report: %w", err)
+	}
+	defer file.Close()
+
+	if err := htmlTemplate.Execute(file, rep); err != nil {
+		return fmt.Errorf("write HTML report: %w", err)
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"hhmm": func(t time.Time) string { return t.Format("15:04") },
+	"rank": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Session report {{.RoomId}} {{.SessionId}}</title>
+<style>
+body { font-family: sans-serif; margin: 24px; color: #222; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f0f0f0; }
+</style>
+</head>
+<body>
+<h1>Session report</h1>
+<p>Room <b>{{.RoomId}}</b>{{if .SessionId}}, session <b>{{.SessionId}}</b>{{end}}<br>
+{{time .StartedAt}} &ndash; {{time .EndedAt}}{{if .StopReason}} ({{.StopReason}}){{end}}</p>
+
+<h2>Totals</h2>
+<table>
+<tr><th>Diamonds</th><th>Gifts</th><th>Chats</th><th>Senders</th></tr>
+<tr><td>{{.Totals.Diamonds}}</td><td>{{.Totals.Gifts}}</td><td>{{.Totals.Chats}}</td><td>{{.Totals.Senders}}</td></tr>
+</table>
+
+<h2>Teams</h2>
+<table>
+<tr><th>Team</th><th>Diamonds</th><th>Gifts</th></tr>
+{{range .Teams}}<tr><td>{{.Name}}</td><td>{{.Diamonds}}</td><td>{{.Gifts}}</td></tr>
+{{end}}</table>
+
+<h2>Streamers</h2>
+<table>
+<tr><th>Streamer</th><th>Team</th><th>Diamonds</th><th>Gifts</th></tr>
+{{range .Streamers}}<tr><td>{{.Name}}</td><td>{{.TeamId}}</td><td>{{.Diamonds}}</td><td>{{.Gifts}}</td></tr>
+{{end}}</table>
+
+<h2>Top gifters</h2>
+<table>
+<tr><th>#</th><th>Sender</th><th>Diamonds</th><th>Gifts</th><th>First gift</th><th>Last gift</th></tr>
+{{range $i, $e := .TopGifters}}<tr><td>{{rank $i}}</td><td>{{$e.SenderName}}</td><td>{{$e.Diamonds}}</td><td>{{$e.GiftCount}}</td><td>{{time $e.FirstGiftAt}}</td><td>{{time $e.LastGiftAt}}</td></tr>
+{{end}}</table>
+
+<h2>Timeline</h2>
+<table>
+<tr><th>Minute</th><th>Diamonds</th><th>Gifts</th><th>Chats</th></tr>
+{{range .Timeline}}<tr><td>{{hhmm .Minute}}</td><td>{{.Diamonds}}</td><td>{{.Gifts}}</td><td>{{.Chats}}</td></tr>
+{{end}}</table>
+
+<h2>Connections</h2>
+<table>
+<tr><th>Room</th><th>Idol</th><th>Final status</th><th>Uptime</th><th>Connects</th><th>Errors</th></tr>
+{{range .Connections}}<tr><td>{{.BigoRoomId}}</td><td>{{.IdolName}}</td><td>{{.FinalStatus}}</td><td>{{printf "%.1f" .UptimePercent}}% ({{.UptimeSeconds}}s)</td><td>{{.Connects}}</td><td>{{range .Errors}}{{time .At}}: {{.Message}}<br>{{end}}</td></tr>
+{{end}}</table>
+
+{{if .BBCoreFinal}}<h2>BB-Core final data</h2>
+<table>
+{{range $k, $v := .BBCoreFinal}}<tr><th>{{$k}}</th><td>{{printf "%v" $v}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,270 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"bbapp/internal/listener"
+)
+
+// compiledRule is a validated rule with its chat pattern and time window parsed
+type compiledRule struct {
+	rule      Rule
+	pattern   *regexp.Regexp
+	window    bool
+	fromMin   int // minutes after midnight
+	toMin     int
+	lastFired time.Time
+}
+
+// Engine evaluates events against a rule set
+type Engine struct {
+	rules []*compiledRule
+	mutex sync.Mutex
+}
+
+// NewEngine validates and compiles a rule set
+func NewEngine(set RuleSet) (*Engine, error) {
+	e := &Engine{}
+	seen := make(map[string]bool, len(set.Rules))
+
+	for _, r := range set.Rules {
+		if r.Id == "" {
+			return nil, fmt.Errorf("rule %q has no id", r.Name)
+		}
+		if seen[r.Id] {
+			return nil, fmt.Errorf("duplicate rule id: %s", r.Id)
+		}
+		seen[r.Id] = true
+
+		compiled, err := compile(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Id, err)
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// Validate checks a rule set without keeping the engine
+func Validate(set RuleSet) error {
+	_, err := NewEngine(set)
+	return err
+}
+
+// Evaluate returns the actions fired by an event. Cooldowns are measured on event time.
+func (e *Engine) Evaluate(event Event) []Firing {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var firings []Firing
+	for _, r := range e.rules {
+		if !r.rule.Enabled || !r.matches(event) {
+			continue
+		}
+
+		if cooldown := time.Duration(r.rule.CooldownSeconds) * time.Second; cooldown > 0 && !r.lastFired.IsZero() {
+			if event.At.Sub(r.lastFired) < cooldown {
+				continue
+			}
+		}
+		r.lastFired = event.At
+
+		for _, action := range r.rule.Actions {
+			firings = append(firings, Firing{RuleId: r.rule.Id, RuleName: r.rule.Name, Action: action, Event: event})
+		}
+	}
+	return firings
+}
+
+// DryRun evaluates a rule set against recorded events without side effects
+func DryRun(set RuleSet, events []Event) ([]Firing, error) {
+	engine, err := NewEngine(set)
+	if err != nil {
+		return nil, err
+	}
+
+	firings := []Firing{}
+	for _, event := range events {
+		firings = append(firings, engine.Evaluate(event)...)
+	}
+	return firings, nil
+}
+
+// FromListener normalizes a listener gift or chat event. teamId and streamerId come from attribution.
+func FromListener(event interface{}, teamId, streamerId string) (Event, bool) {
+	switch e := event.(type) {
+	case listener.BigoGift:
+		return Event{
+			Type:       EventGift,
+			BigoRoomId: e.BigoRoomId,
+			SenderId:   e.SenderId,
+			SenderName: e.SenderName,
+			TeamId:     teamId,
+			StreamerId: streamerId,
+			GiftId:     e.GiftId,
+			GiftName:   e.GiftName,
+			GiftCount:  e.GiftCount,
+			Diamonds:   e.Diamonds,
+			At:         eventTime(e.Timestamp),
+		}, true
+	case listener.BigoChat:
+		return Event{
+			Type:       EventChat,
+			BigoRoomId: e.BigoRoomId,
+			SenderId:   e.SenderId,
+			SenderName: e.SenderName,
+			TeamId:     teamId,
+			StreamerId: streamerId,
+			Message:    e.Message,
+			At:         eventTime(e.Timestamp),
+		}, true
+	}
+	return Event{}, false
+}
+
+func compile(r Rule) (*compiledRule, error) {
+	c := &compiledRule{rule: r}
+
+	switch r.When.EventType {
+	case "", EventGift, EventChat:
+	default:
+		return nil, fmt.Errorf("unknown event type %q", r.When.EventType)
+	}
+
+	if r.When.MaxDiamonds > 0 && r.When.MinDiamonds > r.When.MaxDiamonds {
+		return nil, fmt.Errorf("minDiamonds is greater than maxDiamonds")
+	}
+
+	if r.When.ChatPattern != "" {
+		pattern, err := regexp.Compile(r.When.ChatPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat pattern: %w", err)
+		}
+		c.pattern = pattern
+	}
+
+	if r.When.TimeFrom != "" || r.When.TimeTo != "" {
+		from, err := parseClock(r.When.TimeFrom)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeFrom: %w", err)
+		}
+		to, err := parseClock(r.When.TimeTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeTo: %w", err)
+		}
+		c.window, c.fromMin, c.toMin = true, from, to
+	}
+
+	if len(r.Actions) == 0 {
+		return nil, fmt.Errorf("no actions")
+	}
+	for _, a := range r.Actions {
+		if err := validateAction(a); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func validateAction(a Action) error {
+	switch a.Type {
+	case ActionOverlay:
+		if a.Event == "" {
+			return fmt.Errorf("overlay action needs an event name")
+		}
+	case ActionSound:
+		if a.Sound == "" {
+			return fmt.Errorf("sound action needs a sound")
+		}
+	case ActionStomp:
+		if !strings.HasPrefix(a.Destination, "/app/") {
+			return fmt.Errorf("stomp action destination must start with /app/")
+		}
+	case ActionWebhook:
+		if !strings.HasPrefix(a.URL, "http://") && !strings.HasPrefix(a.URL, "https://") {
+			return fmt.Errorf("webhook action needs an http(s) URL")
+		}
+	case ActionScoreBonus:
+		if a.Bonus == 0 {
+			return fmt.Errorf("scoreBonus action needs a non-zero bonus")
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
+
+func (r *compiledRule) matches(e Event) bool {
+	w := r.rule.When
+
+	if w.EventType != "" && w.EventType != e.Type {
+		return false
+	}
+	if len(w.GiftIds) > 0 || len(w.GiftNames) > 0 {
+		if e.Type != EventGift || !(contains(w.GiftIds, e.GiftId, false) || contains(w.GiftNames, e.GiftName, true)) {
+			return false
+		}
+	}
+	if len(w.SenderIds) > 0 && !contains(w.SenderIds, e.SenderId, false) {
+		return false
+	}
+	if len(w.TeamIds) > 0 && !contains(w.TeamIds, e.TeamId, false) {
+		return false
+	}
+	if w.MinDiamonds > 0 && e.Diamonds < w.MinDiamonds {
+		return false
+	}
+	if w.MaxDiamonds > 0 && e.Diamonds > w.MaxDiamonds {
+		return false
+	}
+	if r.pattern != nil && (e.Type != EventChat || !r.pattern.MatchString(e.Message)) {
+		return false
+	}
+	if r.window {
+		local := e.At.Local()
+		minute := local.Hour()*60 + local.Minute()
+		if r.fromMin <= r.toMin {
+			if minute < r.fromMin || minute >= r.toMin {
+				return false
+			}
+		} else if minute < r.fromMin && minute >= r.toMin { // Window wraps midnight
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, v string, foldCase bool) bool {
+	if v == "" {
+		return false
+	}
+	for _, candidate := range values {
+		if candidate == v || (foldCase && strings.EqualFold(candidate, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func eventTime(timestampMs int64) time.Time {
+	if timestampMs > 0 {
+		return time.UnixMilli(timestampMs)
+	}
+	return time.Now()
+}
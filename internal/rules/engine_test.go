@@ -0,0 +1,132 @@
+package rules_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bbapp/internal/listener"
+	"bbapp/internal/rules"
+)
+
+func overlayAction(name string) []rules.Action {
+	return []rules.Action{{Type: rules.ActionOverlay, Event: name}}
+}
+
+func TestEngine_GiftConditions(t *testing.T) {
+	engine, err := rules.NewEngine(rules.RuleSet{Rules: []rules.Rule{
+		{Id: "big", Enabled: true, When: rules.Condition{EventType: rules.EventGift, MinDiamonds: 1000}, Actions: overlayAction("BIG_GIFT")},
+		{Id: "team2", Enabled: true, When: rules.Condition{GiftNames: []string{"castle"}, TeamIds: []string{"team2"}}, Actions: overlayAction("FLASH_TEAM")},
+		{Id: "off", Enabled: false, When: rules.Condition{}, Actions: overlayAction("NEVER")},
+	}})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	event, _ := rules.FromListener(listener.BigoGift{GiftName: "Castle", Diamonds: 5000}, "team2", "")
+	firings := engine.Evaluate(event)
+	if len(firings) != 2 || firings[0].RuleId != "big" || firings[1].RuleId != "team2" {
+		t.Errorf("Expected big and team2 to fire, got %+v", firings)
+	}
+
+	event, _ = rules.FromListener(listener.BigoGift{GiftName: "Castle", Diamonds: 10}, "team1", "")
+	if firings := engine.Evaluate(event); len(firings) != 0 {
+		t.Errorf("Expected no firings, got %+v", firings)
+	}
+}
+
+func TestEngine_ChatPatternAndCooldown(t *testing.T) {
+	engine, _ := rules.NewEngine(rules.RuleSet{Rules: []rules.Rule{
+		{Id: "song", Enabled: true, When: rules.Condition{ChatPattern: `^!song\b`}, Actions: overlayAction("QUEUE_SONG"), CooldownSeconds: 30},
+	}})
+
+	at := time.Now()
+	fire := func(msg string, offset time.Duration) int {
+		return len(engine.Evaluate(rules.Event{Type: rules.EventChat, Message: msg, At: at.Add(offset)}))
+	}
+
+	if fire("!song despacito", 0) != 1 {
+		t.Error("Expected !song to fire")
+	}
+	if fire("!song again", 10*time.Second) != 0 {
+		t.Error("Expected cooldown to suppress second firing")
+	}
+	if fire("!song later", 31*time.Second) != 1 {
+		t.Error("Expected firing after cooldown")
+	}
+	if fire("play a song", 90*time.Second) != 0 {
+		t.Error("Expected non-matching chat to be ignored")
+	}
+}
+
+func TestEngine_TimeWindowWrapsMidnight(t *testing.T) {
+	engine, _ := rules.NewEngine(rules.RuleSet{Rules: []rules.Rule{
+		{Id: "night", Enabled: true, When: rules.Condition{TimeFrom: "22:00", TimeTo: "02:00"}, Actions: overlayAction("NIGHT")},
+	}})
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	if len(engine.Evaluate(rules.Event{Type: rules.EventGift, At: day.Add(23 * time.Hour)})) != 1 {
+		t.Error("Expected 23:00 to be inside the window")
+	}
+	if len(engine.Evaluate(rules.Event{Type: rules.EventGift, At: day.Add(1 * time.Hour)})) != 1 {
+		t.Error("Expected 01:00 to be inside the window")
+	}
+	if len(engine.Evaluate(rules.Event{Type: rules.EventGift, At: day.Add(12 * time.Hour)})) != 0 {
+		t.Error("Expected 12:00 to be outside the window")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []rules.Rule{
+		{Id: "", Actions: overlayAction("X")},
+		{Id: "a"},
+		{Id: "a", When: rules.Condition{ChatPattern: "("}, Actions: overlayAction("X")},
+		{Id: "a", When: rules.Condition{TimeFrom: "25:00", TimeTo: "01:00"}, Actions: overlayAction("X")},
+		{Id: "a", Actions: []rules.Action{{Type: rules.ActionStomp, Destination: "/topic/x"}}},
+		{Id: "a", Actions: []rules.Action{{Type: rules.ActionWebhook, URL: "ftp://x"}}},
+		{Id: "a", Actions: []rules.Action{{Type: "explode"}}},
+	}
+	for _, r := range invalid {
+		if err := rules.Validate(rules.RuleSet{Rules: []rules.Rule{r}}); err == nil {
+			t.Errorf("Expected error for rule %+v", r)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	set := rules.RuleSet{Rules: []rules.Rule{
+		{Id: "big", Enabled: true, When: rules.Condition{MinDiamonds: 100}, Actions: []rules.Action{
+			{Type: rules.ActionScoreBonus, Bonus: 50},
+			{Type: rules.ActionSound, Sound: "airhorn"},
+		}},
+	}}
+
+	firings, err := rules.DryRun(set, []rules.Event{
+		{Type: rules.EventGift, Diamonds: 10},
+		{Type: rules.EventGift, Diamonds: 500},
+	})
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if len(firings) != 2 || firings[0].Event.Diamonds != 500 {
+		t.Errorf("Expected both actions of one rule to fire for the big gift, got %+v", firings)
+	}
+}
+
+func TestPostWebhook(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	err := rules.PostWebhook("room1", rules.Firing{RuleId: "big", Action: rules.Action{Type: rules.ActionWebhook, URL: server.URL}})
+	if err != nil {
+		t.Fatalf("PostWebhook failed: %v", err)
+	}
+	if received["roomId"] != "room1" || received["ruleId"] != "big" {
+		t.Errorf("Unexpected webhook body: %+v", received)
+	}
+}
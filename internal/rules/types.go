@@ -0,0 +1,83 @@
+package rules
+
+import "time"
+
+// Event types
+const (
+	EventGift = "gift"
+	EventChat = "chat"
+)
+
+// Action types
+const (
+	ActionOverlay    = "overlay"    // Broadcast a named event to the overlay
+	ActionSound      = "sound"      // Play a sound cue in the overlay/UI
+	ActionStomp      = "stomp"      // Publish a payload to a BB-Core STOMP destination
+	ActionWebhook    = "webhook"    // POST the firing to an HTTP endpoint
+	ActionScoreBonus = "scoreBonus" // Add bonus points to a team
+)
+
+// Event is a normalized gift or chat event that rules are evaluated against
+type Event struct {
+	Type       string    `json:"type"` // gift or chat
+	BigoRoomId string    `json:"bigoRoomId"`
+	SenderId   string    `json:"senderId"`
+	SenderName string    `json:"senderName"`
+	TeamId     string    `json:"teamId"`
+	StreamerId string    `json:"streamerId"`
+	GiftId     string    `json:"giftId"`
+	GiftName   string    `json:"giftName"`
+	GiftCount  int       `json:"giftCount"`
+	Diamonds   int64     `json:"diamonds"`
+	Message    string    `json:"message"`
+	At         time.Time `json:"at"`
+}
+
+// Condition describes which events a rule matches. Empty fields match everything.
+type Condition struct {
+	EventType   string   `json:"eventType"`   // gift, chat or empty for both
+	GiftIds     []string `json:"giftIds"`     // Any of these gift IDs
+	GiftNames   []string `json:"giftNames"`   // Any of these gift names (case-insensitive)
+	SenderIds   []string `json:"senderIds"`   // Any of these senders
+	TeamIds     []string `json:"teamIds"`     // Any of these teams
+	MinDiamonds int64    `json:"minDiamonds"` // Inclusive lower bound (0 = none)
+	MaxDiamonds int64    `json:"maxDiamonds"` // Inclusive upper bound (0 = none)
+	ChatPattern string   `json:"chatPattern"` // Regular expression the chat message must match
+	TimeFrom    string   `json:"timeFrom"`    // Local time window start "HH:MM" (may wrap midnight)
+	TimeTo      string   `json:"timeTo"`      // Local time window end "HH:MM" (exclusive)
+}
+
+// Action is something a rule does when it fires
+type Action struct {
+	Type        string                 `json:"type"`
+	Event       string                 `json:"event"`       // overlay: event name
+	Sound       string                 `json:"sound"`       // sound: cue name or URL
+	Destination string                 `json:"destination"` // stomp: destination ({roomId} is substituted)
+	URL         string                 `json:"url"`         // webhook: endpoint
+	Bonus       int64                  `json:"bonus"`       // scoreBonus: points
+	TeamId      string                 `json:"teamId"`      // scoreBonus: team (defaults to the event's team)
+	Payload     map[string]interface{} `json:"payload"`     // Extra data merged into overlay/stomp/webhook payloads
+}
+
+// Rule is a "when X happens, do Y" trigger
+type Rule struct {
+	Id              string    `json:"id"`
+	Name            string    `json:"name"`
+	Enabled         bool      `json:"enabled"`
+	When            Condition `json:"when"`
+	Actions         []Action  `json:"actions"`
+	CooldownSeconds int       `json:"cooldownSeconds"` // Minimum time between firings (0 = none)
+}
+
+// RuleSet is the list of rules stored in a profile
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Firing is a rule action triggered by an event
+type Firing struct {
+	RuleId   string `json:"ruleId"`
+	RuleName string `json:"ruleName"`
+	Action   Action `json:"action"`
+	Event    Event  `json:"event"`
+}
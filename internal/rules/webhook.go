@@ -0,0 +1,38 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// PostWebhook sends a firing as JSON to the action's URL
+func PostWebhook(roomId string, firing Firing) error {
+	body := map[string]interface{}{
+		"roomId":   roomId,
+		"ruleId":   firing.RuleId,
+		"ruleName": firing.RuleName,
+		"event":    firing.Event,
+		"payload":  firing.Action.Payload,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal webhook body: %w", err)
+	}
+
+	resp, err := webhookClient.Post(firing.Action.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,16 +1,24 @@
 package session
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"bbapp/internal/api"
+	"bbapp/internal/config"
+	"bbapp/internal/filter"
 	"bbapp/internal/listener"
+	"bbapp/internal/message"
 	"bbapp/internal/stomp"
 	"time"
 )
 
+// stopTimeout bounds the BB-Core calls made while stopping, so a BB-Core outage cannot hang the UI
+const stopTimeout = 5 * time.Second
+
 // SenderBindingCache tracks which streamer a sender is bound to temporarily
 type SenderBindingCache struct {
 	StreamerBigoId string
@@ -23,18 +31,55 @@ type BBCoreStreamSession struct {
 	stompClient  *stomp.Client
 	heartbeat    *Heartbeat
 	sessionId    string
+	lastStop     *api.StopScriptResponse // Result of the most recent Stop, for session reports
 	roomId       string
 	deviceHash   string
 	isActive     bool
-	config       *api.Config
-	bigoListener *BigoListenerSession // Reference to get buffered events
+	config       atomic.Pointer[config.Manager] // Swapped by UpdateConfig while running
+	bigoListener *BigoListenerSession           // Reference to get buffered events
 	mutex        sync.RWMutex
 	stopChan     chan struct{}
 
 	senderBindings map[string]SenderBindingCache
 	bindingsMutex  sync.Mutex
+
+	onStompState  []func(stomp.StateEvent)
+	callbackMutex sync.Mutex // Guards onStompState; state events arrive while mutex is held by Start/Stop
+
+	validateTrial    TrialValidator      // nil = always ask BB-Core
+	onRevoked        func(reason string) // Called when a background re-validation is refused
+	trialOffline     bool                // Started on a cached entitlement, not yet confirmed by BB-Core
+	revalidateCancel context.CancelFunc
+
+	// Debounced STATUS reporting of listener connections (see status_reporter.go)
+	rooms        map[string]*roomStatus // Connection key -> reporting state
+	reporting    bool                   // Between Start and the final STATUS of Stop
+	statusSource *BigoListenerSession   // Listener whose connection changes are already subscribed
+	statusMutex  sync.Mutex
+
+	// Messages that could not be published while STOMP was reconnecting, replayed in order on reconnect
+	pending      []pendingMessage
+	flushing     bool
+	dropped      int
+	pendingMutex sync.Mutex
+}
+
+// pendingMessage is a STOMP message waiting for the connection to come back
+type pendingMessage struct {
+	destination string
+	payload     interface{}
 }
 
+// TrialValidator validates streamers for a stream start. offline is true when BB-Core could not be
+// reached and the answer (or error) comes from a cached entitlement instead.
+type TrialValidator func(ctx context.Context, streamers []api.ValidateTrialStreamer) (resp *api.ValidateTrialResponse, offline bool, err error)
+
+// revalidateInterval is how often a stream started offline asks BB-Core to confirm the trial
+var revalidateInterval = time.Minute
+
+// maxPendingMessages bounds the reconnect queue; the oldest messages are dropped beyond it
+const maxPendingMessages = 5000
+
 // NewBBCoreStreamSession creates a new BB-Core stream session
 func NewBBCoreStreamSession(apiClient *api.Client, deviceHash string) *BBCoreStreamSession {
 	return &BBCoreStreamSession{
@@ -48,7 +93,7 @@ func NewBBCoreStreamSession(apiClient *api.Client, deviceHash string) *BBCoreStr
 
 // Start starts the BB-Core streaming session
 // Requires an active Bigo listener session
-func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListener *BigoListenerSession, bbCoreURL, accessToken string, durationMinutes int) error {
+func (s *BBCoreStreamSession) Start(roomId string, cfgMgr *config.Manager, bigoListener *BigoListenerSession, bbCoreURL, accessToken string, durationMinutes int) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -56,21 +101,28 @@ func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListe
 		return fmt.Errorf("BB-Core stream session already active")
 	}
 
+	if s.apiClient == nil {
+		return fmt.Errorf("BB-Core stream requires login")
+	}
+
 	// Validate that Bigo listener is active
 	if !bigoListener.IsActive() {
 		return fmt.Errorf("Bigo listener session must be active before starting BB-Core stream")
 	}
 
-	s.config = config
+	cfg := cfgMgr.GetConfig()
+	s.config.Store(cfgMgr)
 	s.roomId = roomId
 	s.bigoListener = bigoListener
+	s.lastStop = nil
+	s.resetPending()
 
 	fmt.Println("[BBCoreStream] Starting BB-Core stream session...")
 
 	// Step 1: Validate trial
 	fmt.Println("[BBCoreStream] Step 1: Validating trial...")
 	streamers := make([]api.ValidateTrialStreamer, 0)
-	for _, team := range config.Teams {
+	for _, team := range cfg.Teams {
 		for _, idol := range team.Streamers {
 			streamers = append(streamers, api.ValidateTrialStreamer{
 				BigoId:     idol.BigoId,
@@ -79,7 +131,8 @@ func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListe
 		}
 	}
 
-	validationResp, err := s.apiClient.ValidateTrial(streamers)
+	validate := s.validator()
+	validationResp, offline, err := validate(context.Background(), streamers)
 	if err != nil {
 		return fmt.Errorf("trial validation failed: %w", err)
 	}
@@ -89,12 +142,17 @@ func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListe
 			validationResp.Message, validationResp.BlockedBigoIds)
 	}
 
-	fmt.Println("[BBCoreStream] ✓ Trial validation passed")
+	s.trialOffline = offline
+	if offline {
+		fmt.Printf("[BBCoreStream] ✓ Trial validation passed offline (%s), confirming in the background\n", validationResp.Message)
+	} else {
+		fmt.Println("[BBCoreStream] ✓ Trial validation passed")
+	}
 
 	// Step 2: Start session at BB-Core
 	fmt.Println("[BBCoreStream] Step 2: Starting session at BB-Core...")
 	scriptPayload := map[string]interface{}{
-		"minTeams": len(config.Teams),
+		"minTeams": len(cfg.Teams),
 	}
 
 	resp, err := s.apiClient.StartSession(roomId, durationMinutes, scriptPayload)
@@ -118,10 +176,15 @@ func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListe
 		stompURL = strings.TrimSuffix(stompURL, "/") + "/ws"
 	}
 
-	stompClient, err := stomp.NewClient(stompURL, accessToken, "")
+	// The API client supplies the token, so reconnects after a JWT expiry use a refreshed one
+	stompClient, err := stomp.NewClient(stompURL, accessToken, "",
+		stomp.WithStateHandler(s.notifyStompState),
+		stomp.WithTokenProvider(s.apiClient))
 	if err != nil {
 		// Rollback: stop session at BB-Core
-		s.apiClient.StopSession(s.sessionId)
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		s.apiClient.StopSessionContext(ctx, s.sessionId)
+		cancel()
 		return fmt.Errorf("STOMP connection failed: %w", err)
 	}
 
@@ -149,11 +212,21 @@ func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListe
 
 	// Step 6: Subscribe to live events
 	fmt.Println("[BBCoreStream] Step 6: Subscribing to Bigo listener events...")
-	s.bigoListener.SubscribeOnGift(func(event interface{}) {
+	s.bigoListener.SubscribeSink(filter.SinkBBCore, func(event interface{}) {
 		s.publishEvent(event)
 	})
 	fmt.Println("[BBCoreStream] ✓ Subscribed to live events")
 
+	// Report connection changes as STATUS, starting with the current state of each connection
+	s.startReporting()
+	if s.statusSource != s.bigoListener {
+		s.statusSource = s.bigoListener
+		s.bigoListener.SubscribeConnectionStatus(s.reportConnection)
+	}
+	for key, conn := range s.bigoListener.connectionsByKey() {
+		s.reportConnection(key, conn)
+	}
+
 	// Step 7: Subscribe to topic
 	topic := fmt.Sprintf("/topic/room/%s/scene", s.roomId)
 	fmt.Printf("[BBCoreStream] Step 7: Subscribing to %s...\n", topic)
@@ -163,13 +236,26 @@ func (s *BBCoreStreamSession) Start(roomId string, config *api.Config, bigoListe
 	fmt.Println("[BBCoreStream] ✓ Subscribed to scene updates")
 
 	s.isActive = true
+	if offline {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.revalidateCancel = cancel
+		go s.revalidate(ctx, validate, streamers)
+	}
 
 	fmt.Printf("[BBCoreStream] ✓✓✓ Stream session fully started: %s\n", s.sessionId)
 	return nil
 }
 
-// Stop stops the BB-Core streaming session
+// Stop stops the BB-Core streaming session, giving BB-Core at most stopTimeout to confirm
 func (s *BBCoreStreamSession) Stop(reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	return s.StopContext(ctx, reason)
+}
+
+// StopContext is like Stop but bounds the BB-Core stop call by ctx.
+// The session is torn down locally even if BB-Core cannot be reached in time.
+func (s *BBCoreStreamSession) StopContext(ctx context.Context, reason string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -179,6 +265,12 @@ func (s *BBCoreStreamSession) Stop(reason string) error {
 
 	fmt.Printf("[BBCoreStream] Stopping stream session (reason: %s)...\n", reason)
 
+	if s.revalidateCancel != nil {
+		s.revalidateCancel()
+		s.revalidateCancel = nil
+	}
+	s.trialOffline = false
+
 	// Step 1: Stop heartbeat
 	if s.heartbeat != nil {
 		fmt.Println("[BBCoreStream] Step 1: Stopping heartbeat...")
@@ -187,32 +279,129 @@ func (s *BBCoreStreamSession) Stop(reason string) error {
 		fmt.Println("[BBCoreStream] ✓ Heartbeat stopped")
 	}
 
-	// Step 2: Disconnect STOMP
+	// Step 2: Report every room as disconnected, then disconnect STOMP
+	s.publishFinalStatus(ctx, reason)
 	if s.stompClient != nil {
 		fmt.Println("[BBCoreStream] Step 2: Disconnecting STOMP...")
-		s.stompClient.Disconnect()
+		if err := s.stompClient.DisconnectContext(ctx); err != nil {
+			fmt.Printf("[BBCoreStream] WARNING: STOMP disconnect: %v\n", err)
+		}
 		s.stompClient = nil
 		fmt.Println("[BBCoreStream] ✓ STOMP disconnected")
 	}
 
 	// Step 3: Stop session at BB-Core
 	fmt.Printf("[BBCoreStream] Step 3: Stopping session %s at BB-Core...\n", s.sessionId)
-	resp, err := s.apiClient.StopSession(s.sessionId)
+	resp, err := s.apiClient.StopSessionContext(ctx, s.sessionId)
 	if err != nil {
 		fmt.Printf("[BBCoreStream] WARNING: Failed to stop session at BB-Core: %v\n", err)
+		resp = &api.StopScriptResponse{SessionId: s.sessionId, RoomId: s.roomId}
 	} else if resp.Status != "COMPLETED" && resp.Status != "STOPPED" {
 		fmt.Printf("[BBCoreStream] WARNING: Unexpected stop status: %s\n", resp.Status)
 	} else {
 		fmt.Printf("[BBCoreStream] ✓ Session stopped at BB-Core (status=%s)\n", resp.Status)
 	}
+	s.lastStop = resp
 
 	s.isActive = false
 	s.sessionId = ""
+	if dropped := s.resetPending(); dropped > 0 {
+		fmt.Printf("[BBCoreStream] WARNING: %d queued messages were not delivered before stop\n", dropped)
+	}
 
 	fmt.Println("[BBCoreStream] ✓✓✓ Stream session stopped successfully")
 	return nil
 }
 
+// UpdateConfig swaps the config used for attribution while the stream runs. Sender bindings
+// are kept, except those to streamers that are no longer in the config.
+func (s *BBCoreStreamSession) UpdateConfig(cfgMgr *config.Manager) {
+	s.config.Store(cfgMgr)
+
+	targets := make(map[string]bool)
+	for _, team := range cfgMgr.GetConfig().Teams {
+		for i := range team.Streamers {
+			targets[streamerTarget(&team.Streamers[i])] = true
+		}
+	}
+
+	s.bindingsMutex.Lock()
+	defer s.bindingsMutex.Unlock()
+	for senderId, binding := range s.senderBindings {
+		if !targets[binding.StreamerBigoId] {
+			delete(s.senderBindings, senderId)
+			fmt.Printf("[BBCoreStream] Dropped binding of sender %s to removed streamer %s\n", senderId, binding.StreamerBigoId)
+		}
+	}
+}
+
+// SetTrialValidator replaces the plain BB-Core trial validation, e.g. to fall back to a cached
+// entitlement. onRevoked is called if BB-Core later refuses a stream that started offline.
+func (s *BBCoreStreamSession) SetTrialValidator(validate TrialValidator, onRevoked func(reason string)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.validateTrial = validate
+	s.onRevoked = onRevoked
+}
+
+// validator returns the trial validation to use. Caller must hold mutex.
+func (s *BBCoreStreamSession) validator() TrialValidator {
+	if s.validateTrial != nil {
+		return s.validateTrial
+	}
+	apiClient := s.apiClient
+	return func(ctx context.Context, streamers []api.ValidateTrialStreamer) (*api.ValidateTrialResponse, bool, error) {
+		resp, err := apiClient.ValidateTrialContext(ctx, streamers)
+		return resp, false, err
+	}
+}
+
+// revalidate asks BB-Core to confirm a stream started offline until it answers.
+// A refusal (rejection or error from BB-Core itself) is reported through onRevoked.
+func (s *BBCoreStreamSession) revalidate(ctx context.Context, validate TrialValidator, streamers []api.ValidateTrialStreamer) {
+	ticker := time.NewTicker(revalidateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		resp, offline, err := validate(ctx, streamers)
+		if ctx.Err() != nil {
+			return
+		}
+		if offline {
+			fmt.Printf("[BBCoreStream] BB-Core still unreachable for trial validation: %v\n", err)
+			continue
+		}
+
+		s.mutex.Lock()
+		s.trialOffline = false
+		onRevoked := s.onRevoked
+		s.mutex.Unlock()
+
+		var reason string
+		switch {
+		case err != nil:
+			reason = fmt.Sprintf("Access revoked: %v", err)
+		case !resp.Allowed:
+			reason = fmt.Sprintf("Access revoked: %s", resp.Message)
+		default:
+			fmt.Println("[BBCoreStream] ✓ Trial confirmed by BB-Core")
+			return
+		}
+
+		fmt.Printf("[BBCoreStream] %s\n", reason)
+		if onRevoked != nil {
+			onRevoked(reason)
+		}
+		return
+	}
+}
+
 // PublishEvent publishes a gift event to BB-Core via STOMP
 func (s *BBCoreStreamSession) publishEvent(event interface{}) error {
 	s.mutex.RLock()
@@ -224,9 +413,9 @@ func (s *BBCoreStreamSession) publishEvent(event interface{}) error {
 		return fmt.Errorf("stream session not active")
 	}
 
-	// Use the Client.Publish method which handles marshaling and sending
-	var dest string
-	var payload interface{}
+	// GIFT and CHAT both go to the /bigo endpoint, as typed messages of the current schema version
+	dest := fmt.Sprintf("/app/room/%s/bigo", s.roomId)
+	var msg message.Message
 
 	switch e := event.(type) {
 	case listener.BigoGift:
@@ -238,69 +427,122 @@ func (s *BBCoreStreamSession) publishEvent(event interface{}) error {
 			return nil
 		}
 
-		// Use legacy endpoint /bigo instead of /gift to match original app.go behavior
-		dest = fmt.Sprintf("/app/room/%s/bigo", s.roomId)
-		giftPayload := BBCoreGiftPayload{
-			Sender:    e.SenderName,
-			TeamId:    s.resolveTeamId(e.BigoRoomId, e.GiftName),
-			GiftName:  e.GiftName,
-			Value:     int64(e.Diamonds),
-			Count:     e.GiftCount,
-			Avatar:    e.SenderAvatar,
-			Timestamp: e.Timestamp,
-			Diamonds:  int64(e.Diamonds), // Populate legacy field
-		}
+		gift := message.NewGift(s.roomId, s.deviceHash, e)
+		gift.StreamerID = resolvedStreamerId // Use the STRICTLY resolved ID
+		gift.TeamID = s.resolveTeamId(e.BigoRoomId, e.GiftName, e.GiftId)
+		msg = gift
+
+		fmt.Printf("[BBCoreStream] Publishing GIFT to %s: Name=%s, Diamonds=%d\n", dest, e.GiftName, e.Diamonds)
+
+	case listener.BigoChat:
+		chat := message.NewChat(s.roomId, s.deviceHash, e)
+		chat.TeamID = s.resolveTeamId(e.BigoRoomId, "", "")
+		msg = chat
+		fmt.Printf("[BBCoreStream] Publishing CHAT to %s: %+v\n", dest, chat)
 
-		// Create a map to ensure we send exactly what the legacy endpoint expects,
-		// including the "type" field which might be required for the /bigo endpoint dispatcher.
-		payloadMap := map[string]interface{}{
-			"type":       "GIFT",
-			"roomId":     s.roomId,
-			"bigoRoomId": e.BigoRoomId,
-			"senderName": giftPayload.Sender,
-			"teamId":     giftPayload.TeamId,
-			"giftName":   giftPayload.GiftName,
-			"value":      giftPayload.Value,
-			"diamonds":   giftPayload.Diamonds,
-			"count":      giftPayload.Count,
-			"avatar":     giftPayload.Avatar,
-			"timestamp":  giftPayload.Timestamp,
-			// Add extra fields if needed by backend, matching app.go
-			"senderId":     e.SenderId,
-			"senderLevel":  e.SenderLevel,
-			"streamerId":   resolvedStreamerId, // Use the STRICTLY resolved ID
-			"streamerName": e.StreamerName,
-			"giftId":       e.GiftId,
-			"giftImageUrl": e.GiftImageUrl,
+	default:
+		return fmt.Errorf("unsupported event type %T", event)
+	}
+
+	if err := msg.Validate(); err != nil {
+		fmt.Printf("[BBCoreStream] WARNING: Dropping message: %v\n", err)
+		return err
+	}
+	return s.publishOrQueue(stompClient, dest, msg)
+}
+
+// publishOrQueue publishes a message, or queues it while the STOMP client is reconnecting.
+// Messages already queued go first so BB-Core sees events in order.
+func (s *BBCoreStreamSession) publishOrQueue(client *stomp.Client, dest string, payload interface{}) error {
+	healthy := client.IsHealthy()
+
+	s.pendingMutex.Lock()
+	if len(s.pending) > 0 || s.flushing || !healthy {
+		s.enqueueLocked(pendingMessage{destination: dest, payload: payload})
+		replay := healthy && !s.flushing
+		s.pendingMutex.Unlock()
+
+		if replay {
+			go s.flushPending() // Left over from a failure that didn't cause a reconnect
 		}
+		return nil
+	}
+	s.pendingMutex.Unlock()
 
-		payload = payloadMap
+	if err := client.Publish(dest, payload); err != nil {
+		fmt.Printf("[BBCoreStream] Publish failed, queueing until reconnect: %v\n", err)
+		s.pendingMutex.Lock()
+		s.enqueueLocked(pendingMessage{destination: dest, payload: payload})
+		s.pendingMutex.Unlock()
+	}
+	return nil
+}
 
-		// Explicitly log the value being sent to identify issues
-		fmt.Printf("[BBCoreStream] >>> SENDING GIFT PAYLOAD (Legacy Mode): Name=%s, Diamonds=%d\n",
-			e.GiftName, e.Diamonds)
+// enqueueLocked adds a message to the reconnect queue. Caller must hold pendingMutex.
+func (s *BBCoreStreamSession) enqueueLocked(msg pendingMessage) {
+	if len(s.pending) >= maxPendingMessages {
+		s.pending = s.pending[1:]
+		s.dropped++
+	}
+	s.pending = append(s.pending, msg)
+}
 
-		fmt.Printf("[BBCoreStream] Publishing GIFT to %s: %+v\n", dest, payload)
+// flushPending replays queued messages after a reconnect, stopping at the first failure
+func (s *BBCoreStreamSession) flushPending() {
+	client := s.GetStompClient()
+	if client == nil {
+		return
+	}
 
-	case listener.BigoChat:
-		dest = fmt.Sprintf("/app/room/%s/chat", s.roomId)
-		payload = BBCoreChatPayload{
-			Sender:    e.SenderName,
-			TeamId:    s.resolveTeamId(e.BigoRoomId, ""),
-			Message:   e.Message,
-			Avatar:    e.SenderAvatar,
-			Timestamp: e.Timestamp,
+	s.pendingMutex.Lock()
+	if s.flushing {
+		s.pendingMutex.Unlock()
+		return
+	}
+	s.flushing = true
+	s.pendingMutex.Unlock()
+
+	sent := 0
+	for {
+		s.pendingMutex.Lock()
+		if len(s.pending) == 0 {
+			s.flushing = false
+			s.pendingMutex.Unlock()
+			break
+		}
+		msg := s.pending[0]
+		s.pendingMutex.Unlock()
+
+		if err := client.Publish(msg.destination, msg.payload); err != nil {
+			// The client marks itself unhealthy; the next reconnect resumes the replay
+			fmt.Printf("[BBCoreStream] Replay interrupted after %d messages: %v\n", sent, err)
+			s.pendingMutex.Lock()
+			s.flushing = false
+			s.pendingMutex.Unlock()
+			return
 		}
-		fmt.Printf("[BBCoreStream] Publishing CHAT to %s: %+v\n", dest, payload)
 
-	default:
-		// Fallback for unknown events, or log error
-		dest = fmt.Sprintf("/app/room/%s/gift", s.roomId)
-		payload = event
-		fmt.Printf("[BBCoreStream] Publishing UNKNOWN event to %s: %+v\n", dest, event)
+		s.pendingMutex.Lock()
+		if len(s.pending) > 0 {
+			s.pending = s.pending[1:]
+		}
+		s.pendingMutex.Unlock()
+		sent++
 	}
 
-	return stompClient.Publish(dest, payload)
+	if sent > 0 {
+		fmt.Printf("[BBCoreStream] ✓ Replayed %d queued messages\n", sent)
+	}
+}
+
+// resetPending clears the reconnect queue and returns how many messages were discarded
+func (s *BBCoreStreamSession) resetPending() int {
+	s.pendingMutex.Lock()
+	defer s.pendingMutex.Unlock()
+	discarded := len(s.pending) + s.dropped
+	s.pending = nil
+	s.dropped = 0
+	return discarded
 }
 
 // GetStatus returns the current status of the BB-Core stream session
@@ -308,12 +550,24 @@ func (s *BBCoreStreamSession) GetStatus() BBCoreStreamStatus {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	return BBCoreStreamStatus{
-		IsActive:   s.isActive,
-		SessionId:  s.sessionId,
-		RoomId:     s.roomId,
-		DeviceHash: s.deviceHash,
+	status := BBCoreStreamStatus{
+		IsActive:     s.isActive,
+		SessionId:    s.sessionId,
+		RoomId:       s.roomId,
+		DeviceHash:   s.deviceHash,
+		TrialOffline: s.trialOffline,
+	}
+	if s.stompClient != nil {
+		connection := s.stompClient.State()
+		status.Connection = &connection
 	}
+
+	s.pendingMutex.Lock()
+	status.QueuedMessages = len(s.pending)
+	status.DroppedMessages = s.dropped
+	s.pendingMutex.Unlock()
+
+	return status
 }
 
 // IsActive returns whether the session is active
@@ -337,131 +591,127 @@ func (s *BBCoreStreamSession) GetSessionId() string {
 	return s.sessionId
 }
 
-// BBCoreStreamStatus represents the status of the BB-Core stream session
-type BBCoreStreamStatus struct {
-	IsActive   bool   `json:"isActive"`
-	SessionId  string `json:"sessionId"`
-	RoomId     string `json:"roomId"`
-	DeviceHash string `json:"deviceHash"`
+// LastStopResult returns BB-Core's response to the most recent Stop (nil if not stopped since Start)
+func (s *BBCoreStreamSession) LastStopResult() *api.StopScriptResponse {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastStop
 }
 
-// BBCoreGiftPayload represents the payload sent to BB-Core for gifts
-type BBCoreGiftPayload struct {
-	Sender    string `json:"sender"`
-	TeamId    string `json:"teamId"`
-	GiftName  string `json:"giftName"`
-	Value     int64  `json:"value"`
-	Count     int    `json:"count"`
-	Avatar    string `json:"avatar"`
-	Timestamp int64  `json:"timestamp"`
-	Diamonds  int64  `json:"diamonds"` // Legacy field for backend compatibility
+// SubscribeStompState registers a callback for STOMP connection state changes
+func (s *BBCoreStreamSession) SubscribeStompState(callback func(stomp.StateEvent)) {
+	s.callbackMutex.Lock()
+	defer s.callbackMutex.Unlock()
+	s.onStompState = append(s.onStompState, callback)
 }
 
-// BBCoreChatPayload represents the payload sent to BB-Core for chat/votes
-type BBCoreChatPayload struct {
-	Sender    string `json:"sender"`
-	TeamId    string `json:"teamId"`
-	Message   string `json:"message"`
-	Avatar    string `json:"avatar"`
-	Timestamp int64  `json:"timestamp"`
+func (s *BBCoreStreamSession) notifyStompState(event stomp.StateEvent) {
+	s.callbackMutex.Lock()
+	callbacks := make([]func(stomp.StateEvent), len(s.onStompState))
+	copy(callbacks, s.onStompState)
+	s.callbackMutex.Unlock()
+
+	switch event.State {
+	case stomp.StateConnected:
+		// Replay in the background; this callback may run while Start holds the session lock
+		go s.flushPending()
+	case stomp.StateFailed:
+		fmt.Printf("[BBCoreStream] WARNING: STOMP connection failed, events stay queued: %s\n", event.Error)
+	}
+	for _, cb := range callbacks {
+		cb(event)
+	}
 }
 
-// resolveTeamId finds the TeamId for a given BigoRoomId or GiftName from the config
-func (s *BBCoreStreamSession) resolveTeamId(bigoRoomId string, giftName string) string {
-	if s.config == nil {
+// BBCoreStreamStatus represents the status of the BB-Core stream session
+type BBCoreStreamStatus struct {
+	IsActive        bool                   `json:"isActive"`
+	SessionId       string                 `json:"sessionId"`
+	RoomId          string                 `json:"roomId"`
+	DeviceHash      string                 `json:"deviceHash"`
+	Connection      *stomp.ConnectionState `json:"connection,omitempty"` // STOMP connection and reconnect counters
+	QueuedMessages  int                    `json:"queuedMessages"`       // Waiting for the STOMP client to reconnect
+	DroppedMessages int                    `json:"droppedMessages"`      // Discarded because the queue was full
+	TrialOffline    bool                   `json:"trialOffline"`         // Running on a cached entitlement until BB-Core confirms it
+}
+
+// resolveTeamId finds the TeamId for a given BigoRoomId or binding gift (by name or ID) from the config,
+// in the same order as attributeGift so BB-Core, overlays and leaderboards credit the same team
+func (s *BBCoreStreamSession) resolveTeamId(bigoRoomId, giftName, giftId string) string {
+	cfgMgr := s.config.Load()
+	if cfgMgr == nil {
 		return ""
 	}
 
 	// 1. Check Binding Gifts first
-	if giftName != "" {
-		for _, team := range s.config.Teams {
-			if strings.EqualFold(team.BindingGift, giftName) {
-				return team.TeamId
-			}
+	if giftName != "" || giftId != "" {
+		if streamer, err := cfgMgr.LookupStreamerBinding(giftName, giftId); err == nil {
+			return teamIdOf(cfgMgr, streamer)
+		}
+		if team, err := cfgMgr.LookupTeamBinding(giftName, giftId); err == nil {
+			return team.TeamId
 		}
 	}
 
 	// 2. Check Streamers
-	for _, team := range s.config.Teams {
-		for _, streamer := range team.Streamers {
-			if streamer.BigoRoomId == bigoRoomId || streamer.BigoId == bigoRoomId {
-				return team.TeamId
-			}
-		}
+	if streamer, err := cfgMgr.LookupStreamerByAnyBigoId(bigoRoomId); err == nil {
+		return teamIdOf(cfgMgr, streamer)
 	}
 	return ""
 }
 
+// streamerTarget is the ID BB-Core attributes a streamer's gifts to: BigoId, else StreamerId
+func streamerTarget(streamer *api.Streamer) string {
+	if streamer.BigoId != "" {
+		return streamer.BigoId
+	}
+	return streamer.StreamerId
+}
+
+// bindSender remembers which streamer a sender's following gifts count for
+func (s *BBCoreStreamSession) bindSender(senderId, targetId, trigger string) {
+	if senderId == "" {
+		return
+	}
+	s.bindingsMutex.Lock()
+	s.senderBindings[senderId] = SenderBindingCache{
+		StreamerBigoId: targetId,
+		ExpiresAt:      time.Now().Add(60 * time.Second),
+	}
+	s.bindingsMutex.Unlock()
+	fmt.Printf("[BBCoreStream] Sender %s bound to streamer %s for 60s (Trigger: %s)\n", senderId, targetId, trigger)
+}
+
 // resolveStreamerId finds the internal StreamerId using Binding Gift priority, then Sender History, then raw ID.
 func (s *BBCoreStreamSession) resolveStreamerId(bigoId string, giftName string, giftId string, senderId string) string {
-	if s.config == nil {
+	cfgMgr := s.config.Load()
+	if cfgMgr == nil {
 		return bigoId
 	}
 
 	// 1. Check Streamer Binding Gifts first (Highest Priority)
 	if giftName != "" || giftId != "" {
-		for _, team := range s.config.Teams {
-			for _, streamer := range team.Streamers {
-				// Check matches against GiftName OR GiftId
-				matchesName := giftName != "" && strings.EqualFold(streamer.BindingGift, giftName)
-				matchesId := giftId != "" && strings.EqualFold(streamer.BindingGift, giftId)
-
-				if matchesName || matchesId {
-					// MATCH FOUND!
-					targetId := streamer.BigoId
-					if targetId == "" {
-						targetId = streamer.StreamerId
-					}
-
-					// Cache this binding for the sender
-					if senderId != "" {
-						s.bindingsMutex.Lock()
-						s.senderBindings[senderId] = SenderBindingCache{
-							StreamerBigoId: targetId,
-							ExpiresAt:      time.Now().Add(60 * time.Second),
-						}
-						s.bindingsMutex.Unlock()
-						fmt.Printf("[BBCoreStream] Sender %s bound to streamer %s for 60s (Trigger: %s)\n", senderId, targetId, giftName)
-					}
-
-					return targetId
-				}
-			}
+		if streamer, err := cfgMgr.LookupStreamerBinding(giftName, giftId); err == nil {
+			targetId := streamerTarget(streamer)
+			s.bindSender(senderId, targetId, giftName)
+			return targetId
 		}
 	}
 
 	// 1.5 Check Team Binding Gifts (Secondary Priority)
 	// If the gift binds to a TEAM, we need to attribute it to a streamer in that team.
 	if giftName != "" || giftId != "" {
-		for _, team := range s.config.Teams {
-			matchesName := giftName != "" && strings.EqualFold(team.BindingGift, giftName)
-			matchesId := giftId != "" && strings.EqualFold(team.BindingGift, giftId)
-
-			if matchesName || matchesId {
-				// Team Match Found!
-				// Try to find a specific streamer in this team that matches the recipient
-				for _, streamer := range team.Streamers {
-					if (streamer.BigoId != "" && strings.EqualFold(streamer.BigoId, bigoId)) ||
-						(streamer.BigoRoomId != "" && strings.EqualFold(streamer.BigoRoomId, bigoId)) {
-
-						targetId := streamer.BigoId
-						if targetId == "" {
-							targetId = streamer.StreamerId
-						}
-						fmt.Printf("[BBCoreStream] Resolved gift '%s' via TEAM binding (Team: %s) -> Explicit Streamer Match: %s\n", giftName, team.Name, streamer.Name)
-						return targetId
-					}
-				}
-
-				// If no precise match, attribute to the first streamer in the team
-				if len(team.Streamers) > 0 {
-					targetId := team.Streamers[0].BigoId
-					if targetId == "" {
-						targetId = team.Streamers[0].StreamerId
-					}
-					fmt.Printf("[BBCoreStream] Resolved gift '%s' via TEAM binding (Team: %s) -> Default Streamer: %s\n", giftName, team.Name, team.Streamers[0].Name)
-					return targetId
-				}
+		if team, err := cfgMgr.LookupTeamBinding(giftName, giftId); err == nil {
+			// Try to find a specific streamer in this team that matches the recipient
+			if streamer, err := cfgMgr.LookupStreamerByAnyBigoId(bigoId); err == nil && cfgMgr.TeamOf(streamer) == team {
+				fmt.Printf("[BBCoreStream] Resolved gift '%s' via TEAM binding (Team: %s) -> Explicit Streamer Match: %s\n", giftName, team.Name, streamer.Name)
+				return streamerTarget(streamer)
+			}
+
+			// If no precise match, attribute to the first streamer in the team
+			if len(team.Streamers) > 0 {
+				fmt.Printf("[BBCoreStream] Resolved gift '%s' via TEAM binding (Team: %s) -> Default Streamer: %s\n", giftName, team.Name, team.Streamers[0].Name)
+				return streamerTarget(&team.Streamers[0])
 			}
 		}
 	}
@@ -486,32 +736,12 @@ func (s *BBCoreStreamSession) resolveStreamerId(bigoId string, giftName string,
 	// 3. Check Direct Streamer Match (Fallback)
 	// If the gift wasn't a binding gift, but it was sent to a known streamer, attribute it to them.
 	if bigoId != "" {
-		for _, team := range s.config.Teams {
-			for _, streamer := range team.Streamers {
-				// Check against both BigoId (user ID) and BigoRoomId (room ID)
-				if (streamer.BigoId != "" && strings.EqualFold(streamer.BigoId, bigoId)) ||
-					(streamer.BigoRoomId != "" && strings.EqualFold(streamer.BigoRoomId, bigoId)) {
-
-					// Auto-bind for future gifts from this sender to this streamer
-					targetId := streamer.BigoId
-					if targetId == "" {
-						targetId = streamer.StreamerId
-					}
-
-					if senderId != "" {
-						s.bindingsMutex.Lock()
-						s.senderBindings[senderId] = SenderBindingCache{
-							StreamerBigoId: targetId,
-							ExpiresAt:      time.Now().Add(60 * time.Second),
-						}
-						s.bindingsMutex.Unlock()
-						fmt.Printf("[BBCoreStream] Sender %s bound to streamer %s for 60s (Trigger: Direct Match on '%s')\n", senderId, targetId, giftName)
-					}
-
-					fmt.Printf("[BBCoreStream] Resolved gift '%s' via direct streamer ID match: %s (Streamer: %s)\n", giftName, bigoId, streamer.Name)
-					return targetId
-				}
-			}
+		if streamer, err := cfgMgr.LookupStreamerByAnyBigoId(bigoId); err == nil {
+			// Auto-bind for future gifts from this sender to this streamer
+			targetId := streamerTarget(streamer)
+			s.bindSender(senderId, targetId, fmt.Sprintf("Direct Match on '%s'", giftName))
+			fmt.Printf("[BBCoreStream] Resolved gift '%s' via direct streamer ID match: %s (Streamer: %s)\n", giftName, bigoId, streamer.Name)
+			return targetId
 		}
 	}
 
@@ -0,0 +1,303 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bbapp/internal/api"
+	"bbapp/internal/bbcoremock"
+	"bbapp/internal/config"
+	"bbapp/internal/listener"
+)
+
+// startMockStream starts a BB-Core stream against the mock, with a listener that is active without a browser
+func startMockStream(t *testing.T, setup ...func(*BBCoreStreamSession)) (*bbcoremock.Server, *BBCoreStreamSession, *BigoListenerSession) {
+	t.Helper()
+	mock := bbcoremock.NewServer("demo", "demo123")
+	ts := httptest.NewServer(mock)
+	t.Cleanup(ts.Close)
+
+	client := api.NewClient(ts.URL, "")
+	auth, err := client.Login("demo", "demo123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	client.SetTokens(auth.AccessToken, auth.RefreshToken)
+
+	bigo := NewBigoListenerSession(nil)
+	bigo.isActive = true
+
+	cfg := bbcoremock.DemoConfig("room-1")
+	stream := NewBBCoreStreamSession(client, "device-1")
+	for _, fn := range setup {
+		fn(stream)
+	}
+	if err := stream.Start("room-1", config.NewManager(&cfg), bigo, ts.URL, auth.AccessToken, 30); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	return mock, stream, bigo
+}
+
+func roseGift(senderId string) listener.BigoGift {
+	// Rose is the binding gift of the demo config's red streamer
+	return listener.BigoGift{BigoRoomId: "1001", SenderId: senderId, SenderName: senderId, GiftName: "Rose", GiftCount: 1, Diamonds: 10}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestBBCoreStream_PublishesToMock(t *testing.T) {
+	mock, stream, bigo := startMockStream(t)
+
+	bigo.dispatch(roseGift("fan-1"), true)
+	messages, err := mock.WaitForMessages("/app/room/room-1/bigo", 1, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages[0].Type != "GIFT" {
+		t.Errorf("Expected a GIFT message, got %s", messages[0].Type)
+	}
+
+	if err := stream.Stop("test done"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	result := stream.LastStopResult()
+	if result == nil || result.Status != bbcoremock.StatusCompleted || result.FinalData["teamScores"] == nil {
+		t.Errorf("Unexpected stop result: %+v", result)
+	}
+}
+
+func TestBBCoreStream_QueuesWhileReconnecting(t *testing.T) {
+	mock, stream, _ := startMockStream(t)
+	defer stream.Stop("test done")
+
+	// The broker goes away; events published until the reconnect are queued, then replayed in order
+	mock.DropConnections()
+	waitUntil(t, "the client to notice the drop", func() bool { return !stream.GetStompClient().IsHealthy() })
+
+	for _, sender := range []string{"fan-1", "fan-2", "fan-3"} {
+		if err := stream.publishEvent(roseGift(sender)); err != nil {
+			t.Fatalf("publishEvent failed: %v", err)
+		}
+	}
+
+	messages, err := mock.WaitForMessages("/app/room/room-1/bigo", 3, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"fan-1", "fan-2", "fan-3"} {
+		if got := string(messages[i].Body); !strings.Contains(got, `"senderId":"`+want+`"`) {
+			t.Errorf("Message %d = %s, want sender %s", i, got, want)
+		}
+	}
+	waitUntil(t, "the queue to drain", func() bool { return stream.GetStatus().QueuedMessages == 0 })
+
+	// An expired token is refreshed through the API client on the next reconnect
+	mock.ExpireTokens()
+	mock.DropConnections()
+	waitUntil(t, "the client to notice the drop", func() bool { return !stream.GetStompClient().IsHealthy() })
+	stream.publishEvent(roseGift("fan-4"))
+	if _, err := mock.WaitForMessages("/app/room/room-1/bigo", 4, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBBCoreStream_OfflineTrialRevoked(t *testing.T) {
+	previous := revalidateInterval
+	revalidateInterval = 10 * time.Millisecond
+	defer func() { revalidateInterval = previous }()
+
+	var calls int
+	revoked := make(chan string, 1)
+	validate := func(ctx context.Context, streamers []api.ValidateTrialStreamer) (*api.ValidateTrialResponse, bool, error) {
+		calls++
+		switch calls {
+		case 1: // Start: BB-Core unreachable, cached entitlement used
+			return &api.ValidateTrialResponse{Allowed: true, Message: "cached"}, true, nil
+		case 2: // Still unreachable
+			return nil, true, errors.New("connection refused")
+		default:
+			return &api.ValidateTrialResponse{Allowed: false, Message: "trial ended"}, false, nil
+		}
+	}
+
+	_, stream, _ := startMockStream(t, func(s *BBCoreStreamSession) {
+		s.SetTrialValidator(validate, func(reason string) { revoked <- reason })
+	})
+	if !stream.GetStatus().TrialOffline {
+		t.Error("Expected the status to show the offline start")
+	}
+
+	select {
+	case reason := <-revoked:
+		if !strings.Contains(reason, "trial ended") {
+			t.Errorf("Unexpected reason: %s", reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the revocation to be reported")
+	}
+	if stream.GetStatus().TrialOffline {
+		t.Error("Expected the offline flag to clear once BB-Core answered")
+	}
+	stream.Stop("revoked")
+}
+
+func TestBBCoreStream_UpdateConfigKeepsBindings(t *testing.T) {
+	stream := NewBBCoreStreamSession(nil, "device-1")
+	cfg := bbcoremock.DemoConfig("room-1")
+	stream.UpdateConfig(config.NewManager(&cfg))
+
+	if got := stream.resolveStreamerId("1001", "Rose", "", "fan-1"); got != "red-idol" {
+		t.Fatalf("resolveStreamerId() = %q", got)
+	}
+
+	// Change a binding gift and add a streamer mid-PK
+	updated := bbcoremock.DemoConfig("room-1")
+	updated.Teams[0].Streamers[0].BindingGift = "Castle"
+	updated.Teams[1].Streamers = append(updated.Teams[1].Streamers, api.Streamer{StreamerId: "3", BigoId: "green-idol", BigoRoomId: "1003"})
+	stream.UpdateConfig(config.NewManager(&updated))
+
+	if got := stream.resolveStreamerId("", "Castle", "", "fan-2"); got != "red-idol" {
+		t.Errorf("Expected the new binding gift to apply, got %q", got)
+	}
+	if got := stream.resolveStreamerId("", "Sticker", "", "fan-1"); got != "red-idol" {
+		t.Errorf("Expected the sender binding to survive the update, got %q", got)
+	}
+	if got := stream.resolveStreamerId("1003", "Sticker", "", ""); got != "green-idol" {
+		t.Errorf("Expected the added streamer to be resolved, got %q", got)
+	}
+
+	// Removing the streamer drops the bindings to it
+	removed := bbcoremock.DemoConfig("room-1")
+	removed.Teams[0].Streamers = nil
+	stream.UpdateConfig(config.NewManager(&removed))
+	if got := stream.resolveStreamerId("", "Sticker", "", "fan-1"); got != "" {
+		t.Errorf("Expected no attribution to a removed streamer, got %q", got)
+	}
+}
+
+func TestBBCoreStream_PublishesStatus(t *testing.T) {
+	previous := statusDebounce
+	statusDebounce = 50 * time.Millisecond
+	defer func() { statusDebounce = previous }()
+
+	mock, stream, bigo := startMockStream(t)
+	dest := "/app/room/room-1/bigo"
+
+	// A streamer room started by the app: CONNECTING is not reported, a flap is reported once
+	bigo.TrackConnection("1001")
+	bigo.UpdateConnectionStatus("1001", "ERROR", "websocket closed", 0)
+	bigo.UpdateConnectionStatus("1001", "CONNECTED", "", 0)
+
+	messages, err := mock.WaitForMessages(dest, 1, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * statusDebounce)
+	if messages = mock.Messages(dest); len(messages) != 1 {
+		t.Fatalf("Expected one debounced STATUS, got %d messages", len(messages))
+	}
+	if body := string(messages[0].Body); messages[0].Type != "STATUS" ||
+		!strings.Contains(body, `"status":"CONNECTED"`) || !strings.Contains(body, `"streamerId":"1"`) {
+		t.Errorf("Unexpected STATUS message: %s", body)
+	}
+
+	// A browser failure is reported as ERROR
+	bigo.UpdateConnectionStatus("1001", "ERROR", "browser closed", 0)
+	messages, err = mock.WaitForMessages(dest, 2, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := string(messages[1].Body); !strings.Contains(body, `"status":"ERROR"`) || !strings.Contains(body, "browser closed") {
+		t.Errorf("Unexpected ERROR STATUS message: %s", body)
+	}
+
+	// Stop reports the room as disconnected with the stop reason, before the app releases it
+	if err := stream.Stop("test done"); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	messages, err = mock.WaitForMessages(dest, 3, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := string(messages[2].Body); !strings.Contains(body, `"status":"DISCONNECTED"`) || !strings.Contains(body, `"errorMessage":"test done"`) {
+		t.Errorf("Unexpected final STATUS message: %s", body)
+	}
+
+	// Changes after the stop are neither published nor kept for the next start
+	bigo.Stop()
+	bigo.ReleaseConnection("1001", "test done")
+	stream.statusMutex.Lock()
+	rooms := len(stream.rooms)
+	stream.statusMutex.Unlock()
+	if rooms != 0 {
+		t.Errorf("Expected no rooms tracked after stop, got %d", rooms)
+	}
+	time.Sleep(2 * statusDebounce)
+	if messages = mock.Messages(dest); len(messages) != 3 {
+		t.Errorf("Expected no STATUS after stop, got %d messages", len(messages))
+	}
+}
+
+func TestBigoListener_TrackedConnectionsOutliveRestarts(t *testing.T) {
+	bigo := NewBigoListenerSession(nil)
+	bigo.isActive = true
+
+	var reported []string
+	bigo.SubscribeConnectionStatus(func(key string, conn BigoConnection) {
+		reported = append(reported, key+" "+conn.Status)
+	})
+
+	bigo.TrackConnection("1001")
+	bigo.Stop() // The listener session's own connections go; the app-managed one stays
+	if _, ok := bigo.connectionsByKey()["1001"]; !ok {
+		t.Fatal("Expected the tracked connection to survive Stop")
+	}
+
+	bigo.ReleaseConnection("1001", "removed")
+	if _, ok := bigo.connectionsByKey()["1001"]; ok {
+		t.Error("Expected ReleaseConnection to forget the connection")
+	}
+	if want := []string{"1001 CONNECTING", "1001 DISCONNECTED"}; strings.Join(reported, ",") != strings.Join(want, ",") {
+		t.Errorf("Reported %v, want %v", reported, want)
+	}
+}
+
+func TestBBCoreStream_ResolveTeamIdMatchesAttribution(t *testing.T) {
+	library := []api.GiftDefinition{{ID: "101", Name: "Rose"}, {ID: "202", Name: "Heart"}}
+	cfg := api.Config{Teams: []api.Team{
+		{TeamId: "red", Streamers: []api.Streamer{{StreamerId: "s1", BigoRoomId: "111", BindingGift: "Rose"}}},
+		{TeamId: "blue", BindingGift: "Heart", Streamers: []api.Streamer{{StreamerId: "s2", BigoRoomId: "222"}}},
+	}}
+	cfgMgr := config.NewManagerWithGifts(&cfg, library)
+
+	stream := NewBBCoreStreamSession(nil, "")
+	stream.UpdateConfig(cfgMgr)
+
+	// Gifts sent in room 222 but identified only by the ID of a binding gift
+	for _, gift := range []listener.BigoGift{
+		{BigoRoomId: "222", GiftId: "101"},
+		{BigoRoomId: "111", GiftId: "202"},
+		{BigoRoomId: "222", GiftName: "Lion"},
+	} {
+		want, _ := attributeGift(cfgMgr, gift)
+		if got := stream.resolveTeamId(gift.BigoRoomId, gift.GiftName, gift.GiftId); got != want {
+			t.Errorf("resolveTeamId(%+v) = %q, attribution says %q", gift, got, want)
+		}
+	}
+	if got := stream.resolveTeamId("222", "", "101"); got != "red" {
+		t.Errorf("Expected gift 101 to credit red, got %q", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"bbapp/internal/api"
 	"bbapp/internal/browser"
+	"bbapp/internal/filter"
 	"bbapp/internal/listener"
 	"strings"
 )
@@ -35,6 +36,19 @@ type BigoConnection struct {
 	TotalDiamonds    int64     `json:"totalDiamonds"`
 	Error            string    `json:"error"`
 	// Browser instance would be managed here in future
+	external bool // Listener run by the app (see TrackConnection); survives Start and Stop
+}
+
+// sinkSubscriber is a callback that receives events filtered for one sink
+type sinkSubscriber struct {
+	sink     string
+	callback func(interface{})
+}
+
+// connectionChange is a connection status change waiting to be delivered to subscribers
+type connectionChange struct {
+	key  string
+	conn BigoConnection
 }
 
 // BigoListenerSession manages hidden browser connections to Bigo rooms
@@ -51,6 +65,9 @@ type BigoListenerSession struct {
 	stopChan        chan struct{}
 	recentGifts     []listener.BigoGift
 	onGiftCallbacks []func(interface{})
+	sinkSubscribers []sinkSubscriber
+	onConnection    []func(key string, conn BigoConnection)
+	eventFilter     *filter.Filter // Optional sender/anti-spam rules applied before delivery
 	giftLibrary     []api.GiftDefinition
 }
 
@@ -69,8 +86,17 @@ func NewBigoListenerSession(browserManager *browser.Manager) *BigoListenerSessio
 	}
 }
 
+// SetConfig replaces the config of a running session
+func (b *BigoListenerSession) SetConfig(cfg *api.Config) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.config = cfg
+}
+
 // Start starts the Bigo listener session and connects to the main room (config.RoomId)
 func (b *BigoListenerSession) Start(config *api.Config) error {
+	var changes []connectionChange
+	defer func() { b.notifyConnection(changes) }() // Runs after unlock
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
@@ -90,8 +116,8 @@ func (b *BigoListenerSession) Start(config *api.Config) error {
 
 	fmt.Printf("[BigoListener] Starting with Gift Library size: %d\n", len(b.giftLibrary))
 
-	// Clear previous connections
-	b.connections = make(map[string]*BigoConnection)
+	// Clear previous connections, keeping the app-managed ones
+	b.connections = b.externalConnections()
 	b.listeners = make(map[string]*listener.BigoListener)
 
 	roomID := config.RoomId
@@ -115,6 +141,7 @@ func (b *BigoListenerSession) Start(config *api.Config) error {
 	}
 
 	b.connections[roomID] = conn
+	changes = append(changes, connectionChange{key: roomID, conn: *conn})
 	fmt.Printf("[BigoListener] Initialized connection for Main Room %s\n", roomID)
 
 	// Fetch user info from API
@@ -170,6 +197,8 @@ func (b *BigoListenerSession) GetBigoIdByRoomId(numericId string) string {
 
 // Stop stops the Bigo listener session and closes all browser connections
 func (b *BigoListenerSession) Stop() error {
+	var changes []connectionChange
+	defer func() { b.notifyConnection(changes) }() // Runs after unlock
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
@@ -191,13 +220,18 @@ func (b *BigoListenerSession) Stop() error {
 
 	// Close all browser connections and listeners
 	for roomId, conn := range b.connections {
+		if conn.external {
+			continue // Released by the app when it stops the browser
+		}
 		conn.Status = "DISCONNECTED"
+		conn.Error = "listener stopped"
+		changes = append(changes, connectionChange{key: roomId, conn: *conn})
 		fmt.Printf("[BigoListener] Disconnected from %s (room: %s)\n", conn.IdolName, roomId)
 	}
 
 	// Clear state
 	b.listeners = make(map[string]*listener.BigoListener)
-	b.connections = make(map[string]*BigoConnection)
+	b.connections = b.externalConnections()
 	b.isActive = false
 
 	fmt.Println("[BigoListener] ✓ Stopped successfully")
@@ -265,11 +299,8 @@ func (b *BigoListenerSession) startRealListener(mapKey, urlId, idolName string)
 		}
 		b.mutex.Unlock()
 
-		// Buffer the event
-		b.BufferEvent(gift)
-
-		// Notify subscribers
-		b.notifySubscribers(gift)
+		// Filter, buffer and notify subscribers
+		b.dispatch(gift, true)
 
 		// Add to recent gifts log
 		b.mutex.Lock()
@@ -290,8 +321,8 @@ func (b *BigoListenerSession) startRealListener(mapKey, urlId, idolName string)
 		}
 		b.mutex.Unlock()
 
-		// Notify subscribers (send to BB-Core)
-		b.notifySubscribers(chat)
+		// Filter and notify subscribers (send to BB-Core)
+		b.dispatch(chat, false)
 	})
 
 	// 4. Start listening
@@ -403,6 +434,8 @@ func (b *BigoListenerSession) cleanupOldEvents() {
 
 // UpdateConnectionStatus updates the status of a specific connection
 func (b *BigoListenerSession) UpdateConnectionStatus(bigoRoomId, status, errorMsg string, msgCount int64) {
+	var changes []connectionChange
+	defer func() { b.notifyConnection(changes) }() // Runs after unlock
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
@@ -417,6 +450,52 @@ func (b *BigoListenerSession) UpdateConnectionStatus(bigoRoomId, status, errorMs
 	conn.MessagesReceived = msgCount
 	// conn.TotalDiamonds is not updated here, preserved from state
 	conn.LastMessageAt = time.Now()
+	changes = append(changes, connectionChange{key: bigoRoomId, conn: *conn})
+}
+
+// TrackConnection registers a connection whose listener is run by the app (e.g. the streamer
+// rooms of a PK session) as CONNECTING. Its status then goes through UpdateConnectionStatus
+// like the session's own connections, until ReleaseConnection.
+func (b *BigoListenerSession) TrackConnection(bigoRoomId string) {
+	var changes []connectionChange
+	defer func() { b.notifyConnection(changes) }() // Runs after unlock
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, exists := b.connections[bigoRoomId]; exists {
+		return
+	}
+	conn := &BigoConnection{BigoRoomId: bigoRoomId, BigoId: bigoRoomId, IdolName: bigoRoomId, Status: "CONNECTING", external: true}
+	b.connections[bigoRoomId] = conn
+	changes = append(changes, connectionChange{key: bigoRoomId, conn: *conn})
+}
+
+// ReleaseConnection reports an app-managed connection as DISCONNECTED and forgets it
+func (b *BigoListenerSession) ReleaseConnection(bigoRoomId, reason string) {
+	var changes []connectionChange
+	defer func() { b.notifyConnection(changes) }() // Runs after unlock
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	conn, exists := b.connections[bigoRoomId]
+	if !exists || !conn.external {
+		return
+	}
+	conn.Status = "DISCONNECTED"
+	conn.Error = reason
+	changes = append(changes, connectionChange{key: bigoRoomId, conn: *conn})
+	delete(b.connections, bigoRoomId)
+}
+
+// externalConnections returns the app-managed connections. Caller must hold mutex.
+func (b *BigoListenerSession) externalConnections() map[string]*BigoConnection {
+	connections := make(map[string]*BigoConnection)
+	for key, conn := range b.connections {
+		if conn.external {
+			connections[key] = conn
+		}
+	}
+	return connections
 }
 
 // parsePayloadGift logic also calls giftHandlers, so startRealListener handles it via l.OnGift?
@@ -438,7 +517,14 @@ func (b *BigoListenerSession) GetStatus() BigoListenerStatus {
 	gifts := make([]listener.BigoGift, len(b.recentGifts))
 	copy(gifts, b.recentGifts)
 
+	var filterStats *filter.Stats
+	if b.eventFilter != nil {
+		stats := b.eventFilter.Stats()
+		filterStats = &stats
+	}
+
 	return BigoListenerStatus{
+		FilterStats:    filterStats,
 		IsActive:       b.isActive,
 		StartTime:      b.startTime,
 		TotalIdols:     len(b.connections),
@@ -474,7 +560,9 @@ func (b *BigoListenerSession) simulateConnection(conn *BigoConnection) {
 
 	b.mutex.Lock()
 	conn.Status = "CONNECTED"
+	change := connectionChange{key: conn.BigoRoomId, conn: *conn}
 	b.mutex.Unlock()
+	b.notifyConnection([]connectionChange{change})
 
 	fmt.Printf("[BigoListener] ✓ Connected to %s (room: %s)\n", conn.IdolName, conn.BigoRoomId)
 }
@@ -488,6 +576,7 @@ type BigoListenerStatus struct {
 	BufferedEvents int                 `json:"bufferedEvents"`
 	Connections    []BigoConnection    `json:"connections"`
 	RecentGifts    []listener.BigoGift `json:"recentGifts"`
+	FilterStats    *filter.Stats       `json:"filterStats,omitempty"`
 }
 
 // SetGiftLibrary updates the gift library used for diamond value lookup
@@ -508,16 +597,113 @@ func (b *BigoListenerSession) SubscribeOnGift(callback func(interface{})) {
 	b.onGiftCallbacks = append(b.onGiftCallbacks, callback)
 }
 
-// notifySubscribers notifies all registered callbacks
-func (b *BigoListenerSession) notifySubscribers(event interface{}) {
+// SubscribeSink registers a callback that only receives events allowed for the given sink
+// (see filter.SinkBBCore and filter.SinkOverlay). Masked events are delivered in masked form.
+func (b *BigoListenerSession) SubscribeSink(sink string, callback func(interface{})) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.sinkSubscribers = append(b.sinkSubscribers, sinkSubscriber{sink: sink, callback: callback})
+}
+
+// connectionsByKey returns a copy of the connections by map key, the key SubscribeConnectionStatus reports
+func (b *BigoListenerSession) connectionsByKey() map[string]BigoConnection {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	connections := make(map[string]BigoConnection, len(b.connections))
+	for key, conn := range b.connections {
+		connections[key] = *conn
+	}
+	return connections
+}
+
+// SubscribeConnectionStatus registers a callback for connection status changes.
+// key is the connection map key, which stays stable when the room ID gets resolved.
+func (b *BigoListenerSession) SubscribeConnectionStatus(callback func(key string, conn BigoConnection)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.onConnection = append(b.onConnection, callback)
+}
+
+// notifyConnection delivers connection status changes. Must be called without holding the lock.
+func (b *BigoListenerSession) notifyConnection(changes []connectionChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	b.mutex.RLock()
+	callbacks := make([]func(string, BigoConnection), len(b.onConnection))
+	copy(callbacks, b.onConnection)
+	b.mutex.RUnlock()
+
+	for _, change := range changes {
+		for _, cb := range callbacks {
+			cb(change.key, change.conn)
+		}
+	}
+}
+
+// SetFilter installs the filter applied to events before they reach sink subscribers (nil disables filtering)
+func (b *BigoListenerSession) SetFilter(f *filter.Filter) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.eventFilter = f
+}
+
+// Dispatch filters and delivers a gift or chat received by a listener outside this session,
+// e.g. the per-streamer listeners of a PK session, exactly like events of its own listener
+func (b *BigoListenerSession) Dispatch(event interface{}) {
+	_, isGift := event.(listener.BigoGift)
+	b.dispatch(event, isGift)
+}
+
+// dispatch filters an event once, buffers it for BB-Core if requested and notifies all subscribers
+func (b *BigoListenerSession) dispatch(event interface{}, buffer bool) {
+	b.mutex.RLock()
+	eventFilter := b.eventFilter
+	b.mutex.RUnlock()
+
+	if eventFilter == nil {
+		if buffer {
+			b.BufferEvent(event)
+		}
+		b.notifySubscribers(event, nil)
+		return
+	}
+
+	result := eventFilter.Apply(event)
+	if buffer {
+		if coreEvent, ok := result.For(filter.SinkBBCore); ok {
+			b.BufferEvent(coreEvent)
+		}
+	}
+	b.notifySubscribers(event, &result)
+}
+
+// notifySubscribers notifies all registered callbacks.
+// Unfiltered callbacks get the raw event; sink subscribers get the filtered result (if any).
+func (b *BigoListenerSession) notifySubscribers(event interface{}, result *filter.Result) {
 	// We use a read lock to copy callbacks, then call them outside the lock to avoid deadlocks
 	b.mutex.RLock()
 	callbacks := make([]func(interface{}), len(b.onGiftCallbacks))
 	copy(callbacks, b.onGiftCallbacks)
+	subscribers := make([]sinkSubscriber, len(b.sinkSubscribers))
+	copy(subscribers, b.sinkSubscribers)
 	b.mutex.RUnlock()
 
 	for _, cb := range callbacks {
 		// Run in goroutine to allow non-blocking processing
 		go cb(event)
 	}
+
+	for _, sub := range subscribers {
+		sinkEvent := event
+		if result != nil {
+			var ok bool
+			if sinkEvent, ok = result.For(sub.sink); !ok {
+				continue
+			}
+		}
+		go sub.callback(sinkEvent)
+	}
 }
@@ -0,0 +1,8 @@
+package session
+
+// SetListening marks the manager's Bigo listener as active without opening a browser
+func SetListening(m *Manager, active bool) {
+	m.bigoListener.mutex.Lock()
+	defer m.bigoListener.mutex.Unlock()
+	m.bigoListener.isActive = active
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -15,6 +16,8 @@ type Heartbeat struct {
 	interval  time.Duration
 	ticker    *time.Ticker
 	stopChan  chan struct{}
+	ctx       context.Context // Cancelled by Stop so an in-flight heartbeat does not delay shutdown
+	cancel    context.CancelFunc
 	mutex     sync.Mutex
 	running   bool
 }
@@ -24,12 +27,16 @@ func NewHeartbeat(manager *Manager, apiClient *api.Client, roomId string, interv
 		interval = 30 * time.Second
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Heartbeat{
 		manager:   manager,
 		apiClient: apiClient,
 		roomId:    roomId,
 		interval:  interval,
 		stopChan:  make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 }
 
@@ -59,6 +66,7 @@ func (h *Heartbeat) Stop() {
 
 	h.running = false
 	h.ticker.Stop()
+	h.cancel()
 	close(h.stopChan)
 
 	fmt.Printf("[Heartbeat] Stopped\n")
@@ -86,7 +94,11 @@ func (h *Heartbeat) sendStatus() {
 		Connections: status.Connections,
 	}
 
-	if err := h.apiClient.SendHeartbeat(req); err != nil {
+	// A heartbeat that outlives its interval is stale anyway
+	ctx, cancel := context.WithTimeout(h.ctx, h.interval)
+	defer cancel()
+
+	if err := h.apiClient.SendHeartbeatContext(ctx, req); err != nil {
 		fmt.Printf("[Heartbeat] ERROR: Failed to send heartbeat: %v\n", err)
 	} else {
 		fmt.Printf("[Heartbeat] ✓ Sent (connections: %d)\n", len(status.Connections))
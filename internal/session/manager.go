@@ -1,36 +1,160 @@
 package session
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"bbapp/internal/api"
 	"bbapp/internal/browser"
 	"bbapp/internal/config"
+	"bbapp/internal/dance"
+	"bbapp/internal/filter"
+	"bbapp/internal/goals"
+	"bbapp/internal/leaderboard"
+	"bbapp/internal/listener"
+	"bbapp/internal/report"
+	"bbapp/internal/rules"
+	"bbapp/internal/sinks"
+	"bbapp/internal/stomp"
 )
 
 type Manager struct {
 	apiClient      *api.Client
 	deviceHash     string
+	eventFilter    *filter.Filter
 	browserManager *browser.Manager
 	bigoListener   *BigoListenerSession
 	bbcoreStream   *BBCoreStreamSession
 	config         *config.Manager
+	giftLibrary    []api.GiftDefinition // Lets config indexes match binding gifts by name or ID
+	leaderboard    *leaderboard.Board
+	leaderboardDir string
+	onLeaderboard  []func(leaderboard.Delta)
+	recorder       *report.Recorder
+	danceQueue     *dance.Queue // Sticker Dance mode (nil when not running)
+	onDance        []func(dance.Event)
+	goalTracker    *goals.Tracker // Free Mode (nil when not running)
+	onGoal         []func(goals.Event)
+	ruleEngine     *rules.Engine // Trigger/action rules (nil when none are set)
+	onRule         []func(rules.Firing)
+	eventSinks     *sinks.Set // Partner webhook/file/broker sinks (nil when none are set)
+	onStompState   []func(stomp.StateEvent)
+	validateTrial  TrialValidator // Kept across Initialize, which replaces bbcoreStream
+	onRevoked      func(reason string)
+	dataMutex      sync.RWMutex // Guards leaderboard, recorder, onStompState and the fields set by Initialize, which are used while mutex is held
 	mutex          sync.RWMutex
 }
 
 func NewManager() *Manager {
 	browserMgr := browser.NewManager()
-	return &Manager{
+	m := &Manager{
 		browserManager: browserMgr,
 		bigoListener:   NewBigoListenerSession(browserMgr),
+		bbcoreStream:   NewBBCoreStreamSession(nil, ""), // Replaced by Initialize
+		leaderboard:    leaderboard.NewBoard(),
+		recorder:       report.NewRecorder(),
 	}
+
+	// Leaderboards and reports count the same events that are scored by BB-Core
+	m.bigoListener.SubscribeSink(filter.SinkBBCore, m.recordEvent)
+	m.bigoListener.SubscribeSink(filter.SinkBBCore, m.feedGoals)
+	m.bigoListener.SubscribeSink(filter.SinkBBCore, m.evaluateRules)
+	m.bigoListener.SubscribeSink(filter.SinkBBCore, m.forwardToSinks)
+	m.bigoListener.SubscribeSink(filter.SinkOverlay, m.feedDance)
+	m.bigoListener.SubscribeConnectionStatus(func(key string, conn BigoConnection) {
+		_, recorder := m.sessionData()
+		recorder.RecordConnection(key, conn.IdolName, conn.Status, conn.Error)
+	})
+	m.bbcoreStream.SubscribeStompState(m.notifyStompState)
+	return m
 }
 
+// Initialize sets the BB-Core API client and device hash, replacing the BB-Core stream session
 func (m *Manager) Initialize(apiClient *api.Client, deviceHash string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.initializeLocked(apiClient, deviceHash)
+}
+
+// AttachAPIClient initializes a manager created before login. It does nothing and returns false
+// once a client is attached or while the BB-Core stream runs.
+func (m *Manager) AttachAPIClient(apiClient *api.Client, deviceHash string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if apiClient == nil || m.apiClient != nil || m.bbcoreStream.IsActive() {
+		return false
+	}
+	m.initializeLocked(apiClient, deviceHash)
+	return true
+}
+
+// initializeLocked replaces the BB-Core stream session. Caller must hold mutex; dataMutex covers
+// the getters that don't take mutex.
+func (m *Manager) initializeLocked(apiClient *api.Client, deviceHash string) {
+	stream := NewBBCoreStreamSession(apiClient, deviceHash)
+	stream.SubscribeStompState(m.notifyStompState)
+	if m.validateTrial != nil {
+		stream.SetTrialValidator(m.validateTrial, m.onRevoked)
+	}
+
+	m.dataMutex.Lock()
 	m.apiClient = apiClient
 	m.deviceHash = deviceHash
-	m.bbcoreStream = NewBBCoreStreamSession(apiClient, deviceHash)
+	m.bbcoreStream = stream
+	m.dataMutex.Unlock()
+}
+
+// SetTrialValidator sets how stream starts validate the trial (see BBCoreStreamSession.SetTrialValidator)
+func (m *Manager) SetTrialValidator(validate TrialValidator, onRevoked func(reason string)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.validateTrial, m.onRevoked = validate, onRevoked
+	m.bbcoreStream.SetTrialValidator(validate, onRevoked)
+}
+
+// stream returns the BB-Core stream session for callers that don't hold mutex
+func (m *Manager) stream() *BBCoreStreamSession {
+	m.dataMutex.RLock()
+	defer m.dataMutex.RUnlock()
+	return m.bbcoreStream
+}
+
+// IsRunning reports whether the room is listening or streaming; only running rooms count against the room quota
+func (m *Manager) IsRunning() bool {
+	return m.bigoListener.IsActive() || m.stream().IsActive()
+}
+
+// UpdateConfig pushes a new config into the running sessions and returns what changed.
+// Attribution indexes are rebuilt and swapped in one step; sender bindings survive.
+func (m *Manager) UpdateConfig(cfg *api.Config) config.Diff {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var old *api.Config
+	if m.config != nil {
+		old = m.config.GetConfig()
+	}
+	diff := config.Compare(old, cfg)
+	if diff.Empty() {
+		return diff
+	}
+
+	cfgMgr := m.indexConfig(cfg)
+	m.config = cfgMgr
+	m.bigoListener.SetConfig(cfg)
+	m.bbcoreStream.UpdateConfig(cfgMgr)
+	return diff
+}
+
+// HasAPIClient reports whether the manager was initialized with a BB-Core API client
+func (m *Manager) HasAPIClient() bool {
+	m.dataMutex.RLock()
+	defer m.dataMutex.RUnlock()
+	return m.apiClient != nil
 }
 
 // StartBigoListener starts only the Bigo listener session
@@ -40,7 +164,8 @@ func (m *Manager) StartBigoListener(cfg *api.Config) error {
 
 	fmt.Printf("[Manager] Starting Bigo listener (current state: active=%v)\n", m.bigoListener.IsActive())
 
-	m.config = config.NewManager(cfg)
+	m.config = m.indexConfig(cfg)
+	m.resetSessionData()
 	err := m.bigoListener.Start(cfg)
 	if err != nil {
 		fmt.Printf("[Manager] ERROR starting Bigo listener: %v\n", err)
@@ -55,52 +180,72 @@ func (m *Manager) StopBigoListener() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	return m.bigoListener.Stop()
+	err := m.bigoListener.Stop()
+	m.saveLeaderboard()
+	return err
 }
 
 // StartBBCoreStream starts only the BB-Core streaming session
 // Auto-starts Bigo listener if not already active (per user preference)
 func (m *Manager) StartBBCoreStream(roomId string, cfg *api.Config, bbCoreURL, accessToken string, durationMinutes int) error {
+	if err := m.validate(cfg); err != nil {
+		return err
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	m.config = m.indexConfig(cfg)
+
 	// Auto-start Bigo listener if not active
 	if !m.bigoListener.IsActive() {
 		fmt.Println("[Manager] Auto-starting Bigo listener before BB-Core stream...")
-		m.config = config.NewManager(cfg)
+		m.resetSessionData()
 		if err := m.bigoListener.Start(cfg); err != nil {
 			return fmt.Errorf("failed to auto-start Bigo listener: %w", err)
 		}
 		fmt.Println("[Manager] ✓ Bigo listener auto-started")
 	}
 
-	return m.bbcoreStream.Start(roomId, cfg, m.bigoListener, bbCoreURL, accessToken, durationMinutes)
+	return m.bbcoreStream.Start(roomId, m.config, m.bigoListener, bbCoreURL, accessToken, durationMinutes)
 }
 
 // StopBBCoreStream stops only the BB-Core streaming session
 // Keeps Bigo listener running (continues buffering events)
 func (m *Manager) StopBBCoreStream(reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	return m.StopBBCoreStreamContext(ctx, reason)
+}
+
+// StopBBCoreStreamContext is like StopBBCoreStream but bounds the BB-Core stop call by ctx
+func (m *Manager) StopBBCoreStreamContext(ctx context.Context, reason string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	return m.bbcoreStream.Stop(reason)
+	return m.bbcoreStream.StopContext(ctx, reason)
 }
 
 // Start starts both sessions (convenience method for backward compatibility)
 func (m *Manager) Start(roomId string, cfg *api.Config, bbCoreURL, accessToken string, durationMinutes int) error {
+	if err := m.validate(cfg); err != nil {
+		return err
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	fmt.Println("[Manager] Starting both Bigo listener and BB-Core stream sessions...")
 
 	// Start Bigo listener first
-	m.config = config.NewManager(cfg)
+	m.config = m.indexConfig(cfg)
+	m.resetSessionData()
 	if err := m.bigoListener.Start(cfg); err != nil {
 		return fmt.Errorf("failed to start Bigo listener: %w", err)
 	}
 
 	// Then start BB-Core stream
-	if err := m.bbcoreStream.Start(roomId, cfg, m.bigoListener, bbCoreURL, accessToken, durationMinutes); err != nil {
+	if err := m.bbcoreStream.Start(roomId, m.config, m.bigoListener, bbCoreURL, accessToken, durationMinutes); err != nil {
 		// Rollback: stop Bigo listener
 		m.bigoListener.Stop()
 		return fmt.Errorf("failed to start BB-Core stream: %w", err)
@@ -112,6 +257,23 @@ func (m *Manager) Start(roomId string, cfg *api.Config, bbCoreURL, accessToken s
 
 // Stop stops both sessions (convenience method for backward compatibility)
 func (m *Manager) Stop(reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	return m.StopContext(ctx, reason)
+}
+
+// StopContext is like Stop but bounds the BB-Core stop call and the sink flush by ctx
+func (m *Manager) StopContext(ctx context.Context, reason string) error {
+	var danceQueue *dance.Queue
+	var eventSinks *sinks.Set
+	defer func() {
+		if danceQueue != nil {
+			danceQueue.Stop() // After unlock, see StartStickerDance
+		}
+		if eventSinks != nil {
+			eventSinks.Close(ctx) // Flushes queued events until ctx expires, see SetSinks
+		}
+	}()
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -119,9 +281,15 @@ func (m *Manager) Stop(reason string) error {
 
 	var streamErr, listenerErr error
 
+	danceQueue = m.danceQueue
+	m.danceQueue = nil
+	m.goalTracker = nil
+	eventSinks = m.eventSinks
+	m.eventSinks = nil
+
 	// Stop BB-Core stream first
 	if m.bbcoreStream.IsActive() {
-		streamErr = m.bbcoreStream.Stop(reason)
+		streamErr = m.bbcoreStream.StopContext(ctx, reason)
 	}
 
 	// Then stop Bigo listener
@@ -129,6 +297,8 @@ func (m *Manager) Stop(reason string) error {
 		listenerErr = m.bigoListener.Stop()
 	}
 
+	m.saveLeaderboard()
+
 	if streamErr != nil {
 		return fmt.Errorf("failed to stop BB-Core stream: %w", streamErr)
 	}
@@ -147,7 +317,7 @@ func (m *Manager) GetBigoListenerStatus() BigoListenerStatus {
 
 // GetBBCoreStreamStatus returns the status of the BB-Core stream session
 func (m *Manager) GetBBCoreStreamStatus() BBCoreStreamStatus {
-	return m.bbcoreStream.GetStatus()
+	return m.stream().GetStatus()
 }
 
 // GetStatus returns the combined status (for backward compatibility)
@@ -187,14 +357,17 @@ func (m *Manager) GetConfig() *config.Manager {
 
 // GetStompClient returns the STOMP client from BB-Core stream session
 func (m *Manager) GetStompClient() interface{} {
-	if m.bbcoreStream == nil {
+	stream := m.stream()
+	if stream == nil {
 		return nil
 	}
-	return m.bbcoreStream.GetStompClient()
+	return stream.GetStompClient()
 }
 
 // GetDeviceHash returns the device hash
 func (m *Manager) GetDeviceHash() string {
+	m.dataMutex.RLock()
+	defer m.dataMutex.RUnlock()
 	return m.deviceHash
 }
 
@@ -203,9 +376,44 @@ func (m *Manager) UpdateConnectionStatus(bigoRoomId, status, errorMsg string, ms
 	m.bigoListener.UpdateConnectionStatus(bigoRoomId, status, errorMsg, msgCount)
 }
 
-// SetGiftLibrary updates the gift library in the Bigo listener
+// TrackConnection registers a Bigo room whose listener is run by the app (see BigoListenerSession.TrackConnection)
+func (m *Manager) TrackConnection(bigoRoomId string) {
+	m.bigoListener.TrackConnection(bigoRoomId)
+}
+
+// ReleaseConnection reports an app-managed Bigo room as disconnected and forgets it
+func (m *Manager) ReleaseConnection(bigoRoomId, reason string) {
+	m.bigoListener.ReleaseConnection(bigoRoomId, reason)
+}
+
+// SetGiftLibrary updates the gift library in the Bigo listener and re-indexes the config
 func (m *Manager) SetGiftLibrary(lib []api.GiftDefinition) {
 	m.bigoListener.SetGiftLibrary(lib)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.giftLibrary = lib
+	if m.config != nil {
+		m.config = m.indexConfig(m.config.GetConfig())
+		m.bbcoreStream.UpdateConfig(m.config)
+	}
+}
+
+// indexConfig builds the config index with the gift library. Caller must hold the lock.
+func (m *Manager) indexConfig(cfg *api.Config) *config.Manager {
+	return config.NewManagerWithGifts(cfg, m.giftLibrary)
+}
+
+// validate rejects a config BB-Core would score ambiguously
+func (m *Manager) validate(cfg *api.Config) error {
+	m.mutex.RLock()
+	library := m.giftLibrary
+	m.mutex.RUnlock()
+
+	if err := config.Validate(cfg, library); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
 }
 
 // BufferEvent buffers an event in the Bigo listener session
@@ -219,3 +427,526 @@ func (m *Manager) BufferEvent(event interface{}) {
 func (m *Manager) SubscribeOnGift(callback func(interface{})) {
 	m.bigoListener.SubscribeOnGift(callback)
 }
+
+// SubscribeSink subscribes to events that pass the filter rules for a sink
+func (m *Manager) SubscribeSink(sink string, callback func(interface{})) {
+	m.bigoListener.SubscribeSink(sink, callback)
+}
+
+// DispatchEvent sends a gift or chat from a listener the app manages through the filter rules
+// to BB-Core, overlays, leaderboards and sinks (see BigoListenerSession.Dispatch)
+func (m *Manager) DispatchEvent(event interface{}) {
+	m.bigoListener.Dispatch(event)
+}
+
+// SetFilterRules validates and applies sender/anti-spam rules to the event pipeline.
+// Hit counters restart whenever rules are replaced.
+func (m *Manager) SetFilterRules(rules filter.Rules) error {
+	f, err := filter.NewFilter(rules)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.eventFilter = f
+	m.mutex.Unlock()
+
+	m.bigoListener.SetFilter(f)
+	return nil
+}
+
+// GetFilterStats returns filter hit counters (zero stats when no rules are set)
+func (m *Manager) GetFilterStats() filter.Stats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.eventFilter == nil {
+		return filter.Stats{Hits: map[string]int64{}}
+	}
+	return m.eventFilter.Stats()
+}
+
+// SubscribeStompState registers a callback for BB-Core STOMP connection state changes
+func (m *Manager) SubscribeStompState(callback func(stomp.StateEvent)) {
+	m.dataMutex.Lock()
+	defer m.dataMutex.Unlock()
+	m.onStompState = append(m.onStompState, callback)
+}
+
+func (m *Manager) notifyStompState(event stomp.StateEvent) {
+	m.dataMutex.RLock()
+	callbacks := make([]func(stomp.StateEvent), len(m.onStompState))
+	copy(callbacks, m.onStompState)
+	m.dataMutex.RUnlock()
+
+	for _, cb := range callbacks {
+		cb(event)
+	}
+}
+
+// SetLeaderboardDir sets the directory leaderboards are written to when the session stops (empty disables saving)
+func (m *Manager) SetLeaderboardDir(dir string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.leaderboardDir = dir
+}
+
+// SubscribeLeaderboard registers a callback for leaderboard changes caused by each gift
+func (m *Manager) SubscribeLeaderboard(callback func(leaderboard.Delta)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onLeaderboard = append(m.onLeaderboard, callback)
+}
+
+// GetLeaderboard returns the top senders overall, per team and per streamer (limit <= 0 means all)
+func (m *Manager) GetLeaderboard(limit int) leaderboard.Snapshot {
+	board, _ := m.sessionData()
+	return board.Snapshot(limit)
+}
+
+// BuildReport assembles the end-of-session report. Call after Stop so BB-Core final data is included.
+func (m *Manager) BuildReport(roomId, reason string) report.Report {
+	board, recorder := m.sessionData()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	meta := report.Meta{
+		RoomId:     roomId,
+		StopReason: reason,
+		TopGifters: board.Snapshot(10).Overall,
+	}
+	if m.config != nil {
+		meta.Config = m.config.GetConfig()
+	}
+	if result := m.bbcoreStream.LastStopResult(); result != nil {
+		meta.SessionId = result.SessionId
+		meta.BBCoreFinal = result.FinalData
+	}
+	return recorder.Build(meta)
+}
+
+// resetSessionData starts fresh leaderboards and report data for a new listener session
+func (m *Manager) resetSessionData() {
+	m.dataMutex.Lock()
+	defer m.dataMutex.Unlock()
+	m.leaderboard = leaderboard.NewBoard()
+	m.recorder = report.NewRecorder()
+}
+
+// sessionData returns the current leaderboard and report recorder
+func (m *Manager) sessionData() (*leaderboard.Board, *report.Recorder) {
+	m.dataMutex.RLock()
+	defer m.dataMutex.RUnlock()
+	return m.leaderboard, m.recorder
+}
+
+// recordEvent adds gifts to the leaderboards and the session report, and counts chats
+func (m *Manager) recordEvent(event interface{}) {
+	board, recorder := m.sessionData()
+
+	if chat, ok := event.(listener.BigoChat); ok {
+		recorder.RecordChat(chat)
+		return
+	}
+
+	gift, ok := event.(listener.BigoGift)
+	if !ok {
+		return
+	}
+
+	m.mutex.RLock()
+	cfg := m.config
+	callbacks := make([]func(leaderboard.Delta), len(m.onLeaderboard))
+	copy(callbacks, m.onLeaderboard)
+	m.mutex.RUnlock()
+
+	teamId, streamerId := attributeGift(cfg, gift)
+	recorder.RecordGift(gift, teamId, streamerId)
+	delta := board.Record(gift, teamId, streamerId)
+
+	for _, cb := range callbacks {
+		cb(delta)
+	}
+}
+
+// saveLeaderboard writes the leaderboard to disk. Caller must hold the lock.
+func (m *Manager) saveLeaderboard() {
+	board, _ := m.sessionData()
+	if m.leaderboardDir == "" || len(board.Snapshot(1).Overall) == 0 {
+		return
+	}
+
+	path := filepath.Join(m.leaderboardDir, time.Now().Format("20060102-150405")+".json")
+	if err := board.Save(path); err != nil {
+		fmt.Printf("[Manager] WARNING: Failed to save leaderboard: %v\n", err)
+		return
+	}
+	fmt.Printf("[Manager] ✓ Leaderboard saved to %s\n", path)
+}
+
+// AttributeGift resolves the team and streamer a gift counts towards with the session's config index
+func (m *Manager) AttributeGift(gift listener.BigoGift) (teamId, streamerId string) {
+	m.mutex.RLock()
+	cfg := m.config
+	m.mutex.RUnlock()
+	return attributeGift(cfg, gift)
+}
+
+// attributeGift resolves the team and streamer a gift counts towards.
+// Binding gifts take priority over the room the gift was received in.
+func attributeGift(cfg *config.Manager, gift listener.BigoGift) (teamId, streamerId string) {
+	if cfg == nil {
+		return "", ""
+	}
+
+	if streamer, err := cfg.LookupStreamerBinding(gift.GiftName, gift.GiftId); err == nil {
+		return teamIdOf(cfg, streamer), streamer.StreamerId
+	}
+	if team, err := cfg.LookupTeamBinding(gift.GiftName, gift.GiftId); err == nil {
+		return team.TeamId, ""
+	}
+	if streamer, err := cfg.LookupStreamerByAnyBigoId(gift.BigoRoomId); err == nil {
+		return teamIdOf(cfg, streamer), streamer.StreamerId
+	}
+	return "", ""
+}
+
+func teamIdOf(cfg *config.Manager, streamer *api.Streamer) string {
+	if team := cfg.TeamOf(streamer); team != nil {
+		return team.TeamId
+	}
+	return ""
+}
+
+// StartStickerDance starts Sticker Dance mode: mapped gifts queue dance performances.
+// Runs on the Bigo listener only (auto-started if needed); BB-Core is not required.
+func (m *Manager) StartStickerDance(cfg *api.Config, danceCfg dance.Config) error {
+	queue, err := dance.NewQueue(danceCfg)
+	if err != nil {
+		return fmt.Errorf("invalid sticker dance config: %w", err)
+	}
+	queue.Subscribe(m.notifyDance)
+
+	// The previous queue notifies subscribers when stopped, so stop it after unlocking
+	var previous *dance.Queue
+	defer func() {
+		if previous != nil {
+			previous.Stop()
+		}
+	}()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.bigoListener.IsActive() {
+		fmt.Println("[Manager] Auto-starting Bigo listener for Sticker Dance...")
+		m.config = m.indexConfig(cfg)
+		m.resetSessionData()
+		if err := m.bigoListener.Start(cfg); err != nil {
+			return fmt.Errorf("failed to auto-start Bigo listener: %w", err)
+		}
+	}
+
+	previous = m.danceQueue
+	m.danceQueue = queue
+
+	fmt.Printf("[Manager] ✓ Sticker Dance started (%d actions, %d mappings)\n", len(danceCfg.Actions), len(danceCfg.Mappings))
+	return nil
+}
+
+// StopStickerDance stops Sticker Dance mode. The Bigo listener is stopped too unless another mode uses it.
+func (m *Manager) StopStickerDance() error {
+	var queue *dance.Queue
+	defer func() {
+		if queue != nil {
+			queue.Stop() // After unlock, see StartStickerDance
+		}
+	}()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.danceQueue == nil {
+		return fmt.Errorf("sticker dance not running")
+	}
+	queue = m.danceQueue
+	m.danceQueue = nil
+
+	return m.stopIdleListenerLocked()
+}
+
+// SkipDance ends the current dance performance early
+func (m *Manager) SkipDance() error {
+	m.mutex.RLock()
+	queue := m.danceQueue
+	m.mutex.RUnlock()
+
+	if queue == nil {
+		return fmt.Errorf("sticker dance not running")
+	}
+	return queue.Skip()
+}
+
+// GetStickerDanceState returns the current and upcoming performances
+func (m *Manager) GetStickerDanceState() dance.State {
+	m.mutex.RLock()
+	queue := m.danceQueue
+	m.mutex.RUnlock()
+
+	if queue == nil {
+		return dance.State{Upcoming: []dance.Performance{}}
+	}
+	return queue.State()
+}
+
+// SubscribeDance registers a callback for Sticker Dance queue events
+func (m *Manager) SubscribeDance(callback func(dance.Event)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onDance = append(m.onDance, callback)
+}
+
+// feedDance queues the dance mapped to a gift while Sticker Dance mode runs
+func (m *Manager) feedDance(event interface{}) {
+	gift, ok := event.(listener.BigoGift)
+	if !ok {
+		return
+	}
+
+	m.mutex.RLock()
+	queue := m.danceQueue
+	m.mutex.RUnlock()
+
+	if queue != nil {
+		queue.HandleGift(gift)
+	}
+}
+
+func (m *Manager) notifyDance(event dance.Event) {
+	m.mutex.RLock()
+	callbacks := make([]func(dance.Event), len(m.onDance))
+	copy(callbacks, m.onDance)
+	m.mutex.RUnlock()
+
+	for _, cb := range callbacks {
+		cb(event)
+	}
+}
+
+// StartFreeMode starts Free Mode: no teams, live gifts advance the configured goals.
+// Runs on the Bigo listener only (auto-started if needed); BB-Core is not required.
+func (m *Manager) StartFreeMode(cfg *api.Config, goalsCfg goals.Config) error {
+	tracker, err := goals.NewTracker(goalsCfg)
+	if err != nil {
+		return fmt.Errorf("invalid free mode goals: %w", err)
+	}
+	tracker.Subscribe(m.notifyGoal)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.bigoListener.IsActive() {
+		fmt.Println("[Manager] Auto-starting Bigo listener for Free Mode...")
+		m.config = m.indexConfig(cfg)
+		m.resetSessionData()
+		if err := m.bigoListener.Start(cfg); err != nil {
+			return fmt.Errorf("failed to auto-start Bigo listener: %w", err)
+		}
+	}
+
+	m.goalTracker = tracker
+	fmt.Printf("[Manager] ✓ Free Mode started (%d goals)\n", len(goalsCfg.Goals))
+	return nil
+}
+
+// StopFreeMode stops Free Mode. The Bigo listener is stopped too unless another mode uses it.
+func (m *Manager) StopFreeMode() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.goalTracker == nil {
+		return fmt.Errorf("free mode not running")
+	}
+	m.goalTracker = nil
+
+	return m.stopIdleListenerLocked()
+}
+
+// stopIdleListenerLocked stops the shared Bigo listener once neither Sticker Dance, Free Mode
+// nor BB-Core uses it. Caller must hold the lock.
+func (m *Manager) stopIdleListenerLocked() error {
+	if !m.bigoListener.IsActive() || m.danceQueue != nil || m.goalTracker != nil || m.bbcoreStream.IsActive() {
+		return nil
+	}
+	err := m.bigoListener.Stop()
+	m.saveLeaderboard()
+	return err
+}
+
+// GetGoalProgress returns the progress of all Free Mode goals (empty when not running)
+func (m *Manager) GetGoalProgress() []goals.Progress {
+	m.mutex.RLock()
+	tracker := m.goalTracker
+	m.mutex.RUnlock()
+
+	if tracker == nil {
+		return []goals.Progress{}
+	}
+	return tracker.Progress()
+}
+
+// ResetGoal sets a Free Mode goal's progress back to zero
+func (m *Manager) ResetGoal(goalId string) error {
+	m.mutex.RLock()
+	tracker := m.goalTracker
+	m.mutex.RUnlock()
+
+	if tracker == nil {
+		return fmt.Errorf("free mode not running")
+	}
+	return tracker.Reset(goalId)
+}
+
+// SubscribeGoals registers a callback for Free Mode goal progress and milestones
+func (m *Manager) SubscribeGoals(callback func(goals.Event)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onGoal = append(m.onGoal, callback)
+}
+
+// feedGoals advances Free Mode goals with a gift
+func (m *Manager) feedGoals(event interface{}) {
+	gift, ok := event.(listener.BigoGift)
+	if !ok {
+		return
+	}
+
+	m.mutex.RLock()
+	tracker := m.goalTracker
+	m.mutex.RUnlock()
+
+	if tracker != nil {
+		tracker.HandleGift(gift)
+	}
+}
+
+func (m *Manager) notifyGoal(event goals.Event) {
+	m.mutex.RLock()
+	callbacks := make([]func(goals.Event), len(m.onGoal))
+	copy(callbacks, m.onGoal)
+	m.mutex.RUnlock()
+
+	for _, cb := range callbacks {
+		cb(event)
+	}
+}
+
+// SetRules validates and applies trigger/action rules (an empty set disables them)
+func (m *Manager) SetRules(set rules.RuleSet) error {
+	engine, err := rules.NewEngine(set)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if len(set.Rules) == 0 {
+		engine = nil
+	}
+	m.ruleEngine = engine
+	return nil
+}
+
+// SubscribeRules registers a callback that executes fired rule actions
+func (m *Manager) SubscribeRules(callback func(rules.Firing)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.onRule = append(m.onRule, callback)
+}
+
+// evaluateRules runs an event through the rule engine and hands firings to subscribers
+func (m *Manager) evaluateRules(event interface{}) {
+	m.mutex.RLock()
+	engine := m.ruleEngine
+	cfg := m.config
+	callbacks := make([]func(rules.Firing), len(m.onRule))
+	copy(callbacks, m.onRule)
+	m.mutex.RUnlock()
+
+	if engine == nil {
+		return
+	}
+
+	normalized, ok := normalizeEvent(cfg, event)
+	if !ok {
+		return
+	}
+	for _, firing := range engine.Evaluate(normalized) {
+		for _, cb := range callbacks {
+			cb(firing)
+		}
+	}
+}
+
+// normalizeEvent attributes a listener gift or chat and converts it into a rules.Event
+func normalizeEvent(cfg *config.Manager, event interface{}) (rules.Event, bool) {
+	// Chats are attributed by the room they were sent in
+	gift, isGift := event.(listener.BigoGift)
+	if chat, ok := event.(listener.BigoChat); ok {
+		gift = listener.BigoGift{BigoRoomId: chat.BigoRoomId}
+	} else if !isGift {
+		return rules.Event{}, false
+	}
+	teamId, streamerId := attributeGift(cfg, gift)
+	return rules.FromListener(event, teamId, streamerId)
+}
+
+// SetSinks validates and opens a room's event sinks, replacing (and flushing) the previous ones
+func (m *Manager) SetSinks(roomId string, cfg sinks.Config) error {
+	set, err := sinks.NewSet(roomId, cfg)
+	if err != nil {
+		return err
+	}
+	if set.Len() == 0 {
+		set = nil
+	}
+
+	m.mutex.Lock()
+	previous := m.eventSinks
+	m.eventSinks = set
+	m.mutex.Unlock()
+
+	// Closing flushes queued events, which may take a few retries
+	if previous != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		previous.Close(ctx)
+	}
+	return nil
+}
+
+// GetSinkHealth returns the delivery status of the room's event sinks
+func (m *Manager) GetSinkHealth() []sinks.Health {
+	m.mutex.RLock()
+	set := m.eventSinks
+	m.mutex.RUnlock()
+
+	if set == nil {
+		return []sinks.Health{}
+	}
+	return set.Health()
+}
+
+// forwardToSinks hands a normalized event to the room's event sinks
+func (m *Manager) forwardToSinks(event interface{}) {
+	m.mutex.RLock()
+	set := m.eventSinks
+	cfg := m.config
+	m.mutex.RUnlock()
+
+	if set == nil {
+		return
+	}
+	if normalized, ok := normalizeEvent(cfg, event); ok {
+		set.Dispatch(normalized)
+	}
+}
@@ -2,6 +2,12 @@ package session_test
 
 import (
 	"testing"
+	"time"
+	"bbapp/internal/api"
+	"bbapp/internal/dance"
+	"bbapp/internal/filter"
+	"bbapp/internal/goals"
+	"bbapp/internal/listener"
 	"bbapp/internal/session"
 )
 
@@ -18,3 +24,73 @@ func TestManager_GetStatus(t *testing.T) {
 		t.Error("Expected inactive status for new manager")
 	}
 }
+
+func TestManager_AttachAPIClientKeepsExistingClient(t *testing.T) {
+	manager := session.NewManager()
+
+	if manager.AttachAPIClient(nil, "hash") {
+		t.Error("Expected nil client to be ignored")
+	}
+	if !manager.AttachAPIClient(api.NewClient("http://localhost", "token"), "hash-1") {
+		t.Fatal("Expected first client to be attached")
+	}
+	if manager.AttachAPIClient(api.NewClient("http://localhost", "token"), "hash-2") {
+		t.Error("Expected second client to be ignored")
+	}
+	if hash := manager.GetDeviceHash(); hash != "hash-1" {
+		t.Errorf("Expected device hash hash-1, got %s", hash)
+	}
+}
+
+func TestManager_StoppingOneModeKeepsSharedListener(t *testing.T) {
+	manager := session.NewManager()
+	session.SetListening(manager, true) // Stands in for the browser-backed listener
+
+	if err := manager.StartStickerDance(&api.Config{}, dance.Config{}); err != nil {
+		t.Fatalf("StartStickerDance failed: %v", err)
+	}
+	if err := manager.StartFreeMode(&api.Config{}, goals.Config{}); err != nil {
+		t.Fatalf("StartFreeMode failed: %v", err)
+	}
+
+	if err := manager.StopStickerDance(); err != nil {
+		t.Fatalf("StopStickerDance failed: %v", err)
+	}
+	if !manager.IsRunning() {
+		t.Fatal("Expected listener to keep running for Free Mode")
+	}
+
+	if err := manager.StopFreeMode(); err != nil {
+		t.Fatalf("StopFreeMode failed: %v", err)
+	}
+	if manager.IsRunning() {
+		t.Error("Expected listener to stop with the last mode")
+	}
+}
+
+func TestManager_DispatchEventAppliesFilter(t *testing.T) {
+	manager := session.NewManager()
+	if err := manager.SetFilterRules(filter.Rules{Senders: filter.SenderRules{Blocked: []string{"spammer"}}}); err != nil {
+		t.Fatalf("SetFilterRules failed: %v", err)
+	}
+
+	overlay := make(chan interface{}, 2)
+	manager.SubscribeSink(filter.SinkOverlay, func(event interface{}) { overlay <- event })
+
+	manager.DispatchEvent(listener.BigoGift{SenderId: "spammer", GiftName: "Rose"})
+	manager.DispatchEvent(listener.BigoGift{SenderId: "fan", GiftName: "Rose"})
+
+	select {
+	case event := <-overlay:
+		if sender := event.(listener.BigoGift).SenderId; sender != "fan" {
+			t.Fatalf("Expected only the gift of fan, got one from %s", sender)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the allowed gift")
+	}
+	select {
+	case event := <-overlay:
+		t.Errorf("Expected blocked sender to be filtered, got %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
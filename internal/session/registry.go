@@ -0,0 +1,130 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrRoomQuotaExceeded is returned when starting a room would exceed the agency room quota
+var ErrRoomQuotaExceeded = errors.New("room quota exceeded")
+
+// Registry keeps one Manager per BB-Core room so several rooms can run in one app instance.
+// Each Manager owns its own Bigo listener, BB-Core stream and browser manager, so rooms are isolated.
+type Registry struct {
+	managers map[string]*Manager // BB-Core roomId -> manager
+	maxRooms int                 // 0 or less means unlimited
+	mutex    sync.RWMutex
+}
+
+// NewRegistry creates a new session registry limited to maxRooms concurrent rooms
+func NewRegistry(maxRooms int) *Registry {
+	return &Registry{
+		managers: make(map[string]*Manager),
+		maxRooms: maxRooms,
+	}
+}
+
+// SetMaxRooms updates the room quota (typically from Agency.MaxRooms after login).
+// Rooms that are already running are kept even if the new quota is lower.
+func (r *Registry) SetMaxRooms(maxRooms int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.maxRooms = maxRooms
+}
+
+// MaxRooms returns the current room quota
+func (r *Registry) MaxRooms() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.maxRooms
+}
+
+// Get returns the manager for a room, if one is registered
+func (r *Registry) Get(roomId string) (*Manager, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	m, ok := r.managers[roomId]
+	return m, ok
+}
+
+// GetOrCreate returns the manager for a room, creating it when missing. Idle managers don't
+// count against the quota, see CheckQuota.
+// init is called once on newly created managers before they become visible to other callers.
+func (r *Registry) GetOrCreate(roomId string, init func(*Manager)) (*Manager, error) {
+	if roomId == "" {
+		return nil, fmt.Errorf("room ID is required")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if m, ok := r.managers[roomId]; ok {
+		return m, nil
+	}
+
+	m := NewManager()
+	if init != nil {
+		init(m)
+	}
+	r.managers[roomId] = m
+
+	fmt.Printf("[Registry] Created session manager for room %s (%d registered)\n", roomId, len(r.managers))
+	return m, nil
+}
+
+// CheckQuota returns ErrRoomQuotaExceeded when starting roomId would run more rooms than the quota.
+// Only running rooms count (see Manager.IsRunning); roomId itself is not counted.
+func (r *Registry) CheckQuota(roomId string) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.maxRooms <= 0 {
+		return nil
+	}
+	running := 0
+	for id, m := range r.managers {
+		if id != roomId && m.IsRunning() {
+			running++
+		}
+	}
+	if running >= r.maxRooms {
+		return fmt.Errorf("%w: %d of %d rooms running", ErrRoomQuotaExceeded, running, r.maxRooms)
+	}
+	return nil
+}
+
+// Remove unregisters a room and returns its manager (nil if unknown).
+// The caller is responsible for stopping the returned manager.
+func (r *Registry) Remove(roomId string) *Manager {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	m, ok := r.managers[roomId]
+	if !ok {
+		return nil
+	}
+	delete(r.managers, roomId)
+	return m
+}
+
+// RoomIds returns the registered room IDs in sorted order
+func (r *Registry) RoomIds() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	ids := make([]string, 0, len(r.managers))
+	for id := range r.managers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Len returns the number of registered rooms
+func (r *Registry) Len() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.managers)
+}
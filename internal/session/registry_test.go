@@ -0,0 +1,97 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+
+	"bbapp/internal/session"
+)
+
+func TestRegistry_GetOrCreate(t *testing.T) {
+	registry := session.NewRegistry(0)
+
+	initCalls := 0
+	init := func(m *session.Manager) { initCalls++ }
+
+	m1, err := registry.GetOrCreate("room-a", init)
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+
+	m2, err := registry.GetOrCreate("room-a", init)
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+
+	if m1 != m2 {
+		t.Error("Expected same manager for same room")
+	}
+	if initCalls != 1 {
+		t.Errorf("Expected init to be called once, got %d", initCalls)
+	}
+
+	m3, _ := registry.GetOrCreate("room-b", init)
+	if m3 == m1 {
+		t.Error("Expected isolated managers for different rooms")
+	}
+
+	ids := registry.RoomIds()
+	if len(ids) != 2 || ids[0] != "room-a" || ids[1] != "room-b" {
+		t.Errorf("Expected [room-a room-b], got %v", ids)
+	}
+}
+
+func TestRegistry_Quota(t *testing.T) {
+	registry := session.NewRegistry(1)
+
+	roomA, err := registry.GetOrCreate("room-a", nil)
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+
+	// Idle rooms don't use the quota
+	if _, err := registry.GetOrCreate("room-b", nil); err != nil {
+		t.Fatalf("Expected an idle room to be created at quota, got %v", err)
+	}
+	if err := registry.CheckQuota("room-b"); err != nil {
+		t.Errorf("Expected room-b to start while room-a is idle, got %v", err)
+	}
+
+	session.SetListening(roomA, true)
+	if err := registry.CheckQuota("room-b"); !errors.Is(err, session.ErrRoomQuotaExceeded) {
+		t.Fatalf("Expected ErrRoomQuotaExceeded, got %v", err)
+	}
+
+	// A running room doesn't count against itself
+	if err := registry.CheckQuota("room-a"); err != nil {
+		t.Errorf("Expected room-a to restart at quota, got %v", err)
+	}
+
+	registry.SetMaxRooms(2)
+	if err := registry.CheckQuota("room-b"); err != nil {
+		t.Errorf("Expected room-b after raising quota, got %v", err)
+	}
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	registry := session.NewRegistry(1)
+	roomA, _ := registry.GetOrCreate("room-a", nil)
+	session.SetListening(roomA, true)
+
+	if m := registry.Remove("room-a"); m == nil {
+		t.Fatal("Expected removed manager")
+	}
+	if _, ok := registry.Get("room-a"); ok {
+		t.Error("Expected room-a to be gone")
+	}
+	if err := registry.CheckQuota("room-b"); err != nil {
+		t.Errorf("Expected free slot after Remove, got %v", err)
+	}
+}
+
+func TestRegistry_EmptyRoomId(t *testing.T) {
+	registry := session.NewRegistry(0)
+	if _, err := registry.GetOrCreate("", nil); err == nil {
+		t.Error("Expected error for empty room ID")
+	}
+}
@@ -0,0 +1,152 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bbapp/internal/message"
+)
+
+// statusDebounce is how long a room's connection status is collected before a STATUS message
+// goes out, so a flapping connection sends its latest state once instead of every change
+var statusDebounce = 2 * time.Second
+
+// roomStatus is the STATUS reporting state of one listener connection
+type roomStatus struct {
+	bigoRoomId string
+	status     string
+	reason     string
+	sentStatus string // Last published, to skip repeats
+	sentReason string
+	timer      *time.Timer // Pending debounced publish
+}
+
+// startReporting starts accepting connection changes, with no room reported yet
+func (s *BBCoreStreamSession) startReporting() {
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
+	s.rooms = make(map[string]*roomStatus)
+	s.reporting = true
+}
+
+// reportConnection records a listener connection change and schedules its STATUS message.
+// States BB-Core doesn't know (e.g. CONNECTING) and changes while the stream is stopped are not reported.
+func (s *BBCoreStreamSession) reportConnection(key string, conn BigoConnection) {
+	switch conn.Status {
+	case message.StatusConnected, message.StatusDisconnected, message.StatusError:
+	default:
+		return
+	}
+
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
+
+	if !s.reporting {
+		return
+	}
+	room, ok := s.rooms[key]
+	if !ok {
+		room = &roomStatus{}
+		s.rooms[key] = room
+	}
+	room.bigoRoomId = conn.BigoRoomId
+	if room.bigoRoomId == "" {
+		room.bigoRoomId = key
+	}
+	room.status, room.reason = conn.Status, conn.Error
+
+	if room.timer == nil {
+		room.timer = time.AfterFunc(statusDebounce, func() { s.flushStatus(key) })
+	}
+}
+
+// flushStatus publishes the latest status of a room if it changed since the last STATUS message
+func (s *BBCoreStreamSession) flushStatus(key string) {
+	s.statusMutex.Lock()
+	room, ok := s.rooms[key]
+	if !ok {
+		s.statusMutex.Unlock()
+		return
+	}
+	room.timer = nil
+	if room.status == room.sentStatus && room.reason == room.sentReason {
+		s.statusMutex.Unlock()
+		return
+	}
+	bigoRoomId, status, reason := room.bigoRoomId, room.status, room.reason
+	s.statusMutex.Unlock()
+
+	s.mutex.RLock()
+	client, active := s.stompClient, s.isActive
+	var msg *message.StatusMessage
+	if active {
+		msg = s.statusMessage(bigoRoomId, status, reason)
+	}
+	s.mutex.RUnlock()
+	if !active || client == nil {
+		return
+	}
+
+	if err := msg.Validate(); err != nil {
+		fmt.Printf("[BBCoreStream] WARNING: Dropping STATUS of %s: %v\n", bigoRoomId, err)
+		return
+	}
+	fmt.Printf("[BBCoreStream] Publishing STATUS %s for %s %s\n", status, bigoRoomId, reason)
+	if err := s.publishOrQueue(client, fmt.Sprintf("/app/room/%s/bigo", msg.RoomID), msg); err != nil {
+		fmt.Printf("[BBCoreStream] WARNING: STATUS of %s not sent: %v\n", bigoRoomId, err)
+		return
+	}
+
+	s.statusMutex.Lock()
+	room.sentStatus, room.sentReason = status, reason
+	s.statusMutex.Unlock()
+}
+
+// publishFinalStatus sends DISCONNECTED with the stop reason for every room not already reported
+// as disconnected and cancels pending STATUS messages. Messages not sent when ctx is done are dropped.
+// Caller must hold mutex with STOMP still up.
+func (s *BBCoreStreamSession) publishFinalStatus(ctx context.Context, reason string) {
+	s.statusMutex.Lock()
+	var final []*message.StatusMessage
+	for _, room := range s.rooms {
+		if room.timer != nil {
+			room.timer.Stop()
+		}
+		if room.sentStatus != message.StatusDisconnected {
+			final = append(final, s.statusMessage(room.bigoRoomId, message.StatusDisconnected, reason))
+		}
+	}
+	s.rooms = nil
+	s.reporting = false
+	s.statusMutex.Unlock()
+
+	if s.stompClient == nil {
+		return
+	}
+	dest := fmt.Sprintf("/app/room/%s/bigo", s.roomId)
+	for _, msg := range final {
+		if err := msg.Validate(); err != nil {
+			fmt.Printf("[BBCoreStream] WARNING: Dropping final STATUS of %s: %v\n", msg.BigoRoomID, err)
+			continue
+		}
+		if err := s.stompClient.PublishContext(ctx, dest, msg); err != nil {
+			fmt.Printf("[BBCoreStream] WARNING: Final STATUS of %s not delivered: %v\n", msg.BigoRoomID, err)
+			if ctx.Err() != nil {
+				return // Don't spend the stop deadline on the remaining rooms
+			}
+		}
+	}
+}
+
+// statusMessage builds a STATUS message, attributing the room to its streamer when the config knows it.
+// Caller must hold mutex (for roomId).
+func (s *BBCoreStreamSession) statusMessage(bigoRoomId, status, reason string) *message.StatusMessage {
+	var streamerId string
+	if cfgMgr := s.config.Load(); cfgMgr != nil {
+		if streamer, err := cfgMgr.LookupStreamerByAnyBigoId(bigoRoomId); err == nil {
+			streamerId = streamer.StreamerId
+		}
+	}
+	return message.NewStatus(s.roomId, s.deviceHash, bigoRoomId, streamerId, status, reason)
+}
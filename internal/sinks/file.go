@@ -0,0 +1,154 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	defaultMaxSizeMB = 10
+	defaultMaxFiles  = 5
+)
+
+// FileSink appends events as NDJSON to <path>/<id>.ndjson. The file is rotated to
+// <id>.ndjson.1 (then .2, ...) when it reaches the size limit.
+type FileSink struct {
+	cfg      SinkConfig
+	path     string
+	maxSize  int64
+	maxFiles int
+	tracker  *tracker
+	file     *os.File
+	size     int64
+	mutex    sync.Mutex
+}
+
+// NewFileSink opens (or creates) the sink's file
+func NewFileSink(roomId string, cfg SinkConfig) (*FileSink, error) {
+	dir := cfg.Path
+	if dir == "" {
+		dir = filepath.Join("./data/sinks", roomId)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create sink directory: %w", err)
+	}
+
+	f := &FileSink{
+		cfg:      cfg,
+		path:     filepath.Join(dir, cfg.Id+".ndjson"),
+		maxSize:  int64(cfg.MaxSizeMB) * 1024 * 1024,
+		maxFiles: cfg.MaxFiles,
+		tracker:  newTracker(cfg),
+	}
+	if f.maxSize == 0 {
+		f.maxSize = defaultMaxSizeMB * 1024 * 1024
+	}
+	if f.maxFiles == 0 {
+		f.maxFiles = defaultMaxFiles
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Name returns the sink's display name
+func (f *FileSink) Name() string {
+	return f.cfg.displayName()
+}
+
+// Send appends an event as one JSON line
+func (f *FileSink) Send(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return ErrClosed
+	}
+	if f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			fmt.Printf("[Sinks] WARNING: %s: %v\n", f.Name(), err)
+		}
+	}
+	if f.file == nil {
+		err := fmt.Errorf("sink file unavailable after failed rotation")
+		f.tracker.failure(1, err)
+		return err
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	if err != nil {
+		err = fmt.Errorf("write event: %w", err)
+		f.tracker.failure(1, err)
+		return err
+	}
+	f.tracker.success(1)
+	return nil
+}
+
+// Health returns the write status
+func (f *FileSink) Health() Health {
+	return f.tracker.snapshot(0)
+}
+
+// Close closes the file. Writes are synchronous, so there is nothing to flush.
+func (f *FileSink) Close(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the current file for appending
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open sink file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat sink file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate shifts <id>.ndjson.N up by one, dropping files beyond maxFiles, and starts a new file
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close sink file: %w", err)
+	}
+	f.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxFiles))
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	renameErr := os.Rename(f.path, f.path+".1")
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("rotate sink file: %w", renameErr)
+	}
+	return nil
+}
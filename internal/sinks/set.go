@@ -0,0 +1,211 @@
+package sinks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"bbapp/internal/rules"
+)
+
+// Queue capacity of the asynchronous sinks
+const queueSize = 1000
+
+// ErrClosed is returned by Send after the sink was closed
+var ErrClosed = errors.New("sink closed")
+
+// Validate checks a sink configuration without opening anything
+func Validate(cfg Config) error {
+	seen := make(map[string]bool, len(cfg.Sinks))
+	for _, s := range cfg.Sinks {
+		if s.Id == "" {
+			return fmt.Errorf("sink %q has no id", s.Name)
+		}
+		if seen[s.Id] {
+			return fmt.Errorf("duplicate sink id: %s", s.Id)
+		}
+		seen[s.Id] = true
+
+		if err := validateSink(s); err != nil {
+			return fmt.Errorf("sink %s: %w", s.Id, err)
+		}
+	}
+	return nil
+}
+
+func validateSink(s SinkConfig) error {
+	for _, t := range s.EventTypes {
+		if t != rules.EventGift && t != rules.EventChat {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+
+	switch s.Type {
+	case TypeWebhook:
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook needs an http(s) url")
+		}
+		if s.BatchSize < 0 || s.FlushIntervalMs < 0 || s.MaxRetries < -1 {
+			return fmt.Errorf("batch size, flush interval and retries must not be negative")
+		}
+	case TypeFile:
+		if s.MaxSizeMB < 0 || s.MaxFiles < 0 {
+			return fmt.Errorf("max size and max files must not be negative")
+		}
+	case TypeStomp:
+		if s.BrokerURL == "" {
+			return fmt.Errorf("stomp sink needs a broker url")
+		}
+		if s.Destination == "" {
+			return fmt.Errorf("stomp sink needs a destination")
+		}
+	default:
+		return fmt.Errorf("unknown sink type %q", s.Type)
+	}
+	return nil
+}
+
+// configuredSink pairs a running sink with its event type filter
+type configuredSink struct {
+	cfg  SinkConfig
+	sink EventSink
+}
+
+// Set is the collection of enabled sinks of one room
+type Set struct {
+	roomId string
+	sinks  []configuredSink
+}
+
+// NewSet validates a configuration and opens its enabled sinks
+func NewSet(roomId string, cfg Config) (*Set, error) {
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	set := &Set{roomId: roomId}
+	for _, c := range cfg.Sinks {
+		if !c.Enabled {
+			continue
+		}
+
+		var sink EventSink
+		var err error
+		switch c.Type {
+		case TypeWebhook:
+			sink = NewWebhookSink(roomId, c)
+		case TypeFile:
+			sink, err = NewFileSink(roomId, c)
+		case TypeStomp:
+			sink = NewStompSink(roomId, c)
+		}
+		if err != nil {
+			set.Close(context.Background()) // Nothing queued yet
+			return nil, fmt.Errorf("sink %s: %w", c.Id, err)
+		}
+		set.sinks = append(set.sinks, configuredSink{cfg: c, sink: sink})
+	}
+	return set, nil
+}
+
+// Len returns the number of open sinks
+func (s *Set) Len() int {
+	return len(s.sinks)
+}
+
+// Dispatch hands an event to every sink that accepts its type
+func (s *Set) Dispatch(event rules.Event) {
+	e := Event{RoomId: s.roomId, Event: event}
+	for _, cs := range s.sinks {
+		if cs.cfg.accepts(event.Type) {
+			cs.sink.Send(e) // Failures are reported through Health
+		}
+	}
+}
+
+// Health returns the status of every open sink
+func (s *Set) Health() []Health {
+	health := make([]Health, 0, len(s.sinks))
+	for _, cs := range s.sinks {
+		health = append(health, cs.sink.Health())
+	}
+	return health
+}
+
+// Close flushes and closes every sink. Sinks still flushing when ctx expires drop their remaining events.
+func (s *Set) Close(ctx context.Context) {
+	for _, cs := range s.sinks {
+		if err := cs.sink.Close(ctx); err != nil {
+			fmt.Printf("[Sinks] WARNING: Failed to close %s: %v\n", cs.sink.Name(), err)
+		}
+	}
+}
+
+// tracker keeps the counters behind a sink's Health
+type tracker struct {
+	mutex  sync.Mutex
+	health Health
+}
+
+func newTracker(cfg SinkConfig) *tracker {
+	return &tracker{health: Health{Id: cfg.Id, Name: cfg.displayName(), Type: cfg.Type, Healthy: true}}
+}
+
+func (t *tracker) success(events int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.health.Healthy {
+		fmt.Printf("[Sinks] ✓ %s recovered\n", t.health.Name)
+	}
+	t.health.Healthy = true
+	t.health.Sent += int64(events)
+	t.health.LastSuccessAt = time.Now()
+}
+
+// failure records an error. events is the number of events given up on (0 for connection errors).
+func (t *tracker) failure(events int, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.health.Healthy {
+		fmt.Printf("[Sinks] WARNING: %s failing: %v\n", t.health.Name, err)
+	}
+	t.health.Healthy = false
+	t.health.Failed += int64(events)
+	t.health.LastError = err.Error()
+	t.health.LastErrorAt = time.Now()
+}
+
+func (t *tracker) drop() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.health.Dropped++
+}
+
+func (t *tracker) snapshot(queued int) Health {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	h := t.health
+	h.Queued = queued
+	return h
+}
+
+// enqueue adds an event to an asynchronous sink's queue, dropping it when the queue is full
+func enqueue(queue chan Event, stop chan struct{}, t *tracker, event Event) error {
+	select {
+	case <-stop:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case queue <- event:
+		return nil
+	default:
+		t.drop()
+		return fmt.Errorf("queue full, event dropped")
+	}
+}
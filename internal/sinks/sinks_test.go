@@ -0,0 +1,243 @@
+package sinks_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"bbapp/internal/rules"
+	"bbapp/internal/sinks"
+)
+
+func gift(id string) rules.Event {
+	return rules.Event{Type: rules.EventGift, SenderId: id, GiftName: "Rose", GiftCount: 1, Diamonds: 1}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []sinks.Config{
+		{Sinks: []sinks.SinkConfig{{Type: sinks.TypeFile}}},
+		{Sinks: []sinks.SinkConfig{{Id: "a", Type: sinks.TypeFile}, {Id: "a", Type: sinks.TypeFile}}},
+		{Sinks: []sinks.SinkConfig{{Id: "a", Type: "ftp"}}},
+		{Sinks: []sinks.SinkConfig{{Id: "a", Type: sinks.TypeWebhook, URL: "ftp://example.com"}}},
+		{Sinks: []sinks.SinkConfig{{Id: "a", Type: sinks.TypeStomp, BrokerURL: "ws://localhost"}}},
+		{Sinks: []sinks.SinkConfig{{Id: "a", Type: sinks.TypeFile, EventTypes: []string{"like"}}}},
+	}
+	for i, cfg := range invalid {
+		if err := sinks.Validate(cfg); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+
+	valid := sinks.Config{Sinks: []sinks.SinkConfig{
+		{Id: "hook", Type: sinks.TypeWebhook, URL: "https://example.com/hook"},
+		{Id: "log", Type: sinks.TypeFile, EventTypes: []string{rules.EventGift}},
+		{Id: "mirror", Type: sinks.TypeStomp, BrokerURL: "ws://localhost:8080", Destination: "/topic/{roomId}"},
+	}}
+	if err := sinks.Validate(valid); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestWebhookSink_BatchesAndSigns(t *testing.T) {
+	var mutex sync.Mutex
+	var batches []sinks.WebhookBatch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get(sinks.SignatureHeader); got != sinks.Sign("s3cret", r.Header.Get(sinks.TimestampHeader), body) {
+			t.Errorf("Signature mismatch: %q", got)
+		}
+		var batch sinks.WebhookBatch
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+		mutex.Lock()
+		batches = append(batches, batch)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	sink := sinks.NewWebhookSink("room-1", sinks.SinkConfig{
+		Id: "hook", Type: sinks.TypeWebhook, URL: server.URL, Secret: "s3cret", BatchSize: 2, FlushIntervalMs: 60000,
+	})
+	for _, id := range []string{"a", "b", "c"} {
+		sink.Send(sinks.Event{RoomId: "room-1", Event: gift(id)})
+	}
+	// The third event is a partial batch and is flushed on Close
+	sink.Close(context.Background())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(batches) != 2 || len(batches[0].Events) != 2 || len(batches[1].Events) != 1 {
+		t.Fatalf("Expected batches of 2 and 1 events, got %+v", batches)
+	}
+	if batches[0].RoomId != "room-1" || batches[0].Events[0].SenderId != "a" {
+		t.Errorf("Unexpected first batch: %+v", batches[0])
+	}
+	if h := sink.Health(); !h.Healthy || h.Sent != 3 {
+		t.Errorf("Unexpected health: %+v", h)
+	}
+}
+
+func TestWebhookSink_Retries(t *testing.T) {
+	var mutex sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := sinks.NewWebhookSink("room-1", sinks.SinkConfig{Id: "hook", URL: server.URL, MaxRetries: 1})
+	sink.Send(sinks.Event{Event: gift("a")})
+	sink.Close(context.Background())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if calls != 2 {
+		t.Errorf("Expected 2 requests, got %d", calls)
+	}
+	if h := sink.Health(); !h.Healthy || h.Sent != 1 || h.Failed != 0 {
+		t.Errorf("Unexpected health: %+v", h)
+	}
+}
+
+func TestWebhookSink_NoRetryOnClientError(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sink := sinks.NewWebhookSink("room-1", sinks.SinkConfig{Id: "hook", URL: server.URL})
+	sink.Send(sinks.Event{Event: gift("a")})
+	sink.Close(context.Background())
+
+	if calls != 1 {
+		t.Errorf("Expected 1 request, got %d", calls)
+	}
+	if h := sink.Health(); h.Healthy || h.Failed != 1 || h.LastError == "" {
+		t.Errorf("Unexpected health: %+v", h)
+	}
+}
+
+func TestWebhookSink_CloseStopsRetryingWhenContextExpires(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink := sinks.NewWebhookSink("room-1", sinks.SinkConfig{Id: "hook", URL: server.URL, MaxRetries: 10})
+	sink.Send(sinks.Event{Event: gift("a")})
+	sink.Send(sinks.Event{Event: gift("b")})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := sink.Close(ctx); err == nil {
+		t.Error("Expected an error when the flush is cut short")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %v, expected it to stop with the context", elapsed)
+	}
+	if h := sink.Health(); h.Sent != 0 || h.Failed != 2 {
+		t.Errorf("Unexpected health: %+v", h)
+	}
+}
+
+func TestFileSink_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := sinks.NewFileSink("room-1", sinks.SinkConfig{Id: "log", Path: dir, MaxSizeMB: 1, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+
+	// ~300 bytes per line: 1MB holds a few thousand lines, so 10000 lines rotate several times
+	event := sinks.Event{RoomId: "room-1", Event: gift("a")}
+	event.Message = string(make([]byte, 200))
+	for i := 0; i < 10000; i++ {
+		if err := sink.Send(event); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	sink.Close(context.Background())
+
+	files, _ := filepath.Glob(filepath.Join(dir, "log.ndjson*"))
+	if len(files) != 3 {
+		t.Fatalf("Expected current file and 2 rotated files, got %v", files)
+	}
+	for _, name := range files {
+		info, _ := os.Stat(name)
+		if info.Size() > 1024*1024 {
+			t.Errorf("%s is larger than the limit: %d", name, info.Size())
+		}
+	}
+
+	f, _ := os.Open(filepath.Join(dir, "log.ndjson.1"))
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	var decoded sinks.Event
+	if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil || decoded.RoomId != "room-1" || decoded.GiftName != "Rose" {
+		t.Errorf("Unexpected line %q: %v", scanner.Text(), err)
+	}
+}
+
+func TestSet_FiltersEventTypes(t *testing.T) {
+	dir := t.TempDir()
+	set, err := sinks.NewSet("room-1", sinks.Config{Sinks: []sinks.SinkConfig{
+		{Id: "gifts", Type: sinks.TypeFile, Enabled: true, Path: dir, EventTypes: []string{rules.EventGift}},
+		{Id: "all", Type: sinks.TypeFile, Enabled: true, Path: dir},
+		{Id: "off", Type: sinks.TypeFile, Path: dir},
+	}})
+	if err != nil {
+		t.Fatalf("NewSet() error = %v", err)
+	}
+
+	set.Dispatch(gift("a"))
+	set.Dispatch(rules.Event{Type: rules.EventChat, Message: "hi"})
+	health := set.Health()
+	set.Close(context.Background())
+
+	if len(health) != 2 || health[0].Sent != 1 || health[1].Sent != 2 {
+		t.Errorf("Unexpected health: %+v", health)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "off.ndjson")); !os.IsNotExist(err) {
+		t.Error("Disabled sink should not be opened")
+	}
+}
+
+func TestStompSink_UnreachableBroker(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	l.Close()
+
+	sink := sinks.NewStompSink("room-1", sinks.SinkConfig{Id: "mirror", BrokerURL: addr, Destination: "/topic/{roomId}"})
+	defer sink.Close(context.Background())
+	sink.Send(sinks.Event{Event: gift("a")})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if h := sink.Health(); !h.Healthy && h.LastError != "" {
+			if h.Queued != 1 {
+				t.Errorf("Expected the event to stay queued, got %d", h.Queued)
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected an unhealthy sink, got %+v", sink.Health())
+}
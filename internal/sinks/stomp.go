@@ -0,0 +1,146 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"bbapp/internal/stomp"
+)
+
+// StompSink publishes events to a secondary STOMP broker. Events are queued while connecting.
+type StompSink struct {
+	cfg         SinkConfig
+	destination string
+	tracker     *tracker
+	queue       chan Event
+	stop        chan struct{}
+	done        chan struct{}
+	abort       chan struct{} // Closed when Close gives up on the remaining events
+	client      *stomp.Client
+	mutex       sync.Mutex
+}
+
+// NewStompSink starts connecting to the broker in the background
+func NewStompSink(roomId string, cfg SinkConfig) *StompSink {
+	s := &StompSink{
+		cfg:         cfg,
+		destination: strings.ReplaceAll(cfg.Destination, "{roomId}", roomId),
+		tracker:     newTracker(cfg),
+		queue:       make(chan Event, queueSize),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		abort:       make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Name returns the sink's display name
+func (s *StompSink) Name() string {
+	return s.cfg.displayName()
+}
+
+// Send queues an event for publishing
+func (s *StompSink) Send(event Event) error {
+	return enqueue(s.queue, s.stop, s.tracker, event)
+}
+
+// Health returns the delivery status. The sink is unhealthy while the broker is unreachable.
+func (s *StompSink) Health() Health {
+	h := s.tracker.snapshot(len(s.queue))
+
+	s.mutex.Lock()
+	client := s.client
+	s.mutex.Unlock()
+	if client == nil {
+		h.Healthy = false
+	} else if state := client.State(); !state.Healthy {
+		h.Healthy = false
+		if state.LastError != "" {
+			h.LastError = state.LastError
+			h.LastErrorAt = state.LastErrorAt
+		}
+	}
+	return h
+}
+
+// Close publishes the queued events and disconnects. Once ctx expires, the events
+// not published yet are counted as failed.
+func (s *StompSink) Close(ctx context.Context) error {
+	select {
+	case <-s.stop:
+		return nil
+	default:
+		close(s.stop)
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		close(s.abort)
+		<-s.done
+		return fmt.Errorf("flush queued events: %w", ctx.Err())
+	}
+}
+
+func (s *StompSink) run() {
+	defer close(s.done)
+
+	client := s.connect()
+	if client == nil {
+		return
+	}
+	defer client.Disconnect()
+
+	for {
+		select {
+		case event := <-s.queue:
+			s.publish(client, event)
+		case <-s.stop:
+			for len(s.queue) > 0 {
+				select {
+				case <-s.abort:
+					s.tracker.failure(len(s.queue), ErrClosed)
+					return
+				default:
+				}
+				s.publish(client, <-s.queue)
+			}
+			return
+		}
+	}
+}
+
+// connect retries until the broker accepts the connection. Later drops are handled by the client's reconnect policy.
+func (s *StompSink) connect() *stomp.Client {
+	policy := stomp.DefaultReconnectPolicy()
+	for attempt := 1; ; attempt++ {
+		client, err := stomp.NewClient(s.cfg.BrokerURL, s.cfg.Token, "")
+		if err == nil {
+			s.mutex.Lock()
+			s.client = client
+			s.mutex.Unlock()
+			fmt.Printf("[Sinks] ✓ %s connected to %s\n", s.Name(), s.cfg.BrokerURL)
+			return client
+		}
+		s.tracker.failure(0, fmt.Errorf("connect: %w", err))
+
+		select {
+		case <-s.stop:
+			return nil
+		case <-time.After(policy.Delay(attempt)):
+		}
+	}
+}
+
+func (s *StompSink) publish(client *stomp.Client, event Event) {
+	if err := client.Publish(s.destination, event); err != nil {
+		s.tracker.failure(1, fmt.Errorf("publish: %w", err))
+		return
+	}
+	s.tracker.success(1)
+}
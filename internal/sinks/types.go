@@ -0,0 +1,96 @@
+package sinks
+
+import (
+	"context"
+	"time"
+
+	"bbapp/internal/rules"
+)
+
+// Sink types
+const (
+	TypeWebhook = "webhook" // Batched, HMAC-signed HTTP POSTs
+	TypeFile    = "file"    // Rotating NDJSON file
+	TypeStomp   = "stomp"   // Secondary STOMP broker
+)
+
+// EventSink receives normalized events. Send must not block the event pipeline.
+type EventSink interface {
+	Name() string
+	Send(event Event) error
+	Health() Health
+	Close(ctx context.Context) error // Flushes queued events until ctx expires
+}
+
+// Event is a normalized gift or chat event of a BB-Core room
+type Event struct {
+	RoomId string `json:"roomId"`
+	rules.Event
+}
+
+// SinkConfig configures one sink. Only the fields of its type are used.
+type SinkConfig struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"` // webhook, file or stomp
+	Enabled    bool     `json:"enabled"`
+	EventTypes []string `json:"eventTypes"` // gift and/or chat; empty forwards both
+
+	// webhook
+	URL             string `json:"url"`
+	Secret          string `json:"secret"`          // Signs requests with HMAC-SHA256 when set
+	BatchSize       int    `json:"batchSize"`       // Events per request (0 = 20)
+	FlushIntervalMs int    `json:"flushIntervalMs"` // Longest wait before sending a partial batch (0 = 1000)
+	MaxRetries      int    `json:"maxRetries"`      // Retries of a failed request (0 = 3, -1 = none)
+
+	// file
+	Path      string `json:"path"`      // Directory of <id>.ndjson (empty = ./data/sinks/<roomId>)
+	MaxSizeMB int    `json:"maxSizeMB"` // Rotate when the file reaches this size (0 = 10)
+	MaxFiles  int    `json:"maxFiles"`  // Rotated files to keep (0 = 5)
+
+	// stomp
+	BrokerURL   string `json:"brokerUrl"`
+	Token       string `json:"token"`
+	Destination string `json:"destination"` // {roomId} is substituted
+}
+
+// Config is the list of sinks stored in a profile
+type Config struct {
+	Sinks []SinkConfig `json:"sinks"`
+}
+
+// Health is the delivery status of one sink
+type Health struct {
+	Id            string    `json:"id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Healthy       bool      `json:"healthy"`
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorAt   time.Time `json:"lastErrorAt"`
+	LastSuccessAt time.Time `json:"lastSuccessAt"`
+	Sent          int64     `json:"sent"`    // Events delivered
+	Failed        int64     `json:"failed"`  // Events given up on after retries
+	Dropped       int64     `json:"dropped"` // Events discarded because the queue was full
+	Queued        int       `json:"queued"`  // Events waiting for delivery
+}
+
+// displayName returns the name shown in logs and health reports
+func (c SinkConfig) displayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Id
+}
+
+// accepts reports whether the sink wants events of the given type
+func (c SinkConfig) accepts(eventType string) bool {
+	if len(c.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range c.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
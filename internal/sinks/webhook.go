@@ -0,0 +1,223 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request headers of signed webhooks. The signature is "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	SignatureHeader = "X-BBapp-Signature"
+	TimestampHeader = "X-BBapp-Timestamp"
+)
+
+const (
+	defaultBatchSize     = 20
+	defaultFlushInterval = time.Second
+	defaultMaxRetries    = 3
+	webhookRetryDelay    = 500 * time.Millisecond // Doubled after every failed attempt
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// WebhookBatch is the JSON body POSTed to webhook sinks
+type WebhookBatch struct {
+	RoomId string    `json:"roomId"`
+	SentAt time.Time `json:"sentAt"`
+	Events []Event   `json:"events"`
+}
+
+// WebhookSink POSTs batches of events to an HTTP endpoint
+type WebhookSink struct {
+	roomId        string
+	cfg           SinkConfig
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	tracker       *tracker
+	queue         chan Event
+	stop          chan struct{}
+	done          chan struct{}
+	ctx           context.Context // Cancelled when Close gives up on the remaining events
+	cancel        context.CancelFunc
+}
+
+// NewWebhookSink starts a webhook sink. Events are sent when a batch is full or the flush interval passes.
+func NewWebhookSink(roomId string, cfg SinkConfig) *WebhookSink {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &WebhookSink{
+		roomId:        roomId,
+		cfg:           cfg,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		maxRetries:    cfg.MaxRetries,
+		tracker:       newTracker(cfg),
+		queue:         make(chan Event, queueSize),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	if w.batchSize == 0 {
+		w.batchSize = defaultBatchSize
+	}
+	if w.flushInterval == 0 {
+		w.flushInterval = defaultFlushInterval
+	}
+	switch {
+	case w.maxRetries == 0:
+		w.maxRetries = defaultMaxRetries
+	case w.maxRetries < 0:
+		w.maxRetries = 0
+	}
+
+	go w.run()
+	return w
+}
+
+// Name returns the sink's display name
+func (w *WebhookSink) Name() string {
+	return w.cfg.displayName()
+}
+
+// Send queues an event for the next batch
+func (w *WebhookSink) Send(event Event) error {
+	return enqueue(w.queue, w.stop, w.tracker, event)
+}
+
+// Health returns the delivery status
+func (w *WebhookSink) Health() Health {
+	return w.tracker.snapshot(len(w.queue))
+}
+
+// Close sends the queued events and stops the sink. Once ctx expires, requests and
+// retries are abandoned and the events not sent yet are counted as failed.
+func (w *WebhookSink) Close(ctx context.Context) error {
+	select {
+	case <-w.stop:
+		return nil
+	default:
+		close(w.stop)
+	}
+	defer w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		<-w.done
+		return fmt.Errorf("flush queued events: %w", ctx.Err())
+	}
+}
+
+func (w *WebhookSink) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	var batch []Event
+	for {
+		select {
+		case event := <-w.queue:
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				w.deliver(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.deliver(batch)
+				batch = nil
+			}
+		case <-w.stop:
+			for len(w.queue) > 0 {
+				batch = append(batch, <-w.queue)
+				if len(batch) >= w.batchSize {
+					w.deliver(batch)
+					batch = nil
+				}
+			}
+			if len(batch) > 0 {
+				w.deliver(batch)
+			}
+			return
+		}
+	}
+}
+
+// deliver POSTs a batch, retrying network errors, 429 and 5xx with exponential backoff
+func (w *WebhookSink) deliver(batch []Event) {
+	if w.ctx.Err() != nil {
+		w.tracker.failure(len(batch), ErrClosed)
+		return
+	}
+	body, err := json.Marshal(WebhookBatch{RoomId: w.roomId, SentAt: time.Now(), Events: batch})
+	if err != nil {
+		w.tracker.failure(len(batch), fmt.Errorf("marshal batch: %w", err))
+		return
+	}
+
+	delay := webhookRetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			w.tracker.success(len(batch))
+			return
+		}
+		if !retry || attempt >= w.maxRetries {
+			w.tracker.failure(len(batch), err)
+			return
+		}
+		select {
+		case <-w.ctx.Done():
+			w.tracker.failure(len(batch), err)
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends one request and reports whether a failure is worth retrying
+func (w *WebhookSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(w.cfg.Secret, timestamp, body))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("post webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// Sign computes the signature header value of a webhook body
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
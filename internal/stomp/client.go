@@ -2,46 +2,88 @@ package stomp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/url"
-	"path"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-stomp/stomp/v3"
+	"github.com/go-stomp/stomp/v3/frame"
 	"github.com/gorilla/websocket"
 )
 
+// Default STOMP heart-beat intervals and websocket deadlines
+const (
+	defaultHeartBeat  = 10 * time.Second
+	sockJSReadTimeout = 90 * time.Second // Covers SockJS 'h' frames (25s), sent whatever the STOMP heart-beat
+	heartBeatMisses   = 3                // Server heart-beats a raw WebSocket may miss before reads time out
+	wsWriteTimeout    = 10 * time.Second
+	disconnectTimeout = 5 * time.Second
+)
+
+// wsHandshakeTimeout bounds raw WebSocket reads until CONNECTED tells the server's heart-beat
+var wsHandshakeTimeout = 30 * time.Second
+
 // Client wraps STOMP connection with auto-reconnection
 type Client struct {
-	conn          *stomp.Conn
-	url           string
-	username      string
-	password      string
-	isHealthy     bool
-	mutex         sync.RWMutex
-	stopMonitor   chan struct{}
-	reconnecting  bool
-	subscriptions map[string]func([]byte) // destination -> handler
+	conn           *stomp.Conn
+	url            string
+	username       string
+	password       string
+	isHealthy      bool
+	mutex          sync.RWMutex
+	stopMonitor    chan struct{}
+	healthCheck    chan struct{} // Signalled when a failure is detected, so the monitor reacts immediately
+	reconnecting   bool
+	closed         bool
+	generation     int // Incremented per connection attempt; stale failure signals are ignored
+	lastError      error
+	state          string
+	onState        []func(StateEvent)
+	heartBeatSend  time.Duration
+	heartBeatRecv  time.Duration
+	transports     []string      // SockJS transports to try (nil = DefaultTransports)
+	tokens         TokenProvider // Supplies a fresh token per connect (nil = use username)
+	authRejected   bool          // The server sent an auth ERROR; refresh before reconnecting
+	policy         ReconnectPolicy
+	attempt        int // Current reconnect attempt (0 when connected)
+	reconnects     int
+	failedAttempts int
+	lastErrorAt    time.Time
+	connectedAt    time.Time
+	nextRetryAt    time.Time
+	subscriptions  map[string]func([]byte) // destination -> handler
 }
 
 // NewClient creates STOMP client with auto-reconnection
 // Supports both raw TCP (host:port) and WebSocket (ws://host:port/path or http://host:port/path)
 // For WebSocket connections requiring auth, pass token as username parameter
-func NewClient(urlStr, username, password string) (*Client, error) {
+func NewClient(urlStr, username, password string, opts ...Option) (*Client, error) {
 	client := &Client{
-		url:         urlStr,
-		username:    username,
-		password:    password,
-		stopMonitor: make(chan struct{}),
-	}
-
-	if err := client.connect(); err != nil {
+		url:           urlStr,
+		username:      username,
+		password:      password,
+		stopMonitor:   make(chan struct{}),
+		healthCheck:   make(chan struct{}, 1),
+		heartBeatSend: defaultHeartBeat,
+		heartBeatRecv: defaultHeartBeat,
+		policy:        DefaultReconnectPolicy(),
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.setState(StateConnecting, nil)
+	if err := client.connectWithRefresh(); err != nil {
+		client.recordError(err)
+		client.setState(StateFailed, err)
 		return nil, err
 	}
+	client.setState(StateConnected, nil)
 
 	go client.monitorConnection()
 
@@ -52,18 +94,36 @@ func NewClient(urlStr, username, password string) (*Client, error) {
 func (c *Client) connect() error {
 	fmt.Printf("[STOMP] Connecting to: %s\n", c.url)
 
+	c.mutex.Lock()
+	c.generation++
+	generation := c.generation
+	c.mutex.Unlock()
+
+	// With a token provider, each attempt picks up the latest (possibly refreshed) token
+	token := c.currentToken()
+
 	var netConn net.Conn
 	var err error
 
 	// Check if it's a WebSocket URL
 	if strings.HasPrefix(c.url, "ws://") || strings.HasPrefix(c.url, "wss://") ||
 		strings.HasPrefix(c.url, "http://") || strings.HasPrefix(c.url, "https://") {
-		fmt.Printf("[STOMP] Using WebSocket transport\n")
-		if c.username != "" {
-			fmt.Printf("[STOMP] Authentication token provided (length: %d)\n", len(c.username))
+		if token != "" {
+			fmt.Printf("[STOMP] Authentication token provided (length: %d)\n", len(token))
+		}
+
+		var endpoint *url.URL
+		var sockJS bool
+		endpoint, sockJS, err = resolveEndpoint(c.url)
+		if err == nil && sockJS {
+			fmt.Printf("[STOMP] Using SockJS transport\n")
+			// SockJS endpoint (username is used as auth token)
+			netConn, err = dialSockJS(endpoint, token, c.transports)
+		} else if err == nil {
+			fmt.Printf("[STOMP] Using WebSocket transport\n")
+			// Raw WebSocket connection (username is used as auth token for WS)
+			netConn, err = dialWebSocket(endpoint.String(), token, false)
 		}
-		// WebSocket connection (username is used as auth token for WS)
-		netConn, err = dialWebSocket(c.url, c.username)
 	} else {
 		fmt.Printf("[STOMP] Using raw TCP transport\n")
 		// Raw TCP connection
@@ -77,19 +137,35 @@ func (c *Client) connect() error {
 
 	fmt.Printf("[STOMP] ✓ Network connection established\n")
 
-	var opts []func(*stomp.Conn) error
-	if c.username != "" {
+	// Raw WebSocket reads time out after missed server heart-beats, known once CONNECTED arrives
+	wsConn, _ := netConn.(*websocketConn)
+
+	// Read/write errors on the transport (including missed heart-beats) mark the connection unhealthy
+	netConn = &watchedConn{Conn: netConn, onError: func(err error) {
+		c.markUnhealthy(generation, fmt.Errorf("transport error: %w", err))
+	}}
+
+	opts := []func(*stomp.Conn) error{
+		stomp.ConnOpt.HeartBeat(c.heartBeatSend, c.heartBeatRecv),
+		stomp.ConnOpt.DisconnectReceiptTimeout(disconnectTimeout),
+	}
+	if wsConn != nil {
+		opts = append(opts, stomp.ConnOpt.ResponseHeaders(func(header *frame.Header) {
+			wsConn.setHeartBeat(serverHeartBeat(header.Get(frame.HeartBeat), c.heartBeatRecv))
+		}))
+	}
+	if token != "" {
 		// Pass token in MULTIPLE headers to be safe (login, Authorization, X-Authorization)
 		// Standard STOMP
-		opts = append(opts, stomp.ConnOpt.Login(c.username, c.password))
+		opts = append(opts, stomp.ConnOpt.Login(token, c.password))
 
 		// Common for JWT/Spring Security
-		token := c.username
-		if !strings.HasPrefix(token, "Bearer ") {
-			token = "Bearer " + token
+		bearer := token
+		if !strings.HasPrefix(bearer, "Bearer ") {
+			bearer = "Bearer " + bearer
 		}
-		opts = append(opts, stomp.ConnOpt.Header("Authorization", token))
-		opts = append(opts, stomp.ConnOpt.Header("X-Authorization", token))
+		opts = append(opts, stomp.ConnOpt.Header("Authorization", bearer))
+		opts = append(opts, stomp.ConnOpt.Header("X-Authorization", bearer))
 	}
 
 	fmt.Printf("[STOMP] Performing STOMP handshake...\n")
@@ -101,8 +177,15 @@ func (c *Client) connect() error {
 	}
 
 	c.mutex.Lock()
+	if c.closed {
+		// Disconnect was called while this attempt was in flight
+		c.mutex.Unlock()
+		conn.MustDisconnect()
+		return fmt.Errorf("client disconnected")
+	}
 	c.conn = conn
 	c.isHealthy = true
+	c.connectedAt = time.Now()
 	c.mutex.Unlock()
 
 	fmt.Printf("[STOMP] ✓ STOMP connection established successfully\n")
@@ -130,71 +213,125 @@ func (c *Client) monitorConnection() {
 	for {
 		select {
 		case <-ticker.C:
-			c.mutex.RLock()
-			healthy := c.isHealthy
-			c.mutex.RUnlock()
-
-			if !healthy {
-				// If reconnection fails after max retries, stop monitoring to avoid abuse
-				if success := c.reconnect(); !success {
-					fmt.Println("[STOMP] Reconnection failed permanently. Stopping monitor to avoid connection abuse.")
-					// Ensure we are in a disconnected state
-					c.Disconnect()
-					return
-				}
-			}
+		case <-c.healthCheck:
 		case <-c.stopMonitor:
 			return
 		}
+
+		c.mutex.RLock()
+		healthy := c.isHealthy
+		c.mutex.RUnlock()
+
+		if !healthy {
+			// If reconnection fails after the policy's max attempts, stop monitoring to avoid abuse
+			if err := c.reconnect(); err != nil {
+				c.mutex.RLock()
+				closed := c.closed
+				c.mutex.RUnlock()
+				if closed {
+					return // Disconnect was called while reconnecting
+				}
+
+				fmt.Println("[STOMP] Reconnection failed permanently. Stopping monitor to avoid connection abuse.")
+				// Ensure we are in a disconnected state
+				c.close()
+				c.setState(StateFailed, err)
+				return
+			}
+		}
 	}
 }
 
-// reconnect attempts to reconnect with exponential backoff
-// Returns true if reconnected successfully, false if all attempts failed
-func (c *Client) reconnect() bool {
+// reconnect attempts to reconnect with increasing delays
+// Returns nil if reconnected successfully, or the last error if all attempts failed
+func (c *Client) reconnect() error {
 	c.mutex.Lock()
 	if c.reconnecting {
 		c.mutex.Unlock()
-		return false
+		return nil
 	}
 	c.reconnecting = true
+	cause := c.lastError
+	authRejected := c.authRejected
+	oldConn := c.conn
+	c.conn = nil
 	c.mutex.Unlock()
 
+	defer func() {
+		c.mutex.Lock()
+		c.reconnecting = false
+		c.mutex.Unlock()
+	}()
+
 	fmt.Printf("[STOMP] Connection lost, attempting reconnection...\n")
 
-	maxRetries := 5
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		time.Sleep(time.Duration(attempt) * 2 * time.Second)
+	// Drop the broken connection so its subscriptions and goroutines are released
+	if oldConn != nil {
+		oldConn.MustDisconnect()
+	}
+
+	// The server dropped us with an auth ERROR; reconnecting with the same token would fail too
+	if authRejected {
+		if err := c.refreshToken(cause); err != nil {
+			fmt.Printf("[STOMP] Continuing reconnection with the current token\n")
+		}
+	}
+
+	policy := c.policy
+	err := cause
+	for attempt := 1; policy.MaxAttempts <= 0 || attempt <= policy.MaxAttempts; attempt++ {
+		delay := policy.Delay(attempt)
+
+		c.mutex.Lock()
+		c.attempt = attempt
+		c.nextRetryAt = time.Now().Add(delay)
+		c.mutex.Unlock()
+		c.setState(StateReconnecting, err)
 
-		if err := c.connect(); err == nil {
+		select {
+		case <-time.After(delay):
+		case <-c.stopMonitor:
+			return fmt.Errorf("client disconnected")
+		}
+
+		if err = c.connectWithRefresh(); err == nil {
 			c.mutex.Lock()
-			c.reconnecting = false
+			c.attempt = 0
+			c.nextRetryAt = time.Time{}
+			c.reconnects++
 			c.mutex.Unlock()
-			fmt.Printf("[STOMP] ✓ Reconnected successfully\n")
-			return true
+
+			fmt.Printf("[STOMP] ✓ Reconnected successfully after %d attempt(s)\n", attempt)
+			c.setState(StateConnected, nil)
+			return nil
 		}
 
-		fmt.Printf("[STOMP] Reconnection attempt %d/%d failed\n", attempt, maxRetries)
+		c.recordError(err)
+		c.mutex.Lock()
+		c.failedAttempts++
+		c.mutex.Unlock()
+
+		if policy.MaxAttempts > 0 {
+			fmt.Printf("[STOMP] Reconnection attempt %d/%d failed: %v\n", attempt, policy.MaxAttempts, err)
+		} else {
+			fmt.Printf("[STOMP] Reconnection attempt %d failed: %v\n", attempt, err)
+		}
 	}
 
+	fmt.Printf("[STOMP] ERROR: Failed to reconnect after %d attempts\n", policy.MaxAttempts)
 	c.mutex.Lock()
-	c.reconnecting = false
+	c.nextRetryAt = time.Time{}
 	c.mutex.Unlock()
-
-	fmt.Printf("[STOMP] ERROR: Failed to reconnect after %d attempts\n", maxRetries)
-	return false
+	return err
 }
 
 // dialWebSocket establishes WebSocket connection for STOMP
-// token parameter is used for Bearer authentication
-func dialWebSocket(urlStr, token string) (net.Conn, error) {
+// token parameter is used for Bearer authentication; sockJS enables SockJS framing
+func dialWebSocket(wsURL, token string, sockJS bool) (net.Conn, error) {
 	fmt.Printf("[STOMP] Setting up WebSocket connection...\n")
 
 	// Defensive: Strip "Bearer " prefix if present to avoid duplication
-	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
-		token = strings.TrimSpace(token[7:])
-		fmt.Printf("[STOMP] Stripped 'Bearer ' prefix from token\n")
-	}
+	token = bearerToken(token)
 
 	// Log token detail for debugging (masked)
 	if len(token) > 10 {
@@ -205,12 +342,6 @@ func dialWebSocket(urlStr, token string) (net.Conn, error) {
 		fmt.Printf("[STOMP] No token provided\n")
 	}
 
-	// Normalize URL (handle scheme and path)
-	wsURL, err := normalizeURL(urlStr)
-	if err != nil {
-		return nil, err
-	}
-
 	u, err := url.Parse(wsURL)
 	if err != nil {
 		return nil, err
@@ -242,6 +373,9 @@ func dialWebSocket(urlStr, token string) (net.Conn, error) {
 	if err != nil {
 		if resp != nil {
 			fmt.Printf("[STOMP] ERROR: WebSocket handshake failed (status: %d)\n", resp.StatusCode)
+			if isAuthStatus(resp.StatusCode) {
+				return nil, fmt.Errorf("WebSocket dial failed: %w (status %d)", ErrAuthRejected, resp.StatusCode)
+			}
 		} else {
 			fmt.Printf("[STOMP] ERROR: WebSocket connection failed: %v\n", err)
 		}
@@ -254,23 +388,53 @@ func dialWebSocket(urlStr, token string) (net.Conn, error) {
 	}
 
 	// Wrap WebSocket connection to implement net.Conn interface
-	return &websocketConn{ws: wsConn}, nil
+	readTimeout := wsHandshakeTimeout
+	if sockJS {
+		readTimeout = sockJSReadTimeout
+	}
+	return &websocketConn{ws: wsConn, sockJS: sockJS, readTimeout: readTimeout, writeTimeout: wsWriteTimeout}, nil
+}
+
+// serverHeartBeat returns the negotiated interval of the server's heart-beats given the
+// CONNECTED heart-beat header and the interval we asked for; 0 when the server sends none
+func serverHeartBeat(header string, recv time.Duration) time.Duration {
+	send, _, err := frame.ParseHeartBeat(header)
+	if err != nil || send == 0 || recv == 0 {
+		return 0
+	}
+	return max(send, recv)
 }
 
 // websocketConn wraps gorilla/websocket to implement net.Conn
-// It handles SockJS framing (unwrapping received JSON arrays, wrapping sent data)
+// For SockJS sessions it handles framing (unwrapping received frames, wrapping sent data)
+// Each read and write gets a deadline, so a silently dropped connection surfaces as an error
 type websocketConn struct {
-	ws         *websocket.Conn
-	readBuffer bytes.Buffer
+	ws           *websocket.Conn
+	sockJS       bool
+	readBuffer   bytes.Buffer
+	readTimeout  time.Duration
+	writeTimeout time.Duration
 }
 
-func (w *websocketConn) Read(p []byte) (n int, err error) {
-	// If we have buffered data, return it first
-	if w.readBuffer.Len() > 0 {
-		return w.readBuffer.Read(p)
+// setHeartBeat derives the read deadline from the server heart-beat interval; 0 disables it.
+// SockJS sessions keep their deadline, as the server sends 'h' frames regardless.
+// Called during the STOMP handshake, before reads move to the connection's read loop.
+func (w *websocketConn) setHeartBeat(interval time.Duration) {
+	if w.sockJS {
+		return
 	}
+	w.readTimeout = interval * heartBeatMisses
+	if w.readTimeout == 0 {
+		w.ws.SetReadDeadline(time.Time{}) // Drop the handshake deadline
+	}
+}
 
-	for {
+func (w *websocketConn) Read(p []byte) (n int, err error) {
+	// Read until there is buffered data to return (SockJS open frames carry none)
+	for w.readBuffer.Len() == 0 {
+		if w.readTimeout > 0 {
+			w.ws.SetReadDeadline(time.Now().Add(w.readTimeout))
+		}
 		msgType, data, err := w.ws.ReadMessage()
 		if err != nil {
 			return 0, err
@@ -281,56 +445,31 @@ func (w *websocketConn) Read(p []byte) (n int, err error) {
 			continue // skip unknown frame types
 		}
 
-		// Handle SockJS frames
-		// SockJS frames are single characters or JSON arrays
-		if len(data) == 0 {
+		if !w.sockJS {
+			w.readBuffer.Write(data)
 			continue
 		}
-
-		frameType := data[0]
-		switch frameType {
-		case 'o': // Open frame
-			continue
-		case 'h': // Heartbeat frame
-			// Translate SockJS heartbeat to STOMP heartbeat (newline)
-			// This keeps the STOMP connection alive even if no real data is flowing
-			w.readBuffer.WriteByte('\n')
-			return w.readBuffer.Read(p)
-		case 'c': // Close frame
-			// e.g. [3000,"Go away!"]
-			return 0, fmt.Errorf("SockJS connection closed by server")
-		case 'a': // Data array frame: ["msg1", "msg2"]
-			var messages []string
-			if err := json.Unmarshal(data[1:], &messages); err != nil {
-				return 0, fmt.Errorf("failed to parse SockJS data frame: %w", err)
-			}
-
-			for _, msg := range messages {
-				w.readBuffer.WriteString(msg)
-			}
-
-			// Return data from buffer
-			return w.readBuffer.Read(p)
-		default:
-			// Treat as raw frame if not SockJS
-			n = copy(p, data)
-			return n, nil
+		if err := decodeSockJSFrame(data, &w.readBuffer); err != nil {
+			return 0, err
 		}
 	}
+
+	return w.readBuffer.Read(p)
 }
 
 func (w *websocketConn) Write(p []byte) (n int, err error) {
-	// Wrap in SockJS array: ["message"]
-	// Use manual string construction to avoid full JSON marshaling overhead for simple string
-	// payload needs to be a JSON string, so we use json.Marshal for the content part to be safe
-	content, err := json.Marshal(string(p))
-	if err != nil {
-		return 0, err
+	data := p
+	if w.sockJS {
+		// Wrap in SockJS array: ["message"]
+		if data, err = encodeSockJSFrame(p); err != nil {
+			return 0, err
+		}
 	}
 
-	sockJsFrame := fmt.Sprintf("[%s]", content)
-
-	err = w.ws.WriteMessage(websocket.TextMessage, []byte(sockJsFrame))
+	if w.writeTimeout > 0 {
+		w.ws.SetWriteDeadline(time.Now().Add(w.writeTimeout))
+	}
+	err = w.ws.WriteMessage(websocket.TextMessage, data)
 	if err != nil {
 		return 0, err
 	}
@@ -368,22 +507,50 @@ func (w *websocketConn) SetWriteDeadline(t time.Time) error {
 func (c *Client) Disconnect() error {
 	fmt.Println("[STOMP] Disconnecting from STOMP server...")
 
-	close(c.stopMonitor)
+	c.mutex.RLock()
+	alreadyClosed := c.closed
+	c.mutex.RUnlock()
 
+	err := c.close()
+	if !alreadyClosed {
+		c.setState(StateDisconnected, nil)
+	}
+	return err
+}
+
+// DisconnectContext is like Disconnect but stops waiting for the DISCONNECT receipt when ctx is done.
+// The connection is closed either way.
+func (c *Client) DisconnectContext(ctx context.Context) error {
+	return waitContext(ctx, c.Disconnect)
+}
+
+// close stops the monitor and closes the connection. Safe to call more than once.
+func (c *Client) close() error {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	if c.closed {
+		c.mutex.Unlock()
+		fmt.Println("[STOMP] Already disconnected")
+		return nil
+	}
+	c.closed = true
+	c.isHealthy = false
+	close(c.stopMonitor)
+	conn := c.conn
+	c.conn = nil
+	c.mutex.Unlock()
 
-	if c.conn != nil {
-		err := c.conn.Disconnect()
-		if err != nil {
-			fmt.Printf("[STOMP] ERROR: Disconnect failed: %v\n", err)
-			return err
-		}
-		fmt.Println("[STOMP] ✓ Disconnected successfully")
+	if conn == nil {
+		fmt.Println("[STOMP] Already disconnected")
 		return nil
 	}
 
-	fmt.Println("[STOMP] Already disconnected")
+	// The DISCONNECT receipt wait is bounded by the disconnect receipt timeout set in connect
+	if err := conn.Disconnect(); err != nil {
+		fmt.Printf("[STOMP] ERROR: Disconnect failed: %v\n", err)
+		return err
+	}
+
+	fmt.Println("[STOMP] ✓ Disconnected successfully")
 	return nil
 }
 
@@ -391,6 +558,7 @@ func (c *Client) Disconnect() error {
 func (c *Client) Publish(destination string, payload interface{}) error {
 	c.mutex.RLock()
 	conn := c.conn
+	generation := c.generation
 	c.mutex.RUnlock()
 
 	if conn == nil {
@@ -414,7 +582,8 @@ func (c *Client) Publish(destination string, payload interface{}) error {
 	)
 
 	if err != nil {
-		c.mutex.Lock()
+		fmt.Printf("[STOMP] ERROR: Publish failed: %v\n", err)
+		c.markUnhealthy(generation, fmt.Errorf("publish failed: %w", err))
 		return err
 	}
 
@@ -422,6 +591,29 @@ func (c *Client) Publish(destination string, payload interface{}) error {
 	return nil
 }
 
+// PublishContext is like Publish but stops waiting when ctx is done. Nothing is sent once ctx is done.
+func (c *Client) PublishContext(ctx context.Context, destination string, payload interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("publish to %s: %w", destination, err)
+	}
+	if err := waitContext(ctx, func() error { return c.Publish(destination, payload) }); err != nil {
+		return fmt.Errorf("publish to %s: %w", destination, err)
+	}
+	return nil
+}
+
+// waitContext runs fn and returns its error, or ctx.Err() once ctx is done. fn keeps running in the background.
+func waitContext(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Subscribe subscribes to a destination. The handler will be called when a message is received.
 // Subscriptions are automatically restored on reconnection.
 func (c *Client) Subscribe(destination string, handler func([]byte)) error {
@@ -441,12 +633,13 @@ func (c *Client) Subscribe(destination string, handler func([]byte)) error {
 	return nil
 }
 
-// subscribeInternal performs the actual STOMP subscription
+// subscribeInternal performs the actual STOMP subscription. Caller must hold the lock.
 func (c *Client) subscribeInternal(destination string, handler func([]byte)) error {
 	sub, err := c.conn.Subscribe(destination, stomp.AckAuto)
 	if err != nil {
 		return err
 	}
+	generation := c.generation
 
 	fmt.Printf("[STOMP] Subscribed to %s\n", destination)
 
@@ -456,10 +649,18 @@ func (c *Client) subscribeInternal(destination string, handler func([]byte)) err
 			msg, ok := <-sub.C
 			if !ok {
 				fmt.Printf("[STOMP] Subscription channel closed for %s\n", destination)
+				// The server or a missed heart-beat closed the connection
+				c.markUnhealthy(generation, fmt.Errorf("subscription to %s closed", destination))
 				return
 			}
 			if msg.Err != nil {
 				fmt.Printf("[STOMP] Error on subscription %s: %v\n", destination, msg.Err)
+				if isAuthError(msg.Err) {
+					c.mutex.Lock()
+					c.authRejected = true
+					c.mutex.Unlock()
+					c.markUnhealthy(generation, msg.Err)
+				}
 				continue
 			}
 			// Call handler
@@ -486,35 +687,3 @@ func (c *Client) resubscribeAll() {
 		}
 	}
 }
-
-// normalizeURL processes the input URL to ensure it's a valid WebSocket URL
-// It handles http->ws conversion and SockJS path construction
-func normalizeURL(urlStr string) (string, error) {
-	u, err := url.Parse(urlStr)
-	if err != nil {
-		return "", fmt.Errorf("invalid URL: %w", err)
-	}
-
-	// Handle scheme conversion
-	if u.Scheme == "http" {
-		u.Scheme = "ws"
-	} else if u.Scheme == "https" {
-		u.Scheme = "wss"
-	}
-
-	// 1. If no path, append /ws (assuming standard BB-Core convention)
-	if u.Path == "" || u.Path == "/" {
-		u.Path = "/ws"
-	}
-
-	// 2. If path ends in /ws, it's likely a SockJS base URL.
-	// standard gorilla/websocket doesn't speak SockJS protocol, so we must
-	// construct the raw websocket endpoint manually: /base/server_id/session_id/websocket
-	if strings.HasSuffix(u.Path, "/ws") {
-		// Append dummy server_id (0-999) and session_id
-		// Using fixed values is fine for a client connection
-		u.Path = path.Join(u.Path, "999", "bbapp", "websocket")
-	}
-
-	return u.String(), nil
-}
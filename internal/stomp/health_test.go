@@ -0,0 +1,263 @@
+package stomp_test
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bbapp/internal/stomp"
+)
+
+// fakeBroker is a minimal STOMP server over TCP. It answers CONNECT and DISCONNECT
+// and lets tests drop the current connection.
+type fakeBroker struct {
+	listener    net.Listener
+	mutex       sync.Mutex
+	current     net.Conn
+	acceptToken string // When set, CONNECT frames with another login get an auth ERROR
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	b := &fakeBroker{listener: l}
+	go b.serve()
+	t.Cleanup(func() { l.Close() })
+	return b
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		b.mutex.Lock()
+		b.current = conn
+		b.mutex.Unlock()
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeBroker) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		raw, err := reader.ReadString(0)
+		if err != nil {
+			return
+		}
+		lines := strings.Split(strings.TrimLeft(raw, "\r\n"), "\n")
+		switch lines[0] {
+		case "CONNECT", "STOMP":
+			b.mutex.Lock()
+			accept := b.acceptToken
+			b.mutex.Unlock()
+			if accept != "" && !containsLine(lines, "login:"+accept) {
+				conn.Write([]byte("ERROR\nmessage:Invalid token\n\nJWT expired\x00"))
+				return
+			}
+			conn.Write([]byte("CONNECTED\nversion:1.2\nheart-beat:0,0\n\n\x00"))
+		case "DISCONNECT":
+			for _, line := range lines[1:] {
+				if strings.HasPrefix(line, "receipt:") {
+					conn.Write([]byte("RECEIPT\nreceipt-id:" + strings.TrimPrefix(line, "receipt:") + "\n\n\x00"))
+				}
+			}
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) setAcceptToken(token string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.acceptToken = token
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *fakeBroker) drop() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.current != nil {
+		b.current.Close()
+	}
+}
+
+func waitForState(t *testing.T, states <-chan stomp.StateEvent, want string) stomp.StateEvent {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-states:
+			if event.State == want {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for state %s", want)
+		}
+	}
+}
+
+func TestClient_StateEventsAndReconnect(t *testing.T) {
+	broker := newFakeBroker(t)
+	states := make(chan stomp.StateEvent, 16)
+
+	client, err := stomp.NewClient(broker.listener.Addr().String(), "", "",
+		stomp.WithStateHandler(func(e stomp.StateEvent) { states <- e }))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	waitForState(t, states, stomp.StateConnecting)
+	waitForState(t, states, stomp.StateConnected)
+	if !client.IsHealthy() {
+		t.Error("Expected client to be healthy after connect")
+	}
+
+	// A silent server-side drop must be detected without waiting for a publish
+	broker.drop()
+	event := waitForState(t, states, stomp.StateReconnecting)
+	if event.Error == "" {
+		t.Error("Expected RECONNECTING to carry the failure reason")
+	}
+	waitForState(t, states, stomp.StateConnected)
+
+	if err := client.Disconnect(); err != nil {
+		t.Errorf("Disconnect failed: %v", err)
+	}
+	waitForState(t, states, stomp.StateDisconnected)
+
+	// A second Disconnect is a no-op
+	if err := client.Disconnect(); err != nil {
+		t.Errorf("Second Disconnect failed: %v", err)
+	}
+}
+
+func TestClient_ConnectFailureReportsFailed(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	l.Close()
+
+	var got []string
+	_, err := stomp.NewClient(addr, "", "", stomp.WithStateHandler(func(e stomp.StateEvent) {
+		got = append(got, e.State)
+	}))
+	if err == nil {
+		t.Fatal("Expected connect to a closed port to fail")
+	}
+	if len(got) != 2 || got[0] != stomp.StateConnecting || got[1] != stomp.StateFailed {
+		t.Errorf("Expected CONNECTING then FAILED, got %v", got)
+	}
+}
+
+// rotatingTokens hands out "token-<n>" and increments n on every refresh
+type rotatingTokens struct {
+	mutex     sync.Mutex
+	n         int
+	refreshes int
+}
+
+func (p *rotatingTokens) GetAccessToken() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return fmt.Sprintf("token-%d", p.n)
+}
+
+func (p *rotatingTokens) RefreshAccessToken() (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.n++
+	p.refreshes++
+	return fmt.Sprintf("token-%d", p.n), nil
+}
+
+func TestClient_RefreshesTokenOnAuthError(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.setAcceptToken("token-1")
+	tokens := &rotatingTokens{}
+	states := make(chan stomp.StateEvent, 16)
+
+	// The initial token is rejected; the client refreshes once and connects
+	client, err := stomp.NewClient(broker.listener.Addr().String(), "", "",
+		stomp.WithTokenProvider(tokens),
+		stomp.WithStateHandler(func(e stomp.StateEvent) { states <- e }))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Disconnect()
+	if tokens.refreshes != 1 {
+		t.Errorf("Expected 1 refresh, got %d", tokens.refreshes)
+	}
+
+	// The token expires while connected; the reconnect must use a refreshed one
+	broker.setAcceptToken("token-2")
+	broker.drop()
+	waitForState(t, states, stomp.StateReconnecting)
+	waitForState(t, states, stomp.StateConnected)
+
+	if tokens.GetAccessToken() != "token-2" {
+		t.Errorf("Expected reconnect to refresh to token-2, got %s", tokens.GetAccessToken())
+	}
+}
+
+func TestReconnectPolicy_Delay(t *testing.T) {
+	policy := stomp.ReconnectPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second, Multiplier: 2}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, expected := range want {
+		if got := policy.Delay(i + 1); got != expected {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, expected)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 50; i++ {
+		if got := policy.Delay(2); got < time.Second || got > 3*time.Second {
+			t.Fatalf("Jittered delay %v outside ±50%% of 2s", got)
+		}
+	}
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	broker := newFakeBroker(t)
+	states := make(chan stomp.StateEvent, 16)
+
+	client, err := stomp.NewClient(broker.listener.Addr().String(), "", "",
+		stomp.WithReconnectPolicy(stomp.ReconnectPolicy{MaxAttempts: 2, InitialDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond}),
+		stomp.WithStateHandler(func(e stomp.StateEvent) { states <- e }))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	// The broker goes away for good
+	broker.listener.Close()
+	broker.drop()
+
+	if event := waitForState(t, states, stomp.StateReconnecting); event.Attempt != 1 {
+		t.Errorf("Expected attempt 1, got %d", event.Attempt)
+	}
+	if event := waitForState(t, states, stomp.StateReconnecting); event.Attempt != 2 {
+		t.Errorf("Expected attempt 2, got %d", event.Attempt)
+	}
+	waitForState(t, states, stomp.StateFailed)
+
+	state := client.State()
+	if state.State != stomp.StateFailed || state.FailedAttempts != 2 || state.LastError == "" || state.Healthy {
+		t.Errorf("Unexpected state after giving up: %+v", state)
+	}
+}
@@ -0,0 +1,104 @@
+package stomp
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy controls how the client retries after losing the connection
+type ReconnectPolicy struct {
+	MaxAttempts  int           // 0 retries forever
+	InitialDelay time.Duration // Delay before the first attempt
+	MaxDelay     time.Duration // Ceiling for the exponential backoff
+	Multiplier   float64       // Growth factor between attempts
+	Jitter       float64       // Random fraction (0-1) added to or removed from each delay
+}
+
+// DefaultReconnectPolicy retries forever: 1s, 2s, 4s ... capped at 1 minute, ±20%
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// WithReconnectPolicy replaces the default reconnect policy. Zero delays and multiplier take the defaults.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(c *Client) {
+		defaults := DefaultReconnectPolicy()
+		if policy.InitialDelay <= 0 {
+			policy.InitialDelay = defaults.InitialDelay
+		}
+		if policy.MaxDelay <= 0 {
+			policy.MaxDelay = defaults.MaxDelay
+		}
+		if policy.Multiplier < 1 {
+			policy.Multiplier = defaults.Multiplier
+		}
+		policy.Jitter = math.Max(0, math.Min(policy.Jitter, 1))
+		c.policy = policy
+	}
+}
+
+// Delay returns how long to wait before the given attempt (starting at 1)
+func (p ReconnectPolicy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// ConnectionState is a snapshot of the client's connection, as returned by State
+type ConnectionState struct {
+	State          string    `json:"state"`
+	Healthy        bool      `json:"healthy"`
+	Attempt        int       `json:"attempt"`        // Current reconnect attempt (0 when connected)
+	MaxAttempts    int       `json:"maxAttempts"`    // 0 = unlimited
+	Reconnects     int       `json:"reconnects"`     // Successful reconnects since the client was created
+	FailedAttempts int       `json:"failedAttempts"` // Failed reconnect attempts since the client was created
+	LastError      string    `json:"lastError,omitempty"`
+	LastErrorAt    time.Time `json:"lastErrorAt"`
+	ConnectedAt    time.Time `json:"connectedAt"`
+	NextRetryAt    time.Time `json:"nextRetryAt"` // Zero unless waiting for a reconnect attempt
+}
+
+// State returns the current connection state and reconnect counters
+func (c *Client) State() ConnectionState {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	state := ConnectionState{
+		State:          c.state,
+		Healthy:        c.isHealthy,
+		Attempt:        c.attempt,
+		MaxAttempts:    c.policy.MaxAttempts,
+		Reconnects:     c.reconnects,
+		FailedAttempts: c.failedAttempts,
+		LastErrorAt:    c.lastErrorAt,
+		ConnectedAt:    c.connectedAt,
+		NextRetryAt:    c.nextRetryAt,
+	}
+	if c.lastError != nil {
+		state.LastError = c.lastError.Error()
+	}
+	return state
+}
+
+// recordError remembers the most recent connection error for State
+func (c *Client) recordError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastError = err
+	c.lastErrorAt = time.Now()
+}
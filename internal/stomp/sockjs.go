@@ -0,0 +1,451 @@
+package stomp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SockJS transports, in the order they are tried by default
+const (
+	TransportWebSocket    = "websocket"
+	TransportXHRStreaming = "xhr_streaming"
+	TransportXHRPolling   = "xhr"
+)
+
+// DefaultTransports is the SockJS fallback order: WebSocket, then xhr-streaming, then xhr-polling
+var DefaultTransports = []string{TransportWebSocket, TransportXHRStreaming, TransportXHRPolling}
+
+var errSockJSClosed = errors.New("SockJS connection closed by server")
+
+// WithTransports restricts the SockJS transports tried, in order (e.g. to skip WebSocket behind a proxy)
+func WithTransports(transports ...string) Option {
+	return func(c *Client) {
+		c.transports = transports
+	}
+}
+
+// sockJSInfo is the response of the SockJS /info probe
+type sockJSInfo struct {
+	WebSocket    bool  `json:"websocket"`
+	CookieNeeded bool  `json:"cookie_needed"`
+	Entropy      int64 `json:"entropy"`
+}
+
+// resolveEndpoint classifies a STOMP URL. SockJS endpoints (no path, or a path ending in /ws)
+// return their http(s) base URL; anything else is a raw WebSocket URL.
+func resolveEndpoint(urlStr string) (*url.URL, bool, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// If no path, append /ws (assuming standard BB-Core convention)
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/ws"
+	}
+
+	if strings.HasSuffix(u.Path, "/ws") {
+		switch u.Scheme {
+		case "ws":
+			u.Scheme = "http"
+		case "wss":
+			u.Scheme = "https"
+		}
+		return u, true, nil
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+	return u, false, nil
+}
+
+// dialSockJS probes /info and opens a SockJS session, falling back through the given transports
+func dialSockJS(base *url.URL, token string, transports []string) (net.Conn, error) {
+	token = bearerToken(token)
+	if len(transports) == 0 {
+		transports = DefaultTransports
+	}
+
+	jar, _ := cookiejar.New(nil) // Keeps the load balancer's sticky session cookie
+	client := &http.Client{Jar: jar}
+
+	info, err := probeSockJSInfo(client, base, token)
+	if errors.Is(err, ErrAuthRejected) {
+		return nil, err
+	}
+	if err != nil {
+		fmt.Printf("[STOMP] WARNING: SockJS /info probe failed, trying transports anyway: %v\n", err)
+		info = &sockJSInfo{WebSocket: true}
+	}
+
+	var lastErr error
+	for _, transport := range transports {
+		if transport == TransportWebSocket && !info.WebSocket {
+			fmt.Println("[STOMP] Server has WebSocket disabled, skipping")
+			continue
+		}
+
+		// Every attempt gets a fresh session, as the server may have half-opened the previous one
+		sessionURL := *base
+		sessionURL.Path = path.Join(base.Path, randomServerId(), randomSessionId())
+
+		fmt.Printf("[STOMP] Trying SockJS transport: %s\n", transport)
+		var conn net.Conn
+		switch transport {
+		case TransportWebSocket:
+			wsURL := sessionURL
+			wsURL.Path = path.Join(sessionURL.Path, "websocket")
+			if wsURL.Scheme == "https" {
+				wsURL.Scheme = "wss"
+			} else {
+				wsURL.Scheme = "ws"
+			}
+			conn, err = dialWebSocket(wsURL.String(), token, true)
+		case TransportXHRStreaming, TransportXHRPolling:
+			conn, err = dialXHR(client, &sessionURL, token, transport == TransportXHRStreaming)
+		default:
+			err = fmt.Errorf("unknown SockJS transport %q", transport)
+		}
+
+		if err == nil {
+			fmt.Printf("[STOMP] ✓ SockJS session open via %s\n", transport)
+			return conn, nil
+		}
+		fmt.Printf("[STOMP] SockJS transport %s failed: %v\n", transport, err)
+		lastErr = err
+		// /info accepted the token, so a refused upgrade is more likely a proxy; xhr refusals are final
+		if errors.Is(err, ErrAuthRejected) && transport != TransportWebSocket {
+			return nil, err
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no usable transport")
+	}
+	return nil, fmt.Errorf("all SockJS transports failed: %w", lastErr)
+}
+
+func probeSockJSInfo(client *http.Client, base *url.URL, token string) (*sockJSInfo, error) {
+	infoURL := *base
+	infoURL.Path = path.Join(base.Path, "info")
+	query := infoURL.Query()
+	query.Set("t", strconv.FormatInt(time.Now().UnixMilli(), 10)) // Cache buster, as in sockjs-client
+	infoURL.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := newSockJSRequest(ctx, http.MethodGet, withToken(&infoURL, token), token, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if isAuthStatus(resp.StatusCode) {
+		return nil, fmt.Errorf("info: %w (status %d)", ErrAuthRejected, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("info returned status %d", resp.StatusCode)
+	}
+
+	var info sockJSInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("decode info: %w", err)
+	}
+	return &info, nil
+}
+
+// decodeSockJSFrame appends the STOMP data carried by a SockJS frame to buf.
+// Open frames carry nothing, heartbeats become a STOMP EOL and close frames return an error.
+func decodeSockJSFrame(frame []byte, buf *bytes.Buffer) error {
+	if len(frame) == 0 {
+		return nil
+	}
+
+	switch frame[0] {
+	case 'o': // Open frame
+		return nil
+	case 'h': // Heartbeat frame
+		// Translate SockJS heartbeat to STOMP heartbeat (newline)
+		// This keeps the STOMP connection alive even if no real data is flowing
+		buf.WriteByte('\n')
+		return nil
+	case 'c': // Close frame, e.g. c[3000,"Go away!"]
+		return fmt.Errorf("%w: %s", errSockJSClosed, frame[1:])
+	case 'a': // Data array frame: a["msg1","msg2"]
+		var messages []string
+		if err := json.Unmarshal(frame[1:], &messages); err != nil {
+			return fmt.Errorf("failed to parse SockJS data frame: %w", err)
+		}
+		for _, msg := range messages {
+			buf.WriteString(msg)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown SockJS frame %q", frame[0])
+	}
+}
+
+// encodeSockJSFrame wraps outgoing data in a SockJS array: ["message"]
+func encodeSockJSFrame(p []byte) ([]byte, error) {
+	return json.Marshal([]string{string(p)})
+}
+
+// xhrConn is a SockJS session over xhr-streaming or xhr-polling, exposed as a net.Conn.
+// Received frames are decoded by a background loop and handed to Read through a pipe.
+type xhrConn struct {
+	client     *http.Client
+	sessionURL *url.URL
+	token      string
+	streaming  bool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	reader     *io.PipeReader
+	writer     *io.PipeWriter
+}
+
+// dialXHR opens a SockJS session and waits for the open frame before returning
+func dialXHR(client *http.Client, sessionURL *url.URL, token string, streaming bool) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, writer := io.Pipe()
+	c := &xhrConn{
+		client:     client,
+		sessionURL: sessionURL,
+		token:      token,
+		streaming:  streaming,
+		ctx:        ctx,
+		cancel:     cancel,
+		reader:     reader,
+		writer:     writer,
+	}
+
+	body, err := c.receive()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	lines := bufio.NewReader(body)
+	frame, err := readSockJSLine(lines, streaming)
+	if err != nil || len(frame) == 0 || frame[0] != 'o' {
+		body.Close()
+		cancel()
+		if err == nil {
+			err = fmt.Errorf("expected open frame, got %q", frame)
+		}
+		return nil, err
+	}
+
+	go c.receiveLoop(body, lines)
+	return c, nil
+}
+
+// receive starts one xhr_streaming or xhr (polling) request
+func (c *xhrConn) receive() (io.ReadCloser, error) {
+	transport := TransportXHRPolling
+	if c.streaming {
+		transport = TransportXHRStreaming
+	}
+	target := *c.sessionURL
+	target.Path = path.Join(c.sessionURL.Path, transport)
+
+	req, err := newSockJSRequest(c.ctx, http.MethodPost, withToken(&target, c.token), c.token, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if isAuthStatus(resp.StatusCode) {
+			return nil, fmt.Errorf("%s: %w (status %d)", transport, ErrAuthRejected, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%s returned status %d", transport, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// receiveLoop decodes frames into the pipe, re-opening the receiving request whenever the server ends it
+func (c *xhrConn) receiveLoop(body io.ReadCloser, lines *bufio.Reader) {
+	var buf bytes.Buffer
+	for {
+		frame, err := readSockJSLine(lines, c.streaming)
+		if err == io.EOF {
+			// Polling requests carry one frame; streaming requests end after the server's byte limit
+			body.Close()
+			if body, err = c.receive(); err != nil {
+				c.writer.CloseWithError(err)
+				return
+			}
+			lines = bufio.NewReader(body)
+			continue
+		}
+		if err == nil {
+			err = decodeSockJSFrame(frame, &buf)
+		}
+		if err != nil {
+			body.Close()
+			c.writer.CloseWithError(err)
+			return
+		}
+
+		if buf.Len() > 0 {
+			if _, err := c.writer.Write(buf.Bytes()); err != nil {
+				body.Close()
+				return // Closed locally
+			}
+			buf.Reset()
+		}
+	}
+}
+
+// readSockJSLine reads one newline-terminated frame. Streaming responses start with a 2KB 'h' prelude.
+func readSockJSLine(r *bufio.Reader, streaming bool) ([]byte, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			continue
+		}
+		if streaming && len(line) > 1 && bytes.Count(line, []byte{'h'}) == len(line) {
+			continue // Prelude that defeats proxy buffering
+		}
+		return line, nil
+	}
+}
+
+func (c *xhrConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (c *xhrConn) Write(p []byte) (int, error) {
+	frame, err := encodeSockJSFrame(p)
+	if err != nil {
+		return 0, err
+	}
+
+	target := *c.sessionURL
+	target.Path = path.Join(c.sessionURL.Path, "xhr_send")
+
+	ctx, cancel := context.WithTimeout(c.ctx, wsWriteTimeout)
+	defer cancel()
+
+	req, err := newSockJSRequest(ctx, http.MethodPost, withToken(&target, c.token), c.token, frame)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("xhr_send returned status %d", resp.StatusCode)
+	}
+	return len(p), nil
+}
+
+func (c *xhrConn) Close() error {
+	c.cancel()
+	c.writer.Close()
+	return c.reader.Close()
+}
+
+func (c *xhrConn) LocalAddr() net.Addr {
+	return sockJSAddr("local")
+}
+
+func (c *xhrConn) RemoteAddr() net.Addr {
+	return sockJSAddr(c.sessionURL.Host)
+}
+
+// Deadlines are not supported over HTTP; dead sessions are detected by STOMP heart-beats
+func (c *xhrConn) SetDeadline(t time.Time) error      { return nil }
+func (c *xhrConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *xhrConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// sockJSAddr is the net.Addr of an xhr session
+type sockJSAddr string
+
+func (a sockJSAddr) Network() string { return "sockjs" }
+func (a sockJSAddr) String() string  { return string(a) }
+
+func newSockJSRequest(ctx context.Context, method, target, token string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain;charset=UTF-8")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// withToken returns the URL with the token added as a query parameter
+func withToken(u *url.URL, token string) string {
+	if token == "" {
+		return u.String()
+	}
+	withQuery := *u
+	query := withQuery.Query()
+	query.Set("token", token)
+	withQuery.RawQuery = query.Encode()
+	return withQuery.String()
+}
+
+// bearerToken strips a "Bearer " prefix so it isn't duplicated in headers
+func bearerToken(token string) string {
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		return strings.TrimSpace(token[7:])
+	}
+	return token
+}
+
+// randomServerId returns a random SockJS server id (000-999)
+func randomServerId() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1000))
+	return fmt.Sprintf("%03d", n.Int64())
+}
+
+// randomSessionId returns a random 8 character SockJS session id
+func randomSessionId() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	id := make([]byte, 8)
+	for i := range id {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		id[i] = alphabet[n.Int64()]
+	}
+	return string(id)
+}
@@ -2,32 +2,308 @@ package stomp
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
-// Mock buffer to simulate network
-type mockBuffer struct {
-	*bytes.Buffer
+// sockJSStandIn is a local SockJS server (/ws prefix) with a minimal STOMP broker behind it
+type sockJSStandIn struct {
+	server         *httptest.Server
+	websocket      bool // Advertised by /info
+	blockWebSocket bool // Reject upgrades, like a proxy that doesn't pass WebSockets
+	mutex          sync.Mutex
+	sessions       map[string]*standInSession
+	opened         []string // "<serverId>/<sessionId>/<transport>" per opened session
+	sent           []string // Destinations of received SEND frames
+	infoToken      string
+}
+
+type standInSession struct {
+	outgoing chan string
+	inbox    strings.Builder
+	opened   bool
+}
+
+func newSockJSStandIn(t *testing.T, websocketEnabled, blockWebSocket bool) *sockJSStandIn {
+	s := &sockJSStandIn{websocket: websocketEnabled, blockWebSocket: blockWebSocket, sessions: make(map[string]*standInSession)}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *sockJSStandIn) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/ws/"), "/")
+	if len(parts) == 1 && parts[0] == "info" {
+		s.mutex.Lock()
+		s.infoToken = r.URL.Query().Get("token")
+		s.mutex.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"websocket": s.websocket, "cookie_needed": false, "entropy": 42})
+		return
+	}
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := parts[0] + "/" + parts[1]
+	s.mutex.Lock()
+	sess, ok := s.sessions[key]
+	if !ok && parts[2] != "xhr_send" {
+		sess = &standInSession{outgoing: make(chan string, 16)}
+		s.sessions[key] = sess
+		s.opened = append(s.opened, key+"/"+parts[2])
+	}
+	s.mutex.Unlock()
+	if sess == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch parts[2] {
+	case "websocket":
+		s.serveWebSocket(w, r, sess)
+	case "xhr_streaming":
+		flusher := w.(http.Flusher)
+		io.WriteString(w, strings.Repeat("h", 2048)+"\n")
+		io.WriteString(w, "o\n")
+		flusher.Flush()
+		for {
+			select {
+			case frame := <-sess.outgoing:
+				io.WriteString(w, "a"+encodeStandIn(frame)+"\n")
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case "xhr":
+		if !sess.opened {
+			sess.opened = true
+			io.WriteString(w, "o\n")
+			return
+		}
+		select {
+		case frame := <-sess.outgoing:
+			io.WriteString(w, "a"+encodeStandIn(frame)+"\n")
+		case <-time.After(200 * time.Millisecond):
+			io.WriteString(w, "h\n")
+		case <-r.Context().Done():
+		}
+	case "xhr_send":
+		var messages []string
+		if err := json.NewDecoder(r.Body).Decode(&messages); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, msg := range messages {
+			s.feed(sess, msg)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *sockJSStandIn) serveWebSocket(w http.ResponseWriter, r *http.Request, sess *standInSession) {
+	if !s.websocket || s.blockWebSocket {
+		http.Error(w, "websockets blocked", http.StatusForbidden)
+		return
+	}
+	upgrader := websocket.Upgrader{Subprotocols: []string{"v12.stomp"}}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	conn.WriteMessage(websocket.TextMessage, []byte("o"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var messages []string
+			json.Unmarshal(data, &messages)
+			for _, msg := range messages {
+				s.feed(sess, msg)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case frame := <-sess.outgoing:
+			conn.WriteMessage(websocket.TextMessage, []byte("a"+encodeStandIn(frame)))
+		case <-done:
+			return
+		}
+	}
+}
+
+// feed handles STOMP frames sent by the client
+func (s *sockJSStandIn) feed(sess *standInSession, data string) {
+	sess.inbox.WriteString(data)
+	for {
+		buffered := sess.inbox.String()
+		end := strings.IndexByte(buffered, 0)
+		if end < 0 {
+			return
+		}
+		sess.inbox.Reset()
+		sess.inbox.WriteString(buffered[end+1:])
+
+		lines := strings.Split(strings.TrimLeft(buffered[:end], "\r\n"), "\n")
+		headers := map[string]string{}
+		for _, line := range lines[1:] {
+			if k, v, ok := strings.Cut(line, ":"); ok {
+				headers[k] = v
+			}
+		}
+
+		switch lines[0] {
+		case "CONNECT", "STOMP":
+			sess.outgoing <- "CONNECTED\nversion:1.2\nheart-beat:0,0\n\n\x00"
+		case "SEND":
+			s.mutex.Lock()
+			s.sent = append(s.sent, headers["destination"])
+			s.mutex.Unlock()
+		case "DISCONNECT":
+			sess.outgoing <- "RECEIPT\nreceipt-id:" + headers["receipt"] + "\n\n\x00"
+		}
+	}
+}
+
+func (s *sockJSStandIn) sentDestinations() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.sent...)
+}
+
+func (s *sockJSStandIn) openedSessions() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.opened...)
+}
+
+func encodeStandIn(frame string) string {
+	data, _ := json.Marshal([]string{frame})
+	return string(data)
+}
+
+func waitForSend(t *testing.T, s *sockJSStandIn, destination string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, d := range s.sentDestinations() {
+			if d == destination {
+				return
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("SEND to %s not received, got %v", destination, s.sentDestinations())
+}
+
+var sessionPathPattern = regexp.MustCompile(`^\d{3}/[a-z0-9]{8}/`)
+
+func TestSockJS_Transports(t *testing.T) {
+	for _, transport := range DefaultTransports {
+		t.Run(transport, func(t *testing.T) {
+			standIn := newSockJSStandIn(t, true, false)
+
+			client, err := NewClient(standIn.server.URL, "Bearer secret-token", "", WithTransports(transport))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			if err := client.Publish("/app/room/r1/bigo", map[string]string{"type": "GIFT"}); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			waitForSend(t, standIn, "/app/room/r1/bigo")
+
+			if err := client.Disconnect(); err != nil {
+				t.Errorf("Disconnect failed: %v", err)
+			}
+
+			opened := standIn.openedSessions()
+			if len(opened) != 1 || !strings.HasSuffix(opened[0], "/"+transport) || !sessionPathPattern.MatchString(opened[0]) {
+				t.Errorf("Expected one %s session with random ids, got %v", transport, opened)
+			}
+			if standIn.infoToken != "secret-token" {
+				t.Errorf("Expected /info probe to carry the token without Bearer prefix, got %q", standIn.infoToken)
+			}
+		})
+	}
 }
 
-func (m *mockBuffer) Close() error {
-	return nil
+func TestSockJS_Fallback(t *testing.T) {
+	tests := []struct {
+		name           string
+		websocket      bool
+		blockWebSocket bool
+		attempts       int
+	}{
+		{"websocket disabled by server", false, false, 1},
+		{"websocket blocked by proxy", true, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := newSockJSStandIn(t, tt.websocket, tt.blockWebSocket)
+
+			client, err := NewClient(standIn.server.URL+"/ws", "", "")
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			defer client.Disconnect()
+
+			if err := client.Publish("/app/room/r1/chat", "hi"); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			waitForSend(t, standIn, "/app/room/r1/chat")
+
+			opened := standIn.openedSessions()
+			if len(opened) != tt.attempts || !strings.HasSuffix(opened[len(opened)-1], "/xhr_streaming") {
+				t.Errorf("Expected fallback to xhr_streaming after %d attempt(s), got %v", tt.attempts, opened)
+			}
+		})
+	}
 }
 
-// TestSockJSRead verifies that valid SockJS frames are unwrapped correctly
-// and control frames are ignored.
-// Note: We can't easily mock the internal websocket.Conn without a real server or deeper refactoring,
-// so we'll test the logic concepts or integration if possible.
-// Given the constraints, we will rely on integration/manual verification for the full flow,
-// or we can test the helper functions if we extracted them.
-// Since we embedded the logic in Read(), let's try a real but local websocket test.
+func TestDecodeSockJSFrame(t *testing.T) {
+	tests := []struct {
+		frame   string
+		want    string
+		wantErr bool
+	}{
+		{"o", "", false},
+		{"h", "\n", false},
+		{`a["CONNECTED\n\n\u0000","MESSAGE\n\n\u0000"]`, "CONNECTED\n\n\x00MESSAGE\n\n\x00", false},
+		{`c[3000,"Go away!"]`, "", true},
+		{`a[broken`, "", true},
+	}
 
-func TestSockJSFraming(t *testing.T) {
-	// This would ideally require a mock websocket server.
-	// For now, we will assume the manual verification and the existing 'test_ws.go'
-	// (if updated to send frames) would be the way.
-	// Or we can rely on the fact that we just implemented standard SockJS protocol.
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		err := decodeSockJSFrame([]byte(tt.frame), &buf)
+		if (err != nil) != tt.wantErr || buf.String() != tt.want {
+			t.Errorf("decodeSockJSFrame(%q) = %q, %v", tt.frame, buf.String(), err)
+		}
+	}
 
-	// Placeholder to pass CI for now, real verification happens in manual step or with a full mock server
-	t.Log("SockJS framing logic implemented - verifying manually with actual server connection")
+	if data, _ := encodeSockJSFrame([]byte("SEND\n\n\x00")); string(data) != `["SEND\n\n\u0000"]` {
+		t.Errorf("Unexpected encoded frame: %s", data)
+	}
 }
@@ -0,0 +1,122 @@
+package stomp
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Connection states reported to state subscribers
+const (
+	StateConnecting   = "CONNECTING"
+	StateConnected    = "CONNECTED"
+	StateReconnecting = "RECONNECTING"
+	StateFailed       = "FAILED"       // Reconnection gave up; the client is closed
+	StateDisconnected = "DISCONNECTED" // Closed by Disconnect
+)
+
+// StateEvent is a connection state change
+type StateEvent struct {
+	State   string    `json:"state"`
+	Error   string    `json:"error,omitempty"` // Why the connection was lost or failed
+	Attempt int       `json:"attempt"`         // Reconnect attempt about to be made (RECONNECTING only)
+	At      time.Time `json:"at"`
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHeartBeat sets the STOMP heart-beat intervals offered to the server (0 disables that direction)
+func WithHeartBeat(send, recv time.Duration) Option {
+	return func(c *Client) {
+		c.heartBeatSend = send
+		c.heartBeatRecv = recv
+	}
+}
+
+// WithStateHandler registers a state callback before the first connect, so CONNECTING/CONNECTED are reported too
+func WithStateHandler(callback func(StateEvent)) Option {
+	return func(c *Client) {
+		c.onState = append(c.onState, callback)
+	}
+}
+
+// SubscribeState registers a callback for connection state changes
+func (c *Client) SubscribeState(callback func(StateEvent)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onState = append(c.onState, callback)
+}
+
+// IsHealthy reports whether the connection is up and no failure has been detected
+func (c *Client) IsHealthy() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.isHealthy
+}
+
+// setState records and broadcasts a state change. Must not be called with the lock held.
+func (c *Client) setState(state string, cause error) {
+	event := StateEvent{State: state, At: time.Now()}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+
+	c.mutex.Lock()
+	c.state = state
+	if state == StateReconnecting {
+		event.Attempt = c.attempt
+	}
+	callbacks := make([]func(StateEvent), len(c.onState))
+	copy(callbacks, c.onState)
+	c.mutex.Unlock()
+
+	for _, cb := range callbacks {
+		cb(event)
+	}
+}
+
+// markUnhealthy flags the connection of the given generation as broken and wakes the monitor.
+// Signals from connections replaced by a reconnect, or after Disconnect, are ignored.
+func (c *Client) markUnhealthy(generation int, cause error) {
+	c.mutex.Lock()
+	if c.closed || generation != c.generation || !c.isHealthy {
+		c.mutex.Unlock()
+		return
+	}
+	c.isHealthy = false
+	c.lastError = cause
+	c.lastErrorAt = time.Now()
+	c.mutex.Unlock()
+
+	fmt.Printf("[STOMP] Connection marked unhealthy: %v\n", cause)
+
+	select {
+	case c.healthCheck <- struct{}{}:
+	default:
+	}
+}
+
+// watchedConn reports the first read or write error of the underlying connection
+type watchedConn struct {
+	net.Conn
+	once    sync.Once
+	onError func(error)
+}
+
+func (w *watchedConn) Read(p []byte) (int, error) {
+	n, err := w.Conn.Read(p)
+	if err != nil {
+		w.once.Do(func() { w.onError(err) })
+	}
+	return n, err
+}
+
+func (w *watchedConn) Write(p []byte) (int, error) {
+	n, err := w.Conn.Write(p)
+	if err != nil {
+		w.once.Do(func() { w.onError(err) })
+	}
+	return n, err
+}
@@ -0,0 +1,113 @@
+package stomp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-stomp/stomp/v3"
+)
+
+// TokenProvider supplies the access token for each connection attempt. *api.Client implements it.
+type TokenProvider interface {
+	GetAccessToken() string
+	RefreshAccessToken() (string, error)
+}
+
+// ErrAuthRejected is wrapped by dial errors when the server answers 401 or 403
+var ErrAuthRejected = errors.New("authentication rejected")
+
+// Substrings of STOMP ERROR frames that mean the token was not accepted
+var authErrorHints = []string{
+	"unauthorized", "unauthenticated", "forbidden", "access denied", "expired",
+	"invalid token", "jwt", "authentication", "401", "403",
+}
+
+// WithTokenProvider makes every connect and reconnect use the provider's current token instead of username
+func WithTokenProvider(provider TokenProvider) Option {
+	return func(c *Client) {
+		c.tokens = provider
+	}
+}
+
+// currentToken returns the token to connect with
+func (c *Client) currentToken() string {
+	if c.tokens != nil {
+		return c.tokens.GetAccessToken()
+	}
+	return c.username
+}
+
+// refreshToken asks the provider for a new token after the server rejected the current one
+func (c *Client) refreshToken(cause error) error {
+	if c.tokens == nil {
+		return cause
+	}
+
+	fmt.Printf("[STOMP] Server rejected the access token, refreshing: %v\n", cause)
+	if _, err := c.tokens.RefreshAccessToken(); err != nil {
+		fmt.Printf("[STOMP] ERROR: Token refresh failed: %v\n", err)
+		return fmt.Errorf("token refresh failed: %w", err)
+	}
+
+	c.mutex.Lock()
+	c.authRejected = false
+	c.mutex.Unlock()
+
+	fmt.Println("[STOMP] ✓ Access token refreshed")
+	return nil
+}
+
+// connectWithRefresh connects, refreshing the token and retrying once if the server rejects it
+func (c *Client) connectWithRefresh() error {
+	err := c.connect()
+	if err == nil || c.tokens == nil || !isAuthError(err) {
+		return err
+	}
+	if refreshErr := c.refreshToken(err); refreshErr != nil {
+		return refreshErr
+	}
+	return c.connect()
+}
+
+// isAuthError reports whether an error means the server rejected the access token
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrAuthRejected) {
+		return true
+	}
+
+	var message string
+	var frameErr stomp.Error
+	var frameErrPtr *stomp.Error
+	switch {
+	case errors.As(err, &frameErr):
+		message = frameErr.Message
+		if frameErr.Frame != nil {
+			message += " " + string(frameErr.Frame.Body)
+		}
+	case errors.As(err, &frameErrPtr):
+		message = frameErrPtr.Message
+		if frameErrPtr.Frame != nil {
+			message += " " + string(frameErrPtr.Frame.Body)
+		}
+	default:
+		return false
+	}
+
+	message = strings.ToLower(message)
+	for _, hint := range authErrorHints {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAuthStatus reports whether an HTTP status means the credentials were refused
+func isAuthStatus(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
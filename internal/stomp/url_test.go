@@ -4,28 +4,29 @@ import (
 	"testing"
 )
 
-func TestNormalizeURL(t *testing.T) {
+func TestResolveEndpoint(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
+		sockJS   bool
 	}{
-		{"http://localhost:8080", "ws://localhost:8080/ws/999/bbapp/websocket"}, // auto-append /ws then SockJS
-		{"https://localhost:8080", "wss://localhost:8080/ws/999/bbapp/websocket"},
-		{"http://localhost:8080/", "ws://localhost:8080/ws/999/bbapp/websocket"},
-		{"http://localhost:8080/backend", "ws://localhost:8080/backend"},         // path preserved
-		{"ws://localhost:8080", "ws://localhost:8080/ws/999/bbapp/websocket"},    // normalized to SockJS
-		{"ws://localhost:8080/ws", "ws://localhost:8080/ws/999/bbapp/websocket"}, // SockJS expansion
-		{"wss://localhost:8080/ws/websocket", "wss://localhost:8080/ws/websocket"},
+		{"http://localhost:8080", "http://localhost:8080/ws", true}, // auto-append /ws, SockJS base
+		{"https://localhost:8080", "https://localhost:8080/ws", true},
+		{"http://localhost:8080/", "http://localhost:8080/ws", true},
+		{"http://localhost:8080/backend", "ws://localhost:8080/backend", false}, // path preserved, raw WebSocket
+		{"ws://localhost:8080", "http://localhost:8080/ws", true},               // SockJS probes over HTTP
+		{"wss://localhost:8080/ws", "https://localhost:8080/ws", true},
+		{"wss://localhost:8080/ws/websocket", "wss://localhost:8080/ws/websocket", false},
 	}
 
 	for _, tt := range tests {
-		got, err := normalizeURL(tt.input)
+		got, sockJS, err := resolveEndpoint(tt.input)
 		if err != nil {
-			t.Errorf("normalizeURL(%q) returned error: %v", tt.input, err)
+			t.Errorf("resolveEndpoint(%q) returned error: %v", tt.input, err)
 			continue
 		}
-		if got != tt.expected {
-			t.Errorf("normalizeURL(%q) = %q, want %q", tt.input, got, tt.expected)
+		if got.String() != tt.expected || sockJS != tt.sockJS {
+			t.Errorf("resolveEndpoint(%q) = %q, %v, want %q, %v", tt.input, got, sockJS, tt.expected, tt.sockJS)
 		}
 	}
 }
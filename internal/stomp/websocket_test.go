@@ -0,0 +1,139 @@
+package stomp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newWebSocketBroker serves raw STOMP over WebSocket, answering CONNECT with the given heart-beat header
+// and DISCONNECT with a RECEIPT when receipts is set
+func newWebSocketBroker(t *testing.T, heartBeat string, receipts bool) string {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"v12.stomp"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			for _, f := range strings.Split(string(data), "\x00") {
+				lines := strings.Split(strings.TrimLeft(f, "\r\n"), "\n")
+				switch lines[0] {
+				case "CONNECT", "STOMP":
+					conn.WriteMessage(websocket.TextMessage, []byte("CONNECTED\nversion:1.2\nheart-beat:"+heartBeat+"\n\n\x00"))
+				case "DISCONNECT":
+					if !receipts {
+						continue
+					}
+					for _, line := range lines[1:] {
+						if receipt, ok := strings.CutPrefix(line, "receipt:"); ok {
+							conn.WriteMessage(websocket.TextMessage, []byte("RECEIPT\nreceipt-id:"+receipt+"\n\n\x00"))
+						}
+					}
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/stomp"
+}
+
+func TestWebSocket_NoServerHeartBeatDisablesReadTimeout(t *testing.T) {
+	previous := wsHandshakeTimeout
+	wsHandshakeTimeout = 100 * time.Millisecond
+	defer func() { wsHandshakeTimeout = previous }()
+
+	url := newWebSocketBroker(t, "0,0", true)
+
+	client, err := NewClient(url, "", "", WithHeartBeat(0, 0))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	// Idle for longer than the handshake deadline: the server promised no heart-beats
+	time.Sleep(300 * time.Millisecond)
+	if !client.IsHealthy() {
+		t.Fatal("Expected an idle connection without heart-beats to stay healthy")
+	}
+	if err := client.Publish("/app/test", map[string]string{"ok": "yes"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+}
+
+func TestWebSocket_MissedServerHeartBeatsTimeOut(t *testing.T) {
+	url := newWebSocketBroker(t, "50,0", true)
+
+	states := make(chan StateEvent, 16)
+	client, err := NewClient(url, "", "", WithHeartBeat(0, 50*time.Millisecond),
+		WithStateHandler(func(e StateEvent) { states <- e }))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	// The server never sends the heart-beats it promised
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-states:
+			if e.State == StateReconnecting {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Expected missed heart-beats to drop the connection")
+		}
+	}
+}
+
+func TestWebSocket_DisconnectContextStopsWaitingForReceipt(t *testing.T) {
+	url := newWebSocketBroker(t, "0,0", false)
+
+	client, err := NewClient(url, "", "", WithHeartBeat(0, 0))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := client.DisconnectContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected DisconnectContext to return at the deadline, took %v", elapsed)
+	}
+	if err := client.PublishContext(ctx, "/app/test", map[string]string{"ok": "yes"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected PublishContext to skip once ctx is done, got %v", err)
+	}
+}
+
+func TestServerHeartBeat(t *testing.T) {
+	tests := []struct {
+		header string
+		recv   time.Duration
+		want   time.Duration
+	}{
+		{"0,0", 10 * time.Second, 0},
+		{"5000,0", 10 * time.Second, 10 * time.Second},
+		{"20000,0", 10 * time.Second, 20 * time.Second},
+		{"20000,0", 0, 0},
+		{"", 10 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := serverHeartBeat(tt.header, tt.recv); got != tt.want {
+			t.Errorf("serverHeartBeat(%q, %v) = %v, want %v", tt.header, tt.recv, got, tt.want)
+		}
+	}
+}